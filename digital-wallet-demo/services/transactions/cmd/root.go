@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
 	"github.com/go-playground/validator/v10"
@@ -66,6 +67,18 @@ func initConfig() {
 	cfg = model.Config{
 		APIServer:     model.Server{Enable: true, Port: 8082},
 		SwaggerServer: model.Server{Enable: false, Port: 1314},
+		MetricsServer: model.Server{Enable: false, Port: 9092},
+		Tracing: model.Tracing{
+			Exporter:    "stdout",
+			SampleRatio: 1,
+		},
+		Idempotency: model.Idempotency{
+			LeaseTimeout: time.Minute,
+		},
+		Health: model.Health{
+			Timeout:    2 * time.Second,
+			DrainDelay: 5 * time.Second,
+		},
 	}
 
 	err := viper.Unmarshal(&cfg)
@@ -6,10 +6,12 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/server"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/tracing"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -31,6 +33,11 @@ var serverCmd = &cobra.Command{
 func runServe(cfg model.Config) error {
 	var servers []server.Server
 
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		return err
+	}
+
 	apiOpts := server.TxnAPIServerOpts{
 		ListenPort: cfg.APIServer.Port,
 		Config:     cfg,
@@ -49,7 +56,14 @@ func runServe(cfg model.Config) error {
 		servers = append(servers, swagServer)
 	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if cfg.MetricsServer.Enable {
+		metricsOpts := server.MetricsServerOpts{
+			ListenPort: cfg.MetricsServer.Port,
+		}
+		servers = append(servers, server.NewMetrics(metricsOpts))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
 	for _, s := range servers {
@@ -65,6 +79,15 @@ func runServe(cfg model.Config) error {
 	// Wait for interrupt signal to gracefully shutdown the server with a timeout of 10 seconds.
 	<-ctx.Done()
 	log.Info("server shutting down")
+
+	// Report not ready first, and keep serving until load balancers stop routing here
+	for _, s := range servers {
+		if d, ok := s.(server.Drainer); ok {
+			d.Drain()
+		}
+	}
+	time.Sleep(cfg.Health.DrainDelay)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -73,6 +96,10 @@ func runServe(cfg model.Config) error {
 			return err
 		}
 	}
+	// Flush the spans of the requests served until shutdown
+	if err := shutdownTracing(ctx); err != nil {
+		log.WithError(err).Error("failed to flush spans")
+	}
 	log.Info("server shutdown gracefully")
 	return nil
 }
@@ -19,18 +19,369 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
+        "/health/live": {
             "get": {
+                "description": "Reports that the process serves requests, without checking its dependencies.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "health"
                 ],
-                "summary": "Health check",
+                "summary": "Liveness check",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.HealthStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Checks the dependencies of the instance. Returns 503 while a hard dependency is down or the instance is draining for shutdown.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.HealthStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.HealthStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger/balances": {
+            "get": {
+                "description": "Returns the sum of completed credits and debits per wallet and currency, used to reconcile wallet balances against the ledger",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get ledger balances",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Restrict to a single wallet",
+                        "name": "subject_wallet_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.LedgerBalance"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger/legacy-transactions": {
+            "get": {
+                "description": "Returns the public IDs of transactions recorded before public IDs, by the serial IDs they were known by. The wallet service replaces the serial IDs it stored with them. Unknown IDs are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Look up legacy transaction IDs",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Serial ID of a legacy transaction, up to 100",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.LegacyTransactionID"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger/reviews/{review_id}": {
+            "patch": {
+                "description": "Sets both transactions of the pair held for the wallet service review to completed (approved) or cancelled (rejected). Repeating the same status is accepted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Settle the pending pair of a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "review_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.ReviewStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Transaction"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger/transactions/{id}": {
+            "get": {
+                "description": "Returns a single transaction by ID, used to reverse it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get a transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "post": {
+                "description": "Records both transactions with the same pair_id, generated unless given in the request",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Create a transaction pair (debit and credit)",
+                "parameters": [
+                    {
+                        "description": "Transaction pair request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.TransactionPairRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -46,12 +397,87 @@ const docTemplate = `{
                                 }
                             ]
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
                     }
                 }
             }
         },
-        "/transactions": {
-            "post": {
+        "/transactions/pairs/{pair_id}": {
+            "get": {
+                "description": "Returns the debit and credit transactions sharing a pair ID, debit first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get a transaction pair",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pair ID",
+                        "name": "pair_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Transaction"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/status": {
+            "patch": {
+                "description": "Moves the transaction and the other leg of its pair from pending to completed, failed or cancelled in one database transaction, and records the change in the status history. Completed, failed and cancelled are terminal. Repeating the current status is accepted. Pairs held for review are refused; they are settled through /ledger/reviews. Only the wallets service may call this endpoint.",
                 "consumes": [
                     "application/json"
                 ],
@@ -61,21 +487,28 @@ const docTemplate = `{
                 "tags": [
                     "transactions"
                 ],
-                "summary": "Create a transaction pair (debit and credit)",
+                "summary": "Update the status of a transaction pair",
                 "parameters": [
                     {
-                        "description": "Transaction pair request",
+                        "type": "string",
+                        "description": "Transaction ID of either leg",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.TransactionPairRequest"
+                            "$ref": "#/definitions/controller.TransactionStatusRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -85,7 +518,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Transaction"
+                                            }
                                         }
                                     }
                                 }
@@ -98,6 +534,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ResponseError"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -109,6 +557,7 @@ const docTemplate = `{
         },
         "/transactions/{subject_wallet_id}": {
             "get": {
+                "description": "Returns the wallet's transactions newest first, one page at a time. Pass meta.next_cursor as cursor to fetch the next page.",
                 "produces": [
                     "application/json"
                 ],
@@ -123,6 +572,82 @@ const docTemplate = `{
                         "name": "subject_wallet_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "deposit",
+                            "withdraw",
+                            "transfer",
+                            "adjustment"
+                        ],
+                        "type": "string",
+                        "description": "Filter by transaction type",
+                        "name": "transaction_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "debit",
+                            "credit"
+                        ],
+                        "type": "string",
+                        "description": "Filter by operation type",
+                        "name": "operation_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "completed",
+                            "failed",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by ISO-4217 currency code",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum amount in minor units (inclusive)",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum amount in minor units (inclusive)",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -141,6 +666,9 @@ const docTemplate = `{
                                             "items": {
                                                 "$ref": "#/definitions/model.Transaction"
                                             }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/controller.PageMeta"
                                         }
                                     }
                                 }
@@ -164,6 +692,23 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "controller.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "hard": {
+                    "type": "boolean"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
         "controller.Error": {
             "type": "object",
             "properties": {
@@ -175,11 +720,48 @@ const docTemplate = `{
                 }
             }
         },
+        "controller.HealthStatus": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/controller.DependencyStatus"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.PageMeta": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "description": "NextCursor is passed as the cursor query parameter to fetch the next page.\nIt is omitted on the last page.",
+                    "type": "string"
+                }
+            }
+        },
         "controller.ResponseData": {
             "type": "object",
             "properties": {
                 "data": {
                     "description": "Data is the response data."
+                },
+                "meta": {
+                    "description": "Meta is the pagination information of list responses.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.PageMeta"
+                        }
+                    ]
                 }
             }
         },
@@ -195,6 +777,25 @@ const docTemplate = `{
                 }
             }
         },
+        "controller.ReviewStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "enum": [
+                        "completed",
+                        "cancelled"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TransactionStatus"
+                        }
+                    ]
+                }
+            }
+        },
         "controller.TransactionPairRequest": {
             "type": "object",
             "required": [
@@ -207,6 +808,15 @@ const docTemplate = `{
                 },
                 "debit_transaction": {
                     "$ref": "#/definitions/controller.TransactionRequest"
+                },
+                "pair_id": {
+                    "description": "Generated when omitted",
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Recorded on both transactions",
+                    "type": "string",
+                    "maxLength": 128
                 }
             }
         },
@@ -224,12 +834,36 @@ const docTemplate = `{
                 "amount": {
                     "type": "integer"
                 },
+                "counter_amount": {
+                    "type": "integer"
+                },
+                "counter_currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "currency": {
+                    "description": "Defaults to USD",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
+                "fx_rate": {
+                    "type": "string"
+                },
                 "object_wallet_id": {
                     "type": "string"
                 },
                 "operation_type": {
                     "$ref": "#/definitions/model.OperationType"
                 },
+                "reversal_of": {
+                    "type": "string"
+                },
+                "review_id": {
+                    "type": "string",
+                    "maxLength": 32
+                },
                 "status": {
                     "$ref": "#/definitions/model.TransactionStatus"
                 },
@@ -241,6 +875,76 @@ const docTemplate = `{
                 }
             }
         },
+        "controller.TransactionStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "status": {
+                    "enum": [
+                        "completed",
+                        "failed",
+                        "cancelled"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TransactionStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "model.Currency": {
+            "type": "string",
+            "enum": [
+                "USD"
+            ],
+            "x-enum-varnames": [
+                "DefaultCurrency"
+            ]
+        },
+        "model.LedgerBalance": {
+            "type": "object",
+            "properties": {
+                "credits": {
+                    "description": "Sum of credit amounts in minor units",
+                    "type": "integer"
+                },
+                "currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "debits": {
+                    "description": "Sum of debit amounts in minor units",
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "integer"
+                },
+                "net": {
+                    "description": "Credits minus debits in minor units",
+                    "type": "integer"
+                },
+                "subject_wallet_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LegacyTransactionID": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "legacy_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "model.OperationType": {
             "type": "string",
             "enum": [
@@ -256,14 +960,34 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "amount": {
-                    "description": "Amount in cents",
+                    "description": "Amount in minor units of Currency",
+                    "type": "integer"
+                },
+                "counter_amount": {
+                    "description": "Amount of the other side of a conversion",
                     "type": "integer"
                 },
+                "counter_currency": {
+                    "description": "Currency of the other side of a conversion",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "fx_rate": {
+                    "description": "Price of one unit of the debited currency in the credited currency",
+                    "type": "string"
+                },
                 "id": {
-                    "type": "integer"
+                    "description": "UUIDv7 identifying the transaction in the API",
+                    "type": "string"
                 },
                 "object_wallet_id": {
                     "type": "string"
@@ -271,6 +995,22 @@ const docTemplate = `{
                 "operation_type": {
                     "$ref": "#/definitions/model.OperationType"
                 },
+                "pair_id": {
+                    "description": "UUID shared by the debit and credit of a pair",
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Client supplied reference of the pair",
+                    "type": "string"
+                },
+                "reversal_of": {
+                    "description": "Public ID of the transaction reversed by this one",
+                    "type": "string"
+                },
+                "review_id": {
+                    "description": "ID of the wallet service review deciding a pending pair",
+                    "type": "string"
+                },
                 "status": {
                     "$ref": "#/definitions/model.TransactionStatus"
                 },
@@ -305,12 +1045,14 @@ const docTemplate = `{
             "enum": [
                 "deposit",
                 "withdraw",
-                "transfer"
+                "transfer",
+                "adjustment"
             ],
             "x-enum-varnames": [
                 "Deposit",
                 "Withdraw",
-                "Transfer"
+                "Transfer",
+                "Adjustment"
             ]
         }
     }
@@ -0,0 +1,192 @@
+// Package auth authenticates the calls of other services to the API.
+//
+// A calling service signs every request with a secret shared with this
+// service. The signature covers the method, the request URI, the calling
+// service, a timestamp, a nonce and the SHA-256 of the body:
+//
+//	X-Service-ID:          wallets
+//	X-Signature-Timestamp: 1717171717
+//	X-Signature-Nonce:     4f1c0a6e2b9d4c1e8a7f3b5d6e9c0a12
+//	X-Signature:           hex(HMAC-SHA256(secret, METHOD\nURI\nSERVICE\nTIMESTAMP\nNONCE\nhex(SHA-256(body))))
+//
+// Requests older than the allowed clock skew are rejected, and a nonce is
+// accepted only once while its request is within the skew.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
+)
+
+const (
+	// HeaderServiceID is the request header naming the calling service.
+	HeaderServiceID = "X-Service-ID"
+	// HeaderTimestamp is the request header carrying the Unix time the request was signed at.
+	HeaderTimestamp = "X-Signature-Timestamp"
+	// HeaderNonce is the request header carrying the random value making a signature unique.
+	HeaderNonce = "X-Signature-Nonce"
+	// HeaderSignature is the request header carrying the hex encoded HMAC-SHA256 signature.
+	HeaderSignature = "X-Signature"
+
+	defaultMaxClockSkew = 5 * time.Minute
+	maxNonceLength      = 128
+)
+
+var (
+	// ErrUnsigned is returned for a request without signature headers.
+	ErrUnsigned = errors.New("request is not signed")
+	// ErrInvalidSignature is returned for a request from an unknown service, with
+	// a wrong signature, a timestamp outside the clock skew or a replayed nonce.
+	ErrInvalidSignature = errors.New("invalid request signature")
+)
+
+// Signed reports whether r carries a signature.
+func Signed(r *http.Request) bool {
+	return r.Header.Get(HeaderSignature) != "" || r.Header.Get(HeaderServiceID) != ""
+}
+
+// Sign sets the signature headers of r, whose body is body, for serviceID.
+func Sign(r *http.Request, body []byte, serviceID, secret string, now time.Time) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	r.Header.Set(HeaderServiceID, serviceID)
+	r.Header.Set(HeaderTimestamp, timestamp)
+	r.Header.Set(HeaderNonce, hex.EncodeToString(nonce))
+	r.Header.Set(HeaderSignature, signature(secret, r.Method, r.URL.RequestURI(), serviceID, timestamp, r.Header.Get(HeaderNonce), body))
+	return nil
+}
+
+// signature returns the hex encoded HMAC-SHA256 of the canonical form of a request.
+func signature(secret, method, uri, serviceID, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	canonical := strings.Join([]string{method, uri, serviceID, timestamp, nonce, hex.EncodeToString(bodyHash[:])}, "\n")
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verifier verifies the signatures of requests from the configured services.
+type Verifier struct {
+	secrets map[string]string // Shared secrets by service ID
+	skew    time.Duration
+	nonces  *nonceCache
+	now     func() time.Time
+}
+
+// NewVerifier returns a verifier for the services of cfg.
+func NewVerifier(cfg model.ServiceAuth) (*Verifier, error) {
+	if len(cfg.Services) == 0 {
+		return nil, errors.New("service authentication requires at least one service")
+	}
+	secrets := make(map[string]string, len(cfg.Services))
+	for _, s := range cfg.Services {
+		if s.ID == "" || s.Secret == "" {
+			return nil, errors.New("service credentials require an ID and a secret")
+		}
+		if _, ok := secrets[s.ID]; ok {
+			return nil, fmt.Errorf("duplicate credentials for service %q", s.ID)
+		}
+		secrets[s.ID] = s.Secret
+	}
+
+	skew := cfg.MaxClockSkew
+	if skew <= 0 {
+		skew = defaultMaxClockSkew
+	}
+	return &Verifier{
+		secrets: secrets,
+		skew:    skew,
+		nonces:  newNonceCache(),
+		now:     time.Now,
+	}, nil
+}
+
+// Verify checks the signature of r, whose body is body, and returns the calling service.
+func (v *Verifier) Verify(r *http.Request, body []byte) (string, error) {
+	if !Signed(r) {
+		return "", ErrUnsigned
+	}
+	serviceID := r.Header.Get(HeaderServiceID)
+	timestamp := r.Header.Get(HeaderTimestamp)
+	nonce := r.Header.Get(HeaderNonce)
+
+	secret, ok := v.secrets[serviceID]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown service %q", ErrInvalidSignature, serviceID)
+	}
+	if nonce == "" || len(nonce) > maxNonceLength {
+		return "", fmt.Errorf("%w: missing or oversized nonce", ErrInvalidSignature)
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed timestamp", ErrInvalidSignature)
+	}
+	now := v.now()
+	signedAt := time.Unix(unix, 0)
+	if signedAt.Before(now.Add(-v.skew)) || signedAt.After(now.Add(v.skew)) {
+		return "", fmt.Errorf("%w: timestamp outside the allowed clock skew", ErrInvalidSignature)
+	}
+
+	want := signature(secret, r.Method, r.URL.RequestURI(), serviceID, timestamp, nonce, body)
+	if !hmac.Equal([]byte(want), []byte(r.Header.Get(HeaderSignature))) {
+		return "", fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+	}
+
+	// Nonces are only remembered for valid signatures, so that unsigned
+	// requests cannot fill the cache. A request outside the skew is rejected
+	// above, so a nonce needs to be remembered until its request expires.
+	if !v.nonces.add(serviceID+":"+nonce, signedAt.Add(v.skew), now) {
+		return "", fmt.Errorf("%w: replayed nonce", ErrInvalidSignature)
+	}
+	return serviceID, nil
+}
+
+// nonceCache remembers the nonces of accepted requests until they expire.
+//
+// The cache is local to the process. Behind several replicas a replayed
+// request could reach a replica that has not seen its nonce; the writes of
+// the wallets service also carry idempotency keys, which guard against that.
+type nonceCache struct {
+	mu        sync.Mutex
+	expiries  map[string]time.Time
+	nextPrune time.Time
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{expiries: map[string]time.Time{}}
+}
+
+// add records nonce until expiresAt and reports whether it was not recorded yet.
+func (n *nonceCache) add(nonce string, expiresAt, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if now.After(n.nextPrune) {
+		for k, exp := range n.expiries {
+			if now.After(exp) {
+				delete(n.expiries, k)
+			}
+		}
+		n.nextPrune = now.Add(time.Minute)
+	}
+
+	if exp, ok := n.expiries[nonce]; ok && !now.After(exp) {
+		return false
+	}
+	n.expiries[nonce] = expiresAt
+	return true
+}
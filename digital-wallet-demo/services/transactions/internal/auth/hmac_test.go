@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	walletsSecret = "wallets-secret-0123456789abcdef0123"
+	otherSecret   = "other-secret-0123456789abcdef012345"
+)
+
+func testVerifier(t *testing.T, now time.Time) *Verifier {
+	v, err := NewVerifier(model.ServiceAuth{
+		MaxClockSkew: time.Minute,
+		Services: []model.ServiceCredential{
+			{ID: "wallets", Secret: walletsSecret},
+			{ID: "ops", Secret: otherSecret},
+		},
+	})
+	require.NoError(t, err)
+	v.now = func() time.Time { return now }
+	return v
+}
+
+func signedRequest(t *testing.T, body, serviceID, secret string, at time.Time) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions?source=relay", bytes.NewBufferString(body))
+	require.NoError(t, Sign(req, []byte(body), serviceID, secret, at))
+	return req
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	body := `{"pair_id":"pair-1"}`
+
+	tests := []struct {
+		name    string
+		req     func() (*http.Request, []byte)
+		want    string
+		wantErr error
+	}{
+		{
+			name: "valid",
+			req: func() (*http.Request, []byte) {
+				return signedRequest(t, body, "wallets", walletsSecret, now), []byte(body)
+			},
+			want: "wallets",
+		},
+		{
+			name: "within_skew",
+			req: func() (*http.Request, []byte) {
+				return signedRequest(t, body, "ops", otherSecret, now.Add(-50*time.Second)), []byte(body)
+			},
+			want: "ops",
+		},
+		{
+			name: "unsigned",
+			req: func() (*http.Request, []byte) {
+				return httptest.NewRequest(http.MethodPost, "/api/v1/transactions", nil), nil
+			},
+			wantErr: ErrUnsigned,
+		},
+		{
+			name: "unknown_service",
+			req: func() (*http.Request, []byte) {
+				return signedRequest(t, body, "reports", walletsSecret, now), []byte(body)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "wrong_secret",
+			req: func() (*http.Request, []byte) {
+				return signedRequest(t, body, "wallets", otherSecret, now), []byte(body)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "changed_body",
+			req: func() (*http.Request, []byte) {
+				return signedRequest(t, body, "wallets", walletsSecret, now), []byte(`{"pair_id":"pair-2"}`)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "changed_query",
+			req: func() (*http.Request, []byte) {
+				req := signedRequest(t, body, "wallets", walletsSecret, now)
+				req.URL.RawQuery = "source=other"
+				return req, []byte(body)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "changed_timestamp",
+			req: func() (*http.Request, []byte) {
+				req := signedRequest(t, body, "wallets", walletsSecret, now)
+				req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix()-1, 10))
+				return req, []byte(body)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "expired",
+			req: func() (*http.Request, []byte) {
+				return signedRequest(t, body, "wallets", walletsSecret, now.Add(-2*time.Minute)), []byte(body)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "from_the_future",
+			req: func() (*http.Request, []byte) {
+				return signedRequest(t, body, "wallets", walletsSecret, now.Add(2*time.Minute)), []byte(body)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, b := tt.req()
+			got, err := testVerifier(t, now).Verify(req, b)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVerifier_Replay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	v := testVerifier(t, now)
+	body := []byte(`{"pair_id":"pair-1"}`)
+	req := signedRequest(t, string(body), "wallets", walletsSecret, now)
+
+	_, err := v.Verify(req, body)
+	require.NoError(t, err)
+	_, err = v.Verify(req, body)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "replayed nonce")
+
+	// A new nonce of the same service is accepted
+	_, err = v.Verify(signedRequest(t, string(body), "wallets", walletsSecret, now), body)
+	assert.NoError(t, err)
+
+	// Once the request is outside the skew, it is rejected for its timestamp and its nonce is forgotten
+	v.now = func() time.Time { return now.Add(2 * time.Minute) }
+	_, err = v.Verify(req, body)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = v.Verify(signedRequest(t, string(body), "wallets", walletsSecret, now.Add(2*time.Minute)), body)
+	assert.NoError(t, err)
+	assert.Len(t, v.nonces.expiries, 1, "expired nonces are pruned")
+}
+
+func TestNewVerifier_Invalid(t *testing.T) {
+	for name, cfg := range map[string]model.ServiceAuth{
+		"no_services":    {},
+		"missing_secret": {Services: []model.ServiceCredential{{ID: "wallets"}}},
+		"duplicate": {Services: []model.ServiceCredential{
+			{ID: "wallets", Secret: walletsSecret},
+			{ID: "wallets", Secret: otherSecret},
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewVerifier(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
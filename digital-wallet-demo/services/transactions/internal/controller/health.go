@@ -1,34 +1,155 @@
 package controller
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
-// HealthHandler is the request handler for the health endpoint.
+// defaultCheckTimeout is the time a dependency has to answer a readiness check when none is set
+const defaultCheckTimeout = 2 * time.Second
+
+// Health statuses of the instance and of its dependencies
+const (
+	HealthOK          = "ok"
+	HealthDegraded    = "degraded"    // A soft dependency is down, the instance still serves traffic
+	HealthUnavailable = "unavailable" // A hard dependency is down
+	HealthDraining    = "draining"    // The instance is shutting down
+	DependencyUp      = "up"
+	DependencyDown    = "down"
+)
+
+// HealthHandler is the request handler for the health endpoints.
 type HealthHandler interface {
-	Health(c echo.Context) error
+	Live(c echo.Context) error
+	Ready(c echo.Context) error
+	// Drain makes the instance report not ready, so that load balancers stop routing to it before it shuts down
+	Drain()
 }
 
-type healthHandler struct{}
+// Dependency is a dependency checked by the readiness endpoint.
+// The instance is not ready while a Hard dependency is down.
+type Dependency struct {
+	Name    string
+	Hard    bool
+	Timeout time.Duration // Defaults to 2s
+	Ping    func(ctx context.Context) error
+}
+
+// HealthStatus is the response of the health endpoints.
+type HealthStatus struct {
+	Status       string                      `json:"status"`
+	Timestamp    time.Time                   `json:"timestamp"`
+	Dependencies map[string]DependencyStatus `json:"dependencies,omitempty"`
+}
 
-// NewHealth returns a new instance of the health handler.
-func NewHealth() HealthHandler {
-	return &healthHandler{}
+// DependencyStatus is the result of the check of a dependency.
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	Hard      bool   `json:"hard"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
 }
 
-// @Summary	Health check
+type healthHandler struct {
+	dependencies []Dependency
+	draining     atomic.Bool
+}
+
+// NewHealth returns a new instance of the health handler checking the given dependencies.
+func NewHealth(dependencies ...Dependency) HealthHandler {
+	return &healthHandler{dependencies: dependencies}
+}
+
+// @Summary	Liveness check
+// @Description	Reports that the process serves requests, without checking its dependencies.
 // @Tags		health
 // @Produce	json
-// @Success	200	{object}	ResponseData{data=time.Time}
-// @Router		/health [get]
-func (t *healthHandler) Health(c echo.Context) error {
+// @Success	200	{object}	ResponseData{data=HealthStatus}
+// @Router		/health/live [get]
+func (t *healthHandler) Live(c echo.Context) error {
 	return c.JSON(http.StatusOK, ResponseData{
-		Data: map[string]interface{}{
-			"status":    "ok",
-			"timestamp": time.Now(),
-		},
+		Data: HealthStatus{Status: HealthOK, Timestamp: time.Now()},
 	})
 }
+
+// @Summary	Readiness check
+// @Description	Checks the dependencies of the instance. Returns 503 while a hard dependency is down or the instance is draining for shutdown.
+// @Tags		health
+// @Produce	json
+// @Success	200	{object}	ResponseData{data=HealthStatus}
+// @Failure	503	{object}	ResponseData{data=HealthStatus}
+// @Router		/health/ready [get]
+func (t *healthHandler) Ready(c echo.Context) error {
+	if t.draining.Load() {
+		return c.JSON(http.StatusServiceUnavailable, ResponseData{
+			Data: HealthStatus{Status: HealthDraining, Timestamp: time.Now()},
+		})
+	}
+
+	health := HealthStatus{
+		Status:       HealthOK,
+		Timestamp:    time.Now(),
+		Dependencies: t.check(c.Request().Context()),
+	}
+	for _, dep := range health.Dependencies {
+		if dep.Status == DependencyUp {
+			continue
+		}
+		if dep.Hard {
+			health.Status = HealthUnavailable
+			break
+		}
+		health.Status = HealthDegraded
+	}
+
+	if health.Status == HealthUnavailable {
+		return c.JSON(http.StatusServiceUnavailable, ResponseData{Data: health})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: health})
+}
+
+func (t *healthHandler) Drain() {
+	t.draining.Store(true)
+}
+
+// check pings the dependencies concurrently, each within its timeout
+func (t *healthHandler) check(ctx context.Context) map[string]DependencyStatus {
+	statuses := make(map[string]DependencyStatus, len(t.dependencies))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, dep := range t.dependencies {
+		wg.Add(1)
+		go func(dep Dependency) {
+			defer wg.Done()
+			timeout := dep.Timeout
+			if timeout <= 0 {
+				timeout = defaultCheckTimeout
+			}
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := dep.Ping(ctx)
+			status := DependencyStatus{
+				Status:    DependencyUp,
+				Hard:      dep.Hard,
+				LatencyMS: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				status.Status = DependencyDown
+				status.Error = err.Error()
+			}
+
+			mu.Lock()
+			statuses[dep.Name] = status
+			mu.Unlock()
+		}(dep)
+	}
+	wg.Wait()
+	return statuses
+}
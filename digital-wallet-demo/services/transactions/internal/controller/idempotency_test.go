@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRequestHash(t *testing.T) {
+	base := idempotencyRequestHash(http.MethodPost, "/api/v1/transactions", []byte(`{"subjectWalletId":"u1","amount":100}`))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		same   bool
+	}{
+		{"Whitespace_and_key_order_ignored", http.MethodPost, "/api/v1/transactions", "{ \"amount\": 100,\n \"subjectWalletId\": \"u1\" }", true},
+		{"Different_body", http.MethodPost, "/api/v1/transactions", `{"subjectWalletId":"u1","amount":200}`, false},
+		{"Different_route", http.MethodPost, "/api/v1/transactions/other", `{"subjectWalletId":"u1","amount":100}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := idempotencyRequestHash(tt.method, tt.path, []byte(tt.body))
+			assert.Equal(t, tt.same, got == base)
+		})
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	dbInstance.Where("1 = 1").Delete(&model.IdempotencyRecord{})
+
+	calls := 0
+	e := echo.New()
+	e.POST("/transactions", func(c echo.Context) error {
+		calls++
+		if strings.Contains(c.Request().Header.Get("X-Test"), "fail") {
+			return c.JSON(http.StatusInternalServerError, ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError}}})
+		}
+		return c.JSON(http.StatusOK, ResponseData{Data: calls})
+	}, Idempotency(repository.NewIdempotencyRepository(dbInstance), time.Minute))
+
+	do := func(key, body, testHeader string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		req.Header.Set("X-Test", testHeader)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Without_key_every_request_is_executed", func(t *testing.T) {
+		calls = 0
+		do("", `{"amount":100}`, "")
+		do("", `{"amount":100}`, "")
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Retry_with_same_key_and_body_is_replayed", func(t *testing.T) {
+		calls = 0
+		first := do("key-replay", `{"amount":100}`, "")
+		second := do("key-replay", `{"amount": 100}`, "")
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.JSONEq(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "true", second.Header().Get(HeaderIdempotentReplayed))
+		assert.Empty(t, first.Header().Get(HeaderIdempotentReplayed))
+	})
+
+	t.Run("Same_key_with_different_body_is_rejected", func(t *testing.T) {
+		calls = 0
+		do("key-reused", `{"amount":100}`, "")
+		rec := do("key-reused", `{"amount":200}`, "")
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), errors.CodeIdempotencyKeyReused)
+	})
+
+	t.Run("Server_errors_are_not_stored", func(t *testing.T) {
+		calls = 0
+		first := do("key-failed", `{"amount":100}`, "fail")
+		second := do("key-failed", `{"amount":100}`, "")
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusInternalServerError, first.Code)
+		assert.Equal(t, http.StatusOK, second.Code)
+	})
+
+	t.Run("Stale_reservations_are_taken_over", func(t *testing.T) {
+		// A request that stopped without storing its response, leased until the past or the future
+		hash := idempotencyRequestHash(http.MethodPost, "/transactions", []byte(`{"amount":100}`))
+		for key, lockedUntil := range map[string]time.Time{"key-stale": time.Now().Add(-time.Second), "key-running": time.Now().Add(time.Minute)} {
+			require.NoError(t, dbInstance.Create(&model.IdempotencyRecord{Key: key, RequestHash: hash, Status: model.IdempotencyInProgress, LockedUntil: lockedUntil}).Error)
+		}
+
+		calls = 0
+		stale := do("key-stale", `{"amount":100}`, "")
+		running := do("key-running", `{"amount":100}`, "")
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusOK, stale.Code)
+		assert.Equal(t, http.StatusConflict, running.Code)
+		assert.Contains(t, running.Body.String(), errors.CodeRequestInProgress)
+
+		// The response of the request that took the key over is stored
+		replayed := do("key-stale", `{"amount":100}`, "")
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "true", replayed.Header().Get(HeaderIdempotentReplayed))
+	})
+}
@@ -4,6 +4,16 @@ package controller
 type ResponseData struct {
 	// Data is the response data.
 	Data interface{} `json:"data,omitempty"`
+	// Meta is the pagination information of list responses.
+	Meta *PageMeta `json:"meta,omitempty"`
+}
+
+// PageMeta is the pagination information of a list response.
+type PageMeta struct {
+	Limit int `json:"limit"`
+	// NextCursor is passed as the cursor query parameter to fetch the next page.
+	// It is omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // ResponseError is the response structure for the application.
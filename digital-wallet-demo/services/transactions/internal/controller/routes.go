@@ -4,10 +4,22 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func InitRoutes(api *echo.Group, controller TransactionHandler) {
-	transactions := api.Group("/transactions")
+func InitRoutes(api *echo.Group, controller TransactionHandler, idempotency, authenticate echo.MiddlewareFunc) {
+	walletsOnly := RequireService(WalletsServiceID)
+
+	transactions := api.Group("/transactions", authenticate)
 	{
-		transactions.POST("", controller.CreateTransactionPair)
+		transactions.POST("", controller.CreateTransactionPair, walletsOnly, idempotency)
+		transactions.GET("/pairs/:pair_id", controller.GetTransactionPair)
 		transactions.GET("/:subject_wallet_id", controller.GetTransactions)
+		transactions.PATCH("/:id/status", controller.UpdateTransactionStatus, walletsOnly, idempotency)
+	}
+
+	ledger := api.Group("/ledger", authenticate)
+	{
+		ledger.GET("/balances", controller.GetLedgerBalances)
+		ledger.GET("/transactions/:id", controller.GetTransaction)
+		ledger.GET("/legacy-transactions", controller.GetLegacyTransactionIDs)
+		ledger.PATCH("/reviews/:review_id", controller.SetReviewStatus, walletsOnly, idempotency)
 	}
 }
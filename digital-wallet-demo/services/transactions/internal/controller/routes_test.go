@@ -1,11 +1,15 @@
 package controller
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/auth"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/repository"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/service"
 	"github.com/labstack/echo/v4"
@@ -30,9 +34,15 @@ func TestRegister(t *testing.T) {
 		target       string
 		expectedCode int
 	}{
-		{"Health_Check", http.MethodGet, "/api/v1/health", http.StatusOK},
+		{"Liveness_Check", http.MethodGet, "/api/v1/health/live", http.StatusOK},
+		{"Readiness_Check", http.MethodGet, "/api/v1/health/ready", http.StatusOK},
 		{"Create_Transaction_without_body", http.MethodPost, "/api/v1/transactions", http.StatusBadRequest},          // Assuming no body is sent, should return BadRequest
 		{"Get_non-existent_Transactions", http.MethodGet, "/api/v1/transactions/non-existent-wallet", http.StatusOK}, // Should return empty array
+		{"Update_Transaction_Status_without_body", http.MethodPatch, "/api/v1/transactions/01890a5d-ac96-774b-bcce-b302099a8057/status", http.StatusBadRequest},
+		{"Get_non-existent_Transaction_Pair", http.MethodGet, "/api/v1/transactions/pairs/5b0e7c2a-9d4f-4e61-8a3b-1f6c9d2e7a40", http.StatusNotFound},
+		{"Get_Ledger_Balances", http.MethodGet, "/api/v1/ledger/balances", http.StatusOK},
+		{"Get_non-existent_Transaction", http.MethodGet, "/api/v1/ledger/transactions/01890a5d-ac96-774b-bcce-b302099a8057", http.StatusNotFound},
+		{"Settle_Review_without_body", http.MethodPatch, "/api/v1/ledger/reviews/non-existent-review", http.StatusBadRequest},
 	}
 
 	for _, tt := range tests {
@@ -45,6 +55,65 @@ func TestRegister(t *testing.T) {
 	}
 }
 
+// stubTransactionHandler answers every transaction endpoint with 204 No Content
+type stubTransactionHandler struct{}
+
+func (stubTransactionHandler) noContent(c echo.Context) error {
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h stubTransactionHandler) CreateTransactionPair(c echo.Context) error   { return h.noContent(c) }
+func (h stubTransactionHandler) GetTransactions(c echo.Context) error         { return h.noContent(c) }
+func (h stubTransactionHandler) GetLedgerBalances(c echo.Context) error       { return h.noContent(c) }
+func (h stubTransactionHandler) GetTransaction(c echo.Context) error          { return h.noContent(c) }
+func (h stubTransactionHandler) GetTransactionPair(c echo.Context) error      { return h.noContent(c) }
+func (h stubTransactionHandler) GetLegacyTransactionIDs(c echo.Context) error { return h.noContent(c) }
+func (h stubTransactionHandler) SetReviewStatus(c echo.Context) error         { return h.noContent(c) }
+func (h stubTransactionHandler) UpdateTransactionStatus(c echo.Context) error { return h.noContent(c) }
+
+func TestInitRoutes_WalletsOnly(t *testing.T) {
+	verifier, err := auth.NewVerifier(model.ServiceAuth{Services: []model.ServiceCredential{
+		{ID: WalletsServiceID, Secret: testWalletsSecret},
+		{ID: "ops", Secret: testOpsSecret},
+	}})
+	require.NoError(t, err)
+
+	e := echo.New()
+	noIdempotency := func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	InitRoutes(e.Group("/api/v1"), stubTransactionHandler{}, noIdempotency, Authenticate(verifier))
+
+	body := []byte(`{"status":"completed"}`)
+	writes := []struct {
+		method string
+		target string
+	}{
+		{http.MethodPost, "/api/v1/transactions"},
+		{http.MethodPatch, "/api/v1/transactions/01890a5d-ac96-774b-bcce-b302099a8057/status"},
+		{http.MethodPatch, "/api/v1/ledger/reviews/review-001"},
+	}
+	callers := []struct {
+		serviceID string
+		secret    string
+		want      int
+	}{
+		{WalletsServiceID, testWalletsSecret, http.StatusNoContent},
+		{"ops", testOpsSecret, http.StatusForbidden},
+	}
+	for _, w := range writes {
+		for _, caller := range callers {
+			t.Run(w.method+" "+w.target+" by "+caller.serviceID, func(t *testing.T) {
+				req := httptest.NewRequest(w.method, w.target, bytes.NewReader(body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				require.NoError(t, auth.Sign(req, body, caller.serviceID, caller.secret, time.Now()))
+
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				assert.Equal(t, caller.want, rec.Code, rec.Body.String())
+			})
+		}
+	}
+}
+
 // setupTestRoutes configures routes for testing with the same pattern as the server
 func setupTestRoutes(e *echo.Echo, db *gorm.DB) {
 	// Set up request validation
@@ -53,9 +122,11 @@ func setupTestRoutes(e *echo.Echo, db *gorm.DB) {
 	// Create API version group
 	api := e.Group("/api/v1")
 
-	// Register health check endpoint
-	healthHandler := NewHealth()
-	api.GET("/health", healthHandler.Health)
+	// Register health check endpoints
+	sqlDB, _ := db.DB()
+	healthHandler := NewHealth(Dependency{Name: "postgres", Hard: true, Ping: sqlDB.PingContext})
+	api.GET("/health/live", healthHandler.Live)
+	api.GET("/health/ready", healthHandler.Ready)
 
 	// Initialize transaction handler with dependencies
 	transactionRepo := repository.NewTransactionRepository(db)
@@ -63,5 +134,5 @@ func setupTestRoutes(e *echo.Echo, db *gorm.DB) {
 	transactionHandler := NewTransactionHandler(transactionService)
 
 	// Register transaction routes
-	InitRoutes(api, transactionHandler)
+	InitRoutes(api, transactionHandler, Idempotency(repository.NewIdempotencyRepository(db), time.Minute), Authenticate(nil))
 }
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/auth"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/errors"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// WalletsServiceID is the identity of the wallets service, the only service recording transaction pairs.
+	WalletsServiceID = "wallets"
+
+	// serviceKey is the context key of the authenticated calling service.
+	serviceKey = "service"
+)
+
+// Authenticate returns a middleware that verifies the signature of requests
+// from other services and stores the calling service in the context.
+//
+// Signed requests with an invalid signature are rejected, as are unsigned
+// writes. Unsigned reads are passed through. Without a verifier every request
+// is passed through unauthenticated and every service check passes.
+func Authenticate(v *auth.Verifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if v == nil {
+			return next
+		}
+		return func(c echo.Context) error {
+			req := c.Request()
+			if !auth.Signed(req) {
+				if isRead(req.Method) {
+					return next(c)
+				}
+				return unauthorized(c, "Missing request signature")
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest,
+					ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			serviceID, err := v.Verify(req, body)
+			if err != nil {
+				return unauthorized(c, "Invalid request signature")
+			}
+			c.Set(serviceKey, serviceID)
+			return next(c)
+		}
+	}
+}
+
+// RequireService returns a middleware that only lets requests signed by serviceID through.
+func RequireService(serviceID string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// Writes are always signed when authentication is enabled
+			caller, ok := c.Get(serviceKey).(string)
+			if ok && caller != serviceID {
+				return c.JSON(http.StatusForbidden,
+					ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: "Calling service may not use this endpoint"}}})
+			}
+			return next(c)
+		}
+	}
+}
+
+func isRead(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func unauthorized(c echo.Context, message string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, "HMAC-SHA256")
+	return c.JSON(http.StatusUnauthorized,
+		ResponseError{Errors: []Error{{Code: errors.CodeUnauthorized, Message: message}}})
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/auth"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWalletsSecret = "wallets-secret-0123456789abcdef0123"
+	testOpsSecret     = "ops-secret-0123456789abcdef01234567"
+)
+
+func TestAuthenticate(t *testing.T) {
+	verifier, err := auth.NewVerifier(model.ServiceAuth{Services: []model.ServiceCredential{
+		{ID: WalletsServiceID, Secret: testWalletsSecret},
+		{ID: "ops", Secret: testOpsSecret},
+	}})
+	require.NoError(t, err)
+
+	e := echo.New()
+	echoBody := func(c echo.Context) error {
+		return c.Stream(http.StatusOK, echo.MIMEApplicationJSON, c.Request().Body)
+	}
+
+	api := e.Group("/api/v1", Authenticate(verifier))
+	api.POST("/transactions", echoBody, RequireService(WalletsServiceID))
+	api.PATCH("/transactions/:id/status", echoBody)
+	api.GET("/transactions/:subject_wallet_id", echoBody)
+
+	body := `{"status":"completed"}`
+	sign := func(req *http.Request, serviceID, secret string) *http.Request {
+		require.NoError(t, auth.Sign(req, []byte(body), serviceID, secret, time.Now()))
+		return req
+	}
+	newRequest := func(method, path string) *http.Request {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		return req
+	}
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"unsigned_read", newRequest(http.MethodGet, "/api/v1/transactions/wallet-1"), http.StatusOK},
+		{"unsigned_write", newRequest(http.MethodPatch, "/api/v1/transactions/1/status"), http.StatusUnauthorized},
+		{"unsigned_pair", newRequest(http.MethodPost, "/api/v1/transactions"), http.StatusUnauthorized},
+		{"wrong_secret", sign(newRequest(http.MethodPatch, "/api/v1/transactions/1/status"), "ops", testWalletsSecret), http.StatusUnauthorized},
+		{"signed_write", sign(newRequest(http.MethodPatch, "/api/v1/transactions/1/status"), "ops", testOpsSecret), http.StatusOK},
+		{"pair_from_wallets", sign(newRequest(http.MethodPost, "/api/v1/transactions"), WalletsServiceID, testWalletsSecret), http.StatusOK},
+		{"pair_from_other_service", sign(newRequest(http.MethodPost, "/api/v1/transactions"), "ops", testOpsSecret), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, tt.req)
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+			if tt.want == http.StatusOK {
+				assert.Equal(t, body, rec.Body.String(), "handler reads the verified body")
+			}
+		})
+	}
+
+	t.Run("replayed_request", func(t *testing.T) {
+		req := sign(newRequest(http.MethodPost, "/api/v1/transactions"), WalletsServiceID, testWalletsSecret)
+		replay := req.Clone(req.Context())
+		replay.Body = io.NopCloser(strings.NewReader(body))
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, replay)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestAuthenticate_Disabled(t *testing.T) {
+	e := echo.New()
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+	e.Group("/api/v1", Authenticate(nil)).POST("/transactions", ok, RequireService(WalletsServiceID))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/transactions", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/errors"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
@@ -13,6 +14,12 @@ import (
 type TransactionHandler interface {
 	CreateTransactionPair(c echo.Context) error
 	GetTransactions(c echo.Context) error
+	GetLedgerBalances(c echo.Context) error
+	GetTransaction(c echo.Context) error
+	GetTransactionPair(c echo.Context) error
+	GetLegacyTransactionIDs(c echo.Context) error
+	SetReviewStatus(c echo.Context) error
+	UpdateTransactionStatus(c echo.Context) error
 }
 
 type transactionHandler struct {
@@ -27,6 +34,8 @@ func NewTransactionHandler(s service.TransactionService) TransactionHandler {
 
 // TransactionPairRequest represents the request for creating a transaction pair
 type TransactionPairRequest struct {
+	PairID            string             `json:"pair_id,omitempty" validate:"omitempty,uuid"`      // Generated when omitted
+	Reference         string             `json:"reference,omitempty" validate:"omitempty,max=128"` // Recorded on both transactions
 	DebitTransaction  TransactionRequest `json:"debit_transaction" validate:"required"`
 	CreditTransaction TransactionRequest `json:"credit_transaction" validate:"required"`
 }
@@ -38,15 +47,87 @@ type TransactionRequest struct {
 	TransactionType model.TransactionType   `json:"transaction_type" validate:"required"`
 	OperationType   model.OperationType     `json:"operation_type" validate:"required"`
 	Amount          int64                   `json:"amount" validate:"required,gt=0"`
+	Currency        model.Currency          `json:"currency,omitempty" validate:"omitempty,validCurrency"` // Defaults to USD
+	FXRate          string                  `json:"fx_rate,omitempty" validate:"omitempty,numeric"`
+	CounterAmount   int64                   `json:"counter_amount,omitempty" validate:"omitempty,gt=0"`
+	CounterCurrency model.Currency          `json:"counter_currency,omitempty" validate:"omitempty,validCurrency"`
+	ReversalOf      *string                 `json:"reversal_of,omitempty" validate:"omitempty,uuid"`
+	ReviewID        *string                 `json:"review_id,omitempty" validate:"omitempty,max=32"`
 	Status          model.TransactionStatus `json:"status" validate:"required"`
 }
 
-// GetTransactionsRequest represents the request for getting transactions
+// toModel converts the request to a transaction, defaulting the currency to USD.
+func (r TransactionRequest) toModel() *model.Transaction {
+	currency := r.Currency
+	if currency == "" {
+		currency = model.DefaultCurrency
+	}
+	return &model.Transaction{
+		SubjectWalletID: r.SubjectWalletID,
+		ObjectWalletID:  r.ObjectWalletID,
+		TransactionType: r.TransactionType,
+		OperationType:   r.OperationType,
+		Amount:          r.Amount,
+		Currency:        currency,
+		FXRate:          r.FXRate,
+		CounterAmount:   r.CounterAmount,
+		CounterCurrency: r.CounterCurrency,
+		ReversalOf:      r.ReversalOf,
+		ReviewID:        r.ReviewID,
+		Status:          r.Status,
+	}
+}
+
+// GetTransactionsRequest represents the request for getting a page of transactions
 type GetTransactionsRequest struct {
-	SubjectWalletID string `param:"subject_wallet_id" validate:"required"`
+	SubjectWalletID string                  `param:"subject_wallet_id" validate:"required"`
+	Limit           int                     `query:"limit" validate:"omitempty,min=1,max=500"`
+	Cursor          string                  `query:"cursor"`
+	TransactionType model.TransactionType   `query:"transaction_type" validate:"validTransactionType"`
+	OperationType   model.OperationType     `query:"operation_type" validate:"validOperationType"`
+	Status          model.TransactionStatus `query:"status" validate:"validTransactionStatus"`
+	Currency        model.Currency          `query:"currency" validate:"validCurrency"`
+	MinAmount       *int64                  `query:"min_amount" validate:"omitempty,gte=0"`
+	MaxAmount       *int64                  `query:"max_amount" validate:"omitempty,gte=0"`
+	CreatedFrom     *time.Time              `query:"created_from"`
+	CreatedTo       *time.Time              `query:"created_to"`
+}
+
+// GetTransactionRequest represents the request for getting a single transaction
+type GetTransactionRequest struct {
+	ID string `param:"id" validate:"required,uuid"`
+}
+
+// GetTransactionPairRequest represents the request for getting both transactions of a pair
+type GetTransactionPairRequest struct {
+	PairID string `param:"pair_id" validate:"required,uuid"`
+}
+
+// LegacyTransactionIDsRequest represents the request for looking up the public IDs of legacy transactions
+type LegacyTransactionIDsRequest struct {
+	IDs []int64 `query:"id" validate:"required,min=1,max=100,dive,gt=0"`
+}
+
+// ReviewStatusRequest represents the request for settling the pending pair of a review
+type ReviewStatusRequest struct {
+	ReviewID string                  `param:"review_id" json:"-" validate:"required,max=32"`
+	Status   model.TransactionStatus `json:"status" validate:"required,oneof=completed cancelled"`
+}
+
+// TransactionStatusRequest represents the request for changing the status of a transaction pair
+type TransactionStatusRequest struct {
+	ID     string                  `param:"id" json:"-" validate:"required,uuid"`
+	Status model.TransactionStatus `json:"status" validate:"required,oneof=completed failed cancelled"`
+	Reason string                  `json:"reason,omitempty" validate:"omitempty,max=255"`
+}
+
+// GetLedgerBalancesRequest represents the request for getting ledger balances
+type GetLedgerBalancesRequest struct {
+	SubjectWalletID string `query:"subject_wallet_id"`
 }
 
 // @Summary	Create a transaction pair (debit and credit)
+// @Description	Records both transactions with the same pair_id, generated unless given in the request
 // @Tags		transactions
 // @Accept		json
 // @Produce	json
@@ -63,26 +144,21 @@ func (h *transactionHandler) CreateTransactionPair(c echo.Context) error {
 	}
 
 	// Convert request to model transactions
-	debitTxn := &model.Transaction{
-		SubjectWalletID: req.DebitTransaction.SubjectWalletID,
-		ObjectWalletID:  req.DebitTransaction.ObjectWalletID,
-		TransactionType: req.DebitTransaction.TransactionType,
-		OperationType:   req.DebitTransaction.OperationType,
-		Amount:          req.DebitTransaction.Amount,
-		Status:          req.DebitTransaction.Status,
-	}
+	debitTxn := req.DebitTransaction.toModel()
+	creditTxn := req.CreditTransaction.toModel()
 
-	creditTxn := &model.Transaction{
-		SubjectWalletID: req.CreditTransaction.SubjectWalletID,
-		ObjectWalletID:  req.CreditTransaction.ObjectWalletID,
-		TransactionType: req.CreditTransaction.TransactionType,
-		OperationType:   req.CreditTransaction.OperationType,
-		Amount:          req.CreditTransaction.Amount,
-		Status:          req.CreditTransaction.Status,
+	// Both sides of a pair must describe the same movement of money
+	if err := model.CheckPairCurrencies(debitTxn, creditTxn); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeCurrencyMismatch, Message: "debit and credit transactions must be in the same currency or record the same conversion"}}})
 	}
 
+	// Link both sides to the pair
+	debitTxn.PairID, creditTxn.PairID = req.PairID, req.PairID
+	debitTxn.Reference, creditTxn.Reference = req.Reference, req.Reference
+
 	// Create transaction pair
-	if err := h.service.CreateTransactionPair(debitTxn, creditTxn); err != nil {
+	if err := h.service.CreateTransactionPair(c.Request().Context(), debitTxn, creditTxn); err != nil {
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
@@ -91,10 +167,21 @@ func (h *transactionHandler) CreateTransactionPair(c echo.Context) error {
 }
 
 // @Summary	Get transactions for a wallet
+// @Description	Returns the wallet's transactions newest first, one page at a time. Pass meta.next_cursor as cursor to fetch the next page.
 // @Tags		transactions
 // @Produce	json
 // @Param		subject_wallet_id	path		string	true	"Subject Wallet ID"
-// @Success	200					{object}	ResponseData{data=[]model.Transaction}
+// @Param		limit				query		int		false	"Page size (default 50, max 500)"
+// @Param		cursor				query		string	false	"Cursor returned by the previous page"
+// @Param		transaction_type	query		string	false	"Filter by transaction type"	Enums(deposit, withdraw, transfer, adjustment)
+// @Param		operation_type		query		string	false	"Filter by operation type"		Enums(debit, credit)
+// @Param		status				query		string	false	"Filter by status"				Enums(pending, completed, failed, cancelled)
+// @Param		currency			query		string	false	"Filter by ISO-4217 currency code"
+// @Param		min_amount			query		int		false	"Minimum amount in minor units (inclusive)"
+// @Param		max_amount			query		int		false	"Maximum amount in minor units (inclusive)"
+// @Param		created_from		query		string	false	"Created at or after (RFC 3339)"
+// @Param		created_to			query		string	false	"Created before (RFC 3339)"
+// @Success	200					{object}	ResponseData{data=[]model.Transaction,meta=PageMeta}
 // @Failure	400					{object}	ResponseError
 // @Failure	500					{object}	ResponseError
 // @Router		/transactions/{subject_wallet_id} [get]
@@ -105,8 +192,220 @@ func (h *transactionHandler) GetTransactions(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	transactions, err := h.service.GetTransactions(req.SubjectWalletID)
+	query := model.TransactionQuery{
+		SubjectWalletID: req.SubjectWalletID,
+		TransactionType: req.TransactionType,
+		OperationType:   req.OperationType,
+		Status:          req.Status,
+		Currency:        req.Currency,
+		MinAmount:       req.MinAmount,
+		MaxAmount:       req.MaxAmount,
+		CreatedFrom:     req.CreatedFrom,
+		CreatedTo:       req.CreatedTo,
+		Limit:           req.Limit,
+	}
+	if req.Cursor != "" {
+		cursor, err := model.DecodeTransactionCursor(req.Cursor)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+		}
+		query.Cursor = cursor
+	}
+
+	page, err := h.service.GetTransactions(c.Request().Context(), query)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{
+		Data: page.Transactions,
+		Meta: &PageMeta{Limit: page.Limit, NextCursor: page.NextCursor},
+	})
+}
+
+// @Summary	Get ledger balances
+// @Description	Returns the sum of completed credits and debits per wallet and currency, used to reconcile wallet balances against the ledger
+// @Tags		transactions
+// @Produce	json
+// @Param		subject_wallet_id	query		string	false	"Restrict to a single wallet"
+// @Success	200					{object}	ResponseData{data=[]model.LedgerBalance}
+// @Failure	400					{object}	ResponseError
+// @Failure	500					{object}	ResponseError
+// @Router		/ledger/balances [get]
+func (h *transactionHandler) GetLedgerBalances(c echo.Context) error {
+	var req GetLedgerBalancesRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	balances, err := h.service.GetLedgerBalances(c.Request().Context(), req.SubjectWalletID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: balances})
+}
+
+// @Summary	Get a transaction
+// @Description	Returns a single transaction by ID, used to reverse it
+// @Tags		transactions
+// @Produce	json
+// @Param		id	path		string	true	"Transaction ID"
+// @Success	200	{object}	ResponseData{data=model.Transaction}
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/ledger/transactions/{id} [get]
+func (h *transactionHandler) GetTransaction(c echo.Context) error {
+	var req GetTransactionRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	transaction, err := h.service.GetTransaction(c.Request().Context(), req.ID)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "transaction not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: transaction})
+}
+
+// @Summary	Look up legacy transaction IDs
+// @Description	Returns the public IDs of transactions recorded before public IDs, by the serial IDs they were known by. The wallet service replaces the serial IDs it stored with them. Unknown IDs are left out.
+// @Tags		transactions
+// @Produce	json
+// @Param		id	query		[]int	true	"Serial ID of a legacy transaction, up to 100"	collectionFormat(multi)
+// @Success	200	{object}	ResponseData{data=[]model.LegacyTransactionID}
+// @Failure	400	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/ledger/legacy-transactions [get]
+func (h *transactionHandler) GetLegacyTransactionIDs(c echo.Context) error {
+	var req LegacyTransactionIDsRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	ids, err := h.service.GetLegacyIDs(c.Request().Context(), req.IDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: ids})
+}
+
+// @Summary	Get a transaction pair
+// @Description	Returns the debit and credit transactions sharing a pair ID, debit first
+// @Tags		transactions
+// @Produce	json
+// @Param		pair_id	path		string	true	"Pair ID"
+// @Success	200		{object}	ResponseData{data=[]model.Transaction}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/transactions/pairs/{pair_id} [get]
+func (h *transactionHandler) GetTransactionPair(c echo.Context) error {
+	var req GetTransactionPairRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	transactions, err := h.service.GetTransactionPair(c.Request().Context(), req.PairID)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "transaction pair not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: transactions})
+}
+
+// @Summary	Settle the pending pair of a review
+// @Description	Sets both transactions of the pair held for the wallet service review to completed (approved) or cancelled (rejected). Repeating the same status is accepted.
+// @Tags		transactions
+// @Accept		json
+// @Produce	json
+// @Param		review_id	path		string				true	"Review ID"
+// @Param		request		body		ReviewStatusRequest	true	"New status"
+// @Success	200			{object}	ResponseData{data=[]model.Transaction}
+// @Failure	400			{object}	ResponseError
+// @Failure	404			{object}	ResponseError
+// @Failure	409			{object}	ResponseError
+// @Failure	500			{object}	ResponseError
+// @Router		/ledger/reviews/{review_id} [patch]
+func (h *transactionHandler) SetReviewStatus(c echo.Context) error {
+	var req ReviewStatusRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	transactions, err := h.service.SetReviewStatus(c.Request().Context(), req.ReviewID, req.Status)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "no transactions for review"}}})
+		}
+		if err == model.ErrNotPending {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeTransactionNotPending, Message: "transactions of the review were already settled"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: transactions})
+}
+
+// @Summary	Update the status of a transaction pair
+// @Description	Moves the transaction and the other leg of its pair from pending to completed, failed or cancelled in one database transaction, and records the change in the status history. Completed, failed and cancelled are terminal. Repeating the current status is accepted. Pairs held for review are refused; they are settled through /ledger/reviews. Only the wallets service may call this endpoint.
+// @Tags		transactions
+// @Accept		json
+// @Produce	json
+// @Param		id		path		string						true	"Transaction ID of either leg"
+// @Param		request	body		TransactionStatusRequest	true	"New status"
+// @Success	200		{object}	ResponseData{data=[]model.Transaction}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/transactions/{id}/status [patch]
+func (h *transactionHandler) UpdateTransactionStatus(c echo.Context) error {
+	var req TransactionStatusRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	transactions, err := h.service.UpdatePairStatus(c.Request().Context(), req.ID, req.Status, req.Reason)
 	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "transaction not found"}}})
+		}
+		if err == model.ErrInvalidTransition {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeInvalidStatusTransition, Message: "transaction pair cannot move to status " + string(req.Status)}}})
+		}
+		if err == model.ErrReviewPair {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeReviewPair, Message: "transaction pair is held for review and is settled by the wallet service review"}}})
+		}
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
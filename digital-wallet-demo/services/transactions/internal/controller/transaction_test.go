@@ -2,8 +2,11 @@ package controller
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/db"
@@ -55,6 +58,60 @@ func TestTransactionHandler_CreateTransactionPair(t *testing.T) {
 				Response:   []byte(`{"data":"Transaction pair created successfully"}`),
 			},
 		},
+		{
+			name:       "successful_transaction_pair_in_other_currency",
+			createBody: `{"debit_transaction":{"subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"debit","amount":1000,"currency":"EUR","status":"completed"},"credit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":1000,"currency":"EUR","status":"completed"}}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":"Transaction pair created successfully"}`),
+			},
+		},
+		{
+			name:       "currency_mismatch",
+			createBody: `{"debit_transaction":{"subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"debit","amount":1000,"currency":"EUR","status":"completed"},"credit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":1000,"status":"completed"}}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+				Response:   []byte(`{"errors":[{"code":"CURRENCY_MISMATCH","message":"debit and credit transactions must be in the same currency or record the same conversion"}]}`),
+			},
+		},
+		{
+			name:       "successful_conversion_transaction_pair",
+			createBody: `{"debit_transaction":{"subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"debit","amount":1000,"currency":"USD","fx_rate":"0.92000000","counter_amount":920,"counter_currency":"EUR","status":"completed"},"credit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":920,"currency":"EUR","fx_rate":"0.92000000","counter_amount":1000,"counter_currency":"USD","status":"completed"}}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":"Transaction pair created successfully"}`),
+			},
+		},
+		{
+			name:       "successful_reversal_transaction_pair",
+			createBody: `{"debit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"debit","amount":400,"reversal_of":"01890a5d-ac96-774b-bcce-b302099a8057","status":"completed"},"credit_transaction":{"subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"credit","amount":400,"reversal_of":"01890a5d-ac96-774b-bcce-b302099a8057","status":"completed"}}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":"Transaction pair created successfully"}`),
+			},
+		},
+		{
+			name:       "successful_transaction_pair_with_pair_id_and_reference",
+			createBody: `{"pair_id":"0f8d3a5e-6b1c-4f2a-9e7d-2c4b6a8e0f13","reference":"invoice-42","debit_transaction":{"subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"debit","amount":1000,"status":"completed"},"credit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":1000,"status":"completed"}}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":"Transaction pair created successfully"}`),
+			},
+		},
+		{
+			name:       "invalid_pair_id",
+			createBody: `{"pair_id":"pair-1","debit_transaction":{"subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"debit","amount":1000,"status":"completed"},"credit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":1000,"status":"completed"}}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:       "unsupported_currency",
+			createBody: `{"debit_transaction":{"subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"debit","amount":1000,"currency":"XYZ","status":"completed"},"credit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":1000,"currency":"XYZ","status":"completed"}}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
 		{
 			name:       "missing_debit_transaction",
 			createBody: `{"credit_transaction":{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":1000,"status":"completed"}}`,
@@ -103,6 +160,20 @@ func TestTransactionHandler_CreateTransactionPair(t *testing.T) {
 			// Assert
 			assert.Equal(t, tt.want.StatusCode, rec.Code)
 
+			if rec.Code == http.StatusCreated {
+				// Both sides are linked by the same pair ID
+				var transactions []model.Transaction
+				require.NoError(t, dbInstance.Order("id").Find(&transactions).Error)
+				require.Len(t, transactions, 2)
+				assert.NotEmpty(t, transactions[0].PairID)
+				assert.Equal(t, transactions[0].PairID, transactions[1].PairID)
+				assert.Equal(t, transactions[0].Reference, transactions[1].Reference)
+
+				// Each side has its own public ID
+				assert.NotEmpty(t, transactions[0].PublicID)
+				assert.NotEqual(t, transactions[0].PublicID, transactions[1].PublicID)
+			}
+
 			if tt.want.Response == nil {
 				return
 			}
@@ -155,7 +226,7 @@ func TestTransactionHandler_GetTransactions(t *testing.T) {
 			subjectWalletID:  "user-002",
 			want: want{
 				StatusCode: http.StatusOK,
-				Response:   []byte(`{"data":[]}`),
+				Response:   []byte(`{"data":[],"meta":{"limit":50}}`),
 			},
 		},
 		{
@@ -208,6 +279,707 @@ func TestTransactionHandler_GetTransactions(t *testing.T) {
 	}
 }
 
+func TestTransactionHandler_GetTransactions_Pagination(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	repository := repository.NewTransactionRepository(dbInstance)
+	service := service.NewTransactionService(repository)
+	handler := NewTransactionHandler(service)
+
+	clearDB(dbInstance, model.Transaction{})
+	for _, amount := range []int64{100, 200, 300, 400, 500} {
+		createTestTransaction(t, dbInstance, "user-001", "deposit-provider-master", model.Deposit, model.Credit, amount)
+	}
+	createTestTransaction(t, dbInstance, "user-001", "user-002", model.Transfer, model.Debit, 250)
+	createTestTransaction(t, dbInstance, "user-002", "user-001", model.Transfer, model.Credit, 250)
+
+	type page struct {
+		Data []model.Transaction `json:"data"`
+		Meta PageMeta            `json:"meta"`
+	}
+	get := func(t *testing.T, query string) (int, page) {
+		req := httptest.NewRequest(http.MethodGet, "/transactions/user-001?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/transactions/:subject_wallet_id")
+		c.SetParamNames("subject_wallet_id")
+		c.SetParamValues("user-001")
+		require.NoError(t, handler.GetTransactions(c))
+
+		var p page
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+		}
+		return rec.Code, p
+	}
+	amounts := func(txns []model.Transaction) []int64 {
+		var out []int64
+		for _, txn := range txns {
+			out = append(out, txn.Amount)
+		}
+		return out
+	}
+
+	t.Run("walk_all_pages", func(t *testing.T) {
+		var got []int64
+		query := "limit=4"
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 3, "too many pages")
+			code, p := get(t, query)
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, 4, p.Meta.Limit)
+			got = append(got, amounts(p.Data)...)
+			if p.Meta.NextCursor == "" {
+				break
+			}
+			query = "limit=4&cursor=" + p.Meta.NextCursor
+		}
+		assert.Equal(t, []int64{250, 500, 400, 300, 200, 100}, got)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		tests := []struct {
+			query string
+			want  []int64
+		}{
+			{"transaction_type=deposit&min_amount=200&max_amount=400", []int64{400, 300, 200}},
+			{"operation_type=debit", []int64{250}},
+			{"status=pending", nil},
+			{"created_from=2000-01-01T00:00:00Z&created_to=2000-01-02T00:00:00Z", nil},
+		}
+		for _, tt := range tests {
+			code, p := get(t, tt.query)
+			require.Equal(t, http.StatusOK, code, tt.query)
+			assert.Equal(t, tt.want, amounts(p.Data), tt.query)
+			assert.Empty(t, p.Meta.NextCursor, tt.query)
+		}
+	})
+
+	t.Run("invalid_parameters", func(t *testing.T) {
+		for _, query := range []string{"cursor=not-a-cursor", "limit=501", "transaction_type=refund", "min_amount=-1", "created_from=yesterday", "currency=XYZ"} {
+			code, _ := get(t, query)
+			assert.Equal(t, http.StatusBadRequest, code, query)
+		}
+	})
+}
+
+func TestTransactionHandler_GetLedgerBalances(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	repository := repository.NewTransactionRepository(dbInstance)
+	service := service.NewTransactionService(repository)
+	handler := NewTransactionHandler(service)
+
+	tests := []struct {
+		name            string
+		subjectWalletID string
+		want            want
+	}{
+		{
+			name: "all_wallets",
+			want: want{
+				StatusCode: http.StatusOK,
+				Response: []byte(`{"data":[
+					{"subject_wallet_id":"deposit-provider-master","currency":"USD","credits":0,"debits":5000,"net":-5000,"entries":1},
+					{"subject_wallet_id":"user-001","currency":"EUR","credits":0,"debits":300,"net":-300,"entries":1},
+					{"subject_wallet_id":"user-001","currency":"USD","credits":5000,"debits":1000,"net":4000,"entries":2},
+					{"subject_wallet_id":"user-002","currency":"EUR","credits":300,"debits":0,"net":300,"entries":1},
+					{"subject_wallet_id":"user-002","currency":"USD","credits":1000,"debits":0,"net":1000,"entries":1}
+				]}`),
+			},
+		},
+		{
+			name:            "single_wallet",
+			subjectWalletID: "user-001",
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":[{"subject_wallet_id":"user-001","currency":"EUR","credits":0,"debits":300,"net":-300,"entries":1},{"subject_wallet_id":"user-001","currency":"USD","credits":5000,"debits":1000,"net":4000,"entries":2}]}`),
+			},
+		},
+		{
+			name:            "unknown_wallet",
+			subjectWalletID: "user-999",
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":[]}`),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean database before each test
+			clearDB(dbInstance, model.Transaction{})
+
+			createTestTransaction(t, dbInstance, "deposit-provider-master", "user-001", model.Deposit, model.Debit, 5000)
+			createTestTransaction(t, dbInstance, "user-001", "deposit-provider-master", model.Deposit, model.Credit, 5000)
+			createTestTransaction(t, dbInstance, "user-001", "user-002", model.Transfer, model.Debit, 1000)
+			createTestTransaction(t, dbInstance, "user-002", "user-001", model.Transfer, model.Credit, 1000)
+
+			// Balances in other currencies are summed separately
+			for _, txn := range []*model.Transaction{
+				model.NewTransaction("user-001", "user-002", model.Transfer, model.Debit, 300),
+				model.NewTransaction("user-002", "user-001", model.Transfer, model.Credit, 300),
+			} {
+				txn.Currency = "EUR"
+				txn.Status = model.Completed
+				require.NoError(t, dbInstance.Create(txn).Error)
+			}
+
+			// Pending transactions do not affect balances
+			pending := model.NewTransaction("user-002", "user-001", model.Transfer, model.Credit, 700)
+			require.NoError(t, dbInstance.Create(pending).Error)
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodGet, "/ledger/balances?subject_wallet_id="+tt.subjectWalletID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/ledger/balances")
+
+			// Execute
+			require.NoError(t, handler.GetLedgerBalances(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestTransactionHandler_GetTransaction(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	repository := repository.NewTransactionRepository(dbInstance)
+	service := service.NewTransactionService(repository)
+	handler := NewTransactionHandler(service)
+
+	// Clean database and create the transaction to look up
+	clearDB(dbInstance, model.Transaction{})
+	txn := model.NewTransaction("user-001", "user-002", model.Transfer, model.Debit, 1000)
+	txn.Status = model.Completed
+	require.NoError(t, dbInstance.Create(txn).Error)
+
+	tests := []struct {
+		name string
+		id   string
+		want want
+	}{
+		{
+			name: "successful_get_transaction",
+			id:   txn.PublicID,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"id":"` + txn.PublicID + `","subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"debit","amount":1000,"currency":"USD","status":"completed"}}`),
+			},
+		},
+		{
+			name: "transaction_not_found",
+			id:   model.NewPublicID(),
+			want: want{
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "invalid_id",
+			id:   "abc",
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "internal_id",
+			id:   strconv.Itoa(txn.ID),
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Prepare
+			req := httptest.NewRequest(http.MethodGet, "/ledger/transactions/"+tt.id, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/ledger/transactions/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			// Execute
+			require.NoError(t, handler.GetTransaction(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			if tt.want.Response == nil {
+				return
+			}
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestTransactionHandler_GetLegacyTransactionIDs(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	repository := repository.NewTransactionRepository(dbInstance)
+	service := service.NewTransactionService(repository)
+	handler := NewTransactionHandler(service)
+
+	// A transaction recorded before public IDs, known by serial ID 7, and a newer one
+	clearDB(dbInstance, model.Transaction{})
+	legacyID := int64(7)
+	legacy := model.NewTransaction("user-001", "user-002", model.Transfer, model.Debit, 1000)
+	legacy.LegacyID = &legacyID
+	require.NoError(t, dbInstance.Create(legacy).Error)
+	current := model.NewTransaction("user-001", "user-002", model.Transfer, model.Debit, 2000)
+	require.NoError(t, dbInstance.Create(current).Error)
+
+	tests := []struct {
+		name  string
+		query string
+		want  want
+	}{
+		{
+			name:  "known_and_unknown_ids",
+			query: "id=7&id=8",
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":[{"legacy_id":7,"id":"` + legacy.PublicID + `"}]}`),
+			},
+		},
+		{
+			// Only legacy transactions are looked up, not the internal ID of newer ones
+			name:  "internal_id",
+			query: "id=" + strconv.Itoa(current.ID),
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":[]}`),
+			},
+		},
+		{
+			name:  "missing_ids",
+			query: "",
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:  "invalid_id",
+			query: "id=abc",
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Prepare
+			req := httptest.NewRequest(http.MethodGet, "/ledger/legacy-transactions?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/ledger/legacy-transactions")
+
+			// Execute
+			require.NoError(t, handler.GetLegacyTransactionIDs(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			if tt.want.Response == nil {
+				return
+			}
+			if diff := cmp.Diff(rec.Body.Bytes(), tt.want.Response, cmpTransformJSON(t)); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTransactionHandler_GetTransactionPair(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	repository := repository.NewTransactionRepository(dbInstance)
+	service := service.NewTransactionService(repository)
+	handler := NewTransactionHandler(service)
+
+	// Clean database and create the pair to look up
+	clearDB(dbInstance, model.Transaction{})
+	pairID := "0f8d3a5e-6b1c-4f2a-9e7d-2c4b6a8e0f13"
+	debitTxn := model.NewTransaction("user-001", "user-002", model.Transfer, model.Debit, 1000)
+	creditTxn := model.NewTransaction("user-002", "user-001", model.Transfer, model.Credit, 1000)
+	for _, txn := range []*model.Transaction{debitTxn, creditTxn} {
+		txn.PairID, txn.Reference, txn.Status = pairID, "invoice-42", model.Completed
+	}
+	require.NoError(t, service.CreateTransactionPair(context.Background(), debitTxn, creditTxn))
+
+	tests := []struct {
+		name   string
+		pairID string
+		want   want
+	}{
+		{
+			name:   "successful_get_transaction_pair",
+			pairID: pairID,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":[{"subject_wallet_id":"user-001","object_wallet_id":"user-002","transaction_type":"transfer","operation_type":"debit","amount":1000,"currency":"USD","pair_id":"0f8d3a5e-6b1c-4f2a-9e7d-2c4b6a8e0f13","reference":"invoice-42","status":"completed"},{"subject_wallet_id":"user-002","object_wallet_id":"user-001","transaction_type":"transfer","operation_type":"credit","amount":1000,"currency":"USD","pair_id":"0f8d3a5e-6b1c-4f2a-9e7d-2c4b6a8e0f13","reference":"invoice-42","status":"completed"}]}`),
+			},
+		},
+		{
+			name:   "transaction_pair_not_found",
+			pairID: "5b0e7c2a-9d4f-4e61-8a3b-1f6c9d2e7a40",
+			want: want{
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name:   "invalid_pair_id",
+			pairID: "pair-1",
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Prepare
+			req := httptest.NewRequest(http.MethodGet, "/transactions/pairs/"+tt.pairID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/transactions/pairs/:pair_id")
+			c.SetParamNames("pair_id")
+			c.SetParamValues(tt.pairID)
+
+			// Execute
+			require.NoError(t, handler.GetTransactionPair(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			if tt.want.Response == nil {
+				return
+			}
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"id": 1, "created_at": 1, "updated_at": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestTransactionHandler_SetReviewStatus(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	repository := repository.NewTransactionRepository(dbInstance)
+	service := service.NewTransactionService(repository)
+	handler := NewTransactionHandler(service)
+
+	tests := []struct {
+		name           string
+		current        model.TransactionStatus // Status of the review's pair
+		reviewID       string
+		requestBody    string
+		wantStatusCode int
+		wantStatus     model.TransactionStatus
+	}{
+		{
+			name:           "complete_pending_pair",
+			current:        model.Pending,
+			reviewID:       "review-001",
+			requestBody:    `{"status":"completed"}`,
+			wantStatusCode: http.StatusOK,
+			wantStatus:     model.Completed,
+		},
+		{
+			name:           "cancel_pending_pair",
+			current:        model.Pending,
+			reviewID:       "review-001",
+			requestBody:    `{"status":"cancelled"}`,
+			wantStatusCode: http.StatusOK,
+			wantStatus:     model.Cancelled,
+		},
+		{
+			name:           "repeated_status",
+			current:        model.Completed,
+			reviewID:       "review-001",
+			requestBody:    `{"status":"completed"}`,
+			wantStatusCode: http.StatusOK,
+			wantStatus:     model.Completed,
+		},
+		{
+			name:           "already_settled",
+			current:        model.Cancelled,
+			reviewID:       "review-001",
+			requestBody:    `{"status":"completed"}`,
+			wantStatusCode: http.StatusConflict,
+			wantStatus:     model.Cancelled,
+		},
+		{
+			name:           "unknown_review",
+			current:        model.Pending,
+			reviewID:       "review-002",
+			requestBody:    `{"status":"completed"}`,
+			wantStatusCode: http.StatusNotFound,
+			wantStatus:     model.Pending,
+		},
+		{
+			name:           "invalid_status",
+			current:        model.Pending,
+			reviewID:       "review-001",
+			requestBody:    `{"status":"failed"}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantStatus:     model.Pending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean database and create the pair of review-001
+			clearDB(dbInstance, model.Transaction{})
+			reviewID := "review-001"
+			for _, opType := range []model.OperationType{model.Debit, model.Credit} {
+				txn := model.NewTransaction("user-001", "user-002", model.Transfer, opType, 1000)
+				txn.Status, txn.ReviewID = tt.current, &reviewID
+				require.NoError(t, dbInstance.Create(txn).Error)
+			}
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPatch, "/ledger/reviews/"+tt.reviewID, bytes.NewReader([]byte(tt.requestBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/ledger/reviews/:review_id")
+			c.SetParamNames("review_id")
+			c.SetParamValues(tt.reviewID)
+
+			// Execute
+			require.NoError(t, handler.SetReviewStatus(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatusCode, rec.Code)
+
+			// Both legs always have the same status
+			var transactions []model.Transaction
+			require.NoError(t, dbInstance.Where("review_id = ?", reviewID).Find(&transactions).Error)
+			require.Len(t, transactions, 2)
+			for _, txn := range transactions {
+				assert.Equal(t, tt.wantStatus, txn.Status)
+			}
+		})
+	}
+}
+
+func TestTransactionHandler_UpdateTransactionStatus(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	repository := repository.NewTransactionRepository(dbInstance)
+	service := service.NewTransactionService(repository)
+	handler := NewTransactionHandler(service)
+
+	tests := []struct {
+		name           string
+		current        model.TransactionStatus // Status of the pair
+		leg            model.OperationType     // Leg whose ID is in the path, zero for an unknown ID
+		reviewID       string                  // Review holding the pair, if any
+		requestBody    string
+		wantStatusCode int
+		wantStatus     model.TransactionStatus
+		wantHistory    int // Status history entries recorded
+	}{
+		{
+			name:           "complete_pending_pair_by_debit",
+			current:        model.Pending,
+			leg:            model.Debit,
+			requestBody:    `{"status":"completed"}`,
+			wantStatusCode: http.StatusOK,
+			wantStatus:     model.Completed,
+			wantHistory:    2,
+		},
+		{
+			name:           "fail_pending_pair_by_credit",
+			current:        model.Pending,
+			leg:            model.Credit,
+			requestBody:    `{"status":"failed","reason":"provider declined"}`,
+			wantStatusCode: http.StatusOK,
+			wantStatus:     model.Failed,
+			wantHistory:    2,
+		},
+		{
+			name:           "repeated_status",
+			current:        model.Cancelled,
+			leg:            model.Debit,
+			requestBody:    `{"status":"cancelled"}`,
+			wantStatusCode: http.StatusOK,
+			wantStatus:     model.Cancelled,
+		},
+		{
+			name:           "terminal_status",
+			current:        model.Completed,
+			leg:            model.Debit,
+			requestBody:    `{"status":"cancelled"}`,
+			wantStatusCode: http.StatusConflict,
+			wantStatus:     model.Completed,
+		},
+		{
+			name:           "back_to_pending",
+			current:        model.Pending,
+			leg:            model.Debit,
+			requestBody:    `{"status":"pending"}`,
+			wantStatusCode: http.StatusBadRequest,
+			wantStatus:     model.Pending,
+		},
+		{
+			name:           "review_pair",
+			current:        model.Pending,
+			leg:            model.Debit,
+			reviewID:       "review-001",
+			requestBody:    `{"status":"completed"}`,
+			wantStatusCode: http.StatusConflict,
+			wantStatus:     model.Pending,
+		},
+		{
+			name:           "unknown_transaction",
+			current:        model.Pending,
+			requestBody:    `{"status":"completed"}`,
+			wantStatusCode: http.StatusNotFound,
+			wantStatus:     model.Pending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean database and create completed pairs between the same wallets around the pair under test
+			clearDB(dbInstance, model.Transaction{}, model.TransactionStatusChange{})
+			createTestPair := func() {
+				createTestTransaction(t, dbInstance, "user-001", "user-002", model.Transfer, model.Debit, 500)
+				createTestTransaction(t, dbInstance, "user-002", "user-001", model.Transfer, model.Credit, 500)
+			}
+			createTestPair()
+			legs := map[model.OperationType]*model.Transaction{
+				model.Debit:  model.NewTransaction("user-001", "user-002", model.Transfer, model.Debit, 1000),
+				model.Credit: model.NewTransaction("user-002", "user-001", model.Transfer, model.Credit, 1000),
+			}
+			for _, opType := range []model.OperationType{model.Debit, model.Credit} {
+				legs[opType].Status = tt.current
+				if tt.reviewID != "" {
+					legs[opType].ReviewID = &tt.reviewID
+				}
+				require.NoError(t, dbInstance.Create(legs[opType]).Error)
+			}
+			createTestPair()
+
+			id := model.NewPublicID()
+			if tt.leg != "" {
+				id = legs[tt.leg].PublicID
+			}
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPatch, "/transactions/"+id+"/status", bytes.NewReader([]byte(tt.requestBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/transactions/:id/status")
+			c.SetParamNames("id")
+			c.SetParamValues(id)
+
+			// Execute
+			require.NoError(t, handler.UpdateTransactionStatus(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatusCode, rec.Code)
+
+			// Both legs always have the same status and other pairs are untouched
+			for _, leg := range legs {
+				var txn model.Transaction
+				require.NoError(t, dbInstance.Take(&txn, leg.ID).Error)
+				assert.Equal(t, tt.wantStatus, txn.Status)
+			}
+			var others int64
+			require.NoError(t, dbInstance.Model(&model.Transaction{}).Where("amount = ? AND status = ?", 500, model.Completed).Count(&others).Error)
+			assert.Equal(t, int64(4), others)
+
+			var history int64
+			require.NoError(t, dbInstance.Model(&model.TransactionStatusChange{}).Count(&history).Error)
+			assert.Equal(t, int64(tt.wantHistory), history)
+		})
+	}
+}
+
 // Helper functions
 func clearDB(db *gorm.DB, models ...interface{}) {
 	for _, model := range models {
@@ -26,6 +26,7 @@ func NewCustomValidator() *CustomValidator {
 	_ = v.RegisterValidation("validTransactionType", model.IsValidTransactionType)
 	_ = v.RegisterValidation("validTransactionStatus", model.IsValidTransactionStatus)
 	_ = v.RegisterValidation("validOperationType", model.IsValidOperationType)
+	_ = v.RegisterValidation("validCurrency", model.IsValidCurrency)
 
 	return &CustomValidator{validator: v}
 }
@@ -10,4 +10,20 @@ const (
 	CodeNotFound = "NOT_FOUND"
 	// CodeBadRequest is a generic error message returned when the request is bad.
 	CodeBadRequest = "BAD_REQUEST"
+	// CodeIdempotencyKeyReused is returned when an Idempotency-Key is replayed with a different request.
+	CodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	// CodeRequestInProgress is returned when a request with the same Idempotency-Key is still being processed.
+	CodeRequestInProgress = "REQUEST_IN_PROGRESS"
+	// CodeCurrencyMismatch is returned when the two sides of a transaction pair are in different currencies.
+	CodeCurrencyMismatch = "CURRENCY_MISMATCH"
+	// CodeTransactionNotPending is returned when a reviewed pair was already settled with another status.
+	CodeTransactionNotPending = "TRANSACTION_NOT_PENDING"
+	// CodeInvalidStatusTransition is returned when a transaction may not move to the requested status.
+	CodeInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
+	// CodeReviewPair is returned when the status of a pair held for review is changed outside of the review settlement.
+	CodeReviewPair = "REVIEW_PAIR"
+	// CodeUnauthorized is returned when a request is not signed by a known service.
+	CodeUnauthorized = "UNAUTHORIZED"
+	// CodeForbidden is returned when the calling service may not use an endpoint.
+	CodeForbidden = "FORBIDDEN"
 )
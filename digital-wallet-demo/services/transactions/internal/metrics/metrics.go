@@ -0,0 +1,78 @@
+// Package metrics provides the Prometheus metrics of the transaction service.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry is the registry of the metrics served on /metrics.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+// HTTPRequestDuration is the latency of API requests by method, route and status.
+var HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "http_request_duration_seconds",
+	Help:    "Latency of API requests by method, route and status.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"method", "route", "status"})
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler returns the handler serving the metrics of Registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDBStats registers the connection pool stats of db, labeled with dbName.
+// A pool already registered under dbName is kept.
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	err := Registry.Register(collectors.NewDBStatsCollector(db, dbName))
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		return nil
+	}
+	return err
+}
+
+// Middleware returns a middleware recording the latency of each request.
+// Requests matching no route are recorded under the route "unmatched", so
+// that probing random paths does not create a series per path.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			// Handle errors here, so that the status of the response is known
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" || c.Response().Status == http.StatusNotFound && route == "/*" {
+				route = "unmatched"
+			}
+			HTTPRequestDuration.WithLabelValues(
+				c.Request().Method,
+				route,
+				strconv.Itoa(c.Response().Status),
+			).Observe(time.Since(start).Seconds())
+			return nil
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware())
+	e.GET("/api/v1/transactions/:wallet_id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, path := range []string{"/api/v1/transactions/user-1", "/api/v1/transactions/user-2", "/random/path"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	families, err := Registry.Gather()
+	require.NoError(t, err)
+
+	counts := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != "http_request_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			var route, status string
+			for _, label := range m.GetLabel() {
+				switch label.GetName() {
+				case "route":
+					route = label.GetValue()
+				case "status":
+					status = label.GetValue()
+				}
+			}
+			counts[route+" "+status] = m.GetHistogram().GetSampleCount()
+		}
+	}
+	assert.Equal(t, map[string]uint64{
+		"/api/v1/transactions/:wallet_id 200": 2,
+		"unmatched 404":                       1,
+	}, counts)
+}
@@ -0,0 +1,18 @@
+package model
+
+import "fmt"
+
+// ErrNotFound is the error for not found.
+var ErrNotFound = fmt.Errorf("not found")
+
+// ErrNotPending is the error for settling a transaction pair that was already settled with another status.
+var ErrNotPending = fmt.Errorf("transaction is not pending")
+
+// ErrInvalidTransition is the error for a status change that the transaction state machine does not allow.
+var ErrInvalidTransition = fmt.Errorf("invalid transaction status transition")
+
+// ErrReviewPair is the error for changing the status of a pair held for review outside of the review settlement.
+var ErrReviewPair = fmt.Errorf("transaction pair is held for review")
+
+// ErrIncompletePair is the error for a transaction whose other leg cannot be found.
+var ErrIncompletePair = fmt.Errorf("other leg of the transaction pair not found")
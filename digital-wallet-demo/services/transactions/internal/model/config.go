@@ -1,11 +1,21 @@
 // Package model provides the data models for the application.
 package model
 
+import (
+	"fmt"
+	"time"
+)
+
 // Config is the configuration for the application.
 type Config struct {
 	APIServer     Server
 	SwaggerServer Server
+	MetricsServer Server // Serves the Prometheus metrics on /metrics
 	PostgreSQL    PostgreSQL
+	ServiceAuth   ServiceAuth
+	Idempotency   Idempotency
+	Tracing       Tracing
+	Health        Health
 }
 
 // Server is the configuration for the server.
@@ -23,3 +33,77 @@ type PostgreSQL struct {
 	DBName   string `validate:"required"`
 	SSLMode  string `validate:"required"`
 }
+
+// Idempotency is the configuration for requests sent with an Idempotency-Key header.
+type Idempotency struct {
+	// Time a request holds its key before a retry may take it over, longer than any request takes
+	LeaseTimeout time.Duration `validate:"gt=0"`
+}
+
+// Health is the configuration for the readiness checks and the draining of the instance on shutdown.
+type Health struct {
+	Timeout    time.Duration `validate:"gt=0"`  // Time each dependency has to answer a readiness check
+	DrainDelay time.Duration `validate:"gte=0"` // Time the instance reports not ready before it stops serving, for load balancers to stop routing to it
+}
+
+// Tracing is the configuration for exporting OpenTelemetry spans.
+// Spans are written to stdout, or sent over OTLP/HTTP to the collector at Endpoint.
+type Tracing struct {
+	Enable      bool
+	Exporter    string  `validate:"omitempty,oneof=stdout otlp"`
+	Endpoint    string  // host:port of the OTLP/HTTP collector, defaults to localhost:4318
+	Insecure    bool    // Send spans to the collector over plain HTTP
+	ServiceName string  // Defaults to transactions
+	SampleRatio float64 `validate:"gte=0,lte=1"` // Ratio of new traces that are sampled, traces of sampled callers are always sampled
+}
+
+// ServiceAuth is the configuration for authenticating calls from other services.
+// Calls are signed with HMAC-SHA256 using a secret shared with the calling service.
+//
+// Secrets are read from the environment only. The API refuses to start with
+// authentication disabled, with a missing secret or with DevServiceSecret
+// unless DevMode is set.
+type ServiceAuth struct {
+	Enable       bool
+	DevMode      bool                // Allows running without authentication, and falls back to DevServiceSecret for secrets that are not set, for development only
+	MaxClockSkew time.Duration       `validate:"gte=0"` // Maximum age of a signed request, and the time its nonce is remembered
+	Services     []ServiceCredential `validate:"dive"`
+}
+
+// ServiceCredential is the shared secret of a service allowed to call the API.
+type ServiceCredential struct {
+	ID        string `validate:"required"`
+	SecretEnv string `validate:"required"` // Environment variable holding the secret
+	Secret    string `mapstructure:"-"`    // Read from SecretEnv by LoadSecrets, never from a config file
+}
+
+// DevServiceSecret is the shared secret of development setups. It is public,
+// so it is only accepted in development mode.
+const DevServiceSecret = "dev-wallets-service-secret-change-me"
+
+// minServiceSecretLength is the minimum length of a shared secret.
+const minServiceSecretLength = 32
+
+// LoadSecrets reads the secret of every service from its environment variable
+// with getenv. Outside development mode, a secret that is not set or equal to
+// DevServiceSecret is an error.
+func (a *ServiceAuth) LoadSecrets(getenv func(string) string) error {
+	services := make([]ServiceCredential, len(a.Services))
+	for i, svc := range a.Services {
+		svc.Secret = getenv(svc.SecretEnv)
+		if svc.Secret == "" && a.DevMode {
+			svc.Secret = DevServiceSecret
+		}
+		switch {
+		case svc.Secret == "":
+			return fmt.Errorf("secret of service %s is not set, set it in %s", svc.ID, svc.SecretEnv)
+		case svc.Secret == DevServiceSecret && !a.DevMode:
+			return fmt.Errorf("secret of service %s in %s is the development secret", svc.ID, svc.SecretEnv)
+		case len(svc.Secret) < minServiceSecretLength:
+			return fmt.Errorf("secret of service %s in %s is shorter than %d characters", svc.ID, svc.SecretEnv, minServiceSecretLength)
+		}
+		services[i] = svc
+	}
+	a.Services = services
+	return nil
+}
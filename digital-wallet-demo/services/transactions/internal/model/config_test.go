@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceAuth_LoadSecrets(t *testing.T) {
+	const secret = "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name       string
+		devMode    bool
+		env        string // Value of WALLETS_SERVICE_SECRET, unset if empty
+		wantSecret string
+		wantErr    bool
+	}{
+		{name: "secret_from_env", env: secret, wantSecret: secret},
+		{name: "missing_secret", wantErr: true},
+		{name: "development_secret", env: DevServiceSecret, wantErr: true},
+		{name: "short_secret", env: "too-short", wantErr: true},
+		{name: "dev_mode_falls_back", devMode: true, wantSecret: DevServiceSecret},
+		{name: "dev_mode_prefers_env", devMode: true, env: secret, wantSecret: secret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The secret in the config file is ignored
+			cfg := ServiceAuth{
+				DevMode:  tt.devMode,
+				Services: []ServiceCredential{{ID: "wallets", SecretEnv: "WALLETS_SERVICE_SECRET", Secret: "from-the-config-file"}},
+			}
+			getenv := func(key string) string {
+				if key == "WALLETS_SERVICE_SECRET" {
+					return tt.env
+				}
+				return ""
+			}
+
+			err := cfg.LoadSecrets(getenv)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSecret, cfg.Services[0].Secret)
+		})
+	}
+}
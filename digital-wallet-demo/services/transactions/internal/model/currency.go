@@ -0,0 +1,65 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Currency is an ISO-4217 currency code.
+// Amounts are always integers in the minor unit of their currency.
+type Currency string
+
+// DefaultCurrency is the currency of transactions that do not specify one.
+const DefaultCurrency = Currency("USD")
+
+// supportedCurrencies lists the currencies accepted by the ledger.
+// It must be kept in sync with the wallet service.
+var supportedCurrencies = map[Currency]bool{
+	"USD": true,
+	"EUR": true,
+	"GBP": true,
+	"BDT": true,
+	"INR": true,
+	"SGD": true,
+	"JPY": true,
+	"KRW": true,
+	"KWD": true,
+	"BHD": true,
+}
+
+// ErrCurrencyMismatch is the error for a transaction pair whose sides do not describe the same movement of money.
+var ErrCurrencyMismatch = fmt.Errorf("currency mismatch")
+
+// IsSupported reports whether c is a supported currency.
+func (c Currency) IsSupported() bool {
+	return supportedCurrencies[c]
+}
+
+// IsValidCurrency checks if the currency is supported
+func IsValidCurrency(fl validator.FieldLevel) bool {
+	if fl.Field().IsZero() {
+		return true
+	}
+	currency := fl.Field().Interface().(Currency)
+	return currency.IsSupported()
+}
+
+// CheckPairCurrencies checks that the two sides of a transaction pair agree.
+// A same-currency pair records no rate. A conversion pair records the same
+// rate on both sides, and each side's counter amount and currency are the
+// amount and currency of the other side.
+func CheckPairCurrencies(debitTxn, creditTxn *Transaction) error {
+	if debitTxn.Currency == creditTxn.Currency {
+		if debitTxn.FXRate != "" || creditTxn.FXRate != "" {
+			return ErrCurrencyMismatch
+		}
+		return nil
+	}
+	if debitTxn.FXRate == "" || debitTxn.FXRate != creditTxn.FXRate ||
+		debitTxn.CounterCurrency != creditTxn.Currency || debitTxn.CounterAmount != creditTxn.Amount ||
+		creditTxn.CounterCurrency != debitTxn.Currency || creditTxn.CounterAmount != debitTxn.Amount {
+		return ErrCurrencyMismatch
+	}
+	return nil
+}
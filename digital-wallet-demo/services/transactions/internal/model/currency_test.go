@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPairCurrencies(t *testing.T) {
+	conversion := func() (*Transaction, *Transaction) {
+		debit := &Transaction{Amount: 1000, Currency: "USD", FXRate: "0.92", CounterAmount: 920, CounterCurrency: "EUR"}
+		credit := &Transaction{Amount: 920, Currency: "EUR", FXRate: "0.92", CounterAmount: 1000, CounterCurrency: "USD"}
+		return debit, credit
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(debit, credit *Transaction)
+		wantErr bool
+	}{
+		{"conversion", func(_, _ *Transaction) {}, false},
+		{"same_currency", func(debit, credit *Transaction) {
+			*debit = Transaction{Amount: 1000, Currency: "USD"}
+			*credit = Transaction{Amount: 1000, Currency: "USD"}
+		}, false},
+		{"same_currency_with_rate", func(debit, credit *Transaction) {
+			credit.Currency, credit.Amount = "USD", 1000
+		}, true},
+		{"missing_rate", func(debit, credit *Transaction) {
+			debit.FXRate, credit.FXRate = "", ""
+		}, true},
+		{"different_rates", func(_, credit *Transaction) {
+			credit.FXRate = "0.93"
+		}, true},
+		{"wrong_counter_amount", func(debit, _ *Transaction) {
+			debit.CounterAmount = 930
+		}, true},
+		{"wrong_counter_currency", func(_, credit *Transaction) {
+			credit.CounterCurrency = "GBP"
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			debit, credit := conversion()
+			tt.modify(debit, credit)
+			err := CheckPairCurrencies(debit, credit)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrCurrencyMismatch)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
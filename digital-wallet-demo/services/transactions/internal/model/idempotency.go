@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// IdempotencyRecord stores the outcome of a request sent with an Idempotency-Key
+// header so that a retried request can be answered without executing it again.
+type IdempotencyRecord struct {
+	Key          string            `gorm:"primaryKey;size:255" json:"key"`
+	RequestHash  string            `gorm:"not null" json:"request_hash"`
+	Status       IdempotencyStatus `gorm:"not null" json:"status"`
+	StatusCode   int               `json:"status_code"`
+	ResponseBody string            `gorm:"type:text" json:"response_body"`
+	LockedUntil  time.Time         `gorm:"not null;default:CURRENT_TIMESTAMP" json:"locked_until"` // End of the lease of an in-progress request, after which a retry takes it over
+	CreatedAt    time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// IdempotencyStatus is the processing status of an idempotent request.
+type IdempotencyStatus string
+
+const (
+	// IdempotencyInProgress is the status while the original request is being handled.
+	// A request that stops before storing its response leaves the key to a retry once LockedUntil has passed.
+	IdempotencyInProgress = IdempotencyStatus("in_progress")
+	// IdempotencyCompleted is the status once the response has been stored.
+	IdempotencyCompleted = IdempotencyStatus("completed")
+)
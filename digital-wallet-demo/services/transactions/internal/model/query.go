@@ -0,0 +1,81 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultPageLimit is the page size used when the request does not specify one.
+	DefaultPageLimit = 50
+	// MaxPageLimit is the largest page size a request may ask for.
+	MaxPageLimit = 500
+)
+
+// ErrInvalidCursor is the error for a cursor that cannot be decoded.
+var ErrInvalidCursor = fmt.Errorf("invalid cursor")
+
+// TransactionQuery selects a page of a wallet's transactions.
+// Zero-valued filters are not applied.
+type TransactionQuery struct {
+	SubjectWalletID string
+	TransactionType TransactionType
+	OperationType   OperationType
+	Status          TransactionStatus
+	Currency        Currency
+	MinAmount       *int64
+	MaxAmount       *int64
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+
+	Limit  int
+	Cursor *TransactionCursor
+}
+
+// TransactionCursor is the position of the last transaction of a page.
+// Transactions are ordered by created_at and public ID, newest first, so the
+// next page starts strictly after this position. The cursor carries only what
+// the API exposes, not the internal ID.
+type TransactionCursor struct {
+	CreatedAt time.Time `json:"t"`
+	PublicID  string    `json:"id"`
+}
+
+// NewTransactionCursor returns the cursor positioned at txn.
+func NewTransactionCursor(txn Transaction) *TransactionCursor {
+	return &TransactionCursor{CreatedAt: txn.CreatedAt, PublicID: txn.PublicID}
+}
+
+// Encode returns the opaque string form of the cursor handed out to clients.
+func (c *TransactionCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeTransactionCursor parses a cursor produced by Encode.
+func DecodeTransactionCursor(s string) (*TransactionCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c TransactionCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(c.PublicID); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// TransactionPage is a page of transactions.
+// NextCursor is empty on the last page.
+type TransactionPage struct {
+	Transactions []Transaction
+	Limit        int
+	NextCursor   string
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionCursor(t *testing.T) {
+	txn := Transaction{ID: 42, PublicID: "0190c3a2-5e4b-7d21-9f3a-6b8c2d4e1f07", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)}
+
+	encoded := NewTransactionCursor(txn).Encode()
+	decoded, err := DecodeTransactionCursor(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, txn.PublicID, decoded.PublicID)
+	assert.True(t, txn.CreatedAt.Equal(decoded.CreatedAt))
+
+	// The cursor carries the public ID, not the internal ID
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"id":"`+txn.PublicID+`"`)
+
+	// Cursors of the internal ID are no longer accepted
+	legacy := base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2024-01-02T03:04:05Z","id":42}`))
+	for _, invalid := range []string{"", "not base64!", "bm90IGpzb24", "e30", legacy} {
+		_, err := DecodeTransactionCursor(invalid)
+		assert.ErrorIs(t, err, ErrInvalidCursor, invalid)
+	}
+}
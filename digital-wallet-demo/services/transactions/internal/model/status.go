@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// transitions lists the statuses a transaction may move to from each status.
+// Completed, failed and cancelled are terminal.
+var transitions = map[TransactionStatus][]TransactionStatus{
+	Pending: {Completed, Failed, Cancelled},
+}
+
+// IsTerminal reports whether no further status change is allowed from s.
+func (s TransactionStatus) IsTerminal() bool {
+	return len(transitions[s]) == 0
+}
+
+// CanTransitionTo reports whether a transaction in status s may be moved to next.
+func (s TransactionStatus) CanTransitionTo(next TransactionStatus) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionStatusChange is an entry of the status history of a transaction.
+// Entries are only ever inserted, in the same database transaction as the
+// status change they record.
+type TransactionStatusChange struct {
+	ID            int               `gorm:"primaryKey" json:"id"`
+	TransactionID int               `gorm:"not null;index" json:"transaction_id"`
+	FromStatus    TransactionStatus `gorm:"type:varchar(50);not null" json:"from_status"`
+	ToStatus      TransactionStatus `gorm:"type:varchar(50);not null" json:"to_status"`
+	Reason        string            `gorm:"type:varchar(255)" json:"reason,omitempty"`
+	CreatedAt     time.Time         `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName returns the table of the status history.
+func (TransactionStatusChange) TableName() string {
+	return "transaction_status_history"
+}
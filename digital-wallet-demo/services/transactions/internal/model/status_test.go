@@ -0,0 +1,23 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionStatusTransitions(t *testing.T) {
+	statuses := []TransactionStatus{Pending, Completed, Failed, Cancelled}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := from == Pending && to != Pending
+			assert.Equal(t, want, from.CanTransitionTo(to), "%s -> %s", from, to)
+		}
+	}
+
+	assert.False(t, Pending.IsTerminal())
+	assert.True(t, Completed.IsTerminal())
+	assert.True(t, Failed.IsTerminal())
+	assert.True(t, Cancelled.IsTerminal())
+}
@@ -4,16 +4,27 @@ import (
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 )
 
 // Transaction represents a wallet transaction
 type Transaction struct {
-	ID              int               `gorm:"primaryKey" json:"id"`
+	ID              int               `gorm:"primaryKey" json:"-"`             // Internal key, used for joins and ordering only
+	PublicID        string            `gorm:"type:uuid;uniqueIndex" json:"id"` // UUIDv7 identifying the transaction in the API
 	SubjectWalletID string            `gorm:"not null;" json:"subject_wallet_id"`
 	ObjectWalletID  string            `gorm:"not null;" json:"object_wallet_id,omitempty"`
 	TransactionType TransactionType   `gorm:"not null" json:"transaction_type"`
 	OperationType   OperationType     `gorm:"not null" json:"operation_type"`
-	Amount          int64             `gorm:"not null" json:"amount"` // Amount in cents
+	Amount          int64             `gorm:"not null" json:"amount"` // Amount in minor units of Currency
+	Currency        Currency          `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
+	FXRate          string            `gorm:"type:varchar(32)" json:"fx_rate,omitempty"`                          // Price of one unit of the debited currency in the credited currency
+	CounterAmount   int64             `json:"counter_amount,omitempty"`                                           // Amount of the other side of a conversion
+	CounterCurrency Currency          `gorm:"type:varchar(3)" json:"counter_currency,omitempty"`                  // Currency of the other side of a conversion
+	ReversalOf      *string           `gorm:"column:reversal_of_id;type:uuid;index" json:"reversal_of,omitempty"` // Public ID of the transaction reversed by this one
+	ReviewID        *string           `gorm:"type:varchar(32);index" json:"review_id,omitempty"`                  // ID of the wallet service review deciding a pending pair
+	PairID          string            `gorm:"type:varchar(36);index" json:"pair_id,omitempty"`                    // UUID shared by the debit and credit of a pair
+	Reference       string            `gorm:"type:varchar(128)" json:"reference,omitempty"`                       // Client supplied reference of the pair
+	LegacyID        *int64            `gorm:"uniqueIndex" json:"-"`                                               // Serial ID of a transaction recorded before public IDs
 	Status          TransactionStatus `gorm:"default:'pending'" json:"status"`
 	CreatedAt       time.Time         `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt       time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
@@ -22,15 +33,23 @@ type Transaction struct {
 // NewTransaction returns a new instance of the Transaction model.
 func NewTransaction(subjectWalletID, objectWalletID string, transactionType TransactionType, operationType OperationType, amount int64) *Transaction {
 	return &Transaction{
+		PublicID:        NewPublicID(),
 		SubjectWalletID: subjectWalletID,
 		ObjectWalletID:  objectWalletID,
 		TransactionType: transactionType,
 		OperationType:   operationType,
 		Amount:          amount,
+		Currency:        DefaultCurrency,
 		Status:          Pending,
 	}
 }
 
+// NewPublicID returns a new public transaction ID. IDs are UUIDv7, so they
+// are ordered by creation time without revealing how many transactions exist.
+func NewPublicID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
 // Provider wallet constants for external wallet service communication
 const (
 	// DepositProviderID is the UserID for the deposit provider wallet
@@ -41,6 +60,23 @@ const (
 	WithdrawProviderID = "withdraw-provider-master"
 )
 
+// LedgerBalance is the net effect of all completed transactions of a wallet in one currency.
+type LedgerBalance struct {
+	SubjectWalletID string   `json:"subject_wallet_id"`
+	Currency        Currency `json:"currency"`
+	Credits         int64    `json:"credits"` // Sum of credit amounts in minor units
+	Debits          int64    `json:"debits"`  // Sum of debit amounts in minor units
+	Net             int64    `json:"net"`     // Credits minus debits in minor units
+	Entries         int64    `json:"entries"`
+}
+
+// LegacyTransactionID maps the serial ID a transaction recorded before public
+// IDs was known by to its public ID.
+type LegacyTransactionID struct {
+	LegacyID int64  `json:"legacy_id"`
+	ID       string `json:"id"`
+}
+
 // OperationType represents the operation type for transactions
 type OperationType string
 
@@ -61,6 +97,8 @@ const (
 	Withdraw = TransactionType("withdraw")
 	// Transfer transaction type
 	Transfer = TransactionType("transfer")
+	// Adjustment transaction type, recorded by ledger reconciliation
+	Adjustment = TransactionType("adjustment")
 )
 
 // TransactionStatus represents the status of a transaction
@@ -83,7 +121,7 @@ func IsValidTransactionType(fl validator.FieldLevel) bool {
 		return true
 	}
 	txnType := fl.Field().Interface().(TransactionType)
-	return txnType == Deposit || txnType == Withdraw || txnType == Transfer
+	return txnType == Deposit || txnType == Withdraw || txnType == Transfer || txnType == Adjustment
 }
 
 // IsValidTransactionStatus checks if the transaction status is valid
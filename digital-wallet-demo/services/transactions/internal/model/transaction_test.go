@@ -0,0 +1,24 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPublicID(t *testing.T) {
+	first, second := NewPublicID(), NewPublicID()
+
+	for _, id := range []string{first, second} {
+		parsed, err := uuid.Parse(id)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), parsed.Version())
+	}
+	assert.NotEqual(t, first, second)
+	assert.LessOrEqual(t, first[:13], second[:13], "IDs start with their creation time")
+
+	txn := NewTransaction("user-001", "user-002", Transfer, Debit, 1000)
+	assert.NotEmpty(t, txn.PublicID)
+}
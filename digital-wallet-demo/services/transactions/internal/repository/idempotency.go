@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository provides database operations for idempotency keys
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record *model.IdempotencyRecord, now time.Time) (bool, error)
+	FindByKey(ctx context.Context, key string) (*model.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, statusCode int, responseBody string) error
+	Release(ctx context.Context, key string) error
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency repository
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Reserve inserts the record unless its key already exists and reports whether the caller now owns the key.
+// An in-progress record of the same request whose lease ended before now is taken over with the lease of record.
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *model.IdempotencyRecord, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"locked_until", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{clause.Expr{
+			SQL:  "idempotency_records.status = ? AND idempotency_records.locked_until < ? AND idempotency_records.request_hash = EXCLUDED.request_hash",
+			Vars: []interface{}{model.IdempotencyInProgress, now},
+		}}},
+	}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindByKey retrieves a record by key, returns ErrNotFound if not exists
+func (r *idempotencyRepository) FindByKey(ctx context.Context, key string) (*model.IdempotencyRecord, error) {
+	var record *model.IdempotencyRecord
+	err := r.db.WithContext(ctx).Where("key = ?", key).Take(&record).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+// Complete stores the response of the original request
+func (r *idempotencyRepository) Complete(ctx context.Context, key string, statusCode int, responseBody string) error {
+	return r.db.WithContext(ctx).Model(&model.IdempotencyRecord{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"status":        model.IdempotencyCompleted,
+		"status_code":   statusCode,
+		"response_body": responseBody,
+	}).Error
+}
+
+// Release deletes a reserved key so that the request can be retried
+func (r *idempotencyRepository) Release(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("key = ?", key).Delete(&model.IdempotencyRecord{}).Error
+}
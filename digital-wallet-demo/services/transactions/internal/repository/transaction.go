@@ -1,14 +1,23 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TransactionRepository provides database operations for transactions
 type TransactionRepository interface {
-	CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error
-	FindAllTransactions(filters map[string]interface{}) ([]model.Transaction, error)
+	CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction) error
+	FindTransactions(ctx context.Context, query model.TransactionQuery) ([]model.Transaction, error)
+	SumLedgerBalances(ctx context.Context, filters map[string]interface{}) ([]model.LedgerBalance, error)
+	FindTransaction(ctx context.Context, publicID string) (*model.Transaction, error)
+	FindPair(ctx context.Context, pairID string) ([]model.Transaction, error)
+	FindLegacyIDs(ctx context.Context, legacyIDs []int64) ([]model.LegacyTransactionID, error)
+	SetReviewStatus(ctx context.Context, reviewID string, status model.TransactionStatus) ([]model.Transaction, error)
+	UpdatePairStatus(ctx context.Context, publicID string, status model.TransactionStatus, reason string) ([]model.Transaction, error)
 }
 
 type transactionRepository struct {
@@ -21,9 +30,9 @@ func NewTransactionRepository(db *gorm.DB) TransactionRepository {
 }
 
 // CreateTransactionPair creates both debit and credit transactions atomically
-func (r *transactionRepository) CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error {
+func (r *transactionRepository) CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction) error {
 	// Begin database transaction
-	tx := r.db.Begin()
+	tx := r.db.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -50,19 +59,264 @@ func (r *transactionRepository) CreateTransactionPair(debitTxn, creditTxn *model
 	return tx.Commit().Error
 }
 
-// FindAllTransactions retrieves transactions matching the query filters
-func (r *transactionRepository) FindAllTransactions(filters map[string]interface{}) ([]model.Transaction, error) {
+// FindTransactions retrieves a wallet's transactions matching the query, newest first.
+// Rows are ordered by (created_at, public_id) so that a cursor identifies a unique position.
+func (r *transactionRepository) FindTransactions(ctx context.Context, query model.TransactionQuery) ([]model.Transaction, error) {
 	var transactions []model.Transaction
-	tx := r.db
+	tx := r.db.WithContext(ctx).Where("subject_wallet_id = ?", query.SubjectWalletID)
+
+	if query.TransactionType != "" {
+		tx = tx.Where("transaction_type = ?", query.TransactionType)
+	}
+	if query.OperationType != "" {
+		tx = tx.Where("operation_type = ?", query.OperationType)
+	}
+	if query.Status != "" {
+		tx = tx.Where("status = ?", query.Status)
+	}
+	if query.Currency != "" {
+		tx = tx.Where("currency = ?", query.Currency)
+	}
+	if query.MinAmount != nil {
+		tx = tx.Where("amount >= ?", *query.MinAmount)
+	}
+	if query.MaxAmount != nil {
+		tx = tx.Where("amount <= ?", *query.MaxAmount)
+	}
+	if query.CreatedFrom != nil {
+		tx = tx.Where("created_at >= ?", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		tx = tx.Where("created_at < ?", *query.CreatedTo)
+	}
+	if query.Cursor != nil {
+		tx = tx.Where("(created_at, public_id) < (?, ?)", query.Cursor.CreatedAt, query.Cursor.PublicID)
+	}
+
+	err := tx.Order("created_at desc, public_id desc").Limit(query.Limit).Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// SumLedgerBalances sums the completed transactions matching the query filters per subject wallet and currency
+func (r *transactionRepository) SumLedgerBalances(ctx context.Context, filters map[string]interface{}) ([]model.LedgerBalance, error) {
+	var balances []model.LedgerBalance
+	tx := r.db.WithContext(ctx).Model(&model.Transaction{}).
+		Select("subject_wallet_id, currency, "+
+			"COALESCE(SUM(CASE WHEN operation_type = ? THEN amount ELSE 0 END), 0) AS credits, "+
+			"COALESCE(SUM(CASE WHEN operation_type = ? THEN amount ELSE 0 END), 0) AS debits, "+
+			"COALESCE(SUM(CASE WHEN operation_type = ? THEN amount ELSE -amount END), 0) AS net, "+
+			"COUNT(*) AS entries", model.Credit, model.Debit, model.Credit).
+		Where("status = ?", model.Completed)
 
 	if len(filters) > 0 {
 		tx = tx.Where(filters)
 	}
 
-	err := tx.Order("created_at desc").Find(&transactions).Error
+	err := tx.Group("subject_wallet_id, currency").Order("subject_wallet_id, currency").Scan(&balances).Error
 	if err != nil {
 		return nil, err
 	}
 
+	return balances, nil
+}
+
+// FindTransaction retrieves a transaction by public ID, returns ErrNotFound if not exists
+func (r *transactionRepository) FindTransaction(ctx context.Context, publicID string) (*model.Transaction, error) {
+	var transaction model.Transaction
+	if err := r.db.WithContext(ctx).Where("public_id = ?", publicID).Take(&transaction).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// FindLegacyIDs retrieves the public IDs of the transactions recorded before
+// public IDs with the given serial IDs. Unknown IDs are left out.
+func (r *transactionRepository) FindLegacyIDs(ctx context.Context, legacyIDs []int64) ([]model.LegacyTransactionID, error) {
+	ids := []model.LegacyTransactionID{}
+	err := r.db.WithContext(ctx).Model(&model.Transaction{}).
+		Select("legacy_id, public_id AS id").
+		Where("legacy_id IN ?", legacyIDs).
+		Order("legacy_id").
+		Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// FindPair retrieves the transactions of a pair, debit first, returns ErrNotFound if none exist
+func (r *transactionRepository) FindPair(ctx context.Context, pairID string) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	if err := r.db.WithContext(ctx).Where("pair_id = ?", pairID).Order("id").Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, model.ErrNotFound
+	}
 	return transactions, nil
 }
+
+// SetReviewStatus sets the status of the pending transactions of a review atomically and returns them.
+// Returns ErrNotFound if the review has no transactions, and ErrNotPending if they were
+// already settled with another status; settling them again with the same status is a no-op.
+func (r *transactionRepository) SetReviewStatus(ctx context.Context, reviewID string, status model.TransactionStatus) ([]model.Transaction, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// Lock both legs so that concurrent settlements are serialized
+	var transactions []model.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("review_id = ?", reviewID).Order("id").Find(&transactions).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		tx.Rollback()
+		return nil, model.ErrNotFound
+	}
+
+	for i := range transactions {
+		switch transactions[i].Status {
+		case model.Pending:
+			if err := setStatus(tx, &transactions[i], status, "review "+reviewID); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		case status:
+		default:
+			tx.Rollback()
+			return nil, model.ErrNotPending
+		}
+	}
+
+	return transactions, tx.Commit().Error
+}
+
+// UpdatePairStatus moves the transaction with the public ID and the other leg of its pair to status atomically
+// and returns both legs, debit first. Returns ErrNotFound if the transaction does not exist,
+// ErrIncompletePair if its other leg cannot be found, ErrReviewPair if the pair is held for review,
+// and ErrInvalidTransition if either leg may not move to status; setting the status the pair
+// already has is a no-op. Review pairs are only settled by SetReviewStatus, along with the funds
+// the wallet service reserved for them.
+func (r *transactionRepository) UpdatePairStatus(ctx context.Context, publicID string, status model.TransactionStatus, reason string) ([]model.Transaction, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// Lock both legs so that concurrent status changes are serialized
+	var transaction model.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("public_id = ?", publicID).Take(&transaction).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	if transaction.ReviewID != nil {
+		tx.Rollback()
+		return nil, model.ErrReviewPair
+	}
+	other, err := findOtherLeg(tx, &transaction)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	transactions := []model.Transaction{transaction, *other}
+	if transaction.OperationType == model.Credit {
+		transactions[0], transactions[1] = transactions[1], transactions[0]
+	}
+
+	// Validate both legs before changing either
+	for _, txn := range transactions {
+		if txn.Status != status && !txn.Status.CanTransitionTo(status) {
+			tx.Rollback()
+			return nil, model.ErrInvalidTransition
+		}
+	}
+	for i := range transactions {
+		if transactions[i].Status == status {
+			continue
+		}
+		if err := setStatus(tx, &transactions[i], status, reason); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	return transactions, tx.Commit().Error
+}
+
+// findOtherLeg locks and returns the other leg of the pair of txn.
+// Legs are found by their pair ID. Transactions recorded before pair IDs were
+// introduced are matched by their review, or otherwise by position: the legs
+// of a pair are inserted debit first in one database transaction and mirror
+// each other, with the wallets swapped and the operation opposite.
+func findOtherLeg(tx *gorm.DB, txn *model.Transaction) (*model.Transaction, error) {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id <> ?", txn.ID)
+	if txn.PairID != "" {
+		query = query.Where("pair_id = ?", txn.PairID)
+	} else if txn.ReviewID != nil {
+		query = query.Where("review_id = ?", *txn.ReviewID)
+	} else {
+		query = query.Where("subject_wallet_id = ? AND object_wallet_id = ? AND transaction_type = ? AND review_id IS NULL",
+			txn.ObjectWalletID, txn.SubjectWalletID, txn.TransactionType)
+		if txn.ReversalOf != nil {
+			query = query.Where("reversal_of_id = ?", *txn.ReversalOf)
+		} else {
+			query = query.Where("reversal_of_id IS NULL")
+		}
+	}
+
+	if txn.OperationType == model.Debit {
+		query = query.Where("operation_type = ? AND id > ?", model.Credit, txn.ID).Order("id")
+	} else {
+		query = query.Where("operation_type = ? AND id < ?", model.Debit, txn.ID).Order("id desc")
+	}
+
+	var other model.Transaction
+	if err := query.Take(&other).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrIncompletePair
+		}
+		return nil, err
+	}
+	return &other, nil
+}
+
+// setStatus changes the status of txn and records the change in the status history.
+func setStatus(tx *gorm.DB, txn *model.Transaction, status model.TransactionStatus, reason string) error {
+	change := model.TransactionStatusChange{
+		TransactionID: txn.ID,
+		FromStatus:    txn.Status,
+		ToStatus:      status,
+		Reason:        reason,
+	}
+	if err := tx.Model(txn).Update("status", status).Error; err != nil {
+		return err
+	}
+	txn.Status = status
+	return tx.Create(&change).Error
+}
@@ -3,9 +3,14 @@ package server
 
 import (
 	"fmt"
+	"os"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/auth"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/controller"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/metrics"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/repository"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/tracing"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/utils"
 	"github.com/labstack/echo/v4/middleware"
 
@@ -29,25 +34,67 @@ func NewAPI(opts TxnAPIServerOpts) (Server, error) {
 	// Initialize global logger
 	utils.InitLogger(logger)
 
+	// Shared secrets are read from the environment, never from the config file.
+	// Running without service authentication must be asked for explicitly, so
+	// that a missing setting never leaves the ledger open to unsigned writes
+	serviceAuth := opts.Config.ServiceAuth
+	switch {
+	case serviceAuth.Enable:
+		if err := serviceAuth.LoadSecrets(os.Getenv); err != nil {
+			return nil, fmt.Errorf("failed to load service credentials: %v", err)
+		}
+	case !serviceAuth.DevMode:
+		return nil, fmt.Errorf("service authentication is disabled: enable serviceAuth, or set serviceAuth.devMode to run without it in development")
+	default:
+		log.Warn("service authentication is disabled in development mode: unsigned writes are accepted from any caller")
+	}
+
 	dbInstance, err := db.New(opts.Config.PostgreSQL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
+	sqlDB, err := dbInstance.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection pool: %v", err)
+	}
+	if err := metrics.RegisterDBStats(sqlDB, opts.Config.PostgreSQL.DBName); err != nil {
+		return nil, fmt.Errorf("failed to register database metrics: %v", err)
+	}
 
 	engine := echo.New()
 
 	// Allow all origins for CORS
 	engine.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{echo.GET, echo.POST, echo.PUT, echo.DELETE},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, controller.HeaderIdempotencyKey, tracing.HeaderRequestID},
+		ExposeHeaders: []string{tracing.HeaderRequestID},
 	}))
 
+	// Every request carries a request ID and a span, for the logs and spans of its work
+	engine.Use(tracing.RequestIDMiddleware(), tracing.Middleware(), metrics.Middleware())
+
+	// Without service authentication, in development mode, callers are trusted
+	var verifier *auth.Verifier
+	if serviceAuth.Enable {
+		verifier, err = auth.NewVerifier(serviceAuth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load service credentials: %v", err)
+		}
+	}
+
+	health := controller.NewHealth(
+		controller.Dependency{Name: "postgres", Hard: true, Timeout: opts.Config.Health.Timeout, Ping: sqlDB.PingContext},
+	)
+
 	s := &txnAPIServer{
-		port:   opts.ListenPort,
-		engine: engine,
-		log:    logger,
-		db:     dbInstance,
+		port:             opts.ListenPort,
+		engine:           engine,
+		log:              logger,
+		db:               dbInstance,
+		verifier:         verifier,
+		health:           health,
+		idempotencyLease: opts.Config.Idempotency.LeaseTimeout,
 	}
 
 	s.setupRoutes(engine)
@@ -78,11 +125,13 @@ func (s *txnAPIServer) setupRoutes(e *echo.Echo) {
 
 	api := e.Group("/api/v1")
 
-	// Health check
-	healthHandler := controller.NewHealth()
-	api.GET("/health", healthHandler.Health)
+	// Health checks
+	api.GET("/health/live", s.health.Live)
+	api.GET("/health/ready", s.health.Ready)
 
 	transactionHandler := s.initTransactionController()
 
-	controller.InitRoutes(api, transactionHandler)
+	idempotency := controller.Idempotency(repository.NewIdempotencyRepository(s.db), s.idempotencyLease)
+
+	controller.InitRoutes(api, transactionHandler, idempotency, controller.Authenticate(s.verifier))
 }
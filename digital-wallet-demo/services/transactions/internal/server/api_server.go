@@ -3,6 +3,10 @@ package server
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/auth"
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/controller"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -10,10 +14,13 @@ import (
 
 // walletAPIServer is the API server for Txn
 type txnAPIServer struct {
-	port   int
-	engine *echo.Echo
-	log    *log.Entry
-	db     *gorm.DB
+	port             int
+	engine           *echo.Echo
+	log              *log.Entry
+	db               *gorm.DB
+	verifier         *auth.Verifier
+	health           controller.HealthHandler
+	idempotencyLease time.Duration
 }
 
 func (s *txnAPIServer) Name() string {
@@ -26,6 +33,12 @@ func (s *txnAPIServer) Run() error {
 	return s.engine.Start(fmt.Sprintf(":%d", s.port))
 }
 
+// Drain makes the Txn API server report not ready, so that load balancers stop routing to it
+func (s *txnAPIServer) Drain() {
+	log.Infof("draining %s serving on port %d", s.Name(), s.port)
+	s.health.Drain()
+}
+
 // Shutdown stops the Txn API server
 func (s *txnAPIServer) Shutdown(ctx context.Context) error {
 	log.Infof("shutting down %s serving on port %d", s.Name(), s.port)
@@ -34,6 +34,7 @@ func TestNewAPI(t *testing.T) {
 						Enable: true,
 						Port:   8082,
 					},
+					ServiceAuth: model.ServiceAuth{DevMode: true},
 				},
 			},
 			wantErr: false,
@@ -55,6 +56,30 @@ func TestNewAPI(t *testing.T) {
 						Enable: true,
 						Port:   8082,
 					},
+					ServiceAuth: model.ServiceAuth{DevMode: true},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Service authentication disabled outside development mode",
+			opts: TxnAPIServerOpts{
+				ListenPort: 8082,
+				Config: model.Config{
+					ServiceAuth: model.ServiceAuth{Enable: false},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Service secret not set outside development mode",
+			opts: TxnAPIServerOpts{
+				ListenPort: 8082,
+				Config: model.Config{
+					ServiceAuth: model.ServiceAuth{
+						Enable:   true,
+						Services: []model.ServiceCredential{{ID: "wallets", SecretEnv: "TEST_UNSET_SERVICE_SECRET"}},
+					},
 				},
 			},
 			wantErr: true,
@@ -1,14 +1,23 @@
 package service
 
 import (
+	"context"
+
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/repository"
+	"github.com/google/uuid"
 )
 
 // TransactionService provides transaction operations
 type TransactionService interface {
-	CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error
-	GetTransactions(subjectWalletID string) ([]model.Transaction, error)
+	CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction) error
+	GetTransactions(ctx context.Context, query model.TransactionQuery) (*model.TransactionPage, error)
+	GetLedgerBalances(ctx context.Context, subjectWalletID string) ([]model.LedgerBalance, error)
+	GetTransaction(ctx context.Context, publicID string) (*model.Transaction, error)
+	GetTransactionPair(ctx context.Context, pairID string) ([]model.Transaction, error)
+	GetLegacyIDs(ctx context.Context, legacyIDs []int64) ([]model.LegacyTransactionID, error)
+	SetReviewStatus(ctx context.Context, reviewID string, status model.TransactionStatus) ([]model.Transaction, error)
+	UpdatePairStatus(ctx context.Context, publicID string, status model.TransactionStatus, reason string) ([]model.Transaction, error)
 }
 
 type transactionService struct {
@@ -20,15 +29,78 @@ func NewTransactionService(repo repository.TransactionRepository) TransactionSer
 	return &transactionService{repo: repo}
 }
 
-// CreateTransactionPair creates both debit and credit transactions atomically
-func (s *transactionService) CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error {
-	return s.repo.CreateTransactionPair(debitTxn, creditTxn)
+// CreateTransactionPair creates both debit and credit transactions atomically.
+// Transactions without a public ID get a new one, and both are linked by a new
+// pair ID unless the debit carries one.
+func (s *transactionService) CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction) error {
+	for _, txn := range []*model.Transaction{debitTxn, creditTxn} {
+		if txn.PublicID == "" {
+			txn.PublicID = model.NewPublicID()
+		}
+	}
+	if debitTxn.PairID == "" {
+		debitTxn.PairID = uuid.NewString()
+	}
+	creditTxn.PairID = debitTxn.PairID
+	return s.repo.CreateTransactionPair(ctx, debitTxn, creditTxn)
+}
+
+// GetTransactions retrieves a page of transactions for a specific wallet
+func (s *transactionService) GetTransactions(ctx context.Context, query model.TransactionQuery) (*model.TransactionPage, error) {
+	if query.Limit <= 0 {
+		query.Limit = model.DefaultPageLimit
+	}
+	if query.Limit > model.MaxPageLimit {
+		query.Limit = model.MaxPageLimit
+	}
+	limit := query.Limit
+
+	// Fetch one extra row to find out whether there is a next page
+	query.Limit++
+	transactions, err := s.repo.FindTransactions(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.TransactionPage{Transactions: transactions, Limit: limit}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		page.NextCursor = model.NewTransactionCursor(transactions[limit-1]).Encode()
+	}
+	return page, nil
 }
 
-// GetTransactions retrieves all transactions for a specific wallet
-func (s *transactionService) GetTransactions(subjectWalletID string) ([]model.Transaction, error) {
-	filters := map[string]interface{}{
-		"subject_wallet_id": subjectWalletID,
+// GetLedgerBalances returns the net of completed credits and debits per wallet.
+// An empty subjectWalletID returns the balances of all wallets.
+func (s *transactionService) GetLedgerBalances(ctx context.Context, subjectWalletID string) ([]model.LedgerBalance, error) {
+	filters := map[string]interface{}{}
+	if subjectWalletID != "" {
+		filters["subject_wallet_id"] = subjectWalletID
 	}
-	return s.repo.FindAllTransactions(filters)
+	return s.repo.SumLedgerBalances(ctx, filters)
+}
+
+// GetTransaction retrieves a single transaction by public ID
+func (s *transactionService) GetTransaction(ctx context.Context, publicID string) (*model.Transaction, error) {
+	return s.repo.FindTransaction(ctx, publicID)
+}
+
+// GetTransactionPair retrieves both transactions of a pair
+func (s *transactionService) GetTransactionPair(ctx context.Context, pairID string) ([]model.Transaction, error) {
+	return s.repo.FindPair(ctx, pairID)
+}
+
+// GetLegacyIDs retrieves the public IDs of transactions recorded before public IDs by their serial IDs
+func (s *transactionService) GetLegacyIDs(ctx context.Context, legacyIDs []int64) ([]model.LegacyTransactionID, error) {
+	return s.repo.FindLegacyIDs(ctx, legacyIDs)
+}
+
+// SetReviewStatus settles the pending pair of a review with the given status
+func (s *transactionService) SetReviewStatus(ctx context.Context, reviewID string, status model.TransactionStatus) ([]model.Transaction, error) {
+	return s.repo.SetReviewStatus(ctx, reviewID, status)
+}
+
+// UpdatePairStatus moves both legs of the pair of a transaction to the given status
+func (s *transactionService) UpdatePairStatus(ctx context.Context, publicID string, status model.TransactionStatus, reason string) ([]model.Transaction, error) {
+	return s.repo.UpdatePairStatus(ctx, publicID, status, reason)
 }
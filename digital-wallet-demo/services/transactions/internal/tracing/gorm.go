@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// GormPlugin records a span for every query run with the context of a traced
+// request or job, as a child of its span. Queries outside a trace are not recorded.
+type GormPlugin struct{}
+
+// Name returns the name of the plugin.
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registers the callbacks starting and ending the span of each query.
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register("tracing:before_create", startQuerySpan("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", endQuerySpan),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", startQuerySpan("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", endQuerySpan),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", startQuerySpan("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", endQuerySpan),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", startQuerySpan("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", endQuerySpan),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", startQuerySpan("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", endQuerySpan),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", startQuerySpan("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", endQuerySpan),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startQuerySpan(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+		_, span := Tracer().Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNamePostgreSQL, semconv.DBOperationName(operation)),
+		)
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func endQuerySpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if tx.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(tx.Statement.Table))
+	}
+	span.SetAttributes(
+		semconv.DBQueryText(tx.Statement.SQL.String()),
+		semconv.DBResponseReturnedRows(int(tx.Statement.RowsAffected)),
+	)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+}
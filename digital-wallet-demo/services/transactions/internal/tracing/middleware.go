@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware returns a middleware recording a server span per request. The
+// span continues the trace of the caller's traceparent header, if any, and is
+// stored in the request context for the spans of the work done by the handler.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			route := c.Path()
+			if route == "" {
+				route = req.URL.Path
+			}
+
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			ctx, span := Tracer().Start(ctx, req.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(req.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(req.URL.Path),
+				),
+			)
+			defer span.End()
+			if id := RequestID(ctx); id != "" {
+				span.SetAttributes(attribute.String("request.id", id))
+			}
+			c.SetRequest(req.WithContext(ctx))
+
+			// Handle errors here, so that the status of the response is known
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return nil
+		}
+	}
+}
@@ -1,14 +1,32 @@
 package utils
 
 import (
+	"context"
+	"sync"
+
+	"github.com/fardinabir/digital-wallet-demo/services/transactions/internal/tracing"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var Logger *log.Entry
 
+var hookOnce sync.Once
+
 // InitLogger initializes the global logger instance
 func InitLogger(loggerInstance *log.Entry) {
 	Logger = loggerInstance
+	hookOnce.Do(func() {
+		loggerInstance.Logger.AddHook(contextHook{})
+	})
+}
+
+// WithContext returns the global logger with the request ID and trace of ctx attached
+func WithContext(ctx context.Context) *log.Entry {
+	if Logger == nil {
+		return log.WithContext(ctx)
+	}
+	return Logger.WithContext(ctx)
 }
 
 // LogError logs an error message with context
@@ -24,3 +42,31 @@ func LogErrorf(format string, args ...interface{}) {
 		Logger.Errorf(format, args...)
 	}
 }
+
+// LogErrorContext logs an error message with the request ID and trace of ctx
+func LogErrorContext(ctx context.Context, message string, err error) {
+	if Logger != nil {
+		Logger.WithContext(ctx).WithError(err).Error(message)
+	}
+}
+
+// contextHook adds the request ID and trace of an entry's context to its fields.
+type contextHook struct{}
+
+func (contextHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (contextHook) Fire(entry *log.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	if id := tracing.RequestID(entry.Context); id != "" {
+		entry.Data["request_id"] = id
+	}
+	if sc := trace.SpanContextFromContext(entry.Context); sc.IsValid() {
+		entry.Data["trace_id"] = sc.TraceID().String()
+		entry.Data["span_id"] = sc.SpanID().String()
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+-- Idempotency Key Schema
+-- Stores the outcome of requests sent with an Idempotency-Key header so that
+-- retried requests are answered with the original response instead of being
+-- executed twice
+
+-- Create idempotency_records table
+CREATE TABLE IF NOT EXISTS idempotency_records (
+    key VARCHAR(255) PRIMARY KEY,
+    request_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(50) NOT NULL CHECK (status IN ('in_progress', 'completed')),
+    status_code INTEGER,
+    response_body TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create index on creation time for purging old keys
+CREATE INDEX IF NOT EXISTS idx_idempotency_records_created_at ON idempotency_records(created_at);
+
+-- Create triggers for automatic updated_at timestamp updates
+DROP TRIGGER IF EXISTS update_idempotency_records_updated_at ON idempotency_records;
+CREATE TRIGGER update_idempotency_records_updated_at
+    BEFORE UPDATE ON idempotency_records
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments to tables and columns for documentation
+COMMENT ON TABLE idempotency_records IS 'Stored responses for requests sent with an Idempotency-Key header';
+COMMENT ON COLUMN idempotency_records.key IS 'Client supplied Idempotency-Key header value';
+COMMENT ON COLUMN idempotency_records.request_hash IS 'SHA-256 of the route and canonical JSON body of the original request';
+COMMENT ON COLUMN idempotency_records.status IS 'Processing status: in_progress or completed';
+COMMENT ON COLUMN idempotency_records.status_code IS 'HTTP status code of the stored response';
+COMMENT ON COLUMN idempotency_records.response_body IS 'Body of the stored response';
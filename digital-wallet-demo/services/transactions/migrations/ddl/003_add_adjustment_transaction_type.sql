@@ -0,0 +1,10 @@
+-- Adjustment Transaction Type
+-- Allows the 'adjustment' transaction type recorded by the wallet service
+-- reconcile command when it compensates drift between wallet balances and the ledger
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('deposit', 'withdraw', 'transfer', 'adjustment'));
+
+-- Create composite index used to sum completed transactions per wallet
+CREATE INDEX IF NOT EXISTS idx_transactions_status_subject_wallet_id ON transactions(status, subject_wallet_id);
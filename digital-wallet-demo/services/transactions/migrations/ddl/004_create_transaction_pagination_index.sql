@@ -0,0 +1,6 @@
+-- Transaction History Pagination
+-- Composite index backing keyset pagination of a wallet's transactions,
+-- ordered newest first by (created_at, public_id)
+
+CREATE INDEX IF NOT EXISTS idx_transactions_subject_wallet_id_created_at_public_id
+    ON transactions(subject_wallet_id, created_at DESC, public_id DESC);
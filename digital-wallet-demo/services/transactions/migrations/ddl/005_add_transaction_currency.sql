@@ -0,0 +1,11 @@
+-- Transaction Currency
+-- Records the ISO-4217 currency of every transaction. Amounts are in minor
+-- units of that currency. Existing transactions were all made in USD
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Replace the index used to sum completed transactions so balances can be grouped per currency
+DROP INDEX IF EXISTS idx_transactions_status_subject_wallet_id;
+CREATE INDEX IF NOT EXISTS idx_transactions_status_subject_wallet_id_currency ON transactions(status, subject_wallet_id, currency);
+
+COMMENT ON COLUMN transactions.currency IS 'ISO-4217 currency code of the amount';
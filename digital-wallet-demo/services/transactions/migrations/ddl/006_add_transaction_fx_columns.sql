@@ -0,0 +1,11 @@
+-- Currency Conversion
+-- Records the exchange rate and the other side's amount on both transactions
+-- of a cross-currency transfer pair
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fx_rate VARCHAR(32);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS counter_amount BIGINT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS counter_currency VARCHAR(3);
+
+COMMENT ON COLUMN transactions.fx_rate IS 'Price of one unit of the debited currency in the credited currency, set on conversions only';
+COMMENT ON COLUMN transactions.counter_amount IS 'Amount of the other transaction of a conversion pair, in minor units of counter_currency';
+COMMENT ON COLUMN transactions.counter_currency IS 'Currency of the other transaction of a conversion pair';
@@ -0,0 +1,10 @@
+-- Transaction Reversals
+-- A reversal is a compensating transaction pair whose transactions both
+-- reference the transaction they reverse
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reversal_of INTEGER;
+
+-- Create index to find the reversals of a transaction
+CREATE INDEX IF NOT EXISTS idx_transactions_reversal_of ON transactions(reversal_of);
+
+COMMENT ON COLUMN transactions.reversal_of IS 'ID of the transaction reversed by this one, set on reversal pairs only';
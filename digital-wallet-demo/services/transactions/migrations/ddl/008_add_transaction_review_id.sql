@@ -0,0 +1,11 @@
+-- Transaction Reviews
+-- A transfer or withdrawal held for review by the wallet service is recorded
+-- as a pending pair whose transactions both reference the review, and is
+-- settled as completed or cancelled once the review is decided
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS review_id VARCHAR(32);
+
+-- Create index to find the transactions of a review
+CREATE INDEX IF NOT EXISTS idx_transactions_review_id ON transactions(review_id);
+
+COMMENT ON COLUMN transactions.review_id IS 'ID of the wallet service review deciding the pair, set on reviewed pairs only';
@@ -0,0 +1,23 @@
+-- Transaction Status History
+-- Every status change of a transaction is recorded in the same database
+-- transaction as the change itself. Entries are never updated or deleted
+
+-- Create transaction_status_history table
+CREATE TABLE IF NOT EXISTS transaction_status_history (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL,
+    from_status VARCHAR(50) NOT NULL CHECK (from_status IN ('pending', 'completed', 'failed', 'cancelled')),
+    to_status VARCHAR(50) NOT NULL CHECK (to_status IN ('pending', 'completed', 'failed', 'cancelled')),
+    reason VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create index to find the history of a transaction
+CREATE INDEX IF NOT EXISTS idx_transaction_status_history_transaction_id ON transaction_status_history(transaction_id);
+
+-- Add comments to tables and columns for documentation
+COMMENT ON TABLE transaction_status_history IS 'Append-only audit trail of transaction status changes';
+COMMENT ON COLUMN transaction_status_history.transaction_id IS 'Transaction whose status changed';
+COMMENT ON COLUMN transaction_status_history.from_status IS 'Status before the change';
+COMMENT ON COLUMN transaction_status_history.to_status IS 'Status after the change';
+COMMENT ON COLUMN transaction_status_history.reason IS 'Reason given for the change, or the review that settled the pair';
@@ -0,0 +1,12 @@
+-- Transaction Pairs
+-- The debit and credit transactions of a pair share a pair ID, generated by
+-- the caller or the transaction service, and the caller's reference
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS pair_id VARCHAR(36);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reference VARCHAR(128);
+
+-- Create index to find the transactions of a pair
+CREATE INDEX IF NOT EXISTS idx_transactions_pair_id ON transactions(pair_id);
+
+COMMENT ON COLUMN transactions.pair_id IS 'UUID shared by the debit and credit transactions of a pair, NULL on transactions recorded before pairs were linked';
+COMMENT ON COLUMN transactions.reference IS 'Client supplied reference of the pair';
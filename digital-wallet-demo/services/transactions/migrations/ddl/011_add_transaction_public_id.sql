@@ -0,0 +1,47 @@
+-- Public Transaction IDs
+-- Transactions are identified in the API by a time-ordered UUIDv7 public ID.
+-- The serial id stays the primary key, used for joins and ordering only
+
+-- uuid_v7_at returns a UUIDv7 for the given time: the Unix time in
+-- milliseconds followed by random bits, with the version set to 7
+CREATE OR REPLACE FUNCTION uuid_v7_at(ts TIMESTAMP WITH TIME ZONE)
+RETURNS UUID AS $$
+    SELECT encode(
+        set_bit(
+            set_bit(
+                overlay(uuid_send(gen_random_uuid())
+                    PLACING substring(int8send(floor(extract(epoch FROM ts) * 1000)::BIGINT) FROM 3)
+                    FROM 1 FOR 6),
+                52, 1),
+            53, 1),
+        'hex')::UUID;
+$$ LANGUAGE SQL VOLATILE;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS public_id UUID;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reversal_of_id UUID;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS legacy_id BIGINT;
+
+-- Backfill the public IDs of existing transactions from their creation time,
+-- so that they sort among newer ones in creation order without revealing the
+-- serial ID. legacy_id keeps the serial ID these transactions were known by,
+-- for the wallet service to look up the public ID of the IDs it stored.
+-- The service sets the public ID of new transactions, the default covers rows
+-- inserted by SQL such as the sample data
+UPDATE transactions SET public_id = uuid_v7_at(created_at), legacy_id = id WHERE public_id IS NULL;
+ALTER TABLE transactions ALTER COLUMN public_id SET DEFAULT uuid_v7_at(clock_timestamp());
+
+-- Reversals reference the public ID of the transaction they reverse
+UPDATE transactions AS reversal
+SET reversal_of_id = reversed.public_id
+FROM transactions AS reversed
+WHERE reversal.reversal_of = reversed.id AND reversal.reversal_of_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_public_id ON transactions(public_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_reversal_of_id ON transactions(reversal_of_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_legacy_id ON transactions(legacy_id);
+
+COMMENT ON COLUMN transactions.id IS 'Internal primary key for transaction records, not exposed in the API';
+COMMENT ON COLUMN transactions.public_id IS 'UUIDv7 identifying the transaction in the API';
+COMMENT ON COLUMN transactions.reversal_of_id IS 'Public ID of the transaction reversed by this one, set on reversal pairs only';
+COMMENT ON COLUMN transactions.legacy_id IS 'Serial ID a transaction recorded before public IDs was known by, looked up by the wallet service';
+COMMENT ON COLUMN transactions.reversal_of IS 'Superseded by reversal_of_id, internal ID of the reversed transaction on reversals recorded before public IDs';
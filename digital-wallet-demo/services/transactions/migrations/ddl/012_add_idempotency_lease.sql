@@ -0,0 +1,8 @@
+-- Idempotency Key Leases
+-- A request holds its Idempotency-Key until locked_until. A request that stops
+-- without storing its response, such as on a crash, no longer blocks the key:
+-- a retry of the same request takes it over once the lease has ended
+
+ALTER TABLE idempotency_records ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+COMMENT ON COLUMN idempotency_records.locked_until IS 'End of the lease of an in_progress request, after which a retry of the same request takes the key over';
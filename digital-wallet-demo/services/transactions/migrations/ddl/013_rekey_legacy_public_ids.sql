@@ -0,0 +1,23 @@
+-- Legacy Public IDs
+-- Transactions recorded before public IDs were first given a public ID made
+-- of a zero timestamp followed by their serial ID, which revealed the serial
+-- ID and was not a valid UUIDv7. They get a UUIDv7 of their creation time
+-- instead, and keep their serial ID in legacy_id for the wallet service to
+-- look up the public ID of the IDs it stored
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS legacy_id BIGINT;
+
+UPDATE transactions
+SET public_id = uuid_v7_at(created_at), legacy_id = id
+WHERE public_id::TEXT LIKE '00000000-0000-7000-8000-%';
+
+-- Reversals of legacy transactions follow the new public ID
+UPDATE transactions AS reversal
+SET reversal_of_id = reversed.public_id
+FROM transactions AS reversed
+WHERE reversal.reversal_of = reversed.id AND reversal.reversal_of_id IS DISTINCT FROM reversed.public_id;
+
+DROP FUNCTION IF EXISTS legacy_transaction_public_id(BIGINT);
+
+-- Pages are ordered by (created_at, public_id), the internal ID is no longer part of the cursor
+DROP INDEX IF EXISTS idx_transactions_subject_wallet_id_created_at_id;
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reconcileFormat      string
+	reconcileOutput      string
+	reconcileOnlyDrift   bool
+	reconcileCompensate  bool
+	reconcileFailOnDrift bool
+)
+
+// reconcileCmd represents the reconcile command
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Check wallet balances against the transaction ledger",
+	Long: `Sums credits minus debits of completed transactions per wallet and currency
+from the transaction service and compares them to each wallet's stored balance
+in that currency. Outbox entries not yet delivered are counted as in flight.
+
+With --compensate, wallets whose drift is confirmed by a second check receive
+an adjustment pair against the reconciliation-suspense account, delivered to
+the transaction service by the outbox relay.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		if reconcileFormat != "json" && reconcileFormat != "csv" {
+			log.Fatalf("unsupported format %q, expected json or csv", reconcileFormat)
+		}
+
+		dbInstance, err := db.New(cfg.PostgreSQL)
+		if err != nil {
+			log.Fatalf("failed to connect to database: %s", err)
+			return
+		}
+
+		reconciler := service.NewReconciliationService(repository.NewWalletRepo(dbInstance), repository.NewOutboxRepo(dbInstance), repository.NewAuditRepo(dbInstance), client.NewTxnClient(cfg.Services.Transaction))
+		report, err := reconciler.Reconcile(cmd.Context())
+		if err != nil {
+			log.Fatalf("failed to reconcile: %s", err)
+		}
+
+		if reconcileCompensate && report.Drifted > 0 {
+			compensated, err := reconciler.Compensate(cmd.Context(), report, model.AuditContext{Actor: model.ReconcileActor})
+			if err != nil {
+				log.Fatalf("failed to compensate drift: %s", err)
+			}
+			for _, w := range compensated {
+				log.Infof("enqueued adjustment of %d %s for wallet %s", w.Drift, w.Currency, w.WalletID)
+			}
+		}
+
+		out := io.Writer(os.Stdout)
+		if reconcileOutput != "" {
+			f, err := os.Create(reconcileOutput)
+			if err != nil {
+				log.Fatalf("failed to create output file: %s", err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if reconcileOnlyDrift {
+			report = report.OnlyDrift()
+		}
+		if reconcileFormat == "csv" {
+			err = writeReconciliationCSV(out, report)
+		} else {
+			err = writeReconciliationJSON(out, report)
+		}
+		if err != nil {
+			log.Fatalf("failed to write report: %s", err)
+		}
+
+		fmt.Fprintf(os.Stderr, "Reconciled %d wallets, %d drifted, total drift %d\n", report.Checked, report.Drifted, report.TotalDrift)
+		if reconcileFailOnDrift && report.Drifted > 0 {
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	reconcileCmd.Flags().StringVar(&reconcileFormat, "format", "json", "report format: json or csv")
+	reconcileCmd.Flags().StringVarP(&reconcileOutput, "output", "o", "", "write the report to a file instead of stdout")
+	reconcileCmd.Flags().BoolVar(&reconcileOnlyDrift, "only-drift", false, "only report wallets that do not reconcile")
+	reconcileCmd.Flags().BoolVar(&reconcileCompensate, "compensate", false, "enqueue adjustment pairs for wallets with stable drift")
+	reconcileCmd.Flags().BoolVar(&reconcileFailOnDrift, "fail-on-drift", false, "exit with status 2 if any wallet drifted")
+	rootCmd.AddCommand(reconcileCmd)
+}
+
+func writeReconciliationJSON(w io.Writer, report *model.ReconciliationReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func writeReconciliationCSV(w io.Writer, report *model.ReconciliationReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"wallet_id", "currency", "status", "balance", "ledger", "in_flight", "drift"}); err != nil {
+		return err
+	}
+	for _, r := range report.Wallets {
+		row := []string{
+			r.WalletID,
+			string(r.Currency),
+			string(r.Status),
+			strconv.FormatInt(r.Balance, 10),
+			strconv.FormatInt(r.Ledger, 10),
+			strconv.FormatInt(r.InFlight, 10),
+			strconv.FormatInt(r.Drift, 10),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
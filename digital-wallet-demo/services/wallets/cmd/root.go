@@ -3,8 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
-	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"github.com/go-playground/validator/v10"
 	log "github.com/sirupsen/logrus"
@@ -57,7 +58,10 @@ func initConfig() {
 		viper.AddConfigPath(".")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	// Read in environment variables that match, with nested keys joined by
+	// underscores: AUTH_SECRET sets auth.secret
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
@@ -67,6 +71,49 @@ func initConfig() {
 	cfg = model.Config{
 		APIServer:     model.Server{Enable: true, Port: 8081},
 		SwaggerServer: model.Server{Enable: false, Port: 1314},
+		MetricsServer: model.Server{Enable: false, Port: 9091},
+		Worker:        model.Worker{Enable: true},
+		Services: model.Services{
+			Transaction: model.Service{
+				Timeout: 5 * time.Second,
+				Retry: model.Retry{
+					MaxAttempts: 3,
+					BaseBackoff: 100 * time.Millisecond,
+					MaxBackoff:  2 * time.Second,
+				},
+				Breaker: model.Breaker{
+					FailureThreshold: 5,
+					OpenTimeout:      30 * time.Second,
+				},
+			},
+		},
+		Outbox: model.Outbox{
+			PollInterval: 2 * time.Second,
+			BatchSize:    50,
+			BaseBackoff:  time.Second,
+			MaxBackoff:   5 * time.Minute,
+			MaxAttempts:  20,
+			LeaseTimeout: 5 * time.Minute,
+		},
+		FX: model.FX{
+			QuoteTTL: 30 * time.Second,
+		},
+		Holds: model.Holds{
+			TTL:            7 * 24 * time.Hour,
+			ExpiryInterval: time.Minute,
+		},
+		Schedules: model.Schedules{
+			PollInterval: 10 * time.Second,
+			BatchSize:    50,
+		},
+		Tracing: model.Tracing{
+			Exporter:    "stdout",
+			SampleRatio: 1,
+		},
+		Health: model.Health{
+			Timeout:    2 * time.Second,
+			DrainDelay: 5 * time.Second,
+		},
 	}
 
 	err := viper.Unmarshal(&cfg)
@@ -78,7 +125,4 @@ func initConfig() {
 	if err := validate.Struct(&cfg); err != nil {
 		log.Fatalf("config validation failed: %v", err)
 	}
-
-	// Set the global config for access from other packages
-	config.SetGlobalConfig(&cfg)
 }
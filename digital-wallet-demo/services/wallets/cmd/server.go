@@ -3,13 +3,20 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/server"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -31,9 +38,41 @@ var serverCmd = &cobra.Command{
 func runServe(cfg model.Config) error {
 	var servers []server.Server
 
+	// The secret shared with the transaction service is read from the environment, never from the config file
+	if err := cfg.Services.Transaction.LoadSecret(os.Getenv); err != nil {
+		return err
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		return err
+	}
+
+	// The API server and the worker share one database pool, Redis client and
+	// transaction client, so that they share the circuit breaker of the
+	// transaction service and the pool metrics cover both
+	dbInstance, err := db.New(cfg.PostgreSQL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	sqlDB, err := dbInstance.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection pool: %v", err)
+	}
+	defer sqlDB.Close()
+	if err := metrics.RegisterDBStats(sqlDB, cfg.PostgreSQL.DBName); err != nil {
+		return fmt.Errorf("failed to register database metrics: %v", err)
+	}
+	redisClient := cache.NewRedisClient(cfg.Redis)
+	defer redisClient.Close()
+	txnClient := client.NewTxnClient(cfg.Services.Transaction)
+
 	apiOpts := server.WalletAPIServerOpts{
-		ListenPort: cfg.APIServer.Port,
-		Config:     cfg,
+		ListenPort:  cfg.APIServer.Port,
+		Config:      cfg,
+		DB:          dbInstance,
+		RedisClient: redisClient,
+		TxnClient:   txnClient,
 	}
 	apiServer, err := server.NewAPI(apiOpts)
 	if err != nil {
@@ -41,6 +80,20 @@ func runServe(cfg model.Config) error {
 	}
 	servers = append(servers, apiServer)
 
+	if cfg.Worker.Enable {
+		workerOpts := server.WorkerOpts{
+			Config:      cfg,
+			DB:          dbInstance,
+			RedisClient: redisClient,
+			TxnClient:   txnClient,
+		}
+		workerServer, err := server.NewWorker(workerOpts)
+		if err != nil {
+			return err
+		}
+		servers = append(servers, workerServer)
+	}
+
 	if cfg.SwaggerServer.Enable {
 		SwaggerOpts := server.SwaggerServerOpts{
 			ListenPort: cfg.SwaggerServer.Port,
@@ -49,7 +102,14 @@ func runServe(cfg model.Config) error {
 		servers = append(servers, swagServer)
 	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if cfg.MetricsServer.Enable {
+		metricsOpts := server.MetricsServerOpts{
+			ListenPort: cfg.MetricsServer.Port,
+		}
+		servers = append(servers, server.NewMetrics(metricsOpts))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
 	for _, s := range servers {
@@ -65,6 +125,15 @@ func runServe(cfg model.Config) error {
 	// Wait for interrupt signal to gracefully shutdown the server with a timeout of 10 seconds.
 	<-ctx.Done()
 	log.Info("server shutting down")
+
+	// Report not ready first, and keep serving until load balancers stop routing here
+	for _, s := range servers {
+		if d, ok := s.(server.Drainer); ok {
+			d.Drain()
+		}
+	}
+	time.Sleep(cfg.Health.DrainDelay)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -73,6 +142,10 @@ func runServe(cfg model.Config) error {
 			return err
 		}
 	}
+	// Flush the spans of the requests served until shutdown
+	if err := shutdownTracing(ctx); err != nil {
+		log.WithError(err).Error("failed to flush spans")
+	}
 	log.Info("server shutdown gracefully")
 	return nil
 }
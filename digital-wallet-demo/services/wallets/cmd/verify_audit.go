@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// verifyAuditCmd represents the verify-audit command
+var verifyAuditCmd = &cobra.Command{
+	Use:   "verify-audit",
+	Short: "Verify the hash chain of the audit log",
+	Long: `Walks the audit log from its first entry and recomputes the hash of every
+entry. Each entry must hold the hash of the entry before it and its stored hash
+must match its contents. The first entry breaking the chain is reported and the
+command exits with status 2; entries after it are not checked.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		dbInstance, err := db.New(cfg.PostgreSQL)
+		if err != nil {
+			log.Fatalf("failed to connect to database: %s", err)
+			return
+		}
+
+		result, err := service.NewAuditService(repository.NewAuditRepo(dbInstance)).Verify(cmd.Context())
+		if err != nil {
+			log.Fatalf("failed to verify audit log: %s", err)
+		}
+
+		if result.BrokenAt != nil {
+			b := result.BrokenAt
+			fmt.Fprintf(os.Stderr, "Audit chain broken at entry %d (%s by %s at %s) after %d intact entries: %s\n",
+				b.ID, b.Action, b.Actor, b.CreatedAt.Format("2006-01-02T15:04:05.000000Z07:00"), result.Entries, result.Reason)
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "Verified %d audit entries, chain intact\n", result.Entries)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyAuditCmd)
+}
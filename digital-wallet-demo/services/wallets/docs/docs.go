@@ -18,15 +18,20 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
+        "/admin/limits": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "admin"
                 ],
-                "summary": "Health check",
+                "summary": "List spending limits",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -39,18 +44,33 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.SpendingLimit"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
                     }
                 }
             }
         },
-        "/users": {
-            "post": {
+        "/admin/limits/types/{acnt_type}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Bounds the withdrawals, transfers and hold captures of every wallet of the account type without a limit of its own.",
                 "consumes": [
                     "application/json"
                 ],
@@ -58,23 +78,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallets"
+                    "admin"
                 ],
-                "summary": "Create a new wallet",
+                "summary": "Set the spending limit of an account type",
                 "parameters": [
                     {
-                        "description": "json",
+                        "enum": [
+                            "user",
+                            "provider"
+                        ],
+                        "type": "string",
+                        "description": "Account type",
+                        "name": "acnt_type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Limit bounds",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.CreateRequest"
+                            "$ref": "#/definitions/controller.LimitBounds"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -84,7 +115,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Wallet"
+                                            "$ref": "#/definitions/model.SpendingLimit"
                                         }
                                     }
                                 }
@@ -104,49 +135,33 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/wallets/deposit": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
                 "tags": [
-                    "wallets"
+                    "admin"
                 ],
-                "summary": "Deposit money to wallet",
+                "summary": "Remove the spending limit of an account type",
                 "parameters": [
                     {
-                        "description": "Deposit request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller.DepositRequest"
-                        }
+                        "enum": [
+                            "user",
+                            "provider"
+                        ],
+                        "type": "string",
+                        "description": "Account type",
+                        "name": "acnt_type",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/controller.ResponseData"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/model.Transaction"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -169,8 +184,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/wallets/transfer": {
-            "post": {
+        "/admin/limits/wallets/{user_id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Bounds the withdrawals, transfers and hold captures of the wallet, in place of the limit of its account type. Outgoing amounts in other currencies are converted at the current rate. Transactions over a limit fail with LIMIT_EXCEEDED.",
                 "consumes": [
                     "application/json"
                 ],
@@ -178,23 +199,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallets"
+                    "admin"
                 ],
-                "summary": "Transfer money between wallets",
+                "summary": "Set the spending limit of a wallet",
                 "parameters": [
                     {
-                        "description": "Transfer request",
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Limit bounds",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.TransferRequest"
+                            "$ref": "#/definitions/controller.LimitBounds"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -204,7 +232,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Transaction"
+                                            "$ref": "#/definitions/model.SpendingLimit"
                                         }
                                     }
                                 }
@@ -223,8 +251,46 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ResponseError"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The limit of the wallet's account type applies again.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove the spending limit of a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/controller.ResponseError"
                         }
@@ -238,8 +304,59 @@ const docTemplate = `{
                 }
             }
         },
-        "/wallets/withdraw": {
-            "post": {
+        "/admin/review-flags": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List wallets flagged for review",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.ReviewFlag"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/review-flags/{user_id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues every later withdrawal and transfer of the wallet for review, whatever the risk rules decide.",
                 "consumes": [
                     "application/json"
                 ],
@@ -247,23 +364,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallets"
+                    "admin"
                 ],
-                "summary": "Withdraw money from wallet",
+                "summary": "Flag a wallet for review",
                 "parameters": [
                     {
-                        "description": "Withdraw request",
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Flag reason",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.WithdrawRequest"
+                            "$ref": "#/definitions/controller.FlagRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -273,7 +396,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Transaction"
+                                            "$ref": "#/definitions/model.ReviewFlag"
                                         }
                                     }
                                 }
@@ -292,8 +415,45 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ResponseError"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove the review flag of a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/controller.ResponseError"
                         }
@@ -307,19 +467,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/wallets/{user_id}": {
+        "/admin/reviews": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the transfers and withdrawals queued for review, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "wallets"
+                    "admin"
                 ],
-                "summary": "View wallet balance \u0026 transaction history",
+                "summary": "List the review queue",
                 "parameters": [
                     {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected"
+                        ],
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Filter by review status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "risk",
+                            "flagged"
+                        ],
+                        "type": "string",
+                        "description": "Filter by reason for review",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "withdraw",
+                            "transfer"
+                        ],
+                        "type": "string",
+                        "description": "Filter by transaction type",
+                        "name": "transaction_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by sender or receiver",
                         "name": "user_id",
-                        "in": "path",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Queued at or after (RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Queued before (RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -334,7 +545,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller.WalletResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Review"
+                                            }
                                         }
                                     }
                                 }
@@ -347,12 +561,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ResponseError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/controller.ResponseError"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -361,131 +569,2655 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controller.CreateRequest": {
-            "type": "object",
-            "required": [
-                "acnt_type",
-                "user_id"
-            ],
+        },
+        "/admin/reviews/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Review"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves the reserved funds to the receiver and completes the pending transaction pair in the ledger.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Approve a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Decision note",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controller.DecideReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Review"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Releases the reserved funds and cancels the pending transaction pair in the ledger.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reject a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Decision note",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controller.DecideReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Review"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/fx/quotes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Locks the current exchange rate for a short window. Pass the quote id as quote_id of a transfer with the same currencies and amount to convert at this rate. A quote can be used once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fx"
+                ],
+                "summary": "Quote a currency conversion",
+                "parameters": [
+                    {
+                        "description": "Quote request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.QuoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.FXQuote"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Reports that the process serves requests, without checking its dependencies.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.HealthStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Checks the dependencies of the instance. Returns 503 while a hard dependency is down or the instance is draining for shutdown.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.HealthStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.HealthStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Create a new wallet",
+                "parameters": [
+                    {
+                        "description": "json",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Wallet"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/deposit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Deposit money to wallet",
+                "parameters": [
+                    {
+                        "description": "Deposit request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.DepositRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/holds": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reserves an amount of the sender's balance for the receiver. The held amount is deducted from the available balance but stays in the balance until the hold is captured. Holds expire after a configured TTL.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Place a hold on wallet funds",
+                "parameters": [
+                    {
+                        "description": "Hold request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.HoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Hold"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/holds/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Get a hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Hold"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/holds/{id}/capture": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Transfers all or part of the held amount to the hold's receiver. The remainder of a partial capture is released. Only the receiver may capture a hold.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Capture a hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Capture request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controller.CaptureRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/holds/{id}/void": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Releases the held amount without moving any funds. Only the sender may void a hold.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Void a hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Hold"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/transactions/{id}/reverse": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves the amount of a completed transaction back with a compensating transaction pair that references it. An amount below the transaction amount makes a partial refund. A transaction can be reversed once, through either the debit or the credit of its pair.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Reverse a transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reverse request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ReverseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/transfer": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "A transfer with a to_currency different from currency is converted at the current rate, or at the rate locked by quote_id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Transfer money between wallets",
+                "parameters": [
+                    {
+                        "description": "Transfer request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.TransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Held for review as pending",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/withdraw": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Withdraw money from wallet",
+                "parameters": [
+                    {
+                        "description": "Withdraw request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.WithdrawRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Held for review as pending",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Transaction"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{user_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Transactions are returned newest first, one page at a time. Pass next_cursor as cursor to fetch the next page. While the transaction service is unavailable, the wallet is returned without transactions and with transactions_unavailable set.",
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "View wallet balance \u0026 transaction history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "deposit",
+                            "withdraw",
+                            "transfer",
+                            "adjustment"
+                        ],
+                        "type": "string",
+                        "description": "Filter by transaction type",
+                        "name": "transaction_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "debit",
+                            "credit"
+                        ],
+                        "type": "string",
+                        "description": "Filter by operation type",
+                        "name": "operation_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "completed",
+                            "failed",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum amount in cents (inclusive)",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum amount in cents (inclusive)",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.WalletResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{user_id}/schedules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "List scheduled transfers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID of the sender",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Schedule"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Schedules a transfer from the wallet once at start_at, or on a daily, weekly, monthly or cron recurrence from start_at. Runs that fail, for example on insufficient funds, are recorded on the schedule and do not stop a recurring schedule.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Schedule a transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID of the sender",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.CreateScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Schedule"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{user_id}/schedules/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Get a scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID of the sender",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Schedule"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Delete a scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID of the sender",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the receiver, amount or timing of a schedule with runs left. A change of timing moves the next run to the first occurrence from now.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Change a scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID of the sender",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule change request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UpdateScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Schedule"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{user_id}/schedules/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Pause a scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID of the sender",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Schedule"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{user_id}/schedules/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Occurrences of a recurring schedule missed while it was paused are skipped. A one-off transfer that became due while paused runs right away.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Resume a scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID of the sender",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Schedule"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{user_id}/status": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Suspended, inactive and closed wallets cannot send or receive funds. Closing is final and requires a zero balance, or sweep_to_user_id to move the remaining funds to. Funds reserved by active holds or pending reviews are not swept: closing fails until they are released.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Change wallet status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status change request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UpdateStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controller.ResponseData"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Wallet"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResponseError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller.BalanceSummary": {
+            "type": "object",
+            "properties": {
+                "available_balance": {
+                    "type": "integer"
+                },
+                "balance": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "$ref": "#/definitions/model.Currency"
+                }
+            }
+        },
+        "controller.CaptureRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount to transfer in minor units of the hold's currency, defaults to the full held amount",
+                    "type": "integer"
+                }
+            }
+        },
+        "controller.CreateRequest": {
+            "type": "object",
+            "required": [
+                "acnt_type",
+                "user_id"
+            ],
+            "properties": {
+                "acnt_type": {
+                    "$ref": "#/definitions/model.AcntType"
+                },
+                "currency": {
+                    "description": "Base currency, defaults to USD",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.CreateScheduleRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "recurrence",
+                "start_at",
+                "to_user_id"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount in minor units of the currency",
+                    "type": "integer"
+                },
+                "cron_expr": {
+                    "description": "Standard 5-field cron expression, evaluated in UTC",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "currency": {
+                    "description": "Defaults to the sender's base currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
+                "recurrence": {
+                    "$ref": "#/definitions/model.Recurrence"
+                },
+                "start_at": {
+                    "description": "Time of the first run",
+                    "type": "string"
+                },
+                "to_currency": {
+                    "description": "Converted at the rate at the time of each run",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
+                "to_user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.DecideReviewRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "controller.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "hard": {
+                    "type": "boolean"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.DepositRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "user_id"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount in minor units of the currency",
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "Defaults to the wallet's base currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
+                "provider_id": {
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Client reference recorded on both transactions",
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.Error": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.FlagRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "controller.HealthStatus": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/controller.DependencyStatus"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.HoldRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "from_user_id",
+                "to_user_id"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount in minor units of the currency",
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "Defaults to the sender's base currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
+                "from_user_id": {
+                    "type": "string"
+                },
+                "to_user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.LimitBounds": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "Defaults to the wallet's base currency, or USD for an account type",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
+                "daily_amount": {
+                    "description": "Outgoing total per UTC day",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "daily_count": {
+                    "description": "Outgoing transactions per UTC day",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "max_transaction": {
+                    "description": "Largest single withdrawal or transfer",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "monthly_amount": {
+                    "description": "Outgoing total per UTC calendar month",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "monthly_count": {
+                    "description": "Outgoing transactions per UTC calendar month",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "controller.QuoteRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "from_currency",
+                "to_currency"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount in minor units of from_currency",
+                    "type": "integer"
+                },
+                "from_currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "to_currency": {
+                    "$ref": "#/definitions/model.Currency"
+                }
+            }
+        },
+        "controller.ResponseData": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Data is the response data."
+                }
+            }
+        },
+        "controller.ResponseError": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "description": "Errors is the response errors.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.Error"
+                    }
+                }
+            }
+        },
+        "controller.ReverseRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount to refund in minor units of the transaction's currency, defaults to the full amount",
+                    "type": "integer"
+                }
+            }
+        },
+        "controller.TransferRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "from_user_id",
+                "to_user_id"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount in minor units of the currency",
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "Defaults to the sender's base currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
+                "from_user_id": {
+                    "type": "string"
+                },
+                "quote_id": {
+                    "description": "Quote locking the conversion rate, see POST /fx/quotes",
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Client reference recorded on both transactions",
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "to_currency": {
+                    "description": "Currency credited to the receiver, defaults to currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
+                "to_user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.UpdateScheduleRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "cron_expr": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "recurrence": {
+                    "$ref": "#/definitions/model.Recurrence"
+                },
+                "start_at": {
+                    "type": "string"
+                },
+                "to_user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.UpdateStatusRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "status"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "status": {
+                    "$ref": "#/definitions/model.Status"
+                },
+                "sweep_to_user_id": {
+                    "description": "Wallet receiving the remaining funds when closing",
+                    "type": "string"
+                }
+            }
+        },
+        "controller.WalletResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "description": "NextCursor is passed as the cursor query parameter to fetch the next page of transactions.\nIt is omitted on the last page.",
+                    "type": "string"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Transaction"
+                    }
+                },
+                "transactions_unavailable": {
+                    "description": "TransactionsUnavailable is set when the transaction history could not be read\nfrom the transaction service. The wallet and its balances are still returned.",
+                    "type": "boolean"
+                },
+                "wallet": {
+                    "$ref": "#/definitions/controller.WalletSummary"
+                }
+            }
+        },
+        "controller.WalletSummary": {
+            "type": "object",
             "properties": {
                 "acnt_type": {
                     "$ref": "#/definitions/model.AcntType"
                 },
-                "user_id": {
+                "available_balance": {
+                    "type": "integer"
+                },
+                "balance": {
+                    "type": "integer"
+                },
+                "balances": {
+                    "description": "Balances in currencies other than the base currency",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.BalanceSummary"
+                    }
+                },
+                "currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.Status"
+                },
+                "status_reason": {
+                    "description": "Reason given for the last status change",
+                    "type": "string"
+                }
+            }
+        },
+        "controller.WithdrawRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "user_id"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount in minor units of the currency",
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "Defaults to the wallet's base currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
+                "provider_id": {
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Client reference recorded on both transactions",
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AcntType": {
+            "type": "string",
+            "enum": [
+                "user",
+                "provider"
+            ],
+            "x-enum-varnames": [
+                "User",
+                "Provider"
+            ]
+        },
+        "model.Currency": {
+            "type": "string",
+            "enum": [
+                "USD"
+            ],
+            "x-enum-varnames": [
+                "DefaultCurrency"
+            ]
+        },
+        "model.FXQuote": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount in minor units of FromCurrency",
+                    "type": "integer"
+                },
+                "counter_amount": {
+                    "description": "Amount in minor units of ToCurrency",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "from_currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "to_currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "used_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Hold": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount in minor units of Currency",
+                    "type": "integer"
+                },
+                "captured_amount": {
+                    "description": "Amount transferred by the capture",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "from_user_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.HoldStatus"
+                },
+                "to_user_id": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "controller.DepositRequest": {
-            "type": "object",
-            "required": [
-                "amount",
-                "user_id"
+        "model.HoldStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "captured",
+                "voided",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "HoldActive",
+                "HoldCaptured",
+                "HoldVoided",
+                "HoldExpired"
+            ]
+        },
+        "model.OperationType": {
+            "type": "string",
+            "enum": [
+                "debit",
+                "credit"
+            ],
+            "x-enum-varnames": [
+                "Debit",
+                "Credit"
+            ]
+        },
+        "model.Recurrence": {
+            "type": "string",
+            "enum": [
+                "once",
+                "daily",
+                "weekly",
+                "monthly",
+                "cron"
             ],
+            "x-enum-varnames": [
+                "Once",
+                "Daily",
+                "Weekly",
+                "Monthly",
+                "Cron"
+            ]
+        },
+        "model.Review": {
+            "type": "object",
             "properties": {
                 "amount": {
+                    "description": "Debited amount in minor units of Currency",
                     "type": "integer"
                 },
-                "provider_id": {
+                "created_at": {
                     "type": "string"
                 },
-                "user_id": {
+                "credit_amount": {
+                    "description": "Credited amount in minor units of CreditCurrency",
+                    "type": "integer"
+                },
+                "credit_currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "decided_at": {
+                    "type": "string"
+                },
+                "from_user_id": {
+                    "type": "string"
+                },
+                "fx_rate": {
+                    "description": "Rate of a conversion",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "note": {
+                    "description": "Note of the admin decision",
+                    "type": "string"
+                },
+                "pair_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "$ref": "#/definitions/model.ReviewReason"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "Name of the risk rule that held the transaction",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.ReviewStatus"
+                },
+                "to_user_id": {
+                    "type": "string"
+                },
+                "transaction_type": {
+                    "$ref": "#/definitions/model.TransactionType"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "controller.Error": {
+        "model.ReviewFlag": {
             "type": "object",
             "properties": {
-                "code": {
+                "created_at": {
                     "type": "string"
                 },
-                "message": {
+                "reason": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "controller.ResponseData": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "description": "Data is the response data."
-                }
-            }
+        "model.ReviewReason": {
+            "type": "string",
+            "enum": [
+                "risk",
+                "flagged"
+            ],
+            "x-enum-varnames": [
+                "ReviewRisk",
+                "ReviewFlagged"
+            ]
         },
-        "controller.ResponseError": {
-            "type": "object",
-            "properties": {
-                "errors": {
-                    "description": "Errors is the response errors.",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/controller.Error"
-                    }
-                }
-            }
+        "model.ReviewStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected"
+            ],
+            "x-enum-varnames": [
+                "ReviewPending",
+                "ReviewApproved",
+                "ReviewRejected"
+            ]
         },
-        "controller.TransferRequest": {
-            "type": "object",
-            "required": [
-                "amount",
-                "from_user_id",
-                "to_user_id"
+        "model.RunStatus": {
+            "type": "string",
+            "enum": [
+                "succeeded",
+                "failed"
             ],
+            "x-enum-varnames": [
+                "RunSucceeded",
+                "RunFailed"
+            ]
+        },
+        "model.Schedule": {
+            "type": "object",
             "properties": {
                 "amount": {
+                    "description": "Amount in minor units of Currency",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "cron_expr": {
+                    "type": "string"
+                },
+                "currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "failure_count": {
                     "type": "integer"
                 },
                 "from_user_id": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "last_run_status": {
+                    "$ref": "#/definitions/model.RunStatus"
+                },
+                "next_run_at": {
+                    "description": "Unset once the schedule has no further runs",
+                    "type": "string"
+                },
+                "recurrence": {
+                    "$ref": "#/definitions/model.Recurrence"
+                },
+                "run_count": {
+                    "type": "integer"
+                },
+                "start_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.ScheduleStatus"
+                },
+                "to_currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
                 "to_user_id": {
                     "type": "string"
-                }
-            }
-        },
-        "controller.WalletResponse": {
-            "type": "object",
-            "properties": {
-                "transactions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Transaction"
-                    }
                 },
-                "wallet": {
-                    "$ref": "#/definitions/controller.WalletSummary"
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "controller.WalletSummary": {
+        "model.ScheduleStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "paused",
+                "completed"
+            ],
+            "x-enum-varnames": [
+                "ScheduleActive",
+                "SchedulePaused",
+                "ScheduleCompleted"
+            ]
+        },
+        "model.SpendingLimit": {
             "type": "object",
             "properties": {
                 "acnt_type": {
                     "$ref": "#/definitions/model.AcntType"
                 },
-                "balance": {
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "daily_amount": {
+                    "description": "Outgoing total per UTC day",
                     "type": "integer"
                 },
-                "status": {
-                    "$ref": "#/definitions/model.Status"
-                }
-            }
-        },
-        "controller.WithdrawRequest": {
-            "type": "object",
-            "required": [
-                "amount",
-                "user_id"
-            ],
-            "properties": {
-                "amount": {
+                "daily_count": {
+                    "description": "Outgoing transactions per UTC day",
                     "type": "integer"
                 },
-                "provider_id": {
+                "id": {
+                    "type": "integer"
+                },
+                "max_transaction": {
+                    "description": "Largest single outgoing transaction",
+                    "type": "integer"
+                },
+                "monthly_amount": {
+                    "description": "Outgoing total per UTC calendar month",
+                    "type": "integer"
+                },
+                "monthly_count": {
+                    "description": "Outgoing transactions per UTC calendar month",
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 },
                 "user_id": {
@@ -493,53 +3225,53 @@ const docTemplate = `{
                 }
             }
         },
-        "model.AcntType": {
-            "type": "string",
-            "enum": [
-                "user",
-                "provider"
-            ],
-            "x-enum-varnames": [
-                "User",
-                "Provider"
-            ]
-        },
-        "model.OperationType": {
-            "type": "string",
-            "enum": [
-                "debit",
-                "credit"
-            ],
-            "x-enum-varnames": [
-                "Debit",
-                "Credit"
-            ]
-        },
         "model.Status": {
             "type": "string",
             "enum": [
                 "active",
                 "inactive",
-                "suspended"
+                "suspended",
+                "closed"
             ],
             "x-enum-varnames": [
                 "Active",
                 "Inactive",
-                "Suspended"
+                "Suspended",
+                "Closed"
             ]
         },
         "model.Transaction": {
             "type": "object",
             "properties": {
                 "amount": {
-                    "description": "Amount in cents",
+                    "description": "Amount in minor units of Currency",
+                    "type": "integer"
+                },
+                "counter_amount": {
+                    "description": "Amount of the other side of a conversion",
                     "type": "integer"
                 },
+                "counter_currency": {
+                    "description": "Currency of the other side of a conversion",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    ]
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
+                "fx_rate": {
+                    "description": "Price of one unit of the debited currency in the credited currency",
+                    "type": "string"
+                },
                 "id": {
-                    "type": "integer"
+                    "description": "Public ID assigned by the transaction service",
+                    "type": "string"
                 },
                 "object_wallet_id": {
                     "type": "string"
@@ -547,6 +3279,22 @@ const docTemplate = `{
                 "operation_type": {
                     "$ref": "#/definitions/model.OperationType"
                 },
+                "pair_id": {
+                    "description": "UUID shared by the debit and credit of a pair",
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Client supplied reference of the pair",
+                    "type": "string"
+                },
+                "reversal_of": {
+                    "description": "ID of the transaction reversed by this one",
+                    "type": "string"
+                },
+                "review_id": {
+                    "description": "ID of the review deciding a pending transaction",
+                    "type": "string"
+                },
                 "status": {
                     "$ref": "#/definitions/model.TransactionStatus"
                 },
@@ -581,12 +3329,14 @@ const docTemplate = `{
             "enum": [
                 "deposit",
                 "withdraw",
-                "transfer"
+                "transfer",
+                "adjustment"
             ],
             "x-enum-varnames": [
                 "Deposit",
                 "Withdraw",
-                "Transfer"
+                "Transfer",
+                "Adjustment"
             ]
         },
         "model.Wallet": {
@@ -596,18 +3346,35 @@ const docTemplate = `{
                     "$ref": "#/definitions/model.AcntType"
                 },
                 "balance": {
-                    "description": "Balance in cents",
+                    "description": "Balance in minor units of Currency",
                     "type": "integer"
                 },
+                "balances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WalletBalance"
+                    }
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "currency": {
+                    "$ref": "#/definitions/model.Currency"
+                },
                 "id": {
-                    "type": "integer"
+                    "description": "UUIDv7 identifying the wallet in the API",
+                    "type": "string"
                 },
                 "status": {
                     "$ref": "#/definitions/model.Status"
                 },
+                "status_changed_at": {
+                    "type": "string"
+                },
+                "status_reason": {
+                    "description": "Reason given for the last status change",
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 },
@@ -615,6 +3382,26 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "model.WalletBalance": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "Balance in minor units of Currency",
+                    "type": "integer"
+                },
+                "currency": {
+                    "$ref": "#/definitions/model.Currency"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "JWT of the caller as \"Bearer \u003ctoken\u003e\", required when authentication is enabled",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -622,7 +3409,7 @@ const docTemplate = `{
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
 	Version:          "0.0.1",
-	Host:             "localhost:8000",
+	Host:             "localhost:8081",
 	BasePath:         "/api/v1",
 	Schemes:          []string{"http"},
 	Title:            "digital-wallet-demonstration API",
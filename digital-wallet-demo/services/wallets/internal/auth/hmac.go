@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HeaderServiceID is the request header naming the calling service.
+	HeaderServiceID = "X-Service-ID"
+	// HeaderTimestamp is the request header carrying the Unix time the request was signed at.
+	HeaderTimestamp = "X-Signature-Timestamp"
+	// HeaderNonce is the request header carrying the random value making a signature unique.
+	HeaderNonce = "X-Signature-Nonce"
+	// HeaderSignature is the request header carrying the hex encoded HMAC-SHA256 signature.
+	HeaderSignature = "X-Signature"
+)
+
+// Signer signs the requests of a service to another service with a shared secret.
+//
+// The signature is the HMAC-SHA256 of the method, the request URI, the
+// service ID, the timestamp, the nonce and the SHA-256 of the body, joined
+// by newlines. The receiving service rejects stale timestamps and replayed nonces.
+type Signer struct {
+	serviceID string
+	secret    []byte
+	now       func() time.Time
+}
+
+// NewSigner returns a signer for the requests of serviceID.
+func NewSigner(serviceID, secret string) *Signer {
+	return &Signer{serviceID: serviceID, secret: []byte(secret), now: time.Now}
+}
+
+// Sign sets the signature headers of r, whose body is body.
+func (s *Signer) Sign(r *http.Request, body []byte) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	bodyHash := sha256.Sum256(body)
+	canonical := strings.Join([]string{
+		r.Method,
+		r.URL.RequestURI(),
+		s.serviceID,
+		timestamp,
+		hex.EncodeToString(nonce),
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(canonical))
+
+	r.Header.Set(HeaderServiceID, s.serviceID)
+	r.Header.Set(HeaderTimestamp, timestamp)
+	r.Header.Set(HeaderNonce, hex.EncodeToString(nonce))
+	r.Header.Set(HeaderSignature, hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
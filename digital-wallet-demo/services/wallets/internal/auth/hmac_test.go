@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner_Sign(t *testing.T) {
+	signer := NewSigner("wallets", "wallets-secret-0123456789abcdef0123")
+	signer.now = func() time.Time { return time.Unix(1704110400, 0) }
+
+	body := []byte(`{"pair_id":"pair-1"}`)
+	req := httptest.NewRequest(http.MethodPost, "http://transactions/api/v1/transactions?source=relay", bytes.NewReader(body))
+	require.NoError(t, signer.Sign(req, body))
+
+	assert.Equal(t, "wallets", req.Header.Get(HeaderServiceID))
+	assert.Equal(t, "1704110400", req.Header.Get(HeaderTimestamp))
+	nonce := req.Header.Get(HeaderNonce)
+	assert.Len(t, nonce, 32)
+
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte("wallets-secret-0123456789abcdef0123"))
+	mac.Write([]byte("POST\n/api/v1/transactions?source=relay\nwallets\n1704110400\n" + nonce + "\n" + hex.EncodeToString(bodyHash[:])))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), req.Header.Get(HeaderSignature))
+
+	// Every request is signed with a new nonce
+	again := httptest.NewRequest(http.MethodPost, "http://transactions/api/v1/transactions", bytes.NewReader(body))
+	require.NoError(t, signer.Sign(again, body))
+	assert.NotEqual(t, nonce, again.Header.Get(HeaderNonce))
+}
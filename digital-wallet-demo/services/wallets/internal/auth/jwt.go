@@ -0,0 +1,234 @@
+// Package auth verifies the JWTs authenticating API requests and signs the
+// requests of this service to other services.
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultRolesClaim = "roles"
+	defaultAdminRole  = "admin"
+)
+
+// ErrInvalidToken is returned for a token that is malformed, expired, wrongly
+// signed or missing a required claim.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	UserID string // Subject of the token
+	Admin  bool   // Whether the caller has the admin role
+}
+
+// CanActFor reports whether the caller may act on the wallet of userID.
+// Admins may act on every wallet, other callers on their own wallet only.
+func (i *Identity) CanActFor(userID string) bool {
+	return i.Admin || i.UserID == userID
+}
+
+// Verifier verifies the signature and claims of tokens and returns the identity they carry.
+type Verifier struct {
+	algorithm  string
+	secret     []byte
+	keys       map[string]*rsa.PublicKey // RS256 keys by key ID, a single key of a PEM file has an empty ID
+	issuer     string
+	audience   string
+	rolesClaim string
+	adminRole  string
+}
+
+// NewVerifier returns a verifier for the tokens described by cfg.
+// The keys of PublicKeyFile and JWKSFile are read once.
+func NewVerifier(cfg model.Auth) (*Verifier, error) {
+	v := &Verifier{
+		algorithm:  cfg.Algorithm,
+		issuer:     cfg.Issuer,
+		audience:   cfg.Audience,
+		rolesClaim: cfg.RolesClaim,
+		adminRole:  cfg.AdminRole,
+	}
+	if v.rolesClaim == "" {
+		v.rolesClaim = defaultRolesClaim
+	}
+	if v.adminRole == "" {
+		v.adminRole = defaultAdminRole
+	}
+
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Name:
+		if cfg.Secret == "" {
+			return nil, errors.New("HS256 requires a secret")
+		}
+		v.secret = []byte(cfg.Secret)
+	case jwt.SigningMethodRS256.Name:
+		keys, err := loadRSAKeys(cfg.PublicKeyFile, cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, expected HS256 or RS256", cfg.Algorithm)
+	}
+	return v, nil
+}
+
+// Verify parses a token and returns the identity of its subject.
+// Tokens must be signed with the configured algorithm and carry a subject and an expiry.
+func (v *Verifier) Verify(token string) (*Identity, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{v.algorithm}),
+		jwt.WithExpirationRequired(),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		opts = append(opts, jwt.WithAudience(v.audience))
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, v.key, opts...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	identity := &Identity{UserID: subject}
+	for _, role := range roles(claims[v.rolesClaim]) {
+		if role == v.adminRole {
+			identity.Admin = true
+		}
+	}
+	return identity, nil
+}
+
+// key returns the key verifying the token's signature.
+func (v *Verifier) key(token *jwt.Token) (any, error) {
+	if v.secret != nil {
+		return v.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	// A token without a key ID is accepted if there is only one key to choose from
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// roles returns the roles of a claim holding a list of roles or a space separated string.
+func roles(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		var list []string
+		for _, role := range value {
+			if s, ok := role.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// loadRSAKeys reads the RSA public key of a PEM file and the RSA keys of a JWKS file.
+func loadRSAKeys(publicKeyFile, jwksFile string) (map[string]*rsa.PublicKey, error) {
+	keys := map[string]*rsa.PublicKey{}
+	if publicKeyFile != "" {
+		b, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key file: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key file: %w", err)
+		}
+		keys[""] = key
+	}
+	if jwksFile != "" {
+		set, err := LoadJWKS(jwksFile)
+		if err != nil {
+			return nil, err
+		}
+		for kid, key := range set {
+			keys[kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("RS256 requires a public key file or a JWKS file")
+	}
+	return keys, nil
+}
+
+// JWKS is the format of a JWKS file. Only RSA signing keys are used.
+//
+//	{"keys": [{"kty": "RSA", "kid": "wallets-1", "use": "sig", "n": "...", "e": "AQAB"}]}
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is a JSON web key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// LoadJWKS reads the RSA signing keys of a JWKS file by key ID.
+func LoadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	var set JWKS
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus of key %q: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent of key %q: %w", k.Kid, err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid exponent of key %q", k.Kid)
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS file has no RSA signing keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "test-secret"
+
+func signHS256(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims(subject string) jwt.MapClaims {
+	return jwt.MapClaims{"sub": subject, "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	v, err := NewVerifier(model.Auth{Algorithm: "HS256", Secret: testSecret, Issuer: "wallet-idp"})
+	require.NoError(t, err)
+
+	withIssuer := func(claims jwt.MapClaims) jwt.MapClaims {
+		claims["iss"] = "wallet-idp"
+		return claims
+	}
+	adminClaims := withIssuer(validClaims("ops-001"))
+	adminClaims["roles"] = []string{"support", "admin"}
+	scopeClaims := withIssuer(validClaims("ops-002"))
+	scopeClaims["roles"] = "support admin"
+
+	tests := []struct {
+		name    string
+		token   string
+		want    *Identity
+		wantErr bool
+	}{
+		{"user", signHS256(t, withIssuer(validClaims("test-user-001"))), &Identity{UserID: "test-user-001"}, false},
+		{"admin_role_list", signHS256(t, adminClaims), &Identity{UserID: "ops-001", Admin: true}, false},
+		{"admin_role_string", signHS256(t, scopeClaims), &Identity{UserID: "ops-002", Admin: true}, false},
+		{"expired", signHS256(t, withIssuer(jwt.MapClaims{"sub": "test-user-001", "exp": time.Now().Add(-time.Minute).Unix()})), nil, true},
+		{"without_expiry", signHS256(t, withIssuer(jwt.MapClaims{"sub": "test-user-001"})), nil, true},
+		{"without_subject", signHS256(t, withIssuer(jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()})), nil, true},
+		{"wrong_issuer", signHS256(t, validClaims("test-user-001")), nil, true},
+		{"wrong_secret", func() string {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, withIssuer(validClaims("test-user-001"))).SignedString([]byte("other"))
+			require.NoError(t, err)
+			return token
+		}(), nil, true},
+		{"unsigned", func() string {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodNone, withIssuer(validClaims("test-user-001"))).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			require.NoError(t, err)
+			return token
+		}(), nil, true},
+		{"malformed", "not-a-token", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Verify(tt.token)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVerifier_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	pemFile := filepath.Join(dir, "public.pem")
+	require.NoError(t, os.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	jwksFile := filepath.Join(dir, "jwks.json")
+	set := JWKS{Keys: []JWK{jwk("key-1", &key.PublicKey), jwk("key-2", &other.PublicKey)}}
+	b, err := json.Marshal(set)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(jwksFile, b, 0o600))
+
+	t.Run("public_key_file", func(t *testing.T) {
+		v, err := NewVerifier(model.Auth{Algorithm: "RS256", PublicKeyFile: pemFile})
+		require.NoError(t, err)
+
+		got, err := v.Verify(signRS256(t, key, "", validClaims("test-user-001")))
+		require.NoError(t, err)
+		assert.Equal(t, "test-user-001", got.UserID)
+
+		_, err = v.Verify(signRS256(t, other, "", validClaims("test-user-001")))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("jwks_file", func(t *testing.T) {
+		v, err := NewVerifier(model.Auth{Algorithm: "RS256", JWKSFile: jwksFile})
+		require.NoError(t, err)
+
+		for kid, signer := range map[string]*rsa.PrivateKey{"key-1": key, "key-2": other} {
+			got, err := v.Verify(signRS256(t, signer, kid, validClaims("test-user-001")))
+			require.NoError(t, err, kid)
+			assert.Equal(t, "test-user-001", got.UserID)
+		}
+
+		_, err = v.Verify(signRS256(t, key, "key-2", validClaims("test-user-001")))
+		assert.ErrorIs(t, err, ErrInvalidToken, "signed with another key than kid")
+		_, err = v.Verify(signRS256(t, key, "key-3", validClaims("test-user-001")))
+		assert.ErrorIs(t, err, ErrInvalidToken, "unknown kid")
+		_, err = v.Verify(signRS256(t, key, "", validClaims("test-user-001")))
+		assert.ErrorIs(t, err, ErrInvalidToken, "no kid with several keys")
+	})
+
+	t.Run("algorithm_mismatch", func(t *testing.T) {
+		v, err := NewVerifier(model.Auth{Algorithm: "RS256", PublicKeyFile: pemFile})
+		require.NoError(t, err)
+
+		// An HS256 token signed with the public key must not pass as RS256
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims("test-user-001")).SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		require.NoError(t, err)
+		_, err = v.Verify(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestNewVerifier_Invalid(t *testing.T) {
+	for name, cfg := range map[string]model.Auth{
+		"no_algorithm":    {},
+		"hs256_no_secret": {Algorithm: "HS256"},
+		"rs256_no_keys":   {Algorithm: "RS256"},
+		"missing_file":    {Algorithm: "RS256", JWKSFile: filepath.Join(t.TempDir(), "missing.json")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewVerifier(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func jwk(kid string, key *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
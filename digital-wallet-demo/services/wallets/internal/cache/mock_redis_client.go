@@ -8,27 +8,27 @@ import (
 
 // MockRedisClient implements RedisClient interface for testing
 type MockRedisClient struct {
-	Transactions map[string][]model.Transaction
+	Transactions map[string]*model.TransactionPage
 }
 
 // NewMockRedisClient creates a new mock Redis client
 func NewMockRedisClient() *MockRedisClient {
 	return &MockRedisClient{
-		Transactions: make(map[string][]model.Transaction),
+		Transactions: make(map[string]*model.TransactionPage),
 	}
 }
 
 // GetTransactionHistory returns mock transaction history
-func (m *MockRedisClient) GetTransactionHistory(ctx context.Context, userID string) ([]model.Transaction, error) {
-	if transactions, exists := m.Transactions[userID]; exists {
-		return transactions, nil
+func (m *MockRedisClient) GetTransactionHistory(ctx context.Context, userID string) (*model.TransactionPage, error) {
+	if page, exists := m.Transactions[userID]; exists {
+		return page, nil
 	}
 	return nil, nil // Cache miss
 }
 
 // SaveTransactionHistory saves mock transaction history
-func (m *MockRedisClient) SaveTransactionHistory(ctx context.Context, userID string, transactions []model.Transaction) error {
-	m.Transactions[userID] = transactions
+func (m *MockRedisClient) SaveTransactionHistory(ctx context.Context, userID string, page *model.TransactionPage) error {
+	m.Transactions[userID] = page
 	return nil
 }
 
@@ -38,6 +38,11 @@ func (m *MockRedisClient) DeleteTransactionHistory(ctx context.Context, userID s
 	return nil
 }
 
+// Ping always succeeds
+func (m *MockRedisClient) Ping(ctx context.Context) error {
+	return nil
+}
+
 // Close does nothing for mock client
 func (m *MockRedisClient) Close() error {
 	return nil
@@ -5,19 +5,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"sync"
 	"time"
 
-	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
 	"github.com/go-redis/redis/v8"
 )
 
 // RedisClient interface defines the Redis operations for transaction caching
 type RedisClient interface {
-	GetTransactionHistory(ctx context.Context, userID string) ([]model.Transaction, error)
-	SaveTransactionHistory(ctx context.Context, userID string, transactions []model.Transaction) error
+	GetTransactionHistory(ctx context.Context, userID string) (*model.TransactionPage, error)
+	SaveTransactionHistory(ctx context.Context, userID string, page *model.TransactionPage) error
 	DeleteTransactionHistory(ctx context.Context, userID string) error
+	Ping(ctx context.Context) error
 	Close() error
 }
 
@@ -27,72 +28,60 @@ type redisClient struct {
 	ttl    time.Duration
 }
 
-var (
-	redisInstance RedisClient
-	redisOnce     sync.Once
-)
-
-// ResetRedisClient resets the singleton instance for testing
-func ResetRedisClient() {
-	redisOnce = sync.Once{}
-	redisInstance = nil
-}
-
-// NewRedisClient creates a new Redis client instance using singleton pattern
-func NewRedisClient() RedisClient {
-	redisOnce.Do(func() {
-		globalConfig := config.GetGlobalConfig()
-		redisConfig := globalConfig.Redis
-
-		rdb := redis.NewClient(&redis.Options{
-			Addr:         fmt.Sprintf("%s:%d", redisConfig.Host, redisConfig.Port),
-			Password:     redisConfig.Password,
-			DB:           redisConfig.DB,
-			MaxRetries:   redisConfig.MaxRetries,
-			PoolSize:     redisConfig.PoolSize,
-			DialTimeout:  10 * time.Second,
-			ReadTimeout:  30 * time.Second,
-			WriteTimeout: 30 * time.Second,
-		})
-
-		redisInstance = &redisClient{
-			client: rdb,
-			ttl:    24 * time.Hour, // Cache for 24 hours
-		}
+// NewRedisClient creates a new Redis client of the cache configured by cfg.
+// The client holds a connection pool; callers share one instance and close it on shutdown.
+func NewRedisClient(cfg model.Redis) RedisClient {
+	rdb := redis.NewClient(&redis.Options{
+		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		MaxRetries:   cfg.MaxRetries,
+		PoolSize:     cfg.PoolSize,
+		DialTimeout:  10 * time.Second,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
 	})
-	return redisInstance
+	rdb.AddHook(tracing.RedisHook{})
+
+	return &redisClient{
+		client: rdb,
+		ttl:    24 * time.Hour, // Cache for 24 hours
+	}
 }
 
 // generateKey creates a unique Redis key for user transaction history
 func (r *redisClient) generateKey(userID string) string {
-	return fmt.Sprintf("wallet:transactions:%s", userID)
+	return fmt.Sprintf("wallet:transactions:first-page:%s", userID)
 }
 
-// GetTransactionHistory retrieves cached transaction history for a user
-func (r *redisClient) GetTransactionHistory(ctx context.Context, userID string) ([]model.Transaction, error) {
+// GetTransactionHistory retrieves the cached first page of transaction history for a user
+func (r *redisClient) GetTransactionHistory(ctx context.Context, userID string) (*model.TransactionPage, error) {
 	key := r.generateKey(userID)
 	val, err := r.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
 			// Cache miss - return empty slice
+			metrics.CacheRequests.WithLabelValues(metrics.CacheMiss).Inc()
 			return nil, nil
 		}
+		metrics.CacheRequests.WithLabelValues(metrics.CacheError).Inc()
 		return nil, fmt.Errorf("failed to get transaction history from cache: %w", err)
 	}
+	metrics.CacheRequests.WithLabelValues(metrics.CacheHit).Inc()
 
-	var transactions []model.Transaction
-	err = json.Unmarshal([]byte(val), &transactions)
+	var page model.TransactionPage
+	err = json.Unmarshal([]byte(val), &page)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal transaction history: %w", err)
 	}
 
-	return transactions, nil
+	return &page, nil
 }
 
-// SaveTransactionHistory caches transaction history for a user
-func (r *redisClient) SaveTransactionHistory(ctx context.Context, userID string, transactions []model.Transaction) error {
+// SaveTransactionHistory caches the first page of transaction history for a user
+func (r *redisClient) SaveTransactionHistory(ctx context.Context, userID string, page *model.TransactionPage) error {
 	key := r.generateKey(userID)
-	data, err := json.Marshal(transactions)
+	data, err := json.Marshal(page)
 	if err != nil {
 		return fmt.Errorf("failed to marshal transaction history: %w", err)
 	}
@@ -116,6 +105,11 @@ func (r *redisClient) DeleteTransactionHistory(ctx context.Context, userID strin
 	return nil
 }
 
+// Ping checks that Redis answers
+func (r *redisClient) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
 // Close closes the Redis client connection
 func (r *redisClient) Close() error {
 	return r.client.Close()
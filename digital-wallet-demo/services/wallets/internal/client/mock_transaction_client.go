@@ -1,29 +1,44 @@
 package client
 
 import (
+	"context"
+	"time"
+
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 )
 
 // MockTransactionClient implements the NewTransaction interface for testing
-type MockTransactionClient struct{}
+type MockTransactionClient struct {
+	// FetchErr is returned by FetchTransactions when set
+	FetchErr error
+	// CreateErr is returned by CreateTransactionPair when set
+	CreateErr error
+}
 
-func (m *MockTransactionClient) CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error {
+func (m *MockTransactionClient) CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction, idempotencyKey string) error {
+	if m.CreateErr != nil {
+		return m.CreateErr
+	}
 	// Mock successful transaction creation
 	// In a real scenario, this would make HTTP calls to the transaction service
 	// But for testing, we just return success
 	return nil
 }
 
-func (m *MockTransactionClient) FetchTransactions(subjectWalletID string) ([]model.Transaction, error) {
+func (m *MockTransactionClient) FetchTransactions(ctx context.Context, subjectWalletID string, query model.TransactionQuery) (*model.TransactionPage, error) {
+	if m.FetchErr != nil {
+		return nil, m.FetchErr
+	}
 	// For test-user-001, return some sample transactions
 	if subjectWalletID == "test-user-001" {
-		return []model.Transaction{
+		transactions := []model.Transaction{
 			{
 				SubjectWalletID: "test-user-001",
 				ObjectWalletID:  "deposit-provider-master",
 				TransactionType: model.Deposit,
 				OperationType:   model.Credit,
 				Amount:          5000,
+				Currency:        model.DefaultCurrency,
 				Status:          model.Completed,
 			},
 			{
@@ -32,10 +47,145 @@ func (m *MockTransactionClient) FetchTransactions(subjectWalletID string) ([]mod
 				TransactionType: model.Withdraw,
 				OperationType:   model.Debit,
 				Amount:          2000,
+				Currency:        model.DefaultCurrency,
 				Status:          model.Completed,
 			},
-		}, nil
+		}
+		// Honour the type filter and page size so that forwarding can be tested
+		page := &model.TransactionPage{Transactions: []model.Transaction{}}
+		for _, txn := range transactions {
+			if query.TransactionType != "" && txn.TransactionType != query.TransactionType {
+				continue
+			}
+			if query.Limit > 0 && len(page.Transactions) == query.Limit {
+				page.NextCursor = "mock-cursor"
+				break
+			}
+			page.Transactions = append(page.Transactions, txn)
+		}
+		return page, nil
 	}
 	// For other wallet IDs, return empty list
-	return []model.Transaction{}, nil
+	return &model.TransactionPage{Transactions: []model.Transaction{}}, nil
+}
+
+func (m *MockTransactionClient) FetchLedgerBalances(ctx context.Context) ([]model.LedgerBalance, error) {
+	// Ledger of test-user-001 matching the transactions returned by FetchTransactions
+	return []model.LedgerBalance{
+		{
+			SubjectWalletID: "test-user-001",
+			Currency:        model.DefaultCurrency,
+			Credits:         5000,
+			Debits:          2000,
+			Net:             3000,
+			Entries:         2,
+		},
+	}, nil
+}
+
+// mockLedgerCreatedAt is the creation time of the ledger transactions, which were made today
+var mockLedgerCreatedAt = time.Now()
+
+// mockTransferPairID is the pair ID of the debit and credit of the transfer in mockLedger
+const mockTransferPairID = "3b5f0c1e-8a2d-4c7e-9f41-2d6b8e0a7c15"
+
+// mockLedger holds the ledger transactions returned by FetchTransaction, by ID
+var mockLedger = map[string]model.Transaction{
+	// Transfer debited from test-user-001
+	"01890a5d-ac96-774b-bcce-b302099a8001": {
+		ID:              "01890a5d-ac96-774b-bcce-b302099a8001",
+		SubjectWalletID: "test-user-001",
+		ObjectWalletID:  "test-user-002",
+		TransactionType: model.Transfer,
+		OperationType:   model.Debit,
+		Amount:          3000,
+		Currency:        model.DefaultCurrency,
+		PairID:          mockTransferPairID,
+		Status:          model.Completed,
+		CreatedAt:       mockLedgerCreatedAt,
+	},
+	// Credit of the same transfer to test-user-002
+	"01890a5d-ac96-774b-bcce-b302099a8005": {
+		ID:              "01890a5d-ac96-774b-bcce-b302099a8005",
+		SubjectWalletID: "test-user-002",
+		ObjectWalletID:  "test-user-001",
+		TransactionType: model.Transfer,
+		OperationType:   model.Credit,
+		Amount:          3000,
+		Currency:        model.DefaultCurrency,
+		PairID:          mockTransferPairID,
+		Status:          model.Completed,
+		CreatedAt:       mockLedgerCreatedAt,
+	},
+	// Deposit credited to test-user-001
+	"01890a5d-ac96-774b-bcce-b302099a8002": {
+		ID:              "01890a5d-ac96-774b-bcce-b302099a8002",
+		SubjectWalletID: "test-user-001",
+		ObjectWalletID:  "deposit-provider-master",
+		TransactionType: model.Deposit,
+		OperationType:   model.Credit,
+		Amount:          5000,
+		Currency:        model.DefaultCurrency,
+		Status:          model.Completed,
+		CreatedAt:       mockLedgerCreatedAt,
+	},
+	// Conversion from USD debited from test-user-001 to EUR credited to test-user-002
+	"01890a5d-ac96-774b-bcce-b302099a8003": {
+		ID:              "01890a5d-ac96-774b-bcce-b302099a8003",
+		SubjectWalletID: "test-user-001",
+		ObjectWalletID:  "test-user-002",
+		TransactionType: model.Transfer,
+		OperationType:   model.Debit,
+		Amount:          3000,
+		Currency:        model.DefaultCurrency,
+		FXRate:          "0.92000000",
+		CounterAmount:   2760,
+		CounterCurrency: "EUR",
+		Status:          model.Completed,
+		CreatedAt:       mockLedgerCreatedAt,
+	},
+	// Pending transfer, which cannot be reversed
+	"01890a5d-ac96-774b-bcce-b302099a8004": {
+		ID:              "01890a5d-ac96-774b-bcce-b302099a8004",
+		SubjectWalletID: "test-user-001",
+		ObjectWalletID:  "test-user-002",
+		TransactionType: model.Transfer,
+		OperationType:   model.Debit,
+		Amount:          3000,
+		Currency:        model.DefaultCurrency,
+		Status:          model.Pending,
+	},
+}
+
+func (m *MockTransactionClient) FetchTransaction(ctx context.Context, id string) (*model.Transaction, error) {
+	txn, ok := mockLedger[id]
+	if !ok {
+		return nil, model.ErrTransactionNotFound
+	}
+	return &txn, nil
+}
+
+// mockLegacyIDs maps the serial IDs of legacy transactions to their public IDs in mockLedger
+var mockLegacyIDs = map[int64]string{
+	1: "01890a5d-ac96-774b-bcce-b302099a8001",
+	2: "01890a5d-ac96-774b-bcce-b302099a8002",
+}
+
+func (m *MockTransactionClient) ResolveLegacyIDs(ctx context.Context, legacyIDs []int64) (map[int64]string, error) {
+	ids := map[int64]string{}
+	for _, legacyID := range legacyIDs {
+		if id, ok := mockLegacyIDs[legacyID]; ok {
+			ids[legacyID] = id
+		}
+	}
+	return ids, nil
+}
+
+func (m *MockTransactionClient) UpdatePairStatus(ctx context.Context, reviewID string, status model.TransactionStatus, idempotencyKey string) error {
+	// Mock successful status update
+	return nil
+}
+
+func (m *MockTransactionClient) Ping(ctx context.Context) error {
+	return nil
 }
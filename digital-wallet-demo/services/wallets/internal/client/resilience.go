@@ -0,0 +1,144 @@
+package client
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+)
+
+// Defaults of the resilience policy for the settings left unset
+const (
+	defaultTimeout     = 5 * time.Second
+	defaultBaseBackoff = 100 * time.Millisecond
+	defaultMaxBackoff  = 2 * time.Second
+	defaultOpenTimeout = 30 * time.Second
+)
+
+// retryable reports whether a call of req may be sent again: reads, and writes
+// carrying an idempotency key, which the transaction service applies only once.
+func retryable(req *http.Request) bool {
+	return req.Method == http.MethodGet || req.Header.Get("Idempotency-Key") != ""
+}
+
+// retryableStatus reports whether a response status is a transient failure of
+// the transaction service or of a proxy in front of it.
+func retryableStatus(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+// retryBackoff returns the delay before retry number retry, counted from 1:
+// BaseBackoff doubled for every retry, capped at MaxBackoff, with full jitter
+// so that the retries of concurrent calls are spread out.
+func retryBackoff(retry int, cfg model.Retry) time.Duration {
+	base, max := cfg.BaseBackoff, cfg.MaxBackoff
+	if base <= 0 {
+		base = defaultBaseBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	backoff := base
+	for i := 1; i < retry && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// breakerState is the state of a circuit breaker
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // Calls are sent
+	breakerOpen                         // Calls fail fast
+	breakerHalfOpen                     // A single call probes the service
+)
+
+// breaker is a circuit breaker opening after a number of consecutive failures.
+// A nil breaker lets every call through.
+type breaker struct {
+	threshold   int
+	openTimeout time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// newBreaker returns the circuit breaker configured by cfg, nil if it is disabled
+func newBreaker(cfg model.Breaker) *breaker {
+	if cfg.FailureThreshold <= 0 {
+		return nil
+	}
+	openTimeout := cfg.OpenTimeout
+	if openTimeout <= 0 {
+		openTimeout = defaultOpenTimeout
+	}
+	return &breaker{threshold: cfg.FailureThreshold, openTimeout: openTimeout, now: time.Now}
+}
+
+// allow reports whether a call may be sent. Once the open timeout has passed,
+// a single call is let through to probe the service.
+func (b *breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// The probe is in flight
+		return false
+	default:
+		return true
+	}
+}
+
+// abandon releases a call let through by allow whose outcome is unknown, because
+// its caller gave up on it. A probe is allowed again right away.
+func (b *breaker) abandon() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+		b.openedAt = b.now().Add(-b.openTimeout)
+	}
+}
+
+// record records the outcome of a call let through by allow
+func (b *breaker) record(success bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
@@ -2,58 +2,222 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"sync"
+	neturl "net/url"
+	"strconv"
 	"time"
 
-	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/config"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/auth"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // NewTransaction interface for communicating with transactions microservice
 type NewTransaction interface {
-	CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error
-	FetchTransactions(subjectWalletID string) ([]model.Transaction, error)
+	CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction, idempotencyKey string) error
+	FetchTransactions(ctx context.Context, subjectWalletID string, query model.TransactionQuery) (*model.TransactionPage, error)
+	FetchLedgerBalances(ctx context.Context) ([]model.LedgerBalance, error)
+	FetchTransaction(ctx context.Context, id string) (*model.Transaction, error)
+	ResolveLegacyIDs(ctx context.Context, legacyIDs []int64) (map[int64]string, error)
+	UpdatePairStatus(ctx context.Context, reviewID string, status model.TransactionStatus, idempotencyKey string) error
+	Ping(ctx context.Context) error
 }
 
 type transactionClient struct {
 	client  *http.Client
 	baseURL string
+	signer  *auth.Signer // Signs requests when a shared secret is configured
+	timeout time.Duration
+	retry   model.Retry
+	breaker *breaker // Fails calls fast while the transaction service is unhealthy, nil if disabled
 }
 
-var (
-	instance NewTransaction
-	once     sync.Once
-)
+// walletsServiceID is the identity requests are signed as when none is configured
+const walletsServiceID = "wallets"
+
+// NewTxnClient returns a client of the transaction service configured by svc.
+// Callers share one instance, so that its circuit breaker sees every call.
+func NewTxnClient(svc model.Service) NewTransaction {
+	return newTransactionClient(svc)
+}
+
+// newTransactionClient returns a client of the transaction service configured by svc
+func newTransactionClient(svc model.Service) *transactionClient {
+	var signer *auth.Signer
+	if svc.Secret != "" {
+		serviceID := svc.ServiceID
+		if serviceID == "" {
+			serviceID = walletsServiceID
+		}
+		signer = auth.NewSigner(serviceID, svc.Secret)
+	}
+
+	timeout := svc.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &transactionClient{
+		// Calls are bounded by the deadline of their context
+		client:  &http.Client{},
+		baseURL: svc.BaseURL,
+		signer:  signer,
+		timeout: timeout,
+		retry:   svc.Retry,
+		breaker: newBreaker(svc.Breaker),
+	}
+}
+
+// send sends req, whose body is body, in a client span, within the configured
+// deadline. Idempotent calls are retried, and calls fail fast with
+// ErrTransactionsUnavailable while the circuit breaker is open. A call that
+// gets no response or a server error fails with ErrTransactionsUnavailable.
+func (tc *transactionClient) send(req *http.Request, body []byte) (*http.Response, error) {
+	caller := req.Context()
+	ctx, span := tracing.Tracer().Start(caller, "transactions "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.String()),
+		),
+	)
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, tc.timeout)
+
+	attempts := 1
+	if retryable(req) && tc.retry.MaxAttempts > 1 {
+		attempts = tc.retry.MaxAttempts
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var resp *http.Response
+		resp, err = tc.attempt(ctx, req, body)
+		if err == nil && resp.StatusCode < http.StatusInternalServerError {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+			// The deadline covers reading the body, it is released once the caller closes it
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		retry := attempt < attempts && err != errCircuitOpen
+		if err == nil {
+			retry = retry && retryableStatus(resp.StatusCode)
+			err = fmt.Errorf("transaction service returned status %d", resp.StatusCode)
+			_ = resp.Body.Close()
+		}
+		if !retry {
+			break
+		}
+
+		timer := time.NewTimer(retryBackoff(attempt, tc.retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		span.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", attempt+1)))
+	}
+	cancel()
 
-// ResetClient resets the singleton instance for testing purposes
-func ResetClient() {
-	once = sync.Once{}
-	instance = nil
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	if caller.Err() != nil {
+		// The caller gave up, the transaction service is not known to be unavailable
+		return nil, caller.Err()
+	}
+	return nil, fmt.Errorf("%w: %v", model.ErrTransactionsUnavailable, err)
 }
 
-// NewTxnClient is a factory method that returns NewTransaction interface with singleton pattern
-func NewTxnClient() NewTransaction {
-	once.Do(func() {
-		baseURL := "http://localhost:8082" // default fallback
-		globalConfig := config.GetGlobalConfig()
-		baseURL = globalConfig.Services.Transaction.BaseURL
+// errCircuitOpen is the error for a call not sent because the circuit breaker is open
+var errCircuitOpen = errors.New("circuit breaker is open")
+
+// attempt signs and sends a copy of req with body. The request ID and trace
+// context of ctx are forwarded, and the outcome is recorded by the breaker.
+func (tc *transactionClient) attempt(ctx context.Context, req *http.Request, body []byte) (*http.Response, error) {
+	if !tc.breaker.allow() {
+		return nil, errCircuitOpen
+	}
+
+	r := req.Clone(ctx)
+	if body != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	tracing.InjectHeaders(ctx, r.Header)
+
+	// Every attempt is signed with a fresh timestamp and nonce, the transaction service rejects replays
+	if tc.signer != nil {
+		if err := tc.signer.Sign(r, body); err != nil {
+			tc.breaker.abandon()
+			return nil, err
+		}
+	}
 
-		instance = &transactionClient{
-			client: &http.Client{
-				Timeout: 30 * time.Second,
-			},
-			baseURL: baseURL,
+	resp, err := tc.client.Do(r)
+	if err != nil {
+		metrics.ObserveClientRequest(req.Method, 0, err)
+		if req.Context().Err() != nil {
+			tc.breaker.abandon()
+		} else {
+			tc.breaker.record(false)
 		}
-	})
-	return instance
+		return nil, err
+	}
+	metrics.ObserveClientRequest(req.Method, resp.StatusCode, nil)
+	tc.breaker.record(resp.StatusCode < http.StatusInternalServerError)
+	return resp, nil
+}
+
+// cancelOnClose cancels the context of a response when its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// Ping checks that the transaction service is live
+func (tc *transactionClient) Ping(ctx context.Context) error {
+	url := fmt.Sprintf("%s/api/v1/health/live", tc.baseURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := tc.send(req, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("transaction service returned status %d", resp.StatusCode)
+	}
+	return nil
 }
 
 // TransactionPairRequest represents the request payload for creating transaction pairs
 type TransactionPairRequest struct {
+	PairID            string             `json:"pair_id,omitempty"`
+	Reference         string             `json:"reference,omitempty"`
 	DebitTransaction  TransactionRequest `json:"debit_transaction"`
 	CreditTransaction TransactionRequest `json:"credit_transaction"`
 }
@@ -65,60 +229,274 @@ type TransactionRequest struct {
 	TransactionType model.TransactionType   `json:"transaction_type"`
 	OperationType   model.OperationType     `json:"operation_type"`
 	Amount          int64                   `json:"amount"`
+	Currency        model.Currency          `json:"currency"`
+	FXRate          string                  `json:"fx_rate,omitempty"`
+	CounterAmount   int64                   `json:"counter_amount,omitempty"`
+	CounterCurrency model.Currency          `json:"counter_currency,omitempty"`
+	ReversalOf      *string                 `json:"reversal_of,omitempty"`
+	ReviewID        *string                 `json:"review_id,omitempty"`
 	Status          model.TransactionStatus `json:"status"`
 }
 
 // TransactionResponse represents the API response wrapper for transactions
 type TransactionResponse struct {
 	Data []model.Transaction `json:"data"`
+	Meta struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"meta"`
 }
 
-// FetchTransactions retrieves transactions for a specific wallet from the transaction service
-func (tc *transactionClient) FetchTransactions(subjectWalletID string) ([]model.Transaction, error) {
+// FetchTransactions retrieves a page of transactions for a specific wallet from the transaction service
+func (tc *transactionClient) FetchTransactions(ctx context.Context, subjectWalletID string, query model.TransactionQuery) (*model.TransactionPage, error) {
 	// Create HTTP request
-	url := fmt.Sprintf("%s/api/v1/transactions/%s", tc.baseURL, subjectWalletID)
-	req, err := http.NewRequest("GET", url, nil)
+	url := fmt.Sprintf("%s/api/v1/transactions/%s", tc.baseURL, neturl.PathEscape(subjectWalletID))
+	if params := transactionQueryParams(query); len(params) > 0 {
+		url += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		utils.LogError("Failed to create HTTP request for fetching transactions", err)
+		utils.LogErrorContext(ctx, "Failed to create HTTP request for fetching transactions", err)
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send the request
-	resp, err := tc.client.Do(req)
+	resp, err := tc.send(req, nil)
 	if err != nil {
-		utils.LogError("Failed to send fetch transactions request", err)
+		utils.LogErrorContext(ctx, "Failed to send fetch transactions request", err)
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check response status
+	if resp.StatusCode == http.StatusBadRequest {
+		// Filters are validated by the wallet API, so this is an invalid or foreign cursor
+		return nil, model.ErrInvalidTransactionQuery
+	}
 	if resp.StatusCode != http.StatusOK {
-		utils.LogError(fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+		utils.LogErrorContext(ctx, fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
 		return nil, fmt.Errorf("transaction service returned status %d", resp.StatusCode)
 	}
 
 	// Parse response
 	var response TransactionResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		utils.LogError("Failed to decode transactions response", err)
+		utils.LogErrorContext(ctx, "Failed to decode transactions response", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &model.TransactionPage{
+		Transactions: response.Data,
+		NextCursor:   response.Meta.NextCursor,
+	}, nil
+}
+
+// transactionQueryParams encodes the non-zero fields of query as query parameters
+func transactionQueryParams(query model.TransactionQuery) neturl.Values {
+	params := neturl.Values{}
+	if query.Limit > 0 {
+		params.Set("limit", strconv.Itoa(query.Limit))
+	}
+	if query.Cursor != "" {
+		params.Set("cursor", query.Cursor)
+	}
+	if query.TransactionType != "" {
+		params.Set("transaction_type", string(query.TransactionType))
+	}
+	if query.OperationType != "" {
+		params.Set("operation_type", string(query.OperationType))
+	}
+	if query.Status != "" {
+		params.Set("status", string(query.Status))
+	}
+	if query.MinAmount != nil {
+		params.Set("min_amount", strconv.FormatInt(*query.MinAmount, 10))
+	}
+	if query.MaxAmount != nil {
+		params.Set("max_amount", strconv.FormatInt(*query.MaxAmount, 10))
+	}
+	if query.CreatedFrom != nil {
+		params.Set("created_from", query.CreatedFrom.Format(time.RFC3339Nano))
+	}
+	if query.CreatedTo != nil {
+		params.Set("created_to", query.CreatedTo.Format(time.RFC3339Nano))
+	}
+	return params
+}
+
+// LedgerBalanceResponse represents the API response wrapper for ledger balances
+type LedgerBalanceResponse struct {
+	Data []model.LedgerBalance `json:"data"`
+}
+
+// FetchLedgerBalances retrieves the net of completed transactions per wallet from the transaction service
+func (tc *transactionClient) FetchLedgerBalances(ctx context.Context) ([]model.LedgerBalance, error) {
+	// Create HTTP request
+	url := fmt.Sprintf("%s/api/v1/ledger/balances", tc.baseURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to create HTTP request for fetching ledger balances", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send the request
+	resp, err := tc.send(req, nil)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to send fetch ledger balances request", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		utils.LogErrorContext(ctx, fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+		return nil, fmt.Errorf("transaction service returned status %d", resp.StatusCode)
+	}
+
+	// Parse response
+	var response LedgerBalanceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		utils.LogErrorContext(ctx, "Failed to decode ledger balances response", err)
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return response.Data, nil
 }
 
-// CreateTransactionPair sends both debit and credit transactions to the transactions microservice
-func (tc *transactionClient) CreateTransactionPair(debitTxn, creditTxn *model.Transaction) error {
+// SingleTransactionResponse represents the API response wrapper for a single transaction
+type SingleTransactionResponse struct {
+	Data model.Transaction `json:"data"`
+}
+
+// FetchTransaction retrieves a single transaction by ID from the transaction service
+func (tc *transactionClient) FetchTransaction(ctx context.Context, id string) (*model.Transaction, error) {
+	// Create HTTP request
+	url := fmt.Sprintf("%s/api/v1/ledger/transactions/%s", tc.baseURL, neturl.PathEscape(id))
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to create HTTP request for fetching transaction", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send the request
+	resp, err := tc.send(req, nil)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to send fetch transaction request", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check response status
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, model.ErrTransactionNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		utils.LogErrorContext(ctx, fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+		return nil, fmt.Errorf("transaction service returned status %d", resp.StatusCode)
+	}
+
+	// Parse response
+	var response SingleTransactionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		utils.LogErrorContext(ctx, "Failed to decode transaction response", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &response.Data, nil
+}
+
+// maxLegacyIDs is the number of legacy IDs the transaction service looks up per request
+const maxLegacyIDs = 100
+
+// LegacyTransactionIDResponse represents the API response wrapper for legacy transaction IDs
+type LegacyTransactionIDResponse struct {
+	Data []struct {
+		LegacyID int64  `json:"legacy_id"`
+		ID       string `json:"id"`
+	} `json:"data"`
+}
+
+// ResolveLegacyIDs retrieves the public IDs of transactions recorded before
+// public IDs from the transaction service, by the serial IDs they were known
+// by. Unknown IDs are left out of the result.
+func (tc *transactionClient) ResolveLegacyIDs(ctx context.Context, legacyIDs []int64) (map[int64]string, error) {
+	ids := make(map[int64]string, len(legacyIDs))
+	for start := 0; start < len(legacyIDs); start += maxLegacyIDs {
+		end := min(start+maxLegacyIDs, len(legacyIDs))
+		if err := tc.resolveLegacyIDs(ctx, legacyIDs[start:end], ids); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// resolveLegacyIDs looks up a batch of legacy IDs and adds their public IDs to ids
+func (tc *transactionClient) resolveLegacyIDs(ctx context.Context, legacyIDs []int64, ids map[int64]string) error {
+	// Create HTTP request
+	params := neturl.Values{}
+	for _, id := range legacyIDs {
+		params.Add("id", strconv.FormatInt(id, 10))
+	}
+	url := fmt.Sprintf("%s/api/v1/ledger/legacy-transactions?%s", tc.baseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to create HTTP request for resolving legacy transaction IDs", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send the request
+	resp, err := tc.send(req, nil)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to send resolve legacy transaction IDs request", err)
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		utils.LogErrorContext(ctx, fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+		return fmt.Errorf("transaction service returned status %d", resp.StatusCode)
+	}
+
+	// Parse response
+	var response LegacyTransactionIDResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		utils.LogErrorContext(ctx, "Failed to decode legacy transaction IDs response", err)
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, id := range response.Data {
+		ids[id.LegacyID] = id.ID
+	}
+	return nil
+}
+
+// CreateTransactionPair sends both debit and credit transactions to the transactions microservice.
+// Requests sent with the same idempotencyKey are recorded only once.
+func (tc *transactionClient) CreateTransactionPair(ctx context.Context, debitTxn, creditTxn *model.Transaction, idempotencyKey string) error {
 	// Prepare the request payload
 	request := TransactionPairRequest{
+		PairID:    debitTxn.PairID,
+		Reference: debitTxn.Reference,
 		DebitTransaction: TransactionRequest{
 			SubjectWalletID: debitTxn.SubjectWalletID,
 			ObjectWalletID:  debitTxn.ObjectWalletID,
 			TransactionType: debitTxn.TransactionType,
 			OperationType:   debitTxn.OperationType,
 			Amount:          debitTxn.Amount,
+			Currency:        debitTxn.Currency,
+			FXRate:          debitTxn.FXRate,
+			CounterAmount:   debitTxn.CounterAmount,
+			CounterCurrency: debitTxn.CounterCurrency,
+			ReversalOf:      debitTxn.ReversalOf,
+			ReviewID:        debitTxn.ReviewID,
 			Status:          debitTxn.Status,
 		},
 		CreditTransaction: TransactionRequest{
@@ -127,6 +505,12 @@ func (tc *transactionClient) CreateTransactionPair(debitTxn, creditTxn *model.Tr
 			TransactionType: creditTxn.TransactionType,
 			OperationType:   creditTxn.OperationType,
 			Amount:          creditTxn.Amount,
+			Currency:        creditTxn.Currency,
+			FXRate:          creditTxn.FXRate,
+			CounterAmount:   creditTxn.CounterAmount,
+			CounterCurrency: creditTxn.CounterCurrency,
+			ReversalOf:      creditTxn.ReversalOf,
+			ReviewID:        creditTxn.ReviewID,
 			Status:          creditTxn.Status,
 		},
 	}
@@ -134,34 +518,82 @@ func (tc *transactionClient) CreateTransactionPair(debitTxn, creditTxn *model.Tr
 	// Marshal the request to JSON
 	jsonData, err := json.Marshal(request)
 	if err != nil {
-		utils.LogError("Failed to marshal transaction pair request", err)
+		utils.LogErrorContext(ctx, "Failed to marshal transaction pair request", err)
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Create HTTP request
 	url := fmt.Sprintf("%s/api/v1/transactions", tc.baseURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		utils.LogError("Failed to create HTTP request for transaction pair", err)
+		utils.LogErrorContext(ctx, "Failed to create HTTP request for transaction pair", err)
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
 
 	// Send the request
-	resp, err := tc.client.Do(req)
+	resp, err := tc.send(req, jsonData)
 	if err != nil {
-		utils.LogError("Failed to send transaction pair request", err)
+		utils.LogErrorContext(ctx, "Failed to send transaction pair request", err)
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check response status
 	if resp.StatusCode != http.StatusCreated {
-		utils.LogError(fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+		utils.LogErrorContext(ctx, fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
 		return fmt.Errorf("transaction service returned status %d", resp.StatusCode)
 	}
 
 	// Successfully created transaction pair
 	return nil
 }
+
+// PairStatusRequest represents the request payload for setting the status of a reviewed pair
+type PairStatusRequest struct {
+	Status model.TransactionStatus `json:"status"`
+}
+
+// UpdatePairStatus sets the status of the pending transaction pair of a review in the transactions microservice.
+// Requests sent with the same idempotencyKey are applied only once.
+func (tc *transactionClient) UpdatePairStatus(ctx context.Context, reviewID string, status model.TransactionStatus, idempotencyKey string) error {
+	// Marshal the request to JSON
+	jsonData, err := json.Marshal(PairStatusRequest{Status: status})
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to marshal pair status request", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Create HTTP request
+	url := fmt.Sprintf("%s/api/v1/ledger/reviews/%s", tc.baseURL, neturl.PathEscape(reviewID))
+	req, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to create HTTP request for pair status", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	// Send the request
+	resp, err := tc.send(req, jsonData)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to send pair status request", err)
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check response status, a pair that is not recorded yet is retried by the relay
+	if resp.StatusCode != http.StatusOK {
+		utils.LogErrorContext(ctx, fmt.Sprintf("Transaction microservice returned status %d", resp.StatusCode), nil)
+		return fmt.Errorf("transaction service returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
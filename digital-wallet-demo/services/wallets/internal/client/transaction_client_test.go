@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testService returns the configuration of a client of the transaction service at baseURL
+func testService(baseURL string) model.Service {
+	return model.Service{
+		BaseURL: baseURL,
+		Secret:  "test-wallets-service-secret-0123456789",
+		Timeout: time.Second,
+		Retry:   model.Retry{MaxAttempts: 3, BaseBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+	}
+}
+
+func TestTransactionClient_Retry(t *testing.T) {
+	var calls atomic.Int32
+	var mu sync.Mutex
+	nonces := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		nonces[r.Header.Get("X-Signature-Nonce")] = true
+		mu.Unlock()
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data":[],"meta":{"next_cursor":""}}`))
+	}))
+	defer server.Close()
+
+	tc := newTransactionClient(testService(server.URL))
+
+	page, err := tc.FetchTransactions(context.Background(), "test-user", model.TransactionQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Transactions)
+	assert.Equal(t, int32(3), calls.Load())
+	// Every attempt is signed anew
+	assert.Len(t, nonces, 3)
+}
+
+func TestTransactionClient_RetryExhausted(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	tc := newTransactionClient(testService(server.URL))
+
+	_, err := tc.FetchTransactions(context.Background(), "test-user", model.TransactionQuery{})
+	assert.ErrorIs(t, err, model.ErrTransactionsUnavailable)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestTransactionClient_NoRetryWithoutIdempotencyKey(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tc := newTransactionClient(testService(server.URL))
+	debitTxn := &model.Transaction{TransactionType: model.Transfer, OperationType: model.Debit, Amount: 100}
+	creditTxn := &model.Transaction{TransactionType: model.Transfer, OperationType: model.Credit, Amount: 100}
+
+	err := tc.CreateTransactionPair(context.Background(), debitTxn, creditTxn, "")
+	assert.ErrorIs(t, err, model.ErrTransactionsUnavailable)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// With an idempotency key, the pair is recorded only once however often it is sent
+	calls.Store(0)
+	err = tc.CreateTransactionPair(context.Background(), debitTxn, creditTxn, "outbox-1")
+	assert.ErrorIs(t, err, model.ErrTransactionsUnavailable)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestTransactionClient_ResolveLegacyIDs(t *testing.T) {
+	var mu sync.Mutex
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/ledger/legacy-transactions", r.URL.Path)
+		ids := r.URL.Query()["id"]
+		mu.Lock()
+		batches = append(batches, len(ids))
+		mu.Unlock()
+		// Only the first ID of every batch is known
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, `{"data":[{"legacy_id":%s,"id":"public-%s"}]}`, ids[0], ids[0])
+	}))
+	defer server.Close()
+
+	tc := newTransactionClient(testService(server.URL))
+	legacyIDs := make([]int64, 2*maxLegacyIDs+1)
+	for i := range legacyIDs {
+		legacyIDs[i] = int64(i + 1)
+	}
+
+	ids, err := tc.ResolveLegacyIDs(context.Background(), legacyIDs)
+	require.NoError(t, err)
+	assert.Equal(t, []int{maxLegacyIDs, maxLegacyIDs, 1}, batches)
+	assert.Equal(t, map[int64]string{1: "public-1", 101: "public-101", 201: "public-201"}, ids)
+}
+
+func TestTransactionClient_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	svc := testService(server.URL)
+	svc.Timeout = 50 * time.Millisecond
+	tc := newTransactionClient(svc)
+
+	start := time.Now()
+	_, err := tc.FetchTransactions(context.Background(), "test-user", model.TransactionQuery{})
+	assert.ErrorIs(t, err, model.ErrTransactionsUnavailable)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// A caller giving up is not an unavailable transaction service
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = tc.FetchTransactions(ctx, "test-user", model.TransactionQuery{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, model.ErrTransactionsUnavailable)
+}
+
+func TestTransactionClient_Breaker(t *testing.T) {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	svc := testService(server.URL)
+	svc.Retry = model.Retry{}
+	svc.Breaker = model.Breaker{FailureThreshold: 2, OpenTimeout: time.Minute}
+	tc := newTransactionClient(svc)
+	now := time.Now()
+	tc.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, err := tc.FetchLedgerBalances(context.Background())
+		assert.ErrorIs(t, err, model.ErrTransactionsUnavailable)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+
+	// The breaker is open, calls fail without reaching the transaction service
+	healthy.Store(true)
+	_, err := tc.FetchLedgerBalances(context.Background())
+	assert.ErrorIs(t, err, model.ErrTransactionsUnavailable)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// After the open timeout, a probe closes the breaker again
+	now = now.Add(time.Minute)
+	_, err = tc.FetchLedgerBalances(context.Background())
+	require.NoError(t, err)
+	_, err = tc.FetchLedgerBalances(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	b := newBreaker(model.Breaker{FailureThreshold: 1, OpenTimeout: time.Minute})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	require.True(t, b.allow())
+	b.record(false)
+	assert.False(t, b.allow())
+
+	// A single probe is let through once the open timeout has passed
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow())
+	assert.False(t, b.allow())
+
+	// A failed probe opens the breaker again
+	b.record(false)
+	assert.False(t, b.allow())
+
+	// An abandoned probe lets the next call probe right away
+	now = now.Add(time.Minute)
+	require.True(t, b.allow())
+	b.abandon()
+	assert.True(t, b.allow())
+	b.record(true)
+	assert.True(t, b.allow())
+	assert.True(t, b.allow())
+
+	assert.Nil(t, newBreaker(model.Breaker{}))
+	assert.True(t, (*breaker)(nil).allow())
+}
+
+func TestRetryBackoff(t *testing.T) {
+	cfg := model.Retry{BaseBackoff: 10 * time.Millisecond, MaxBackoff: 40 * time.Millisecond}
+	for retry, max := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 40 * time.Millisecond, 10: 40 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			backoff := retryBackoff(retry, cfg)
+			assert.GreaterOrEqual(t, backoff, time.Duration(0))
+			assert.LessOrEqual(t, backoff, max)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/auth"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// identityKey is the context key of the authenticated caller.
+	identityKey = "identity"
+
+	// devModeKey is the context key marking requests served without authentication in development mode.
+	devModeKey = "devMode"
+
+	walletForbidden = "Caller may not act on this wallet"
+	adminForbidden  = "Admin role required"
+)
+
+// Authenticate returns a middleware that rejects requests without a valid
+// bearer token and stores the caller's identity in the context.
+//
+// Without a verifier, which is only allowed in development mode, requests are
+// passed through unauthenticated: the caller may act on every wallet but is
+// never an admin.
+func Authenticate(v *auth.Verifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if v == nil {
+			return func(c echo.Context) error {
+				c.Set(devModeKey, true)
+				return next(c)
+			}
+		}
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok || token == "" {
+				return unauthorized(c, "Missing bearer token")
+			}
+
+			identity, err := v.Verify(token)
+			if err != nil {
+				return unauthorized(c, "Invalid bearer token")
+			}
+			c.Set(identityKey, identity)
+			return next(c)
+		}
+	}
+}
+
+// RequireAdmin is a middleware that only lets authenticated admins through.
+func RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !callerIsAdmin(c) {
+			return forbidden(c, adminForbidden)
+		}
+		return next(c)
+	}
+}
+
+// RequireOwner returns a middleware that only lets the owner of the wallet
+// named by the path parameter, or an admin, through.
+func RequireOwner(param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !canActFor(c, c.Param(param)) {
+				return forbidden(c, walletForbidden)
+			}
+			return next(c)
+		}
+	}
+}
+
+// callerIdentity returns the authenticated caller, or nil if the request was not authenticated.
+func callerIdentity(c echo.Context) *auth.Identity {
+	identity, _ := c.Get(identityKey).(*auth.Identity)
+	return identity
+}
+
+// canActFor reports whether the caller may act on the wallet of userID.
+// Unauthenticated callers may only act on wallets in development mode.
+func canActFor(c echo.Context, userID string) bool {
+	if identity := callerIdentity(c); identity != nil {
+		return identity.CanActFor(userID)
+	}
+	devMode, _ := c.Get(devModeKey).(bool)
+	return devMode
+}
+
+// callerIsAdmin reports whether the caller is authenticated and has the admin role.
+func callerIsAdmin(c echo.Context) bool {
+	identity := callerIdentity(c)
+	return identity != nil && identity.Admin
+}
+
+func unauthorized(c echo.Context, message string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+	return c.JSON(http.StatusUnauthorized,
+		ResponseError{Errors: []Error{{Code: errors.CodeUnauthorized, Message: message}}})
+}
+
+func forbidden(c echo.Context, message string) error {
+	return c.JSON(http.StatusForbidden,
+		ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: message}}})
+}
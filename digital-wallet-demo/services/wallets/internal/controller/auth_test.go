@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/auth"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testToken(t *testing.T, subject string, roles ...string) string {
+	claims := jwt.MapClaims{"sub": subject, "exp": time.Now().Add(time.Hour).Unix()}
+	if len(roles) > 0 {
+		claims["roles"] = roles
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+	return token
+}
+
+func TestAuthenticate(t *testing.T) {
+	verifier, err := auth.NewVerifier(model.Auth{Algorithm: "HS256", Secret: "test-secret"})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+	handler := NewWalletController(nil)
+
+	api := e.Group("/api/v1", Authenticate(verifier))
+	api.GET("/wallets/:user_id", ok, RequireOwner("user_id"))
+	api.GET("/admin/limits", ok, RequireAdmin)
+	api.POST("/wallets/withdraw", handler.Withdraw)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		token  string
+		want   int
+	}{
+		{"missing_token", http.MethodGet, "/api/v1/wallets/test-user-001", "", "", http.StatusUnauthorized},
+		{"invalid_token", http.MethodGet, "/api/v1/wallets/test-user-001", "", "not-a-token", http.StatusUnauthorized},
+		{"own_wallet", http.MethodGet, "/api/v1/wallets/test-user-001", "", testToken(t, "test-user-001"), http.StatusNoContent},
+		{"other_wallet", http.MethodGet, "/api/v1/wallets/test-user-002", "", testToken(t, "test-user-001"), http.StatusForbidden},
+		{"admin_other_wallet", http.MethodGet, "/api/v1/wallets/test-user-002", "", testToken(t, "ops-001", "admin"), http.StatusNoContent},
+		{"admin_endpoint_as_user", http.MethodGet, "/api/v1/admin/limits", "", testToken(t, "test-user-001"), http.StatusForbidden},
+		{"admin_endpoint_as_admin", http.MethodGet, "/api/v1/admin/limits", "", testToken(t, "ops-001", "admin"), http.StatusNoContent},
+		{"withdraw_from_other_wallet", http.MethodPost, "/api/v1/wallets/withdraw", `{"user_id":"test-user-002", "amount":100}`, testToken(t, "test-user-001"), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestAuthenticate_Disabled(t *testing.T) {
+	e := echo.New()
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+
+	api := e.Group("/api/v1", Authenticate(nil))
+	api.GET("/wallets/:user_id", ok, RequireOwner("user_id"))
+	api.GET("/admin/limits", ok, RequireAdmin)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/v1/wallets/test-user-001", http.StatusNoContent},
+		{"/api/v1/admin/limits", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		assert.Equal(t, tt.want, rec.Code, tt.path)
+	}
+}
+
+func TestAuthorize_WithoutIdentity(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	assert.False(t, canActFor(c, "test-user-001"))
+	assert.False(t, callerIsAdmin(c))
+}
+
+// holdStubWallet serves a hold between two other users, a hold test-user-001
+// sent, a hold test-user-001 receives and a hold that fails to load, and fails
+// the test on any other call.
+type holdStubWallet struct {
+	service.Wallet
+}
+
+func (holdStubWallet) GetHold(_ context.Context, id string) (*model.Hold, error) {
+	switch id {
+	case "hold-sent":
+		return &model.Hold{ID: id, FromUserID: "test-user-001", ToUserID: "test-user-002", Amount: 100}, nil
+	case "hold-received":
+		return &model.Hold{ID: id, FromUserID: "test-user-002", ToUserID: "test-user-001", Amount: 100}, nil
+	case "hold-unavailable":
+		return nil, errors.New("database unavailable")
+	}
+	return &model.Hold{ID: id, FromUserID: "test-user-002", ToUserID: "test-user-003", Amount: 100}, nil
+}
+
+func TestInitRoutes_NonOwner(t *testing.T) {
+	verifier, err := auth.NewVerifier(model.Auth{Algorithm: "HS256", Secret: "test-secret"})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	passthrough := func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	InitRoutes(e.Group("/api/v1"),
+		NewWalletController(holdStubWallet{}),
+		NewFXController(nil),
+		NewScheduleController(nil),
+		NewLimitController(nil),
+		NewReviewController(nil),
+		passthrough,
+		Authenticate(verifier),
+	)
+	token := testToken(t, "test-user-001")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"withdraw", http.MethodPost, "/api/v1/wallets/withdraw", `{"user_id":"test-user-002", "amount":100}`},
+		{"transfer", http.MethodPost, "/api/v1/wallets/transfer", `{"from_user_id":"test-user-002", "to_user_id":"test-user-001", "amount":100}`},
+		{"create_hold", http.MethodPost, "/api/v1/wallets/holds", `{"from_user_id":"test-user-002", "to_user_id":"test-user-001", "amount":100}`},
+		{"get_hold", http.MethodGet, "/api/v1/wallets/holds/hold-001", ""},
+		{"capture_hold", http.MethodPost, "/api/v1/wallets/holds/hold-001/capture", `{}`},
+		{"void_hold", http.MethodPost, "/api/v1/wallets/holds/hold-001/void", ""},
+		{"capture_sent_hold", http.MethodPost, "/api/v1/wallets/holds/hold-sent/capture", `{}`},
+		{"void_received_hold", http.MethodPost, "/api/v1/wallets/holds/hold-received/void", ""},
+		{"capture_unavailable_hold", http.MethodPost, "/api/v1/wallets/holds/hold-unavailable/capture", `{}`},
+		{"void_unavailable_hold", http.MethodPost, "/api/v1/wallets/holds/hold-unavailable/void", ""},
+		{"transactions", http.MethodGet, "/api/v1/wallets/test-user-002", ""},
+		{"schedules", http.MethodGet, "/api/v1/wallets/test-user-002/schedules", ""},
+		{"wallet_status", http.MethodPatch, "/api/v1/wallets/test-user-001/status", `{"status":"frozen"}`},
+		{"reverse", http.MethodPost, "/api/v1/wallets/transactions/0190c3a2-5e4b-7d21-9f3a-6b8c2d4e1f07/reverse", ""},
+		{"admin_limits", http.MethodGet, "/api/v1/admin/limits", ""},
+		{"admin_reviews", http.MethodPost, "/api/v1/admin/reviews/review-001/approve", ""},
+		{"admin_review_flags", http.MethodPut, "/api/v1/admin/review-flags/test-user-002", `{"reason":"chargebacks"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+		})
+	}
+}
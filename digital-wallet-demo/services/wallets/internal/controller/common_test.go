@@ -3,10 +3,20 @@ package controller
 import (
 	"encoding/json"
 	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/fx"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 // cmpTransformJSON はcmp.DiffでJSON文字列([]byte)を比較のためのオプション
@@ -34,3 +44,55 @@ func ignoreMapEntires(want map[string]any) cmp.Option {
 		return ok
 	})
 }
+
+// inDevMode marks c as served without authentication in development mode, as
+// the Authenticate middleware does without a verifier, so that handlers called
+// directly may act on every wallet.
+func inDevMode(c echo.Context) echo.Context {
+	c.Set(devModeKey, true)
+	return c
+}
+
+// newTestWalletService returns a wallet service on db with the default test
+// dependencies: repositories on db, a mock cache and transaction client, a risk
+// engine without rules, static rates and holds expiring after an hour. Each of
+// edits changes the defaults before the service is created.
+func newTestWalletService(t *testing.T, db *gorm.DB, edits ...func(opts *service.WalletServiceOpts)) service.Wallet {
+	opts := service.WalletServiceOpts{
+		WalletRepository:   repository.NewWalletRepo(db),
+		OutboxRepository:   repository.NewOutboxRepo(db),
+		FXQuoteRepository:  repository.NewFXQuoteRepo(db),
+		ReversalRepository: repository.NewReversalRepo(db),
+		HoldRepository:     repository.NewHoldRepo(db),
+		LimitRepository:    repository.NewLimitRepo(db),
+		ReviewRepository:   repository.NewReviewRepo(db),
+		AuditRepository:    repository.NewAuditRepo(db),
+		RedisClient:        cache.NewMockRedisClient(),
+		TxnClient:          &client.MockTransactionClient{},
+		RiskEngine:         newTestRiskEngine(t, db, model.Risk{}),
+		Rates:              fx.DefaultStaticRates(),
+		HoldTTL:            time.Hour,
+	}
+	for _, edit := range edits {
+		edit(&opts)
+	}
+	return service.NewWalletService(opts)
+}
+
+// newTestReviewService returns a review service on db with the default test
+// dependencies: repositories on db and a mock cache. Each of edits changes the
+// defaults before the service is created.
+func newTestReviewService(db *gorm.DB, edits ...func(opts *service.ReviewServiceOpts)) service.Review {
+	opts := service.ReviewServiceOpts{
+		ReviewRepository: repository.NewReviewRepo(db),
+		WalletRepository: repository.NewWalletRepo(db),
+		LimitRepository:  repository.NewLimitRepo(db),
+		OutboxRepository: repository.NewOutboxRepo(db),
+		AuditRepository:  repository.NewAuditRepo(db),
+		RedisClient:      cache.NewMockRedisClient(),
+	}
+	for _, edit := range edits {
+		edit(&opts)
+	}
+	return service.NewReviewService(opts)
+}
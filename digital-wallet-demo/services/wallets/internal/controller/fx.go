@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// FXHandler is the request handler for the exchange rate endpoints.
+type FXHandler interface {
+	CreateQuote(c echo.Context) error
+}
+
+type fxHandler struct {
+	Handler
+	service service.FX
+}
+
+// NewFXController returns a new instance of the FX handler.
+func NewFXController(s service.FX) FXHandler {
+	return &fxHandler{service: s}
+}
+
+// QuoteRequest represents the request for an exchange rate quote
+type QuoteRequest struct {
+	FromCurrency model.Currency `json:"from_currency" validate:"required,validCurrency"`
+	ToCurrency   model.Currency `json:"to_currency" validate:"required,validCurrency,nefield=FromCurrency"`
+	Amount       int64          `json:"amount" validate:"required,gt=0"` // Amount in minor units of from_currency
+}
+
+// @Summary	Quote a currency conversion
+// @Description	Locks the current exchange rate for a short window. Pass the quote id as quote_id of a transfer with the same currencies and amount to convert at this rate. A quote can be used once.
+// @Tags		fx
+// @Accept		json
+// @Produce	json
+// @Param		request	body		QuoteRequest	true	"Quote request"
+// @Success	201		{object}	ResponseData{data=model.FXQuote}
+// @Failure	400		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/fx/quotes [post]
+func (h *fxHandler) CreateQuote(c echo.Context) error {
+	var req QuoteRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	quote, err := h.service.Quote(c.Request().Context(), req.FromCurrency, req.ToCurrency, req.Amount)
+	if err != nil {
+		if err == model.ErrRateUnavailable {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeRateUnavailable, Message: "No exchange rate available for the currency pair"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseData{Data: quote})
+}
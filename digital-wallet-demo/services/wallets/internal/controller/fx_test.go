@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/fx"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/google/go-cmp/cmp"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFXHandler_CreateQuote(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	rates, err := fx.NewStaticRates("USD", map[model.Currency]string{"EUR": "0.92", "JPY": "150.25"})
+	require.NoError(t, err)
+	service := service.NewFXService(repository.NewFXQuoteRepo(dbInstance), rates, time.Minute)
+	handler := NewFXController(service)
+
+	tests := []struct {
+		name      string
+		quoteBody string
+		want      want
+	}{
+		{
+			name:      "successful_quote",
+			quoteBody: `{"from_currency":"USD", "to_currency":"EUR", "amount":10000}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"from_currency":"USD", "to_currency":"EUR", "amount":10000, "counter_amount":9200, "rate":"0.92000000"}}`),
+			},
+		},
+		{
+			name:      "cross_rate_quote",
+			quoteBody: `{"from_currency":"JPY", "to_currency":"EUR", "amount":15025}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"from_currency":"JPY", "to_currency":"EUR", "amount":15025, "counter_amount":9200, "rate":"0.00612313"}}`),
+			},
+		},
+		{
+			name:      "rate_unavailable",
+			quoteBody: `{"from_currency":"USD", "to_currency":"GBP", "amount":10000}`,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+			},
+		},
+		{
+			name:      "same_currency",
+			quoteBody: `{"from_currency":"USD", "to_currency":"USD", "amount":10000}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:      "unsupported_currency",
+			quoteBody: `{"from_currency":"USD", "to_currency":"XYZ", "amount":10000}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:      "missing_amount",
+			quoteBody: `{"from_currency":"USD", "to_currency":"EUR"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean database before each test
+			clearDB(dbInstance, model.FXQuote{})
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPost, "/fx/quotes", bytes.NewReader([]byte(tt.quoteBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/fx/quotes")
+
+			// Execute
+			require.NoError(t, handler.CreateQuote(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			if tt.want.Response == nil {
+				return
+			}
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"created_at": 1, "expires_at": 1, "id": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
@@ -4,9 +4,17 @@ package controller
 import (
 	"net/http"
 
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
 	"github.com/labstack/echo/v4"
 )
 
+// Actors recorded in the audit log for requests not made on behalf of a wallet's user.
+const (
+	apiActor   = "api"
+	adminActor = "admin"
+)
+
 // Handler is the request handler for the application.
 type Handler struct{}
 
@@ -20,3 +28,26 @@ func (h Handler) MustBind(c echo.Context, req interface{}) error {
 	}
 	return nil
 }
+
+// AuditContext returns the audit context of a change requested by actor.
+// The authenticated caller of the request takes the place of actor. The
+// request ID is the one stored in the request context by the request ID
+// middleware, or else the X-Request-ID header of the request.
+func (h Handler) AuditContext(c echo.Context, actor string) model.AuditContext {
+	if identity := callerIdentity(c); identity != nil {
+		actor = userActor(identity.UserID)
+		if identity.Admin {
+			actor = adminActor + ":" + identity.UserID
+		}
+	}
+	requestID := tracing.RequestID(c.Request().Context())
+	if requestID == "" {
+		requestID = c.Request().Header.Get(echo.HeaderXRequestID)
+	}
+	return model.AuditContext{Actor: actor, RequestID: requestID}
+}
+
+// userActor returns the actor recorded in the audit log for a change requested on behalf of userID.
+func userActor(userID string) string {
+	return "user:" + userID
+}
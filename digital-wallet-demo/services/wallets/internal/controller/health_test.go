@@ -1,25 +1,121 @@
 package controller
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestHealth(t *testing.T) {
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+func hang(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// serveHealth calls handle and returns the response code and health status
+func serveHealth(t *testing.T, handle echo.HandlerFunc) (int, HealthStatus) {
 	e := echo.New()
-	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	rec := httptest.NewRecorder()
-	c := e.NewContext(req, rec)
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/health/ready", nil), rec)
+	require.NoError(t, handle(c))
+
+	var resp struct {
+		Data HealthStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp.Data
+}
+
+func TestHealthLive(t *testing.T) {
+	h := NewHealth(Dependency{Name: "postgres", Hard: true, Ping: down})
+
+	code, health := serveHealth(t, h.Live)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthOK, health.Status)
+	assert.Empty(t, health.Dependencies)
+}
+
+func TestHealthReady(t *testing.T) {
+	tests := []struct {
+		name         string
+		dependencies []Dependency
+		expectedCode int
+		expected     string
+	}{
+		{
+			name: "All dependencies up",
+			dependencies: []Dependency{
+				{Name: "postgres", Hard: true, Ping: up},
+				{Name: "redis", Ping: up},
+			},
+			expectedCode: http.StatusOK,
+			expected:     HealthOK,
+		},
+		{
+			name: "Soft dependency down",
+			dependencies: []Dependency{
+				{Name: "postgres", Hard: true, Ping: up},
+				{Name: "redis", Ping: down},
+			},
+			expectedCode: http.StatusOK,
+			expected:     HealthDegraded,
+		},
+		{
+			name: "Hard dependency down",
+			dependencies: []Dependency{
+				{Name: "postgres", Hard: true, Ping: down},
+				{Name: "redis", Ping: down},
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expected:     HealthUnavailable,
+		},
+		{
+			name: "Hard dependency timed out",
+			dependencies: []Dependency{
+				{Name: "postgres", Hard: true, Timeout: 10 * time.Millisecond, Ping: hang},
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expected:     HealthUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealth(tt.dependencies...)
+
+			code, health := serveHealth(t, h.Ready)
+			assert.Equal(t, tt.expectedCode, code)
+			assert.Equal(t, tt.expected, health.Status)
+			require.Len(t, health.Dependencies, len(tt.dependencies))
+			for _, dep := range tt.dependencies {
+				status := health.Dependencies[dep.Name]
+				assert.Equal(t, dep.Hard, status.Hard)
+				assert.Equal(t, status.Status == DependencyDown, status.Error != "")
+			}
+		})
+	}
+}
+
+func TestHealthDrain(t *testing.T) {
+	h := NewHealth(Dependency{Name: "postgres", Hard: true, Ping: up})
+	h.Drain()
 
-	h := NewHealth()
+	code, health := serveHealth(t, h.Ready)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthDraining, health.Status)
 
-	err := h.Health(c)
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, rec.Code)
-	assert.NotEmpty(t, rec.Body.String())
+	// The instance still serves requests while draining
+	code, _ = serveHealth(t, h.Live)
+	assert.Equal(t, http.StatusOK, code)
 }
@@ -0,0 +1,259 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// HoldRequest represents the request for placing a hold
+type HoldRequest struct {
+	FromUserID string         `json:"from_user_id" validate:"required"`
+	ToUserID   string         `json:"to_user_id" validate:"required"`
+	Amount     int            `json:"amount" validate:"required,gt=0"`                       // Amount in minor units of the currency
+	Currency   model.Currency `json:"currency,omitempty" validate:"omitempty,validCurrency"` // Defaults to the sender's base currency
+}
+
+// CaptureRequest represents the request for capturing a hold
+type CaptureRequest struct {
+	HoldID string `param:"id" json:"-" validate:"required"`
+	Amount int64  `json:"amount,omitempty" validate:"omitempty,gt=0"` // Amount to transfer in minor units of the hold's currency, defaults to the full held amount
+}
+
+// HoldIDRequest is the request parameter for operations on a single hold
+type HoldIDRequest struct {
+	HoldID string `param:"id" validate:"required"`
+}
+
+// @Summary	Place a hold on wallet funds
+// @Description	Reserves an amount of the sender's balance for the receiver. The held amount is deducted from the available balance but stays in the balance until the hold is captured. Holds expire after a configured TTL.
+// @Tags		holds
+// @Accept		json
+// @Produce	json
+// @Param		request	body		HoldRequest	true	"Hold request"
+// @Success	201		{object}	ResponseData{data=model.Hold}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/holds [post]
+func (t *walletHandler) CreateHold(c echo.Context) error {
+	var req HoldRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if !canActFor(c, req.FromUserID) {
+		return forbidden(c, walletForbidden)
+	}
+
+	// Validate that from and to wallets are different
+	if req.FromUserID == req.ToUserID {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Cannot hold funds for the same wallet"}}})
+	}
+
+	hold, err := t.service.CreateHold(c.Request().Context(), service.HoldParams{
+		FromUserID: req.FromUserID,
+		ToUserID:   req.ToUserID,
+		Amount:     int64(req.Amount),
+		Currency:   req.Currency,
+		Audit:      t.AuditContext(c, apiActor),
+	})
+	if err != nil {
+		if err == model.ErrUnsupportedCurrency {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+		}
+		if code, ok := walletStatusErrors[err]; ok {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: code, Message: err.Error()}}})
+		}
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+		}
+		if err == model.ErrInsufficientFunds {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient available balance"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseData{Data: hold})
+}
+
+// @Summary	Get a hold
+// @Tags		holds
+// @Produce	json
+// @Param		id	path		string	true	"Hold ID"
+// @Success	200	{object}	ResponseData{data=model.Hold}
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/holds/{id} [get]
+func (t *walletHandler) GetHold(c echo.Context) error {
+	var req HoldIDRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	hold, err := t.service.GetHold(c.Request().Context(), req.HoldID)
+	if err != nil {
+		if err == model.ErrHoldNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Hold not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	if !canActFor(c, hold.FromUserID) && !canActFor(c, hold.ToUserID) {
+		return forbidden(c, walletForbidden)
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: hold})
+}
+
+// @Summary	Capture a hold
+// @Description	Transfers all or part of the held amount to the hold's receiver. The remainder of a partial capture is released. Only the receiver may capture a hold.
+// @Tags		holds
+// @Accept		json
+// @Produce	json
+// @Param		id		path		string			true	"Hold ID"
+// @Param		request	body		CaptureRequest	false	"Capture request"
+// @Success	201		{object}	ResponseData{data=model.Transaction}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/holds/{id}/capture [post]
+func (t *walletHandler) CaptureHold(c echo.Context) error {
+	var req CaptureRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if !t.canActOnHold(c, req.HoldID, holdReceiver) {
+		return forbidden(c, walletForbidden)
+	}
+
+	transaction, err := t.service.CaptureHold(c.Request().Context(), service.CaptureParams{
+		HoldID: req.HoldID,
+		Amount: req.Amount,
+		Audit:  t.AuditContext(c, apiActor),
+	})
+	if err != nil {
+		if err == model.ErrHoldNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Hold not found"}}})
+		}
+		if err == model.ErrHoldExpired {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeHoldExpired, Message: "Hold has expired"}}})
+		}
+		if err == model.ErrHoldNotActive {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeHoldNotActive, Message: "Hold has already been captured or voided"}}})
+		}
+		if err == model.ErrCaptureAmount {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeCaptureAmountExceeded, Message: "Amount exceeds the held amount"}}})
+		}
+		if code, ok := walletStatusErrors[err]; ok {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: code, Message: err.Error()}}})
+		}
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+		}
+		if err == model.ErrInsufficientFunds {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
+		}
+		if err == model.ErrLimitExceeded {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeLimitExceeded, Message: "Spending limit of the wallet exceeded"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseData{Data: transaction})
+}
+
+// @Summary	Void a hold
+// @Description	Releases the held amount without moving any funds. Only the sender may void a hold.
+// @Tags		holds
+// @Produce	json
+// @Param		id	path		string	true	"Hold ID"
+// @Success	200	{object}	ResponseData{data=model.Hold}
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	409	{object}	ResponseError
+// @Failure	422	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/holds/{id}/void [post]
+func (t *walletHandler) VoidHold(c echo.Context) error {
+	var req HoldIDRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if !t.canActOnHold(c, req.HoldID, holdSender) {
+		return forbidden(c, walletForbidden)
+	}
+
+	hold, err := t.service.VoidHold(c.Request().Context(), req.HoldID, t.AuditContext(c, apiActor))
+	if err != nil {
+		if err == model.ErrHoldNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Hold not found"}}})
+		}
+		if err == model.ErrHoldExpired {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeHoldExpired, Message: "Hold has expired"}}})
+		}
+		if err == model.ErrHoldNotActive {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeHoldNotActive, Message: "Hold has already been captured or voided"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: hold})
+}
+
+// canActOnHold reports whether the caller may act for the party of the hold
+// returned by party: the receiver captures a hold and the sender voids it.
+// A hold that does not exist is left to the handler to report; a hold that
+// cannot be loaded is refused.
+func (t *walletHandler) canActOnHold(c echo.Context, id string, party func(*model.Hold) string) bool {
+	hold, err := t.service.GetHold(c.Request().Context(), id)
+	if err == model.ErrHoldNotFound {
+		return true
+	}
+	if err != nil {
+		utils.LogErrorContext(c.Request().Context(), "Failed to load hold for authorization", err)
+		return false
+	}
+	return canActFor(c, party(hold))
+}
+
+func holdSender(hold *model.Hold) string   { return hold.FromUserID }
+func holdReceiver(hold *model.Hold) string { return hold.ToUserID }
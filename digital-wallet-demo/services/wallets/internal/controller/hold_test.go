@@ -0,0 +1,378 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWalletHandler_CreateHold(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	service := newTestWalletService(t, dbInstance)
+	handler := NewWalletController(service)
+
+	tests := []struct {
+		name        string
+		requestBody string
+		held        int64 // Amount already held from test-user-001
+		expiredHeld int64 // Amount held from test-user-001 by a hold past its expiry
+		want        want
+	}{
+		{
+			name:        "successful_hold",
+			requestBody: `{"from_user_id":"test-user-001","to_user_id":"test-user-002","amount":4000}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "currency":"USD", "amount":4000, "captured_amount":0, "status":"active"}}`),
+			},
+		},
+		{
+			name:        "exceeds_available_balance",
+			requestBody: `{"from_user_id":"test-user-001","to_user_id":"test-user-002","amount":4000}`,
+			held:        7000,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+				Response:   []byte(`{"errors":[{"code":"BAD_REQUEST", "message":"Insufficient available balance"}]}`),
+			},
+		},
+		{
+			name:        "expired_hold_not_counted",
+			requestBody: `{"from_user_id":"test-user-001","to_user_id":"test-user-002","amount":4000}`,
+			expiredHeld: 7000,
+			want: want{
+				StatusCode: http.StatusCreated,
+			},
+		},
+		{
+			name:        "same_wallet",
+			requestBody: `{"from_user_id":"test-user-001","to_user_id":"test-user-001","amount":4000}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "receiver_not_found",
+			requestBody: `{"from_user_id":"test-user-001","to_user_id":"non-existent-user","amount":4000}`,
+			want: want{
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name:        "invalid_amount",
+			requestBody: `{"from_user_id":"test-user-001","to_user_id":"test-user-002","amount":0}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean database before each test
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.Hold{})
+
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			if tt.held != 0 {
+				createTestHold(t, dbInstance, tt.held, model.HoldActive, time.Now().Add(time.Hour))
+			}
+			if tt.expiredHeld != 0 {
+				createTestHold(t, dbInstance, tt.expiredHeld, model.HoldActive, time.Now().Add(-time.Minute))
+			}
+
+			// Prepare
+			requestID := uuid.NewString()
+			req := httptest.NewRequest(http.MethodPost, "/wallets/holds", bytes.NewReader([]byte(tt.requestBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderXRequestID, requestID)
+			rec := httptest.NewRecorder()
+			c := inDevMode(e.NewContext(req, rec))
+
+			// Execute
+			require.NoError(t, handler.CreateHold(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			// A hold moves no funds and records nothing in the ledger
+			assertOutboxEntries(t, dbInstance, false)
+			var sender model.Wallet
+			require.NoError(t, dbInstance.Where("user_id = ?", "test-user-001").First(&sender).Error)
+			assert.Equal(t, int64(10000), sender.Balance)
+
+			// A placed hold is recorded in the audit log
+			assertHoldAudit(t, dbInstance, requestID, model.AuditHoldPlaced, tt.want.StatusCode == http.StatusCreated)
+
+			if tt.want.Response == nil {
+				return
+			}
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"id": 1, "expires_at": 1, "created_at": 1, "updated_at": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestWalletHandler_CaptureHold(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	service := newTestWalletService(t, dbInstance)
+	handler := NewWalletController(service)
+
+	tests := []struct {
+		name         string
+		body         string
+		holdStatus   model.HoldStatus
+		expiresIn    time.Duration
+		senderStatus model.Status
+		want         want
+		wantSender   int64 // Balance of test-user-001 after the request
+		wantStatus   model.HoldStatus
+	}{
+		{
+			name:       "full_capture",
+			holdStatus: model.HoldActive,
+			expiresIn:  time.Hour,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"test-user-002", "transaction_type":"transfer", "operation_type":"debit", "amount":4000, "currency":"USD", "status":"completed"}}`),
+			},
+			wantSender: 6000,
+			wantStatus: model.HoldCaptured,
+		},
+		{
+			name:       "partial_capture",
+			body:       `{"amount":1500}`,
+			holdStatus: model.HoldActive,
+			expiresIn:  time.Hour,
+			want: want{
+				StatusCode: http.StatusCreated,
+			},
+			wantSender: 8500,
+			wantStatus: model.HoldCaptured,
+		},
+		{
+			name:       "amount_exceeds_hold",
+			body:       `{"amount":4001}`,
+			holdStatus: model.HoldActive,
+			expiresIn:  time.Hour,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+				Response:   []byte(`{"errors":[{"code":"CAPTURE_AMOUNT_EXCEEDED", "message":"Amount exceeds the held amount"}]}`),
+			},
+			wantSender: 10000,
+			wantStatus: model.HoldActive,
+		},
+		{
+			name:       "expired_hold",
+			holdStatus: model.HoldActive,
+			expiresIn:  -time.Minute,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+				Response:   []byte(`{"errors":[{"code":"HOLD_EXPIRED", "message":"Hold has expired"}]}`),
+			},
+			wantSender: 10000,
+			wantStatus: model.HoldActive,
+		},
+		{
+			name:       "already_voided",
+			holdStatus: model.HoldVoided,
+			expiresIn:  time.Hour,
+			want: want{
+				StatusCode: http.StatusConflict,
+				Response:   []byte(`{"errors":[{"code":"HOLD_NOT_ACTIVE", "message":"Hold has already been captured or voided"}]}`),
+			},
+			wantSender: 10000,
+			wantStatus: model.HoldVoided,
+		},
+		{
+			name:         "sender_suspended",
+			holdStatus:   model.HoldActive,
+			expiresIn:    time.Hour,
+			senderStatus: model.Suspended,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+			},
+			wantSender: 10000,
+			wantStatus: model.HoldActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Clean database before each test
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.Hold{})
+
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			if tt.senderStatus != "" {
+				setTestWalletStatus(t, dbInstance, "test-user-001", tt.senderStatus)
+			}
+			holdID := createTestHold(t, dbInstance, 4000, tt.holdStatus, time.Now().Add(tt.expiresIn))
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPost, "/wallets/holds/"+holdID+"/capture", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := inDevMode(e.NewContext(req, rec))
+			c.SetPath("/wallets/holds/:id/capture")
+			c.SetParamNames("id")
+			c.SetParamValues(holdID)
+
+			// Execute
+			require.NoError(t, handler.CaptureHold(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+			assertOutboxEntries(t, dbInstance, tt.want.StatusCode == http.StatusCreated)
+
+			var sender model.Wallet
+			require.NoError(t, dbInstance.Where("user_id = ?", "test-user-001").First(&sender).Error)
+			assert.Equal(t, tt.wantSender, sender.Balance)
+
+			var hold model.Hold
+			require.NoError(t, dbInstance.Where("id = ?", holdID).First(&hold).Error)
+			assert.Equal(t, tt.wantStatus, hold.Status)
+
+			if tt.want.Response == nil {
+				return
+			}
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1, "id": 1, "pair_id": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestWalletHandler_VoidHold(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	service := newTestWalletService(t, dbInstance)
+	handler := NewWalletController(service)
+
+	tests := []struct {
+		name           string
+		holdStatus     model.HoldStatus
+		holdID         string // Defaults to the ID of the created hold
+		wantStatusCode int
+		wantStatus     model.HoldStatus
+	}{
+		{"successful_void", model.HoldActive, "", http.StatusOK, model.HoldVoided},
+		{"already_captured", model.HoldCaptured, "", http.StatusConflict, model.HoldCaptured},
+		{"already_expired", model.HoldExpired, "", http.StatusUnprocessableEntity, model.HoldExpired},
+		{"hold_not_found", model.HoldActive, "non-existent-hold", http.StatusNotFound, model.HoldActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean database before each test
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.Hold{})
+
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			holdID := createTestHold(t, dbInstance, 4000, tt.holdStatus, time.Now().Add(time.Hour))
+			target := holdID
+			if tt.holdID != "" {
+				target = tt.holdID
+			}
+
+			// Prepare
+			requestID := uuid.NewString()
+			req := httptest.NewRequest(http.MethodPost, "/wallets/holds/"+target+"/void", nil)
+			req.Header.Set(echo.HeaderXRequestID, requestID)
+			rec := httptest.NewRecorder()
+			c := inDevMode(e.NewContext(req, rec))
+			c.SetPath("/wallets/holds/:id/void")
+			c.SetParamNames("id")
+			c.SetParamValues(target)
+
+			// Execute
+			require.NoError(t, handler.VoidHold(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatusCode, rec.Code)
+
+			var hold model.Hold
+			require.NoError(t, dbInstance.Where("id = ?", holdID).First(&hold).Error)
+			assert.Equal(t, tt.wantStatus, hold.Status)
+
+			// A voided hold is recorded in the audit log
+			assertHoldAudit(t, dbInstance, requestID, model.AuditHoldVoided, tt.wantStatusCode == http.StatusOK)
+		})
+	}
+}
+
+// assertHoldAudit checks that the request recorded one audit entry of action
+// on a hold from test-user-001 if wantEntry is set, and none otherwise.
+func assertHoldAudit(t *testing.T, db *gorm.DB, requestID string, action model.AuditAction, wantEntry bool) {
+	t.Helper()
+	var entries []model.AuditEntry
+	require.NoError(t, db.Where("request_id = ?", requestID).Find(&entries).Error)
+	if !wantEntry {
+		assert.Empty(t, entries)
+		return
+	}
+	require.Len(t, entries, 1)
+	assert.Equal(t, action, entries[0].Action)
+	assert.Equal(t, "test-user-001", entries[0].UserID)
+	assert.Equal(t, model.DefaultCurrency, entries[0].Currency)
+}
+
+// createTestHold places a hold of amount from test-user-001 to test-user-002 and returns its ID.
+func createTestHold(t *testing.T, db *gorm.DB, amount int64, status model.HoldStatus, expiresAt time.Time) string {
+	hold, err := model.NewHold("test-user-001", "test-user-002", "USD", amount, time.Hour)
+	require.NoError(t, err)
+	hold.Status = status
+	hold.ExpiresAt = expiresAt
+	require.NoError(t, db.Create(hold).Error)
+	return hold.ID
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// HeaderIdempotencyKey is the request header carrying the client supplied idempotency key.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is set on responses replayed from a stored idempotency record.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency returns a middleware that makes a route safe to retry.
+//
+// The first request carrying an Idempotency-Key header is executed and its
+// response stored. A retry with the same key and the same body receives the
+// stored response without executing the handler again; a retry with the same
+// key but a different body is rejected with 409. Requests without the header
+// are passed through unchanged. Server errors are not stored so that they can
+// be retried.
+//
+// Keys are scoped to the authenticated caller, so that callers reusing each
+// other's keys never see each other's responses; the middleware must run
+// after Authenticate.
+func Idempotency(repo repository.Idempotency) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return c.JSON(http.StatusBadRequest,
+					ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Idempotency-Key is too long"}}})
+			}
+
+			key = scopedIdempotencyKey(c, key)
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest,
+					ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+			hash := idempotencyRequestHash(c.Request().Method, c.Path(), body)
+
+			reserved, err := repo.Reserve(c.Request().Context(), &model.IdempotencyRecord{
+				Key:         key,
+				RequestHash: hash,
+				Status:      model.IdempotencyInProgress,
+			})
+			if err != nil {
+				utils.LogErrorContext(c.Request().Context(), "Failed to reserve idempotency key", err)
+				return c.JSON(http.StatusInternalServerError,
+					ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+			}
+			if !reserved {
+				return replayIdempotentResponse(c, repo, key, hash)
+			}
+
+			recorder := &bodyRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			// The outcome is stored even if the client went away, so that the key
+			// is not left in progress after the handler committed its work
+			storeCtx := context.WithoutCancel(c.Request().Context())
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				if err := repo.Release(storeCtx, key); err != nil {
+					utils.LogErrorContext(storeCtx, "Failed to release idempotency key", err)
+				}
+				return nil
+			}
+			if err := repo.Complete(storeCtx, key, status, recorder.body.String()); err != nil {
+				utils.LogErrorContext(storeCtx, "Failed to store idempotent response", err)
+			}
+			return nil
+		}
+	}
+}
+
+// scopedIdempotencyKey prefixes key with the subject of the authenticated
+// caller. Unauthenticated requests of development mode share one scope.
+func scopedIdempotencyKey(c echo.Context, key string) string {
+	if identity := callerIdentity(c); identity != nil {
+		return identity.UserID + ":" + key
+	}
+	return key
+}
+
+// replayIdempotentResponse answers a request whose key has already been used.
+func replayIdempotentResponse(c echo.Context, repo repository.Idempotency, key, hash string) error {
+	record, err := repo.FindByKey(c.Request().Context(), key)
+	if err != nil {
+		if err == model.ErrNotFound {
+			// The original request failed and released the key in the meantime
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeRequestInProgress, Message: "A request with this Idempotency-Key is being processed, retry later"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	if record.RequestHash != hash {
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeIdempotencyKeyReused, Message: "Idempotency-Key was already used with a different request"}}})
+	}
+	if record.Status != model.IdempotencyCompleted {
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeRequestInProgress, Message: "A request with this Idempotency-Key is being processed, retry later"}}})
+	}
+
+	c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+	return c.Blob(record.StatusCode, echo.MIMEApplicationJSONCharsetUTF8, []byte(record.ResponseBody))
+}
+
+// idempotencyRequestHash fingerprints a request by route and body.
+// JSON bodies are canonicalized so that formatting and key order do not matter.
+func idempotencyRequestHash(method, path string, body []byte) string {
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err == nil {
+		if canonical, err := json.Marshal(decoded); err == nil {
+			body = canonical
+		}
+	}
+
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bodyRecorder copies the response body while it is written to the client.
+type bodyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/auth"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRequestHash(t *testing.T) {
+	base := idempotencyRequestHash(http.MethodPost, "/api/v1/wallets/deposit", []byte(`{"userId":"u1","amount":100}`))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		same   bool
+	}{
+		{"Whitespace_and_key_order_ignored", http.MethodPost, "/api/v1/wallets/deposit", "{ \"amount\": 100,\n \"userId\": \"u1\" }", true},
+		{"Different_body", http.MethodPost, "/api/v1/wallets/deposit", `{"userId":"u1","amount":200}`, false},
+		{"Different_route", http.MethodPost, "/api/v1/wallets/withdraw", `{"userId":"u1","amount":100}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := idempotencyRequestHash(tt.method, tt.path, []byte(tt.body))
+			assert.Equal(t, tt.same, got == base)
+		})
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	dbInstance.Where("1 = 1").Delete(&model.IdempotencyRecord{})
+
+	calls := 0
+	e := echo.New()
+	// Stands in for Authenticate, the caller is named by the X-Test-User header
+	authenticate := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if user := c.Request().Header.Get("X-Test-User"); user != "" {
+				c.Set(identityKey, &auth.Identity{UserID: user})
+			}
+			return next(c)
+		}
+	}
+	e.POST("/deposit", func(c echo.Context) error {
+		calls++
+		if strings.Contains(c.Request().Header.Get("X-Test"), "fail") {
+			return c.JSON(http.StatusInternalServerError, ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError}}})
+		}
+		return c.JSON(http.StatusOK, ResponseData{Data: calls})
+	}, authenticate, Idempotency(repository.NewIdempotencyRepo(dbInstance)))
+
+	doAs := func(user, key, body, testHeader string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/deposit", strings.NewReader(body))
+		req.Header.Set("X-Test-User", user)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		req.Header.Set("X-Test", testHeader)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	do := func(key, body, testHeader string) *httptest.ResponseRecorder {
+		return doAs("", key, body, testHeader)
+	}
+
+	t.Run("Without_key_every_request_is_executed", func(t *testing.T) {
+		calls = 0
+		do("", `{"amount":100}`, "")
+		do("", `{"amount":100}`, "")
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Retry_with_same_key_and_body_is_replayed", func(t *testing.T) {
+		calls = 0
+		first := do("key-replay", `{"amount":100}`, "")
+		second := do("key-replay", `{"amount": 100}`, "")
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.JSONEq(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "true", second.Header().Get(HeaderIdempotentReplayed))
+		assert.Empty(t, first.Header().Get(HeaderIdempotentReplayed))
+	})
+
+	t.Run("Same_key_with_different_body_is_rejected", func(t *testing.T) {
+		calls = 0
+		do("key-reused", `{"amount":100}`, "")
+		rec := do("key-reused", `{"amount":200}`, "")
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), errors.CodeIdempotencyKeyReused)
+	})
+
+	t.Run("Server_errors_are_not_stored", func(t *testing.T) {
+		calls = 0
+		first := do("key-failed", `{"amount":100}`, "fail")
+		second := do("key-failed", `{"amount":100}`, "")
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusInternalServerError, first.Code)
+		assert.Equal(t, http.StatusOK, second.Code)
+	})
+	t.Run("Same_key_of_different_callers_is_not_shared", func(t *testing.T) {
+		calls = 0
+		first := doAs("test-user-001", "key-shared", `{"amount":100}`, "")
+		second := doAs("test-user-002", "key-shared", `{"amount":100}`, "")
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Empty(t, second.Header().Get(HeaderIdempotentReplayed))
+		assert.NotEqual(t, first.Body.String(), second.Body.String())
+
+		retry := doAs("test-user-001", "key-shared", `{"amount":100}`, "")
+		assert.Equal(t, 2, calls)
+		assert.JSONEq(t, first.Body.String(), retry.Body.String())
+	})
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// LimitHandler is the request handler for the spending limit admin endpoints.
+type LimitHandler interface {
+	List(c echo.Context) error
+	SetForWallet(c echo.Context) error
+	DeleteForWallet(c echo.Context) error
+	SetForType(c echo.Context) error
+	DeleteForType(c echo.Context) error
+}
+
+type limitHandler struct {
+	Handler
+	service service.Limit
+}
+
+// NewLimitController returns a new instance of the spending limit handler.
+func NewLimitController(s service.Limit) LimitHandler {
+	return &limitHandler{service: s}
+}
+
+// LimitBounds are the bounds of a spending limit, amounts in minor units of the currency. Zero bounds are not enforced.
+type LimitBounds struct {
+	Currency       model.Currency `json:"currency,omitempty" validate:"omitempty,validCurrency"` // Defaults to the wallet's base currency, or USD for an account type
+	MaxTransaction int64          `json:"max_transaction" validate:"gte=0"`                      // Largest single withdrawal or transfer
+	DailyAmount    int64          `json:"daily_amount" validate:"gte=0"`                         // Outgoing total per UTC day
+	MonthlyAmount  int64          `json:"monthly_amount" validate:"gte=0"`                       // Outgoing total per UTC calendar month
+	DailyCount     int            `json:"daily_count" validate:"gte=0"`                          // Outgoing transactions per UTC day
+	MonthlyCount   int            `json:"monthly_count" validate:"gte=0"`                        // Outgoing transactions per UTC calendar month
+}
+
+// WalletLimitRequest represents the request for setting the spending limit of a wallet
+type WalletLimitRequest struct {
+	UserID string `param:"user_id" json:"-" validate:"required"`
+	LimitBounds
+}
+
+// TypeLimitRequest represents the request for setting the spending limit of an account type
+type TypeLimitRequest struct {
+	AcntType model.AcntType `param:"acnt_type" json:"-" validate:"required,validAcntType"`
+	LimitBounds
+}
+
+// WalletLimitIDRequest is the request parameter for removing the spending limit of a wallet
+type WalletLimitIDRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+}
+
+// TypeLimitIDRequest is the request parameter for removing the spending limit of an account type
+type TypeLimitIDRequest struct {
+	AcntType model.AcntType `param:"acnt_type" validate:"required,validAcntType"`
+}
+
+func (b LimitBounds) params(audit model.AuditContext) service.LimitParams {
+	return service.LimitParams{
+		Currency:       b.Currency,
+		MaxTransaction: b.MaxTransaction,
+		DailyAmount:    b.DailyAmount,
+		MonthlyAmount:  b.MonthlyAmount,
+		DailyCount:     b.DailyCount,
+		MonthlyCount:   b.MonthlyCount,
+		Audit:          audit,
+	}
+}
+
+// @Summary	List spending limits
+// @Tags		admin
+// @Produce	json
+// @Success	200	{object}	ResponseData{data=[]model.SpendingLimit}
+// @Failure	500	{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/limits [get]
+func (h *limitHandler) List(c echo.Context) error {
+	limits, err := h.service.List(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: limits})
+}
+
+// @Summary	Set the spending limit of a wallet
+// @Description	Bounds the withdrawals, transfers and hold captures of the wallet, in place of the limit of its account type. Outgoing amounts in other currencies are converted at the current rate. Transactions over a limit fail with LIMIT_EXCEEDED.
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string		true	"User ID"
+// @Param		request	body		LimitBounds	true	"Limit bounds"
+// @Success	200		{object}	ResponseData{data=model.SpendingLimit}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/limits/wallets/{user_id} [put]
+func (h *limitHandler) SetForWallet(c echo.Context) error {
+	var req WalletLimitRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	limit, err := h.service.SetForWallet(c.Request().Context(), req.UserID, req.params(h.AuditContext(c, adminActor)))
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: limit})
+}
+
+// @Summary	Remove the spending limit of a wallet
+// @Description	The limit of the wallet's account type applies again.
+// @Tags		admin
+// @Param		user_id	path	string	true	"User ID"
+// @Success	204
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/limits/wallets/{user_id} [delete]
+func (h *limitHandler) DeleteForWallet(c echo.Context) error {
+	var req WalletLimitIDRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	return h.deleted(c, h.service.DeleteForWallet(c.Request().Context(), req.UserID, h.AuditContext(c, adminActor)))
+}
+
+// @Summary	Set the spending limit of an account type
+// @Description	Bounds the withdrawals, transfers and hold captures of every wallet of the account type without a limit of its own.
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		acnt_type	path		string		true	"Account type"	Enums(user, provider)
+// @Param		request		body		LimitBounds	true	"Limit bounds"
+// @Success	200			{object}	ResponseData{data=model.SpendingLimit}
+// @Failure	400			{object}	ResponseError
+// @Failure	500			{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/limits/types/{acnt_type} [put]
+func (h *limitHandler) SetForType(c echo.Context) error {
+	var req TypeLimitRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	limit, err := h.service.SetForType(c.Request().Context(), req.AcntType, req.params(h.AuditContext(c, adminActor)))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: limit})
+}
+
+// @Summary	Remove the spending limit of an account type
+// @Tags		admin
+// @Param		acnt_type	path	string	true	"Account type"	Enums(user, provider)
+// @Success	204
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/limits/types/{acnt_type} [delete]
+func (h *limitHandler) DeleteForType(c echo.Context) error {
+	var req TypeLimitIDRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	return h.deleted(c, h.service.DeleteForType(c.Request().Context(), req.AcntType, h.AuditContext(c, adminActor)))
+}
+
+// deleted writes the response of a limit removal.
+func (h *limitHandler) deleted(c echo.Context, err error) error {
+	if err != nil {
+		if err == model.ErrLimitNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Spending limit not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
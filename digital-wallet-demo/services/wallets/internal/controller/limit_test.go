@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/google/go-cmp/cmp"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestLimitHandler_SetForWallet(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewLimitController(service.NewLimitService(repository.NewLimitRepo(dbInstance), repository.NewWalletRepo(dbInstance), repository.NewAuditRepo(dbInstance)))
+
+	tests := []struct {
+		name        string
+		userID      string
+		existing    bool // Whether the wallet already has a limit
+		requestBody string
+		want        want
+	}{
+		{
+			name:        "successful_set",
+			userID:      "test-user-001",
+			requestBody: `{"max_transaction":5000,"daily_amount":20000,"daily_count":10}`,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"user_id":"test-user-001", "currency":"USD", "max_transaction":5000, "daily_amount":20000, "monthly_amount":0, "daily_count":10, "monthly_count":0}}`),
+			},
+		},
+		{
+			name:        "replaces_existing_limit",
+			userID:      "test-user-001",
+			existing:    true,
+			requestBody: `{"currency":"EUR","monthly_amount":100000}`,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"user_id":"test-user-001", "currency":"EUR", "max_transaction":0, "daily_amount":0, "monthly_amount":100000, "daily_count":0, "monthly_count":0}}`),
+			},
+		},
+		{
+			name:        "negative_bound",
+			userID:      "test-user-001",
+			requestBody: `{"max_transaction":-1}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "unsupported_currency",
+			userID:      "test-user-001",
+			requestBody: `{"currency":"XXX","max_transaction":5000}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "wallet_not_found",
+			userID:      "non-existent-user",
+			requestBody: `{"max_transaction":5000}`,
+			want: want{
+				StatusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean database before each test
+			clearDB(dbInstance, model.SpendingLimit{}, model.WalletBalance{}, model.Wallet{})
+
+			createTestWallet(t, dbInstance, "test-user-001", model.User)
+			if tt.existing {
+				createTestLimit(t, dbInstance, &model.SpendingLimit{UserID: strPtr("test-user-001"), Currency: model.DefaultCurrency, MaxTransaction: 100})
+			}
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPut, "/admin/limits/wallets/"+tt.userID, bytes.NewReader([]byte(tt.requestBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/admin/limits/wallets/:user_id")
+			c.SetParamNames("user_id")
+			c.SetParamValues(tt.userID)
+
+			// Execute
+			require.NoError(t, handler.SetForWallet(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			if tt.want.Response == nil {
+				return
+			}
+			got := rec.Body.Bytes()
+
+			var count int64
+			require.NoError(t, dbInstance.Model(&model.SpendingLimit{}).Count(&count).Error)
+			assert.Equal(t, int64(1), count)
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"id": 1, "created_at": 1, "updated_at": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestWalletHandler_TransferLimits(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	service := newTestWalletService(t, dbInstance)
+	handler := NewWalletController(service)
+
+	userType := model.User
+	tests := []struct {
+		name           string
+		limits         []model.SpendingLimit
+		spent          int64 // Amount already sent today, in one earlier transfer
+		transferBody   string
+		wantStatusCode int
+	}{
+		{
+			name:           "no_limit",
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":9000}`,
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name:           "max_transaction_exceeded",
+			limits:         []model.SpendingLimit{{UserID: strPtr("test-user-001"), Currency: model.DefaultCurrency, MaxTransaction: 5000}},
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":5001}`,
+			wantStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "daily_amount_exceeded",
+			limits:         []model.SpendingLimit{{UserID: strPtr("test-user-001"), Currency: model.DefaultCurrency, DailyAmount: 5000}},
+			spent:          3000,
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":2001}`,
+			wantStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "daily_count_exceeded",
+			limits:         []model.SpendingLimit{{UserID: strPtr("test-user-001"), Currency: model.DefaultCurrency, DailyCount: 1}},
+			spent:          100,
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":100}`,
+			wantStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "account_type_limit",
+			limits:         []model.SpendingLimit{{AcntType: &userType, Currency: model.DefaultCurrency, MaxTransaction: 1000}},
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":2000}`,
+			wantStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "wallet_limit_replaces_account_type_limit",
+			limits: []model.SpendingLimit{
+				{AcntType: &userType, Currency: model.DefaultCurrency, MaxTransaction: 1000},
+				{UserID: strPtr("test-user-001"), Currency: model.DefaultCurrency, MaxTransaction: 5000},
+			},
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":2000}`,
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name:           "converted_to_limit_currency",
+			limits:         []model.SpendingLimit{{UserID: strPtr("test-user-001"), Currency: "JPY", MaxTransaction: 100}},
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":100}`,
+			wantStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Clean database before each test
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.SpendingLimit{}, model.SpendingUsage{})
+
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			for i := range tt.limits {
+				createTestLimit(t, dbInstance, &tt.limits[i])
+			}
+			if tt.spent != 0 {
+				require.NoError(t, dbInstance.Create(&model.SpendingUsage{UserID: "test-user-001", Currency: model.DefaultCurrency, Day: model.DayStart(time.Now()), Amount: tt.spent, Count: 1}).Error)
+			}
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPost, "/wallets/transfer", bytes.NewReader([]byte(tt.transferBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := inDevMode(e.NewContext(req, rec))
+			c.SetPath("/wallets/transfer")
+
+			// Execute
+			require.NoError(t, handler.Transfer(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatusCode, rec.Code)
+			assertOutboxEntries(t, dbInstance, tt.wantStatusCode == http.StatusCreated)
+			if tt.wantStatusCode == http.StatusUnprocessableEntity {
+				assert.Contains(t, rec.Body.String(), `"LIMIT_EXCEEDED"`)
+			}
+
+			// Only transfers that went through count towards the limits
+			var usage model.SpendingUsage
+			err := dbInstance.Where("user_id = ?", "test-user-001").Take(&usage).Error
+			if tt.wantStatusCode == http.StatusCreated {
+				require.NoError(t, err)
+				assert.Equal(t, 1, usage.Count)
+			} else if tt.spent == 0 {
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			}
+		})
+	}
+}
+
+func createTestLimit(t *testing.T, db *gorm.DB, limit *model.SpendingLimit) {
+	require.NoError(t, db.Create(limit).Error)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
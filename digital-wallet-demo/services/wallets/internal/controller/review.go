@@ -0,0 +1,291 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// ReviewHandler is the request handler for the review queue admin endpoints.
+type ReviewHandler interface {
+	List(c echo.Context) error
+	Get(c echo.Context) error
+	Approve(c echo.Context) error
+	Reject(c echo.Context) error
+	ListFlags(c echo.Context) error
+	Flag(c echo.Context) error
+	Unflag(c echo.Context) error
+}
+
+type reviewHandler struct {
+	Handler
+	service service.Review
+}
+
+// NewReviewController returns a new instance of the review queue handler.
+func NewReviewController(s service.Review) ReviewHandler {
+	return &reviewHandler{service: s}
+}
+
+// ListReviewsRequest represents the filters of the review queue
+type ListReviewsRequest struct {
+	Status          model.ReviewStatus    `query:"status" validate:"omitempty,oneof=pending approved rejected"`
+	Reason          model.ReviewReason    `query:"reason" validate:"omitempty,oneof=risk flagged"`
+	TransactionType model.TransactionType `query:"transaction_type" validate:"omitempty,oneof=withdraw transfer"`
+	UserID          string                `query:"user_id"`
+	CreatedFrom     *time.Time            `query:"created_from"`
+	CreatedTo       *time.Time            `query:"created_to"`
+}
+
+// ReviewIDRequest is the request parameter for finding a review
+type ReviewIDRequest struct {
+	ID string `param:"id" validate:"required"`
+}
+
+// DecideReviewRequest represents the request for approving or rejecting a review
+type DecideReviewRequest struct {
+	ID   string `param:"id" json:"-" validate:"required"`
+	Note string `json:"note,omitempty" validate:"max=255"`
+}
+
+// FlagRequest represents the request for flagging a wallet for review
+type FlagRequest struct {
+	UserID string `param:"user_id" json:"-" validate:"required"`
+	Reason string `json:"reason,omitempty" validate:"max=255"`
+}
+
+// FlagIDRequest is the request parameter for removing the review flag of a wallet
+type FlagIDRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+}
+
+// @Summary	List the review queue
+// @Description	Returns the transfers and withdrawals queued for review, oldest first.
+// @Tags		admin
+// @Produce	json
+// @Param		status				query		string	false	"Filter by review status"		Enums(pending, approved, rejected)
+// @Param		reason				query		string	false	"Filter by reason for review"	Enums(risk, flagged)
+// @Param		transaction_type	query		string	false	"Filter by transaction type"	Enums(withdraw, transfer)
+// @Param		user_id				query		string	false	"Filter by sender or receiver"
+// @Param		created_from		query		string	false	"Queued at or after (RFC 3339)"
+// @Param		created_to			query		string	false	"Queued before (RFC 3339)"
+// @Success	200					{object}	ResponseData{data=[]model.Review}
+// @Failure	400					{object}	ResponseError
+// @Failure	500					{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/reviews [get]
+func (h *reviewHandler) List(c echo.Context) error {
+	var req ListReviewsRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	reviews, err := h.service.List(c.Request().Context(), model.ReviewQuery{
+		Status:          req.Status,
+		Reason:          req.Reason,
+		TransactionType: req.TransactionType,
+		UserID:          req.UserID,
+		CreatedFrom:     req.CreatedFrom,
+		CreatedTo:       req.CreatedTo,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: reviews})
+}
+
+// @Summary	Get a review
+// @Tags		admin
+// @Produce	json
+// @Param		id	path		string	true	"Review ID"
+// @Success	200	{object}	ResponseData{data=model.Review}
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/reviews/{id} [get]
+func (h *reviewHandler) Get(c echo.Context) error {
+	var req ReviewIDRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	review, err := h.service.Get(c.Request().Context(), req.ID)
+	if err != nil {
+		if err == model.ErrReviewNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Review not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: review})
+}
+
+// @Summary	Approve a review
+// @Description	Moves the reserved funds to the receiver and completes the pending transaction pair in the ledger.
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		id		path		string				true	"Review ID"
+// @Param		request	body		DecideReviewRequest	false	"Decision note"
+// @Success	200		{object}	ResponseData{data=model.Review}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/reviews/{id}/approve [post]
+func (h *reviewHandler) Approve(c echo.Context) error {
+	var req DecideReviewRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	review, err := h.service.Approve(c.Request().Context(), req.ID, req.Note, h.AuditContext(c, adminActor))
+	return h.decided(c, review, err)
+}
+
+// @Summary	Reject a review
+// @Description	Releases the reserved funds and cancels the pending transaction pair in the ledger.
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		id		path		string				true	"Review ID"
+// @Param		request	body		DecideReviewRequest	false	"Decision note"
+// @Success	200		{object}	ResponseData{data=model.Review}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/reviews/{id}/reject [post]
+func (h *reviewHandler) Reject(c echo.Context) error {
+	var req DecideReviewRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	review, err := h.service.Reject(c.Request().Context(), req.ID, req.Note, h.AuditContext(c, adminActor))
+	return h.decided(c, review, err)
+}
+
+// decided writes the response of a review decision.
+func (h *reviewHandler) decided(c echo.Context, review *model.Review, err error) error {
+	if err != nil {
+		if err == model.ErrReviewNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Review not found"}}})
+		}
+		if err == model.ErrReviewDecided {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeReviewDecided, Message: "Review has already been approved or rejected"}}})
+		}
+		if code, ok := walletStatusErrors[err]; ok {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: code, Message: err.Error()}}})
+		}
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+		}
+		if err == model.ErrInsufficientFunds {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: review})
+}
+
+// @Summary	List wallets flagged for review
+// @Tags		admin
+// @Produce	json
+// @Success	200	{object}	ResponseData{data=[]model.ReviewFlag}
+// @Failure	500	{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/review-flags [get]
+func (h *reviewHandler) ListFlags(c echo.Context) error {
+	flags, err := h.service.ListFlags(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: flags})
+}
+
+// @Summary	Flag a wallet for review
+// @Description	Queues every later withdrawal and transfer of the wallet for review, whatever the risk rules decide.
+// @Tags		admin
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string		true	"User ID"
+// @Param		request	body		FlagRequest	false	"Flag reason"
+// @Success	200		{object}	ResponseData{data=model.ReviewFlag}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/review-flags/{user_id} [put]
+func (h *reviewHandler) Flag(c echo.Context) error {
+	var req FlagRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	flag, err := h.service.Flag(c.Request().Context(), req.UserID, req.Reason, h.AuditContext(c, adminActor))
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: flag})
+}
+
+// @Summary	Remove the review flag of a wallet
+// @Tags		admin
+// @Param		user_id	path	string	true	"User ID"
+// @Success	204
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/admin/review-flags/{user_id} [delete]
+func (h *reviewHandler) Unflag(c echo.Context) error {
+	var req FlagIDRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if err := h.service.Unflag(c.Request().Context(), req.UserID, h.AuditContext(c, adminActor)); err != nil {
+		if err == model.ErrReviewFlagNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Review flag not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
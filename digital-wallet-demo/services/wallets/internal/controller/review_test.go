@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestReviewHandler_Decide(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewReviewController(newTestReviewService(dbInstance))
+
+	tests := []struct {
+		name            string
+		approve         bool
+		status          model.ReviewStatus // Status of the existing review
+		reviewID        string             // Defaults to the existing review
+		wantStatusCode  int
+		wantReview      model.ReviewStatus
+		wantPairStatus  model.TransactionStatus
+		wantFromBalance int64
+		wantToBalance   int64
+		wantSpent       int64 // Spending usage of the sender, which counted the review when it was queued
+	}{
+		{
+			name:            "approve_moves_funds",
+			approve:         true,
+			status:          model.ReviewPending,
+			wantStatusCode:  http.StatusOK,
+			wantReview:      model.ReviewApproved,
+			wantPairStatus:  model.Completed,
+			wantFromBalance: 4000,
+			wantToBalance:   6000,
+			wantSpent:       6000,
+		},
+		{
+			name:            "reject_releases_funds",
+			status:          model.ReviewPending,
+			wantStatusCode:  http.StatusOK,
+			wantReview:      model.ReviewRejected,
+			wantPairStatus:  model.Cancelled,
+			wantFromBalance: 10000,
+		},
+		{
+			name:            "already_decided",
+			approve:         true,
+			status:          model.ReviewRejected,
+			wantStatusCode:  http.StatusConflict,
+			wantReview:      model.ReviewRejected,
+			wantFromBalance: 10000,
+			wantSpent:       6000,
+		},
+		{
+			name:            "review_not_found",
+			approve:         true,
+			status:          model.ReviewPending,
+			reviewID:        "non-existent-review",
+			wantStatusCode:  http.StatusNotFound,
+			wantReview:      model.ReviewPending,
+			wantFromBalance: 10000,
+			wantSpent:       6000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				clearDB(dbInstance, model.Review{})
+			}()
+
+			// Clean database before each test
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.Review{}, model.SpendingUsage{})
+
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			review := createTestReview(t, dbInstance, "test-user-001", "test-user-002", 6000, tt.status)
+			pairEntry, err := model.NewTransactionPairEntry(review.Transactions(model.Pending))
+			require.NoError(t, err)
+			pairEntry.Status = model.OutboxDelivered
+			require.NoError(t, dbInstance.Create(pairEntry).Error)
+			require.NoError(t, dbInstance.Create(&model.SpendingUsage{UserID: "test-user-001", Currency: model.DefaultCurrency, Day: model.DayStart(review.CreatedAt), Amount: 6000, Count: 1}).Error)
+			reviewID := tt.reviewID
+			if reviewID == "" {
+				reviewID = review.ID
+			}
+
+			// Prepare
+			action, decide := "reject", handler.Reject
+			if tt.approve {
+				action, decide = "approve", handler.Approve
+			}
+			req := httptest.NewRequest(http.MethodPost, "/admin/reviews/"+reviewID+"/"+action, bytes.NewReader([]byte(`{"note":"checked"}`)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/admin/reviews/:id/" + action)
+			c.SetParamNames("id")
+			c.SetParamValues(reviewID)
+
+			// Execute
+			require.NoError(t, decide(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatusCode, rec.Code)
+
+			var got model.Review
+			require.NoError(t, dbInstance.Where("id = ?", review.ID).Take(&got).Error)
+			assert.Equal(t, tt.wantReview, got.Status)
+
+			// A decision records the new status of the pair for the ledger, delivered after the pair
+			var entries []model.OutboxEntry
+			require.NoError(t, dbInstance.Where("event_type = ?", model.OutboxPairStatus).Find(&entries).Error)
+			if tt.wantPairStatus == "" {
+				assert.Empty(t, entries)
+			} else {
+				require.Len(t, entries, 1)
+				assert.Equal(t, model.OutboxPairStatus, entries[0].EventType)
+				status, err := entries[0].PairStatus()
+				require.NoError(t, err)
+				assert.Equal(t, review.ID, status.ReviewID)
+				assert.Equal(t, tt.wantPairStatus, status.Status)
+				assert.Equal(t, &pairEntry.ID, entries[0].DependsOn)
+			}
+
+			for userID, want := range map[string]int64{"test-user-001": tt.wantFromBalance, "test-user-002": tt.wantToBalance} {
+				var wallet model.Wallet
+				require.NoError(t, dbInstance.Where("user_id = ?", userID).Take(&wallet).Error)
+				assert.Equal(t, want, wallet.Balance, userID)
+			}
+
+			// A rejected transaction no longer counts against the sender's limit
+			var usage model.SpendingUsage
+			require.NoError(t, dbInstance.Where("user_id = ?", "test-user-001").Take(&usage).Error)
+			assert.Equal(t, tt.wantSpent, usage.Amount)
+		})
+	}
+}
+
+func TestWalletHandler_TransferFlagged(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	service := newTestWalletService(t, dbInstance)
+	handler := NewWalletController(service)
+
+	tests := []struct {
+		name           string
+		flagged        bool
+		reviewing      int64 // Amount of an earlier transfer waiting for review
+		transferBody   string
+		wantStatusCode int
+		wantReviews    int
+	}{
+		{
+			name:           "not_flagged",
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":1000}`,
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name:           "flagged",
+			flagged:        true,
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":1000}`,
+			wantStatusCode: http.StatusAccepted,
+			wantReviews:    1,
+		},
+		{
+			name:           "funds_reserved_by_review",
+			reviewing:      9500,
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":1000}`,
+			wantStatusCode: http.StatusUnprocessableEntity,
+			wantReviews:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				clearDB(dbInstance, model.Review{}, model.ReviewFlag{})
+			}()
+
+			// Clean database before each test
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.Review{}, model.ReviewFlag{})
+
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			if tt.flagged {
+				require.NoError(t, dbInstance.Create(&model.ReviewFlag{UserID: "test-user-001"}).Error)
+			}
+			if tt.reviewing != 0 {
+				createTestReview(t, dbInstance, "test-user-001", "test-user-002", tt.reviewing, model.ReviewPending)
+			}
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPost, "/wallets/transfer", bytes.NewReader([]byte(tt.transferBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := inDevMode(e.NewContext(req, rec))
+			c.SetPath("/wallets/transfer")
+
+			// Execute
+			require.NoError(t, handler.Transfer(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatusCode, rec.Code)
+			assertOutboxEntries(t, dbInstance, tt.wantStatusCode != http.StatusUnprocessableEntity)
+
+			var reviews []model.Review
+			require.NoError(t, dbInstance.Find(&reviews).Error)
+			assert.Len(t, reviews, tt.wantReviews)
+			if tt.flagged {
+				assert.Equal(t, model.ReviewFlagged, reviews[0].Reason)
+				assert.Contains(t, rec.Body.String(), `"review_id":"`+reviews[0].ID+`"`)
+			}
+		})
+	}
+}
+
+func createTestReview(t *testing.T, db *gorm.DB, fromUserID, toUserID string, amount int64, status model.ReviewStatus) *model.Review {
+	debitTxn := &model.Transaction{SubjectWalletID: fromUserID, ObjectWalletID: toUserID, TransactionType: model.Transfer, OperationType: model.Debit, Amount: amount, Currency: model.DefaultCurrency, Status: model.Pending}
+	creditTxn := &model.Transaction{SubjectWalletID: toUserID, ObjectWalletID: fromUserID, TransactionType: model.Transfer, OperationType: model.Credit, Amount: amount, Currency: model.DefaultCurrency, Status: model.Pending}
+	review, err := model.NewReview(debitTxn, creditTxn, model.ReviewRisk, "large-amount")
+	require.NoError(t, err)
+	review.Status = status
+	require.NoError(t, db.Create(review).Error)
+	return review
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/fx"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/risk"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWalletHandler_TransferRisk(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+
+	rules := model.Risk{
+		Enable: true,
+		Rules: []model.RiskRule{
+			{Name: "trusted-amount", Type: model.AmountRule, Action: model.RiskApprove, TransactionTypes: []model.TransactionType{model.Transfer}, Amount: 9000},
+			{Name: "large-amount", Type: model.AmountRule, Action: model.RiskReview, Amount: 5000},
+			{Name: "fan-out", Type: model.RecipientsRule, Action: model.RiskReject, Period: time.Hour, MaxRecipients: 1},
+			{Name: "round-trip", Type: model.RoundTripRule, Action: model.RiskReview, Period: time.Hour},
+		},
+	}
+	service := newTestWalletService(t, dbInstance, func(opts *service.WalletServiceOpts) {
+		opts.RiskEngine = newTestRiskEngine(t, dbInstance, rules)
+	})
+	handler := NewWalletController(service)
+
+	tests := []struct {
+		name           string
+		earlier        []model.RiskDecision // Transfers decided before
+		transferBody   string
+		wantStatusCode int
+		wantAction     model.RiskAction
+		wantRule       string
+		wantBalance    int64
+	}{
+		{
+			name:           "approved_without_match",
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":1000}`,
+			wantStatusCode: http.StatusCreated,
+			wantAction:     model.RiskApprove,
+			wantBalance:    9000,
+		},
+		{
+			name:           "approved_by_earlier_rule",
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":9500}`,
+			wantStatusCode: http.StatusCreated,
+			wantAction:     model.RiskApprove,
+			wantRule:       "trusted-amount",
+			wantBalance:    500,
+		},
+		{
+			name:           "held_for_review",
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":6000}`,
+			wantStatusCode: http.StatusAccepted,
+			wantAction:     model.RiskReview,
+			wantRule:       "large-amount",
+			wantBalance:    10000,
+		},
+		{
+			name:           "too_many_recipients",
+			earlier:        []model.RiskDecision{{TransactionType: model.Transfer, FromUserID: "test-user-001", ToUserID: "test-user-003", Amount: 100, Currency: model.DefaultCurrency, Action: model.RiskApprove}},
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":1000}`,
+			wantStatusCode: http.StatusUnprocessableEntity,
+			wantAction:     model.RiskReject,
+			wantRule:       "fan-out",
+			wantBalance:    10000,
+		},
+		{
+			name:           "round_trip",
+			earlier:        []model.RiskDecision{{TransactionType: model.Transfer, FromUserID: "test-user-002", ToUserID: "test-user-001", Amount: 100, Currency: model.DefaultCurrency, Action: model.RiskApprove}},
+			transferBody:   `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":1000}`,
+			wantStatusCode: http.StatusAccepted,
+			wantAction:     model.RiskReview,
+			wantRule:       "round-trip",
+			wantBalance:    10000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Clean database before each test
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.RiskDecision{}, model.Review{})
+
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			for i := range tt.earlier {
+				tt.earlier[i].CreatedAt = time.Now().Add(-time.Minute)
+				require.NoError(t, dbInstance.Create(&tt.earlier[i]).Error)
+			}
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPost, "/wallets/transfer", bytes.NewReader([]byte(tt.transferBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := inDevMode(e.NewContext(req, rec))
+			c.SetPath("/wallets/transfer")
+
+			// Execute
+			require.NoError(t, handler.Transfer(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatusCode, rec.Code)
+			assertOutboxEntries(t, dbInstance, tt.wantStatusCode != http.StatusUnprocessableEntity)
+			if tt.wantStatusCode == http.StatusAccepted {
+				assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+			}
+
+			// Every decision is recorded with its rule, rejections included
+			var decision model.RiskDecision
+			require.NoError(t, dbInstance.Where("from_user_id = ? AND to_user_id = ?", "test-user-001", "test-user-002").Take(&decision).Error)
+			assert.Equal(t, tt.wantAction, decision.Action)
+			assert.Equal(t, tt.wantRule, decision.Rule)
+
+			var wallet model.Wallet
+			require.NoError(t, dbInstance.Where("user_id = ?", "test-user-001").Take(&wallet).Error)
+			assert.Equal(t, tt.wantBalance, wallet.Balance)
+		})
+	}
+}
+
+// newTestRiskEngine returns a risk engine evaluating the given rules.
+func newTestRiskEngine(t *testing.T, db *gorm.DB, cfg model.Risk) service.RiskEngine {
+	engine, err := risk.NewEngine(cfg, repository.NewRiskDecisionRepo(db), fx.DefaultStaticRates())
+	require.NoError(t, err)
+	return engine
+}
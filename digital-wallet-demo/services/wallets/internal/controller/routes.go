@@ -4,13 +4,51 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func InitRoutes(api *echo.Group, controller WalletHandler) {
-	wallet := api.Group("/wallets")
+func InitRoutes(api *echo.Group, controller WalletHandler, fxController FXHandler, scheduleController ScheduleHandler, limitController LimitHandler, reviewController ReviewHandler, idempotency, authenticate echo.MiddlewareFunc) {
+	wallet := api.Group("/wallets", authenticate)
 	{
 		wallet.POST("", controller.Create)
-		wallet.POST("/deposit", controller.Deposit)
-		wallet.POST("/withdraw", controller.Withdraw)
-		wallet.POST("/transfer", controller.Transfer)
-		wallet.GET("/:user_id", controller.FetchTransactions)
+		wallet.POST("/deposit", controller.Deposit, idempotency)
+		wallet.POST("/withdraw", controller.Withdraw, idempotency)
+		wallet.POST("/transfer", controller.Transfer, idempotency)
+		wallet.POST("/holds", controller.CreateHold, idempotency)
+		wallet.GET("/holds/:id", controller.GetHold)
+		wallet.POST("/holds/:id/capture", controller.CaptureHold, idempotency)
+		wallet.POST("/holds/:id/void", controller.VoidHold, idempotency)
+		wallet.GET("/:user_id", controller.FetchTransactions, RequireOwner("user_id"))
+		wallet.PATCH("/:user_id/status", controller.UpdateStatus, RequireAdmin)
+		wallet.POST("/transactions/:id/reverse", controller.Reverse, RequireAdmin, idempotency)
+	}
+
+	schedules := wallet.Group("/:user_id/schedules", RequireOwner("user_id"))
+	{
+		schedules.POST("", scheduleController.Create, idempotency)
+		schedules.GET("", scheduleController.List)
+		schedules.GET("/:id", scheduleController.Get)
+		schedules.PATCH("/:id", scheduleController.Update)
+		schedules.DELETE("/:id", scheduleController.Delete)
+		schedules.POST("/:id/pause", scheduleController.Pause)
+		schedules.POST("/:id/resume", scheduleController.Resume)
+	}
+
+	fx := api.Group("/fx", authenticate)
+	{
+		fx.POST("/quotes", fxController.CreateQuote)
+	}
+
+	admin := api.Group("/admin", authenticate, RequireAdmin)
+	{
+		admin.GET("/limits", limitController.List)
+		admin.PUT("/limits/wallets/:user_id", limitController.SetForWallet)
+		admin.DELETE("/limits/wallets/:user_id", limitController.DeleteForWallet)
+		admin.PUT("/limits/types/:acnt_type", limitController.SetForType)
+		admin.DELETE("/limits/types/:acnt_type", limitController.DeleteForType)
+		admin.GET("/reviews", reviewController.List)
+		admin.GET("/reviews/:id", reviewController.Get)
+		admin.POST("/reviews/:id/approve", reviewController.Approve)
+		admin.POST("/reviews/:id/reject", reviewController.Reject)
+		admin.GET("/review-flags", reviewController.ListFlags)
+		admin.PUT("/review-flags/:user_id", reviewController.Flag)
+		admin.DELETE("/review-flags/:user_id", reviewController.Unflag)
 	}
 }
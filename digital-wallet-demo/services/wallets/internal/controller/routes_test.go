@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/fx"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
 	"github.com/labstack/echo/v4"
@@ -21,7 +23,7 @@ func TestRegister(t *testing.T) {
 	require.NoError(t, err)
 	err = db.Migrate(dbInstance)
 	require.NoError(t, err)
-	setupTestRoutes(e, dbInstance)
+	setupTestRoutes(t, e, dbInstance)
 
 	// Test cases
 	tests := []struct {
@@ -30,12 +32,25 @@ func TestRegister(t *testing.T) {
 		target       string
 		expectedCode int
 	}{
-		{"Health_Check", http.MethodGet, "/api/v1/health", http.StatusOK},
-		{"Create_Wallet_without_body", http.MethodPost, "/api/v1/wallets", http.StatusBadRequest},             // Assuming no body is sent, should return BadRequest
-		{"Get_non-existent_Wallet", http.MethodGet, "/api/v1/wallets/non-existent-user", http.StatusNotFound}, // Assuming no wallet with this user_id exists
-		{"Deposit_without_body", http.MethodPost, "/api/v1/wallets/deposit", http.StatusBadRequest},           // Assuming no body is sent, should return BadRequest
-		{"Withdraw_without_body", http.MethodPost, "/api/v1/wallets/withdraw", http.StatusBadRequest},         // Assuming no body is sent, should return BadRequest
-		{"Transfer_without_body", http.MethodPost, "/api/v1/wallets/transfer", http.StatusBadRequest},         // Assuming no body is sent, should return BadRequest
+		{"Liveness_Check", http.MethodGet, "/api/v1/health/live", http.StatusOK},
+		{"Readiness_Check", http.MethodGet, "/api/v1/health/ready", http.StatusOK},
+		{"Create_Wallet_without_body", http.MethodPost, "/api/v1/wallets", http.StatusBadRequest},                              // Assuming no body is sent, should return BadRequest
+		{"Get_non-existent_Wallet", http.MethodGet, "/api/v1/wallets/non-existent-user", http.StatusNotFound},                  // Assuming no wallet with this user_id exists
+		{"Deposit_without_body", http.MethodPost, "/api/v1/wallets/deposit", http.StatusBadRequest},                            // Assuming no body is sent, should return BadRequest
+		{"Withdraw_without_body", http.MethodPost, "/api/v1/wallets/withdraw", http.StatusBadRequest},                          // Assuming no body is sent, should return BadRequest
+		{"Transfer_without_body", http.MethodPost, "/api/v1/wallets/transfer", http.StatusBadRequest},                          // Assuming no body is sent, should return BadRequest
+		{"Quote_without_body", http.MethodPost, "/api/v1/fx/quotes", http.StatusBadRequest},                                    // Assuming no body is sent, should return BadRequest
+		{"Update_Status_without_body", http.MethodPatch, "/api/v1/wallets/test-user/status", http.StatusBadRequest},            // Assuming no body is sent, should return BadRequest
+		{"Reverse_invalid_transaction_id", http.MethodPost, "/api/v1/wallets/transactions/abc/reverse", http.StatusBadRequest}, // Transaction IDs are UUIDs
+		{"Hold_without_body", http.MethodPost, "/api/v1/wallets/holds", http.StatusBadRequest},                                 // Assuming no body is sent, should return BadRequest
+		{"Create_Schedule_without_body", http.MethodPost, "/api/v1/wallets/test-user/schedules", http.StatusBadRequest},
+		{"Get_non-existent_Schedule", http.MethodGet, "/api/v1/wallets/test-user/schedules/1", http.StatusNotFound},
+		{"Set_Limit_of_unknown_Account_Type", http.MethodPut, "/api/v1/admin/limits/types/merchant", http.StatusBadRequest},
+		{"Delete_non-existent_Wallet_Limit", http.MethodDelete, "/api/v1/admin/limits/wallets/non-existent-user", http.StatusNotFound},
+		{"List_Reviews_with_unknown_Status", http.MethodGet, "/api/v1/admin/reviews?status=unknown", http.StatusBadRequest},
+		{"Approve_non-existent_Review", http.MethodPost, "/api/v1/admin/reviews/non-existent-review/approve", http.StatusNotFound},
+		{"Delete_non-existent_Review_Flag", http.MethodDelete, "/api/v1/admin/review-flags/non-existent-user", http.StatusNotFound},
+		{"Get_non-existent_Hold", http.MethodGet, "/api/v1/wallets/holds/non-existent-hold", http.StatusNotFound}, // Assuming no hold with this ID exists
 	}
 
 	for _, tt := range tests {
@@ -49,22 +64,32 @@ func TestRegister(t *testing.T) {
 }
 
 // setupTestRoutes configures routes for testing with the same pattern as the server
-func setupTestRoutes(e *echo.Echo, db *gorm.DB) {
+func setupTestRoutes(t *testing.T, e *echo.Echo, db *gorm.DB) {
 	// Set up request validation
 	e.Validator = NewCustomValidator()
 
 	// Create API version group
 	api := e.Group("/api/v1")
 
-	// Register health check endpoint
-	healthHandler := NewHealth()
-	api.GET("/health", healthHandler.Health)
+	// Register health check endpoints
+	sqlDB, _ := db.DB()
+	healthHandler := NewHealth(Dependency{Name: "postgres", Hard: true, Ping: sqlDB.PingContext})
+	api.GET("/health/live", healthHandler.Live)
+	api.GET("/health/ready", healthHandler.Ready)
 
 	// Initialize wallet handler with dependencies
 	walletRepo := repository.NewWalletRepo(db)
-	walletService := service.NewWalletService(walletRepo)
+	fxQuoteRepo := repository.NewFXQuoteRepo(db)
+	limitRepo := repository.NewLimitRepo(db)
+	auditRepo := repository.NewAuditRepo(db)
+	walletService := newTestWalletService(t, db)
 	walletHandler := NewWalletController(walletService)
+	fxHandler := NewFXController(service.NewFXService(fxQuoteRepo, fx.DefaultStaticRates(), time.Minute))
+
+	scheduleHandler := NewScheduleController(service.NewScheduleService(repository.NewScheduleRepo(db), walletRepo))
+	limitHandler := NewLimitController(service.NewLimitService(limitRepo, walletRepo, auditRepo))
+	reviewHandler := NewReviewController(newTestReviewService(db))
 
 	// Register wallet routes
-	InitRoutes(api, walletHandler)
+	InitRoutes(api, walletHandler, fxHandler, scheduleHandler, limitHandler, reviewHandler, Idempotency(repository.NewIdempotencyRepo(db)), Authenticate(nil))
 }
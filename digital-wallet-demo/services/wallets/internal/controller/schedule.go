@@ -0,0 +1,326 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+const scheduleConflictMessage = "Schedule is being run, retry the change"
+
+// ScheduleHandler is the request handler for the scheduled transfer endpoints.
+type ScheduleHandler interface {
+	Create(c echo.Context) error
+	List(c echo.Context) error
+	Get(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+	Pause(c echo.Context) error
+	Resume(c echo.Context) error
+}
+
+type scheduleHandler struct {
+	Handler
+	service service.Schedule
+}
+
+// NewScheduleController returns a new instance of the schedule handler.
+func NewScheduleController(s service.Schedule) ScheduleHandler {
+	return &scheduleHandler{service: s}
+}
+
+// CreateScheduleRequest represents the request for scheduling a transfer
+type CreateScheduleRequest struct {
+	UserID     string           `param:"user_id" json:"-" validate:"required"`
+	ToUserID   string           `json:"to_user_id" validate:"required,nefield=UserID"`
+	Amount     int64            `json:"amount" validate:"required,gt=0"`                          // Amount in minor units of the currency
+	Currency   model.Currency   `json:"currency,omitempty" validate:"omitempty,validCurrency"`    // Defaults to the sender's base currency
+	ToCurrency model.Currency   `json:"to_currency,omitempty" validate:"omitempty,validCurrency"` // Converted at the rate at the time of each run
+	Recurrence model.Recurrence `json:"recurrence" validate:"required,validRecurrence"`
+	CronExpr   string           `json:"cron_expr,omitempty" validate:"required_if=Recurrence cron,max=100"` // Standard 5-field cron expression, evaluated in UTC
+	StartAt    time.Time        `json:"start_at" validate:"required"`                                       // Time of the first run
+}
+
+// UpdateScheduleRequest represents the request for changing a scheduled transfer
+type UpdateScheduleRequest struct {
+	UserID     string           `param:"user_id" json:"-" validate:"required"`
+	ID         int              `param:"id" json:"-" validate:"required,gt=0"`
+	ToUserID   string           `json:"to_user_id,omitempty" validate:"omitempty,nefield=UserID"`
+	Amount     int64            `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Recurrence model.Recurrence `json:"recurrence,omitempty" validate:"omitempty,validRecurrence"`
+	CronExpr   string           `json:"cron_expr,omitempty" validate:"max=100"`
+	StartAt    *time.Time       `json:"start_at,omitempty"`
+}
+
+// ScheduleIDRequest is the request parameter for operations on a single schedule
+type ScheduleIDRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+	ID     int    `param:"id" validate:"required,gt=0"`
+}
+
+// ListSchedulesRequest is the request parameter for listing the schedules of a wallet
+type ListSchedulesRequest struct {
+	UserID string `param:"user_id" validate:"required"`
+}
+
+// @Summary	Schedule a transfer
+// @Description	Schedules a transfer from the wallet once at start_at, or on a daily, weekly, monthly or cron recurrence from start_at. Runs that fail, for example on insufficient funds, are recorded on the schedule and do not stop a recurring schedule.
+// @Tags		schedules
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string					true	"User ID of the sender"
+// @Param		request	body		CreateScheduleRequest	true	"Schedule request"
+// @Success	201		{object}	ResponseData{data=model.Schedule}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/{user_id}/schedules [post]
+func (t *scheduleHandler) Create(c echo.Context) error {
+	var req CreateScheduleRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	schedule, err := t.service.Create(c.Request().Context(), service.ScheduleParams{
+		FromUserID: req.UserID,
+		ToUserID:   req.ToUserID,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		ToCurrency: req.ToCurrency,
+		Recurrence: req.Recurrence,
+		CronExpr:   req.CronExpr,
+		StartAt:    req.StartAt,
+	})
+	if err != nil {
+		if err == model.ErrInvalidSchedule {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Schedule has an invalid recurrence or no run after now"}}})
+		}
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseData{Data: schedule})
+}
+
+// @Summary	List scheduled transfers
+// @Tags		schedules
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID of the sender"
+// @Success	200		{object}	ResponseData{data=[]model.Schedule}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/{user_id}/schedules [get]
+func (t *scheduleHandler) List(c echo.Context) error {
+	var req ListSchedulesRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	schedules, err := t.service.List(c.Request().Context(), req.UserID)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: schedules})
+}
+
+// @Summary	Get a scheduled transfer
+// @Tags		schedules
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID of the sender"
+// @Param		id		path		int		true	"Schedule ID"
+// @Success	200		{object}	ResponseData{data=model.Schedule}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/{user_id}/schedules/{id} [get]
+func (t *scheduleHandler) Get(c echo.Context) error {
+	var req ScheduleIDRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	schedule, err := t.service.Get(c.Request().Context(), req.UserID, req.ID)
+	if err != nil {
+		if err == model.ErrScheduleNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Schedule not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: schedule})
+}
+
+// @Summary	Change a scheduled transfer
+// @Description	Changes the receiver, amount or timing of a schedule with runs left. A change of timing moves the next run to the first occurrence from now.
+// @Tags		schedules
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string					true	"User ID of the sender"
+// @Param		id		path		int						true	"Schedule ID"
+// @Param		request	body		UpdateScheduleRequest	true	"Schedule change request"
+// @Success	200		{object}	ResponseData{data=model.Schedule}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/{user_id}/schedules/{id} [patch]
+func (t *scheduleHandler) Update(c echo.Context) error {
+	var req UpdateScheduleRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	schedule, err := t.service.Update(c.Request().Context(), service.UpdateScheduleParams{
+		FromUserID: req.UserID,
+		ID:         req.ID,
+		ToUserID:   req.ToUserID,
+		Amount:     req.Amount,
+		Recurrence: req.Recurrence,
+		CronExpr:   req.CronExpr,
+		StartAt:    req.StartAt,
+	})
+	if err != nil {
+		if err == model.ErrInvalidSchedule {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Schedule has an invalid recurrence or no run after now"}}})
+		}
+		if err == model.ErrScheduleNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Schedule not found"}}})
+		}
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+		}
+		if err == model.ErrScheduleState {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeInvalidScheduleState, Message: "Completed schedules cannot be changed"}}})
+		}
+		if err == model.ErrScheduleConflict {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeScheduleConflict, Message: scheduleConflictMessage}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: schedule})
+}
+
+// @Summary	Delete a scheduled transfer
+// @Tags		schedules
+// @Param		user_id	path	string	true	"User ID of the sender"
+// @Param		id		path	int		true	"Schedule ID"
+// @Success	204
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/{user_id}/schedules/{id} [delete]
+func (t *scheduleHandler) Delete(c echo.Context) error {
+	var req ScheduleIDRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if err := t.service.Delete(c.Request().Context(), req.UserID, req.ID); err != nil {
+		if err == model.ErrScheduleNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Schedule not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary	Pause a scheduled transfer
+// @Tags		schedules
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID of the sender"
+// @Param		id		path		int		true	"Schedule ID"
+// @Success	200		{object}	ResponseData{data=model.Schedule}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/{user_id}/schedules/{id}/pause [post]
+func (t *scheduleHandler) Pause(c echo.Context) error {
+	return t.changeState(c, t.service.Pause, "Only active schedules can be paused")
+}
+
+// @Summary	Resume a scheduled transfer
+// @Description	Occurrences of a recurring schedule missed while it was paused are skipped. A one-off transfer that became due while paused runs right away.
+// @Tags		schedules
+// @Produce	json
+// @Param		user_id	path		string	true	"User ID of the sender"
+// @Param		id		path		int		true	"Schedule ID"
+// @Success	200		{object}	ResponseData{data=model.Schedule}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/{user_id}/schedules/{id}/resume [post]
+func (t *scheduleHandler) Resume(c echo.Context) error {
+	return t.changeState(c, t.service.Resume, "Only paused schedules can be resumed")
+}
+
+// changeState pauses or resumes the schedule of the request with change.
+func (t *scheduleHandler) changeState(c echo.Context, change func(ctx context.Context, userID string, id int) (*model.Schedule, error), stateMessage string) error {
+	var req ScheduleIDRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	schedule, err := change(c.Request().Context(), req.UserID, req.ID)
+	if err != nil {
+		if err == model.ErrScheduleNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Schedule not found"}}})
+		}
+		if err == model.ErrScheduleState {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeInvalidScheduleState, Message: stateMessage}}})
+		}
+		if err == model.ErrScheduleConflict {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeScheduleConflict, Message: scheduleConflictMessage}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: schedule})
+}
@@ -0,0 +1,329 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/google/go-cmp/cmp"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestScheduleHandler_Create(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewScheduleController(service.NewScheduleService(repository.NewScheduleRepo(dbInstance), repository.NewWalletRepo(dbInstance)))
+
+	startAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name        string
+		userID      string
+		requestBody string
+		want        want
+	}{
+		{
+			name:        "successful_monthly_schedule",
+			userID:      "test-user-001",
+			requestBody: `{"to_user_id":"test-user-002","amount":2500,"recurrence":"monthly","start_at":"` + startAt + `"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":2500, "recurrence":"monthly", "status":"active", "run_count":0, "failure_count":0}}`),
+			},
+		},
+		{
+			name:        "successful_cron_schedule",
+			userID:      "test-user-001",
+			requestBody: `{"to_user_id":"test-user-002","amount":2500,"recurrence":"cron","cron_expr":"0 9 * * 1-5","start_at":"` + startAt + `"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+			},
+		},
+		{
+			name:        "cron_without_expression",
+			userID:      "test-user-001",
+			requestBody: `{"to_user_id":"test-user-002","amount":2500,"recurrence":"cron","start_at":"` + startAt + `"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "invalid_cron_expression",
+			userID:      "test-user-001",
+			requestBody: `{"to_user_id":"test-user-002","amount":2500,"recurrence":"cron","cron_expr":"every day","start_at":"` + startAt + `"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "one_off_in_the_past",
+			userID:      "test-user-001",
+			requestBody: `{"to_user_id":"test-user-002","amount":2500,"recurrence":"once","start_at":"2020-01-01T00:00:00Z"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "unknown_recurrence",
+			userID:      "test-user-001",
+			requestBody: `{"to_user_id":"test-user-002","amount":2500,"recurrence":"yearly","start_at":"` + startAt + `"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "same_wallet",
+			userID:      "test-user-001",
+			requestBody: `{"to_user_id":"test-user-001","amount":2500,"recurrence":"daily","start_at":"` + startAt + `"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "receiver_not_found",
+			userID:      "test-user-001",
+			requestBody: `{"to_user_id":"non-existent-user","amount":2500,"recurrence":"daily","start_at":"` + startAt + `"}`,
+			want: want{
+				StatusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean database before each test
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.Schedule{})
+
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPost, "/wallets/"+tt.userID+"/schedules", bytes.NewReader([]byte(tt.requestBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/schedules")
+			c.SetParamNames("user_id")
+			c.SetParamValues(tt.userID)
+
+			// Execute
+			require.NoError(t, handler.Create(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			var count int64
+			require.NoError(t, dbInstance.Model(&model.Schedule{}).Count(&count).Error)
+			assert.Equal(t, tt.want.StatusCode == http.StatusCreated, count == 1)
+
+			if tt.want.Response == nil {
+				return
+			}
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"id": 1, "start_at": 1, "next_run_at": 1, "created_at": 1, "updated_at": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestScheduleHandler_PauseResume(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewScheduleController(service.NewScheduleService(repository.NewScheduleRepo(dbInstance), repository.NewWalletRepo(dbInstance)))
+
+	tests := []struct {
+		name           string
+		action         string
+		status         model.ScheduleStatus
+		userID         string
+		wantStatusCode int
+		wantStatus     model.ScheduleStatus
+	}{
+		{"pause_active", "pause", model.ScheduleActive, "test-user-001", http.StatusOK, model.SchedulePaused},
+		{"pause_paused", "pause", model.SchedulePaused, "test-user-001", http.StatusConflict, model.SchedulePaused},
+		{"resume_paused", "resume", model.SchedulePaused, "test-user-001", http.StatusOK, model.ScheduleActive},
+		{"resume_completed", "resume", model.ScheduleCompleted, "test-user-001", http.StatusConflict, model.ScheduleCompleted},
+		{"other_wallet", "pause", model.ScheduleActive, "test-user-002", http.StatusNotFound, model.ScheduleActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean database before each test
+			clearDB(dbInstance, model.Schedule{})
+
+			id := createTestSchedule(t, dbInstance, tt.status)
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPost, "/wallets/"+tt.userID+"/schedules/"+strconv.Itoa(id)+"/"+tt.action, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/schedules/:id/" + tt.action)
+			c.SetParamNames("user_id", "id")
+			c.SetParamValues(tt.userID, strconv.Itoa(id))
+
+			// Execute
+			if tt.action == "pause" {
+				require.NoError(t, handler.Pause(c))
+			} else {
+				require.NoError(t, handler.Resume(c))
+			}
+
+			// Assert
+			assert.Equal(t, tt.wantStatusCode, rec.Code)
+
+			var schedule model.Schedule
+			require.NoError(t, dbInstance.First(&schedule, id).Error)
+			assert.Equal(t, tt.wantStatus, schedule.Status)
+		})
+	}
+}
+
+// advancingScheduleRepo advances a schedule like the runner does, right after
+// the first time it is read, so that the change being made works on a stale copy.
+type advancingScheduleRepo struct {
+	repository.Schedule
+	advanceTo *time.Time
+}
+
+func (r *advancingScheduleRepo) FindByID(ctx context.Context, userID string, id int) (*model.Schedule, error) {
+	sched, err := r.Schedule.FindByID(ctx, userID, id)
+	if err != nil || r.advanceTo == nil {
+		return sched, err
+	}
+
+	tx := r.BeginTransaction(ctx)
+	if err := r.Advance(tx, id, r.advanceTo, model.ScheduleActive); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	r.advanceTo = nil
+	return sched, nil
+}
+
+func TestScheduleHandler_PauseConcurrentAdvance(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Schedule{})
+
+	id := createTestSchedule(t, dbInstance, model.ScheduleActive)
+	advanced := time.Now().Add(25 * time.Hour).UTC().Truncate(time.Microsecond)
+	repo := &advancingScheduleRepo{Schedule: repository.NewScheduleRepo(dbInstance), advanceTo: &advanced}
+	handler := NewScheduleController(service.NewScheduleService(repo, repository.NewWalletRepo(dbInstance)))
+
+	req := httptest.NewRequest(http.MethodPost, "/wallets/test-user-001/schedules/"+strconv.Itoa(id)+"/pause", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/wallets/:user_id/schedules/:id/pause")
+	c.SetParamNames("user_id", "id")
+	c.SetParamValues("test-user-001", strconv.Itoa(id))
+
+	require.NoError(t, handler.Pause(c))
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The pause is applied to the advanced schedule instead of writing back the stale next run
+	var schedule model.Schedule
+	require.NoError(t, dbInstance.First(&schedule, id).Error)
+	assert.Equal(t, model.SchedulePaused, schedule.Status)
+	require.NotNil(t, schedule.NextRunAt)
+	assert.True(t, advanced.Equal(*schedule.NextRunAt), "next run %s, want %s", schedule.NextRunAt, advanced)
+}
+
+func TestScheduleHandler_Delete(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewScheduleController(service.NewScheduleService(repository.NewScheduleRepo(dbInstance), repository.NewWalletRepo(dbInstance)))
+
+	tests := []struct {
+		name           string
+		userID         string
+		wantStatusCode int
+		wantRemaining  int64
+	}{
+		{"successful_delete", "test-user-001", http.StatusNoContent, 0},
+		{"other_wallet", "test-user-002", http.StatusNotFound, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Clean database before each test
+			clearDB(dbInstance, model.Schedule{})
+
+			id := strconv.Itoa(createTestSchedule(t, dbInstance, model.ScheduleActive))
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodDelete, "/wallets/"+tt.userID+"/schedules/"+id, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/schedules/:id")
+			c.SetParamNames("user_id", "id")
+			c.SetParamValues(tt.userID, id)
+
+			// Execute
+			require.NoError(t, handler.Delete(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatusCode, rec.Code)
+
+			var count int64
+			require.NoError(t, dbInstance.Model(&model.Schedule{}).Count(&count).Error)
+			assert.Equal(t, tt.wantRemaining, count)
+		})
+	}
+}
+
+// createTestSchedule stores a daily transfer from test-user-001 to test-user-002 and returns its ID.
+func createTestSchedule(t *testing.T, db *gorm.DB, status model.ScheduleStatus) int {
+	next := time.Now().Add(time.Hour)
+	schedule := &model.Schedule{
+		FromUserID: "test-user-001",
+		ToUserID:   "test-user-002",
+		Amount:     2500,
+		Recurrence: model.Daily,
+		StartAt:    next,
+		NextRunAt:  &next,
+		Status:     status,
+	}
+	require.NoError(t, db.Create(schedule).Error)
+	return schedule.ID
+}
@@ -25,6 +25,8 @@ func NewCustomValidator() *CustomValidator {
 	// Register the custom validation for wallet system
 	_ = v.RegisterValidation("validWalletStatus", model.IsValidStatus)
 	_ = v.RegisterValidation("validAcntType", model.IsValidAcntType)
+	_ = v.RegisterValidation("validCurrency", model.IsValidCurrency)
+	_ = v.RegisterValidation("validRecurrence", model.IsValidRecurrence)
 
 	return &CustomValidator{validator: v}
 }
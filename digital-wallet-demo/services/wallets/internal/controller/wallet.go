@@ -2,8 +2,10 @@ package controller
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/errors"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
 	"github.com/labstack/echo/v4"
@@ -16,6 +18,12 @@ type WalletHandler interface {
 	Withdraw(c echo.Context) error
 	Transfer(c echo.Context) error
 	FetchTransactions(c echo.Context) error
+	UpdateStatus(c echo.Context) error
+	Reverse(c echo.Context) error
+	CreateHold(c echo.Context) error
+	GetHold(c echo.Context) error
+	CaptureHold(c echo.Context) error
+	VoidHold(c echo.Context) error
 }
 
 type walletHandler struct {
@@ -32,40 +40,91 @@ func NewWalletController(s service.Wallet) WalletHandler {
 type CreateRequest struct {
 	UserID   string         `json:"user_id" validate:"required"`
 	AcntType model.AcntType `json:"acnt_type" validate:"required,validAcntType"`
+	Currency model.Currency `json:"currency,omitempty" validate:"omitempty,validCurrency"` // Base currency, defaults to USD
 }
 
 // DepositRequest represents the request for deposit operation
 type DepositRequest struct {
-	UserID     string  `json:"user_id" validate:"required"`
-	Amount     int     `json:"amount" validate:"required,gt=0"`
-	ProviderID *string `json:"provider_id,omitempty"`
+	UserID     string         `json:"user_id" validate:"required"`
+	Amount     int            `json:"amount" validate:"required,gt=0"`                       // Amount in minor units of the currency
+	Currency   model.Currency `json:"currency,omitempty" validate:"omitempty,validCurrency"` // Defaults to the wallet's base currency
+	ProviderID *string        `json:"provider_id,omitempty"`
+	Reference  string         `json:"reference,omitempty" validate:"omitempty,max=128"` // Client reference recorded on both transactions
 }
 
 // WithdrawRequest represents the request for withdraw operation
 type WithdrawRequest struct {
-	UserID     string  `json:"user_id" validate:"required"`
-	Amount     int     `json:"amount" validate:"required,gt=0"`
-	ProviderID *string `json:"provider_id,omitempty"`
+	UserID     string         `json:"user_id" validate:"required"`
+	Amount     int            `json:"amount" validate:"required,gt=0"`                       // Amount in minor units of the currency
+	Currency   model.Currency `json:"currency,omitempty" validate:"omitempty,validCurrency"` // Defaults to the wallet's base currency
+	ProviderID *string        `json:"provider_id,omitempty"`
+	Reference  string         `json:"reference,omitempty" validate:"omitempty,max=128"` // Client reference recorded on both transactions
 }
 
 // TransferRequest represents the request for transfer operation
 type TransferRequest struct {
-	FromUserID string `json:"from_user_id" validate:"required"`
-	ToUserID   string `json:"to_user_id" validate:"required"`
-	Amount     int    `json:"amount" validate:"required,gt=0"`
+	FromUserID string         `json:"from_user_id" validate:"required"`
+	ToUserID   string         `json:"to_user_id" validate:"required"`
+	Amount     int            `json:"amount" validate:"required,gt=0"`                          // Amount in minor units of the currency
+	Currency   model.Currency `json:"currency,omitempty" validate:"omitempty,validCurrency"`    // Defaults to the sender's base currency
+	ToCurrency model.Currency `json:"to_currency,omitempty" validate:"omitempty,validCurrency"` // Currency credited to the receiver, defaults to currency
+	QuoteID    string         `json:"quote_id,omitempty"`                                       // Quote locking the conversion rate, see POST /fx/quotes
+	Reference  string         `json:"reference,omitempty" validate:"omitempty,max=128"`         // Client reference recorded on both transactions
+}
+
+// UpdateStatusRequest represents the request for changing a wallet's status
+type UpdateStatusRequest struct {
+	UserID        string       `param:"user_id" json:"-" validate:"required"`
+	Status        model.Status `json:"status" validate:"required,validWalletStatus"`
+	Reason        string       `json:"reason" validate:"required,max=500"`
+	SweepToUserID string       `json:"sweep_to_user_id,omitempty"` // Wallet receiving the remaining funds when closing
+}
+
+// ReverseRequest represents the request for reversing a transaction
+type ReverseRequest struct {
+	TransactionID string `param:"id" json:"-" validate:"required,uuid"`
+	Amount        int64  `json:"amount,omitempty" validate:"omitempty,gt=0"` // Amount to refund in minor units of the transaction's currency, defaults to the full amount
+}
+
+// walletStatusErrors are the errors of wallets that cannot send or receive funds, by error code
+var walletStatusErrors = map[error]string{
+	model.ErrWalletInactive:  errors.CodeWalletInactive,
+	model.ErrWalletSuspended: errors.CodeWalletSuspended,
+	model.ErrWalletClosed:    errors.CodeWalletClosed,
 }
 
 // WalletSummary represents essential wallet information for API responses
+//
+// Balance is the ledger balance. AvailableBalance excludes the amounts reserved
+// by active holds and pending reviews, and is what can be withdrawn, transferred
+// or held.
 type WalletSummary struct {
-	Balance  int64          `json:"balance"`
-	AcntType model.AcntType `json:"acnt_type"`
-	Status   model.Status   `json:"status"`
+	Balance          int64            `json:"balance"`
+	AvailableBalance int64            `json:"available_balance"`
+	Currency         model.Currency   `json:"currency"`
+	Balances         []BalanceSummary `json:"balances,omitempty"` // Balances in currencies other than the base currency
+	AcntType         model.AcntType   `json:"acnt_type"`
+	Status           model.Status     `json:"status"`
+	StatusReason     string           `json:"status_reason,omitempty"` // Reason given for the last status change
+}
+
+// BalanceSummary represents a wallet's balance in a currency other than its base currency
+type BalanceSummary struct {
+	Currency         model.Currency `json:"currency"`
+	Balance          int64          `json:"balance"`
+	AvailableBalance int64          `json:"available_balance"`
 }
 
-// WalletResponse represents wallet with transaction history
+// WalletResponse represents wallet with a page of its transaction history
 type WalletResponse struct {
 	Wallet       WalletSummary       `json:"wallet"`
 	Transactions []model.Transaction `json:"transactions"`
+	// NextCursor is passed as the cursor query parameter to fetch the next page of transactions.
+	// It is omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TransactionsUnavailable is set when the transaction history could not be read
+	// from the transaction service. The wallet and its balances are still returned.
+	TransactionsUnavailable bool `json:"transactions_unavailable,omitempty"`
 }
 
 // @Summary	Create a new wallet
@@ -76,6 +135,7 @@ type WalletResponse struct {
 // @Success	201		{object}	ResponseData{data=model.Wallet}
 // @Failure	400		{object}	ResponseError
 // @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
 // @Router		/wallets [post]
 func (t *walletHandler) Create(c echo.Context) error {
 	var req CreateRequest
@@ -84,8 +144,19 @@ func (t *walletHandler) Create(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
+	if !canActFor(c, req.UserID) {
+		return forbidden(c, walletForbidden)
+	}
+	// Provider wallets back deposits and withdrawals of every user
+	if req.AcntType == model.Provider && !callerIsAdmin(c) {
+		return forbidden(c, adminForbidden)
+	}
+
 	wallet := model.NewWallet(req.UserID, req.AcntType)
-	if err := t.service.Create(wallet); err != nil {
+	if req.Currency != "" {
+		wallet.Currency = req.Currency
+	}
+	if err := t.service.Create(c.Request().Context(), wallet, t.AuditContext(c, userActor(req.UserID))); err != nil {
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
@@ -101,7 +172,9 @@ func (t *walletHandler) Create(c echo.Context) error {
 // @Success	201		{object}	ResponseData{data=model.Transaction}
 // @Failure	400		{object}	ResponseError
 // @Failure	404		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
 // @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
 // @Router		/wallets/deposit [post]
 func (t *walletHandler) Deposit(c echo.Context) error {
 	var req DepositRequest
@@ -110,8 +183,27 @@ func (t *walletHandler) Deposit(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	transaction, err := t.service.Deposit(req.UserID, req.Amount, req.ProviderID)
+	if !canActFor(c, req.UserID) {
+		return forbidden(c, walletForbidden)
+	}
+
+	transaction, err := t.service.Deposit(c.Request().Context(), service.DepositParams{
+		UserID:     req.UserID,
+		Amount:     int64(req.Amount),
+		Currency:   req.Currency,
+		ProviderID: req.ProviderID,
+		Reference:  req.Reference,
+		Audit:      t.AuditContext(c, userActor(req.UserID)),
+	})
 	if err != nil {
+		if err == model.ErrUnsupportedCurrency {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+		}
+		if code, ok := walletStatusErrors[err]; ok {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: code, Message: err.Error()}}})
+		}
 		if err == model.ErrNotFound {
 			return c.JSON(http.StatusNotFound,
 				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
@@ -120,6 +212,7 @@ func (t *walletHandler) Deposit(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
 
+	metrics.ObserveTransaction(model.Deposit, transaction)
 	return c.JSON(http.StatusCreated, ResponseData{Data: transaction})
 }
 
@@ -129,10 +222,12 @@ func (t *walletHandler) Deposit(c echo.Context) error {
 // @Produce	json
 // @Param		request	body		WithdrawRequest	true	"Withdraw request"
 // @Success	201		{object}	ResponseData{data=model.Transaction}
+// @Success	202		{object}	ResponseData{data=model.Transaction}	"Held for review as pending"
 // @Failure	400		{object}	ResponseError
 // @Failure	404		{object}	ResponseError
 // @Failure	422		{object}	ResponseError
 // @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
 // @Router		/wallets/withdraw [post]
 func (t *walletHandler) Withdraw(c echo.Context) error {
 	var req WithdrawRequest
@@ -141,33 +236,71 @@ func (t *walletHandler) Withdraw(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	transaction, err := t.service.Withdraw(req.UserID, req.Amount, req.ProviderID)
+	if !canActFor(c, req.UserID) {
+		return forbidden(c, walletForbidden)
+	}
+
+	transaction, err := t.service.Withdraw(c.Request().Context(), service.WithdrawParams{
+		UserID:     req.UserID,
+		Amount:     int64(req.Amount),
+		Currency:   req.Currency,
+		ProviderID: req.ProviderID,
+		Reference:  req.Reference,
+		Audit:      t.AuditContext(c, userActor(req.UserID)),
+	})
 	if err != nil {
+		if err == model.ErrUnsupportedCurrency {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+		}
+		if code, ok := walletStatusErrors[err]; ok {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: code, Message: err.Error()}}})
+		}
 		if err == model.ErrNotFound {
 			return c.JSON(http.StatusNotFound,
 				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
 		}
 		if err == model.ErrInsufficientFunds {
+			metrics.InsufficientFunds.WithLabelValues(string(model.Withdraw)).Inc()
 			return c.JSON(http.StatusUnprocessableEntity,
 				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
 		}
+		if err == model.ErrLimitExceeded {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeLimitExceeded, Message: "Spending limit of the wallet exceeded"}}})
+		}
+		if err == model.ErrRiskRejected {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeRiskRejected, Message: "Transaction rejected by risk rules"}}})
+		}
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
 
+	metrics.ObserveTransaction(model.Withdraw, transaction)
+
+	// A transaction held for review is accepted, but not completed
+	if transaction.Status == model.Pending {
+		return c.JSON(http.StatusAccepted, ResponseData{Data: transaction})
+	}
 	return c.JSON(http.StatusCreated, ResponseData{Data: transaction})
 }
 
 // @Summary	Transfer money between wallets
+// @Description	A transfer with a to_currency different from currency is converted at the current rate, or at the rate locked by quote_id.
 // @Tags		wallets
 // @Accept		json
 // @Produce	json
 // @Param		request	body		TransferRequest	true	"Transfer request"
 // @Success	201		{object}	ResponseData{data=model.Transaction}
+// @Success	202		{object}	ResponseData{data=model.Transaction}	"Held for review as pending"
 // @Failure	400		{object}	ResponseError
 // @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
 // @Failure	422		{object}	ResponseError
 // @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
 // @Router		/wallets/transfer [post]
 func (t *walletHandler) Transfer(c echo.Context) error {
 	var req TransferRequest
@@ -176,41 +309,117 @@ func (t *walletHandler) Transfer(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
+	if !canActFor(c, req.FromUserID) {
+		return forbidden(c, walletForbidden)
+	}
+
 	// Validate that from and to wallets are different
 	if req.FromUserID == req.ToUserID {
 		return c.JSON(http.StatusBadRequest,
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Cannot transfer to the same wallet"}}})
 	}
 
-	transaction, err := t.service.Transfer(req.FromUserID, req.ToUserID, req.Amount)
+	transaction, err := t.service.Transfer(c.Request().Context(), service.TransferParams{
+		FromUserID: req.FromUserID,
+		ToUserID:   req.ToUserID,
+		Amount:     int64(req.Amount),
+		Currency:   req.Currency,
+		ToCurrency: req.ToCurrency,
+		QuoteID:    req.QuoteID,
+		Reference:  req.Reference,
+		Audit:      t.AuditContext(c, userActor(req.FromUserID)),
+	})
 	if err != nil {
+		if err == model.ErrUnsupportedCurrency {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+		}
+		if err == model.ErrQuoteNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Quote not found"}}})
+		}
+		if err == model.ErrQuoteExpired {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeQuoteExpired, Message: "Quote has expired"}}})
+		}
+		if err == model.ErrQuoteUsed {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeQuoteUsed, Message: "Quote has already been used"}}})
+		}
+		if err == model.ErrQuoteMismatch {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeQuoteMismatch, Message: "Quote does not match the currencies and amount of the transfer"}}})
+		}
+		if err == model.ErrRateUnavailable {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeRateUnavailable, Message: "No exchange rate available for the currency pair"}}})
+		}
+		if code, ok := walletStatusErrors[err]; ok {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: code, Message: err.Error()}}})
+		}
 		if err == model.ErrNotFound {
 			return c.JSON(http.StatusNotFound,
 				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
 		}
 		if err == model.ErrInsufficientFunds {
+			metrics.InsufficientFunds.WithLabelValues(string(model.Transfer)).Inc()
 			return c.JSON(http.StatusUnprocessableEntity,
 				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
 		}
+		if err == model.ErrLimitExceeded {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeLimitExceeded, Message: "Spending limit of the wallet exceeded"}}})
+		}
+		if err == model.ErrRiskRejected {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeRiskRejected, Message: "Transaction rejected by risk rules"}}})
+		}
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
 
+	metrics.ObserveTransaction(model.Transfer, transaction)
+
+	// A transaction held for review is accepted, but not completed
+	if transaction.Status == model.Pending {
+		return c.JSON(http.StatusAccepted, ResponseData{Data: transaction})
+	}
 	return c.JSON(http.StatusCreated, ResponseData{Data: transaction})
 }
 
-// FindRequest is the request parameter for finding a wallet
+// FindRequest is the request parameter for finding a wallet with a page of its transactions
 type FindRequest struct {
-	UserID string `param:"user_id" validate:"required"`
+	UserID          string                  `param:"user_id" validate:"required"`
+	Limit           int                     `query:"limit" validate:"omitempty,min=1,max=500"`
+	Cursor          string                  `query:"cursor"`
+	TransactionType model.TransactionType   `query:"transaction_type" validate:"omitempty,oneof=deposit withdraw transfer adjustment"`
+	OperationType   model.OperationType     `query:"operation_type" validate:"omitempty,oneof=debit credit"`
+	Status          model.TransactionStatus `query:"status" validate:"omitempty,oneof=pending completed failed cancelled"`
+	MinAmount       *int64                  `query:"min_amount" validate:"omitempty,gte=0"`
+	MaxAmount       *int64                  `query:"max_amount" validate:"omitempty,gte=0"`
+	CreatedFrom     *time.Time              `query:"created_from"`
+	CreatedTo       *time.Time              `query:"created_to"`
 }
 
 // @Summary	View wallet balance & transaction history
+// @Description	Transactions are returned newest first, one page at a time. Pass next_cursor as cursor to fetch the next page. While the transaction service is unavailable, the wallet is returned without transactions and with transactions_unavailable set.
 // @Tags		wallets
-// @Param		user_id	path		string	true	"User ID"
+// @Param		user_id				path		string	true	"User ID"
+// @Param		limit				query		int		false	"Page size (default 50, max 500)"
+// @Param		cursor				query		string	false	"Cursor returned by the previous page"
+// @Param		transaction_type	query		string	false	"Filter by transaction type"	Enums(deposit, withdraw, transfer, adjustment)
+// @Param		operation_type		query		string	false	"Filter by operation type"		Enums(debit, credit)
+// @Param		status				query		string	false	"Filter by status"				Enums(pending, completed, failed, cancelled)
+// @Param		min_amount			query		int		false	"Minimum amount in cents (inclusive)"
+// @Param		max_amount			query		int		false	"Maximum amount in cents (inclusive)"
+// @Param		created_from		query		string	false	"Created at or after (RFC 3339)"
+// @Param		created_to			query		string	false	"Created before (RFC 3339)"
 // @Success	200		{object}	ResponseData{data=WalletResponse}
 // @Failure	400		{object}	ResponseError
 // @Failure	404		{object}	ResponseError
 // @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
 // @Router		/wallets/{user_id} [get]
 func (t *walletHandler) FetchTransactions(c echo.Context) error {
 	var req FindRequest
@@ -219,23 +428,178 @@ func (t *walletHandler) FetchTransactions(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	wallet, transactions, err := t.service.GetWalletWithTransactions(req.UserID)
+	query := model.TransactionQuery{
+		Limit:           req.Limit,
+		Cursor:          req.Cursor,
+		TransactionType: req.TransactionType,
+		OperationType:   req.OperationType,
+		Status:          req.Status,
+		MinAmount:       req.MinAmount,
+		MaxAmount:       req.MaxAmount,
+		CreatedFrom:     req.CreatedFrom,
+		CreatedTo:       req.CreatedTo,
+	}
+
+	wallet, page, err := t.service.GetWalletWithTransactions(c.Request().Context(), req.UserID, query)
 	if err != nil {
 		if err == model.ErrNotFound {
 			return c.JSON(http.StatusNotFound,
 				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "wallet not found"}}})
 		}
+		if err == model.ErrInvalidTransactionQuery {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "invalid cursor"}}})
+		}
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
 
+	balances := make([]BalanceSummary, 0, len(wallet.Balances))
+	for _, b := range wallet.Balances {
+		balances = append(balances, BalanceSummary{
+			Currency:         b.Currency,
+			Balance:          b.Balance,
+			AvailableBalance: wallet.AvailableIn(b.Currency),
+		})
+	}
+
 	response := WalletResponse{
 		Wallet: WalletSummary{
-			Balance:  wallet.Balance,
-			AcntType: wallet.AcntType,
-			Status:   wallet.Status,
+			Balance:          wallet.Balance,
+			AvailableBalance: wallet.AvailableIn(wallet.Currency),
+			Currency:         wallet.Currency,
+			Balances:         balances,
+			AcntType:         wallet.AcntType,
+			Status:           wallet.Status,
+			StatusReason:     wallet.StatusReason,
 		},
-		Transactions: transactions,
+		Transactions:            page.Transactions,
+		NextCursor:              page.NextCursor,
+		TransactionsUnavailable: page.Unavailable,
 	}
 	return c.JSON(http.StatusOK, ResponseData{Data: response})
 }
+
+// @Summary	Change wallet status
+// @Description	Suspended, inactive and closed wallets cannot send or receive funds. Closing is final and requires a zero balance, or sweep_to_user_id to move the remaining funds to. Funds reserved by active holds or pending reviews are not swept: closing fails until they are released.
+// @Tags		wallets
+// @Accept		json
+// @Produce	json
+// @Param		user_id	path		string				true	"User ID"
+// @Param		request	body		UpdateStatusRequest	true	"Status change request"
+// @Success	200		{object}	ResponseData{data=model.Wallet}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/{user_id}/status [patch]
+func (t *walletHandler) UpdateStatus(c echo.Context) error {
+	var req UpdateStatusRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	wallet, err := t.service.UpdateStatus(c.Request().Context(), service.UpdateStatusParams{
+		UserID:        req.UserID,
+		Status:        req.Status,
+		Reason:        req.Reason,
+		SweepToUserID: req.SweepToUserID,
+		Audit:         t.AuditContext(c, adminActor),
+	})
+	if err != nil {
+		if err == model.ErrInvalidSweepTarget {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Funds can only be swept to another wallet when closing"}}})
+		}
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+		}
+		if err == model.ErrInvalidStatusTransition {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeInvalidStatusTransition, Message: "Wallet cannot be moved to the requested status"}}})
+		}
+		if err == model.ErrWalletNotEmpty {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeWalletNotEmpty, Message: "Wallet balance must be zero or swept to another wallet"}}})
+		}
+		if err == model.ErrFundsReserved {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeFundsReserved, Message: "Wallet funds are reserved by active holds or pending reviews, capture or void the holds and decide the reviews first"}}})
+		}
+		if code, ok := walletStatusErrors[err]; ok {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: code, Message: "Sweep wallet cannot receive funds: " + err.Error()}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: wallet})
+}
+
+// @Summary	Reverse a transaction
+// @Description	Moves the amount of a completed transaction back with a compensating transaction pair that references it. An amount below the transaction amount makes a partial refund. A transaction can be reversed once, through either the debit or the credit of its pair.
+// @Tags		wallets
+// @Accept		json
+// @Produce	json
+// @Param		id		path		string			true	"Transaction ID"
+// @Param		request	body		ReverseRequest	false	"Reverse request"
+// @Success	201		{object}	ResponseData{data=model.Transaction}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	422		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Security	BearerAuth
+// @Router		/wallets/transactions/{id}/reverse [post]
+func (t *walletHandler) Reverse(c echo.Context) error {
+	var req ReverseRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	transaction, err := t.service.Reverse(c.Request().Context(), service.ReverseParams{
+		TransactionID: req.TransactionID,
+		Amount:        req.Amount,
+		Audit:         t.AuditContext(c, adminActor),
+	})
+	if err != nil {
+		if err == model.ErrTransactionNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Transaction not found"}}})
+		}
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "Wallet not found"}}})
+		}
+		if err == model.ErrNotReversible {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotReversible, Message: "Only completed transactions that are not reversals can be reversed"}}})
+		}
+		if err == model.ErrReversalAmount {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeReversalAmountExceeded, Message: "Amount exceeds the transaction amount"}}})
+		}
+		if err == model.ErrAlreadyReversed {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeAlreadyReversed, Message: "Transaction has already been reversed"}}})
+		}
+		if code, ok := walletStatusErrors[err]; ok {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: code, Message: err.Error()}}})
+		}
+		if err == model.ErrInsufficientFunds {
+			return c.JSON(http.StatusUnprocessableEntity,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "Insufficient balance"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseData{Data: transaction})
+}
@@ -2,18 +2,22 @@ package controller
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
-	"github.com/agiledragon/gomonkey/v2"
-	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,8 +36,7 @@ func TestWalletHandler_Create(t *testing.T) {
 	require.NoError(t, err)
 	err = db.Migrate(dbInstance)
 	require.NoError(t, err)
-	repository := repository.NewWalletRepo(dbInstance)
-	service := service.NewWalletService(repository)
+	service := newTestWalletService(t, dbInstance)
 	handler := NewWalletController(service)
 
 	tests := []struct {
@@ -47,7 +50,7 @@ func TestWalletHandler_Create(t *testing.T) {
 			createBody: `{"user_id":"test-user-001", "acnt_type":"user"}`,
 			want: want{
 				StatusCode: http.StatusCreated,
-				Response:   []byte(`{"data":{"user_id":"test-user-001", "acnt_type":"user", "balance":0, "status":"active"}}`),
+				Response:   []byte(`{"data":{"user_id":"test-user-001", "acnt_type":"user", "balance":0, "currency":"USD", "status":"active"}}`),
 			},
 		},
 		{
@@ -55,7 +58,22 @@ func TestWalletHandler_Create(t *testing.T) {
 			createBody: `{"user_id":"test-provider-001", "acnt_type":"provider"}`,
 			want: want{
 				StatusCode: http.StatusCreated,
-				Response:   []byte(`{"data":{"user_id":"test-provider-001", "acnt_type":"provider", "balance":0, "status":"active"}}`),
+				Response:   []byte(`{"data":{"user_id":"test-provider-001", "acnt_type":"provider", "balance":0, "currency":"USD", "status":"active"}}`),
+			},
+		},
+		{
+			name:       "successful_create_wallet_with_currency",
+			createBody: `{"user_id":"test-user-004", "acnt_type":"user", "currency":"EUR"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"user_id":"test-user-004", "acnt_type":"user", "balance":0, "currency":"EUR", "status":"active"}}`),
+			},
+		},
+		{
+			name:       "unsupported_currency",
+			createBody: `{"user_id":"test-user-005", "acnt_type":"user", "currency":"XYZ"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
 			},
 		},
 		{
@@ -91,13 +109,13 @@ func TestWalletHandler_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Clean database before each test
-			clearDB(dbInstance, model.Wallet{})
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{})
 
 			// Prepare
 			req := httptest.NewRequest(http.MethodPost, "/wallets", bytes.NewReader([]byte(tt.createBody)))
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 			rec := httptest.NewRecorder()
-			c := e.NewContext(req, rec)
+			c := inDevMode(e.NewContext(req, rec))
 			c.SetPath("/wallets")
 
 			// Execute
@@ -111,6 +129,17 @@ func TestWalletHandler_Create(t *testing.T) {
 			}
 			got := rec.Body.Bytes()
 
+			// The wallet is identified by its public UUIDv7, not its primary key
+			var created struct {
+				Data struct {
+					ID string `json:"id"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(got, &created))
+			publicID, err := uuid.Parse(created.Data.ID)
+			require.NoError(t, err)
+			assert.Equal(t, uuid.Version(7), publicID.Version())
+
 			opts := []cmp.Option{
 				cmpTransformJSON(t),
 				ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1, "id": 1}),
@@ -135,8 +164,7 @@ func TestWalletHandler_Deposit(t *testing.T) {
 	require.NoError(t, err)
 	err = db.Migrate(dbInstance)
 	require.NoError(t, err)
-	repository := repository.NewWalletRepo(dbInstance)
-	service := service.NewWalletService(repository)
+	service := newTestWalletService(t, dbInstance)
 	handler := NewWalletController(service)
 
 	tests := []struct {
@@ -151,7 +179,7 @@ func TestWalletHandler_Deposit(t *testing.T) {
 			depositBody: `{"user_id":"test-user-001", "amount":5000, "provider_id":"deposit-provider-master"}`,
 			want: want{
 				StatusCode: http.StatusCreated,
-				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":5000, "status":"completed"}}`),
+				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":5000, "currency":"USD", "status":"completed"}}`),
 			},
 		},
 		{
@@ -160,7 +188,33 @@ func TestWalletHandler_Deposit(t *testing.T) {
 			depositBody: `{"user_id":"test-user-001", "amount":3000}`,
 			want: want{
 				StatusCode: http.StatusCreated,
-				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":3000, "status":"completed"}}`),
+				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":3000, "currency":"USD", "status":"completed"}}`),
+			},
+		},
+		{
+			name:        "deposit_with_reference",
+			setupWallet: true,
+			depositBody: `{"user_id":"test-user-001", "amount":2000, "reference":"invoice-42"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":2000, "currency":"USD", "reference":"invoice-42", "status":"completed"}}`),
+			},
+		},
+		{
+			name:        "deposit_in_other_currency",
+			setupWallet: true,
+			depositBody: `{"user_id":"test-user-001", "amount":4000, "currency":"EUR"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":4000, "currency":"EUR", "status":"completed"}}`),
+			},
+		},
+		{
+			name:        "unsupported_currency",
+			setupWallet: true,
+			depositBody: `{"user_id":"test-user-001", "amount":4000, "currency":"XYZ"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
 			},
 		},
 		{
@@ -207,29 +261,15 @@ func TestWalletHandler_Deposit(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Reset client singleton and mock transaction client
-			client.ResetClient()
-			cache.ResetRedisClient()
-			patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
-				return &client.MockTransactionClient{}
-			})
-			redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
-				return cache.NewMockRedisClient()
-			})
-			defer func() {
-				patches.Reset()
-				redisPatches.Reset()
-				client.ResetClient()
-				cache.ResetRedisClient()
-			}()
 
 			// Clean database before each test
-			clearDB(dbInstance, model.Wallet{})
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{})
 
 			// Setup wallet if needed
 			if tt.setupWallet {
 				createTestWallet(t, dbInstance, "test-user-001", model.User)
 				createTestWalletWithBalance(t, dbInstance, "deposit-provider-master", model.Provider, 1000000) // Provider with sufficient balance
+				createTestCurrencyBalance(t, dbInstance, "deposit-provider-master", "EUR", 1000000)
 			}
 			// Always create default provider for tests that might use it
 			if tt.name == "deposit_without_provider_id" && !tt.setupWallet {
@@ -241,7 +281,7 @@ func TestWalletHandler_Deposit(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/wallets/deposit", bytes.NewReader([]byte(tt.depositBody)))
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 			rec := httptest.NewRecorder()
-			c := e.NewContext(req, rec)
+			c := inDevMode(e.NewContext(req, rec))
 			c.SetPath("/wallets/deposit")
 
 			// Execute
@@ -249,6 +289,7 @@ func TestWalletHandler_Deposit(t *testing.T) {
 
 			// Assert
 			assert.Equal(t, tt.want.StatusCode, rec.Code)
+			assertOutboxEntries(t, dbInstance, tt.want.StatusCode == http.StatusCreated)
 
 			if tt.want.Response == nil {
 				return
@@ -257,7 +298,7 @@ func TestWalletHandler_Deposit(t *testing.T) {
 
 			opts := []cmp.Option{
 				cmpTransformJSON(t),
-				ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1, "id": 1}),
+				ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1, "id": 1, "pair_id": 1}),
 			}
 			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
 				t.Errorf("return value mismatch (-got +want):\n%s", diff)
@@ -279,14 +320,15 @@ func TestWalletHandler_Withdraw(t *testing.T) {
 	require.NoError(t, err)
 	err = db.Migrate(dbInstance)
 	require.NoError(t, err)
-	repository := repository.NewWalletRepo(dbInstance)
-	service := service.NewWalletService(repository)
+	service := newTestWalletService(t, dbInstance)
 	handler := NewWalletController(service)
 
 	tests := []struct {
 		name           string
 		setupWallet    bool
 		initialBalance int64
+		walletStatus   model.Status
+		held           int64 // Amount held from the wallet by an active hold
 		withdrawBody   string
 		want           want
 	}{
@@ -297,7 +339,7 @@ func TestWalletHandler_Withdraw(t *testing.T) {
 			withdrawBody:   `{"user_id":"test-user-001", "amount":3000, "provider_id":"withdraw-provider-master"}`,
 			want: want{
 				StatusCode: http.StatusCreated,
-				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"withdraw-provider-master", "transaction_type":"withdraw", "operation_type":"debit", "amount":3000, "status":"completed"}}`),
+				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"withdraw-provider-master", "transaction_type":"withdraw", "operation_type":"debit", "amount":3000, "currency":"USD", "status":"completed"}}`),
 			},
 		},
 		{
@@ -309,6 +351,27 @@ func TestWalletHandler_Withdraw(t *testing.T) {
 				StatusCode: http.StatusUnprocessableEntity,
 			},
 		},
+		{
+			name:           "held_funds_unavailable",
+			setupWallet:    true,
+			initialBalance: 10000,
+			held:           8000,
+			withdrawBody:   `{"user_id":"test-user-001", "amount":3000, "provider_id":"withdraw-provider-master"}`,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+			},
+		},
+		{
+			name:           "suspended_wallet",
+			setupWallet:    true,
+			initialBalance: 10000,
+			walletStatus:   model.Suspended,
+			withdrawBody:   `{"user_id":"test-user-001", "amount":3000, "provider_id":"withdraw-provider-master"}`,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+				Response:   []byte(`{"errors":[{"code":"WALLET_SUSPENDED", "message":"wallet is suspended"}]}`),
+			},
+		},
 		{
 			name:         "missing_user_id",
 			setupWallet:  false,
@@ -347,36 +410,27 @@ func TestWalletHandler_Withdraw(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Reset client singleton and mock transaction client
-			client.ResetClient()
-			cache.ResetRedisClient()
-			patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
-				return &client.MockTransactionClient{}
-			})
-			redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
-				return cache.NewMockRedisClient()
-			})
-			defer func() {
-				patches.Reset()
-				redisPatches.Reset()
-				client.ResetClient()
-				cache.ResetRedisClient()
-			}()
 
 			// Clean database before each test
-			clearDB(dbInstance, model.Wallet{})
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.Hold{})
 
 			// Setup wallet if needed
 			if tt.setupWallet {
 				createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, tt.initialBalance)
 				createTestWalletWithBalance(t, dbInstance, "withdraw-provider-master", model.Provider, 1000000) // Provider with sufficient balance
+				if tt.walletStatus != "" {
+					setTestWalletStatus(t, dbInstance, "test-user-001", tt.walletStatus)
+				}
+				if tt.held != 0 {
+					createTestHold(t, dbInstance, tt.held, model.HoldActive, time.Now().Add(time.Hour))
+				}
 			}
 
 			// Prepare
 			req := httptest.NewRequest(http.MethodPost, "/wallets/withdraw", bytes.NewReader([]byte(tt.withdrawBody)))
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 			rec := httptest.NewRecorder()
-			c := e.NewContext(req, rec)
+			c := inDevMode(e.NewContext(req, rec))
 			c.SetPath("/wallets/withdraw")
 
 			// Execute
@@ -384,6 +438,7 @@ func TestWalletHandler_Withdraw(t *testing.T) {
 
 			// Assert
 			assert.Equal(t, tt.want.StatusCode, rec.Code)
+			assertOutboxEntries(t, dbInstance, tt.want.StatusCode == http.StatusCreated)
 
 			if tt.want.Response == nil {
 				return
@@ -392,7 +447,7 @@ func TestWalletHandler_Withdraw(t *testing.T) {
 
 			opts := []cmp.Option{
 				cmpTransformJSON(t),
-				ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1, "id": 1}),
+				ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1, "id": 1, "pair_id": 1}),
 			}
 			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
 				t.Errorf("return value mismatch (-got +want):\n%s", diff)
@@ -414,8 +469,7 @@ func TestWalletHandler_Transfer(t *testing.T) {
 	require.NoError(t, err)
 	err = db.Migrate(dbInstance)
 	require.NoError(t, err)
-	repository := repository.NewWalletRepo(dbInstance)
-	service := service.NewWalletService(repository)
+	service := newTestWalletService(t, dbInstance)
 	handler := NewWalletController(service)
 
 	tests := []struct {
@@ -423,6 +477,7 @@ func TestWalletHandler_Transfer(t *testing.T) {
 		setupWallets bool
 		fromBalance  int64
 		toBalance    int64
+		toStatus     model.Status
 		transferBody string
 		want         want
 	}{
@@ -434,7 +489,7 @@ func TestWalletHandler_Transfer(t *testing.T) {
 			transferBody: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":3000}`,
 			want: want{
 				StatusCode: http.StatusCreated,
-				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"test-user-002", "transaction_type":"transfer", "operation_type":"debit", "amount":3000, "status":"completed"}}`),
+				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"test-user-002", "transaction_type":"transfer", "operation_type":"debit", "amount":3000, "currency":"USD", "status":"completed"}}`),
 			},
 		},
 		{
@@ -447,6 +502,48 @@ func TestWalletHandler_Transfer(t *testing.T) {
 				StatusCode: http.StatusUnprocessableEntity,
 			},
 		},
+		{
+			name:         "cross_currency_transfer",
+			setupWallets: true,
+			fromBalance:  10000,
+			toBalance:    5000,
+			transferBody: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":3000, "currency":"USD", "to_currency":"EUR"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"test-user-002", "transaction_type":"transfer", "operation_type":"debit", "amount":3000, "currency":"USD", "fx_rate":"0.92000000", "counter_amount":2760, "counter_currency":"EUR", "status":"completed"}}`),
+			},
+		},
+		{
+			name:         "unknown_quote",
+			setupWallets: true,
+			fromBalance:  10000,
+			toBalance:    5000,
+			transferBody: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":3000, "to_currency":"EUR", "quote_id":"unknown"}`,
+			want: want{
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name:         "insufficient_funds_in_currency",
+			setupWallets: true,
+			fromBalance:  10000,
+			toBalance:    5000,
+			transferBody: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":3000, "currency":"EUR"}`,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+			},
+		},
+		{
+			name:         "receiver_closed",
+			setupWallets: true,
+			fromBalance:  10000,
+			toStatus:     model.Closed,
+			transferBody: `{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":3000}`,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+				Response:   []byte(`{"errors":[{"code":"WALLET_CLOSED", "message":"wallet is closed"}]}`),
+			},
+		},
 		{
 			name:         "transfer_to_same_wallet",
 			setupWallets: true,
@@ -505,24 +602,9 @@ func TestWalletHandler_Transfer(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Reset client singleton and mock transaction client
-			client.ResetClient()
-			cache.ResetRedisClient()
-			patches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
-				return &client.MockTransactionClient{}
-			})
-			redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
-				return cache.NewMockRedisClient()
-			})
-			defer func() {
-				patches.Reset()
-				redisPatches.Reset()
-				client.ResetClient()
-				cache.ResetRedisClient()
-			}()
 
 			// Clean database before each test
-			clearDB(dbInstance, model.Wallet{})
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.FXQuote{})
 
 			// Setup wallets if needed
 			if tt.setupWallets {
@@ -530,13 +612,16 @@ func TestWalletHandler_Transfer(t *testing.T) {
 				if tt.name != "transfer_to_same_wallet" {
 					createTestWalletWithBalance(t, dbInstance, "test-user-002", model.User, tt.toBalance)
 				}
+				if tt.toStatus != "" {
+					setTestWalletStatus(t, dbInstance, "test-user-002", tt.toStatus)
+				}
 			}
 
 			// Prepare
 			req := httptest.NewRequest(http.MethodPost, "/wallets/transfer", bytes.NewReader([]byte(tt.transferBody)))
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 			rec := httptest.NewRecorder()
-			c := e.NewContext(req, rec)
+			c := inDevMode(e.NewContext(req, rec))
 			c.SetPath("/wallets/transfer")
 
 			// Execute
@@ -544,6 +629,7 @@ func TestWalletHandler_Transfer(t *testing.T) {
 
 			// Assert
 			assert.Equal(t, tt.want.StatusCode, rec.Code)
+			assertOutboxEntries(t, dbInstance, tt.want.StatusCode == http.StatusCreated)
 
 			if tt.want.Response == nil {
 				return
@@ -552,7 +638,7 @@ func TestWalletHandler_Transfer(t *testing.T) {
 
 			opts := []cmp.Option{
 				cmpTransformJSON(t),
-				ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1, "id": 1}),
+				ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1, "id": 1, "pair_id": 1}),
 			}
 			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
 				t.Errorf("return value mismatch (-got +want):\n%s", diff)
@@ -562,6 +648,68 @@ func TestWalletHandler_Transfer(t *testing.T) {
 	}
 }
 
+func TestWalletHandler_TransferWithQuote(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	quoteRepo := repository.NewFXQuoteRepo(dbInstance)
+	service := newTestWalletService(t, dbInstance)
+	handler := NewWalletController(service)
+
+	clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.FXQuote{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
+	createTestWalletWithBalance(t, dbInstance, "test-user-002", model.User, 0)
+
+	// A quote locked at a rate different from the current one
+	quote, err := model.NewFXQuote("USD", "EUR", 3000, big.NewRat(9, 10), time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, quoteRepo.Create(context.Background(), quote))
+	expired, err := model.NewFXQuote("USD", "EUR", 3000, big.NewRat(9, 10), -time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, quoteRepo.Create(context.Background(), expired))
+
+	transfer := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/wallets/transfer", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := inDevMode(e.NewContext(req, rec))
+		c.SetPath("/wallets/transfer")
+		require.NoError(t, handler.Transfer(c))
+		return rec
+	}
+	body := func(quoteID string, amount int) string {
+		return fmt.Sprintf(`{"from_user_id":"test-user-001", "to_user_id":"test-user-002", "amount":%d, "to_currency":"EUR", "quote_id":%q}`, amount, quoteID)
+	}
+
+	rec := transfer(body(expired.ID, 3000))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = transfer(body(quote.ID, 2000))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = transfer(body(quote.ID, 3000))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	want := []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"test-user-002", "transaction_type":"transfer", "operation_type":"debit", "amount":3000, "currency":"USD", "fx_rate":"0.90000000", "counter_amount":2700, "counter_currency":"EUR", "status":"completed"}}`)
+	opts := []cmp.Option{
+		cmpTransformJSON(t),
+		ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1, "id": 1, "pair_id": 1}),
+	}
+	if diff := cmp.Diff(rec.Body.Bytes(), want, opts...); diff != "" {
+		t.Errorf("return value mismatch (-got +want):\n%s", diff)
+	}
+
+	receiver, err := repository.NewWalletRepo(dbInstance).FindByUserID(context.Background(), "test-user-002")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2700), receiver.BalanceIn("EUR"))
+
+	// A quote can only be used once
+	rec = transfer(body(quote.ID, 3000))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
 func TestWalletHandler_Find(t *testing.T) {
 	type want struct {
 		StatusCode int
@@ -574,14 +722,12 @@ func TestWalletHandler_Find(t *testing.T) {
 	require.NoError(t, err)
 	err = db.Migrate(dbInstance)
 	require.NoError(t, err)
-	repository := repository.NewWalletRepo(dbInstance)
-	service := service.NewWalletService(repository)
-	handler := NewWalletController(service)
 
 	// Test the mock directly to ensure it's working as expected
 	mockClient := &client.MockTransactionClient{}
-	txns, err := mockClient.FetchTransactions("test-user-001")
+	page, err := mockClient.FetchTransactions(context.Background(), "test-user-001", model.TransactionQuery{})
 	require.NoError(t, err)
+	txns := page.Transactions
 	require.Len(t, txns, 2, "Expected 2 transactions from mock")
 	require.Equal(t, "test-user-001", txns[0].SubjectWalletID)
 	require.Equal(t, model.Deposit, txns[0].TransactionType)
@@ -590,6 +736,10 @@ func TestWalletHandler_Find(t *testing.T) {
 		name        string
 		setupWallet bool
 		userID      string
+		query       string
+		txnErr      error
+		held        int64 // Amount held from test-user-001
+		reviewing   int64 // Amount of a transfer of test-user-001 waiting for review
 		want        want
 	}{
 		{
@@ -598,7 +748,57 @@ func TestWalletHandler_Find(t *testing.T) {
 			userID:      "test-user-001",
 			want: want{
 				StatusCode: http.StatusOK,
-				Response:   []byte(`{"data":{"wallet":{"balance":10000, "acnt_type":"user", "status":"active"}, "transactions":[{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":5000, "status":"completed"}, {"subject_wallet_id":"test-user-001", "object_wallet_id":"withdraw-provider-master", "transaction_type":"withdraw", "operation_type":"debit", "amount":2000, "status":"completed"}]}}`),
+				Response:   []byte(`{"data":{"wallet":{"balance":10000, "available_balance":10000, "currency":"USD", "acnt_type":"user", "status":"active"}, "transactions":[{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":5000, "currency":"USD", "status":"completed"}, {"subject_wallet_id":"test-user-001", "object_wallet_id":"withdraw-provider-master", "transaction_type":"withdraw", "operation_type":"debit", "amount":2000, "currency":"USD", "status":"completed"}]}}`),
+			},
+		},
+		{
+			name:        "reserved_funds",
+			setupWallet: true,
+			userID:      "test-user-001",
+			held:        1500,
+			reviewing:   2500,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"wallet":{"balance":10000, "available_balance":6000, "currency":"USD", "acnt_type":"user", "status":"active"}, "transactions":[{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":5000, "currency":"USD", "status":"completed"}, {"subject_wallet_id":"test-user-001", "object_wallet_id":"withdraw-provider-master", "transaction_type":"withdraw", "operation_type":"debit", "amount":2000, "currency":"USD", "status":"completed"}]}}`),
+			},
+		},
+		{
+			name:        "filtered_find",
+			setupWallet: true,
+			userID:      "test-user-001",
+			query:       "?transaction_type=withdraw",
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"wallet":{"balance":10000, "available_balance":10000, "currency":"USD", "acnt_type":"user", "status":"active"}, "transactions":[{"subject_wallet_id":"test-user-001", "object_wallet_id":"withdraw-provider-master", "transaction_type":"withdraw", "operation_type":"debit", "amount":2000, "currency":"USD", "status":"completed"}]}}`),
+			},
+		},
+		{
+			name:        "paginated_find",
+			setupWallet: true,
+			userID:      "test-user-001",
+			query:       "?limit=1",
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"wallet":{"balance":10000, "available_balance":10000, "currency":"USD", "acnt_type":"user", "status":"active"}, "transactions":[{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"credit", "amount":5000, "currency":"USD", "status":"completed"}], "next_cursor":"mock-cursor"}}`),
+			},
+		},
+		{
+			name:        "transactions_unavailable",
+			setupWallet: true,
+			userID:      "test-user-001",
+			txnErr:      fmt.Errorf("%w: circuit breaker is open", model.ErrTransactionsUnavailable),
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"wallet":{"balance":10000, "available_balance":10000, "currency":"USD", "acnt_type":"user", "status":"active"}, "transactions":[], "transactions_unavailable":true}}`),
+			},
+		},
+		{
+			name:        "invalid_filter",
+			setupWallet: true,
+			userID:      "test-user-001",
+			query:       "?transaction_type=refund",
+			want: want{
+				StatusCode: http.StatusBadRequest,
 			},
 		},
 		{
@@ -621,37 +821,28 @@ func TestWalletHandler_Find(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Reset client singletons and mock both transaction and Redis clients
-			client.ResetClient()
-			cache.ResetRedisClient()
-
-			// Mock transaction client
-			txnPatches := gomonkey.ApplyFunc(client.NewTxnClient, func() client.NewTransaction {
-				return &client.MockTransactionClient{}
+			// Every case starts with an empty cache and its own transaction service
+			service := newTestWalletService(t, dbInstance, func(opts *service.WalletServiceOpts) {
+				opts.TxnClient = &client.MockTransactionClient{FetchErr: tt.txnErr}
 			})
-
-			// Mock Redis client
-			redisPatches := gomonkey.ApplyFunc(cache.NewRedisClient, func() cache.RedisClient {
-				return cache.NewMockRedisClient()
-			})
-
-			defer func() {
-				txnPatches.Reset()
-				redisPatches.Reset()
-				client.ResetClient()
-				cache.ResetRedisClient()
-			}()
+			handler := NewWalletController(service)
 
 			// Clean database before each test
-			clearDB(dbInstance, model.Wallet{})
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.Hold{}, model.Review{})
 
 			// Setup wallet if needed
 			if tt.setupWallet {
 				createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 10000)
 			}
+			if tt.held != 0 {
+				createTestHold(t, dbInstance, tt.held, model.HoldActive, time.Now().Add(time.Hour))
+			}
+			if tt.reviewing != 0 {
+				createTestReview(t, dbInstance, "test-user-001", "test-user-002", tt.reviewing, model.ReviewPending)
+			}
 
 			// Prepare
-			req := httptest.NewRequest(http.MethodGet, "/wallets/"+tt.userID, nil)
+			req := httptest.NewRequest(http.MethodGet, "/wallets/"+tt.userID+tt.query, nil)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
@@ -682,6 +873,549 @@ func TestWalletHandler_Find(t *testing.T) {
 	}
 }
 
+func TestWalletHandler_UpdateStatus(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	service := newTestWalletService(t, dbInstance)
+	handler := NewWalletController(service)
+
+	tests := []struct {
+		name          string
+		userID        string
+		balance       int64
+		eurBalance    int64
+		initialStatus model.Status
+		held          int64 // Amount of an active hold of test-user-001
+		reviewing     int64 // Amount of a pending review of test-user-001
+		body          string
+		want          want
+		wantSwept     bool
+	}{
+		{
+			name:   "suspend",
+			userID: "test-user-001",
+			body:   `{"status":"suspended", "reason":"chargeback investigation"}`,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"user_id":"test-user-001", "acnt_type":"user", "currency":"USD", "balance":0, "status":"suspended", "status_reason":"chargeback investigation"}}`),
+			},
+		},
+		{
+			name:          "reactivate",
+			userID:        "test-user-001",
+			initialStatus: model.Suspended,
+			body:          `{"status":"active", "reason":"investigation closed"}`,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"user_id":"test-user-001", "acnt_type":"user", "currency":"USD", "balance":0, "status":"active", "status_reason":"investigation closed"}}`),
+			},
+		},
+		{
+			name:   "close_empty_wallet",
+			userID: "test-user-001",
+			body:   `{"status":"closed", "reason":"customer request"}`,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"user_id":"test-user-001", "acnt_type":"user", "currency":"USD", "balance":0, "status":"closed", "status_reason":"customer request"}}`),
+			},
+		},
+		{
+			name:    "close_with_balance",
+			userID:  "test-user-001",
+			balance: 5000,
+			body:    `{"status":"closed", "reason":"customer request"}`,
+			want: want{
+				StatusCode: http.StatusConflict,
+				Response:   []byte(`{"errors":[{"code":"WALLET_NOT_EMPTY", "message":"Wallet balance must be zero or swept to another wallet"}]}`),
+			},
+		},
+		{
+			name:          "close_suspended_with_sweep",
+			userID:        "test-user-001",
+			balance:       5000,
+			eurBalance:    2000,
+			initialStatus: model.Suspended,
+			body:          `{"status":"closed", "reason":"fraud confirmed", "sweep_to_user_id":"test-user-002"}`,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"user_id":"test-user-001", "acnt_type":"user", "currency":"USD", "balance":0, "balances":[{"currency":"EUR", "balance":0}], "status":"closed", "status_reason":"fraud confirmed"}}`),
+			},
+			wantSwept: true,
+		},
+		{
+			name:    "close_with_active_hold",
+			userID:  "test-user-001",
+			balance: 5000,
+			held:    2000,
+			body:    `{"status":"closed", "reason":"customer request", "sweep_to_user_id":"test-user-002"}`,
+			want: want{
+				StatusCode: http.StatusConflict,
+				Response:   []byte(`{"errors":[{"code":"FUNDS_RESERVED", "message":"Wallet funds are reserved by active holds or pending reviews, capture or void the holds and decide the reviews first"}]}`),
+			},
+		},
+		{
+			name:      "close_with_pending_review",
+			userID:    "test-user-001",
+			balance:   5000,
+			reviewing: 2000,
+			body:      `{"status":"closed", "reason":"customer request", "sweep_to_user_id":"test-user-002"}`,
+			want: want{
+				StatusCode: http.StatusConflict,
+				Response:   []byte(`{"errors":[{"code":"FUNDS_RESERVED", "message":"Wallet funds are reserved by active holds or pending reviews, capture or void the holds and decide the reviews first"}]}`),
+			},
+		},
+		{
+			name:          "reopen_closed_wallet",
+			userID:        "test-user-001",
+			initialStatus: model.Closed,
+			body:          `{"status":"active", "reason":"customer request"}`,
+			want: want{
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name:   "close_provider_wallet",
+			userID: "deposit-provider-master",
+			body:   `{"status":"closed", "reason":"decommissioned"}`,
+			want: want{
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name:   "sweep_without_closing",
+			userID: "test-user-001",
+			body:   `{"status":"suspended", "reason":"review", "sweep_to_user_id":"test-user-002"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:   "invalid_status",
+			userID: "test-user-001",
+			body:   `{"status":"frozen", "reason":"review"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:   "missing_reason",
+			userID: "test-user-001",
+			body:   `{"status":"suspended"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:   "wallet_not_found",
+			userID: "non-existent-user",
+			body:   `{"status":"suspended", "reason":"review"}`,
+			want: want{
+				StatusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Clean database before each test
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.Hold{}, model.Review{})
+
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, tt.balance)
+			createTestWallet(t, dbInstance, "test-user-002", model.User)
+			createTestWallet(t, dbInstance, "deposit-provider-master", model.Provider)
+			if tt.eurBalance != 0 {
+				createTestCurrencyBalance(t, dbInstance, "test-user-001", "EUR", tt.eurBalance)
+			}
+			if tt.held != 0 {
+				createTestHold(t, dbInstance, tt.held, model.HoldActive, time.Now().Add(time.Hour))
+			}
+			if tt.reviewing != 0 {
+				createTestReview(t, dbInstance, "test-user-001", "test-user-002", tt.reviewing, model.ReviewPending)
+			}
+			if tt.initialStatus != "" {
+				setTestWalletStatus(t, dbInstance, "test-user-001", tt.initialStatus)
+			}
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPatch, "/wallets/"+tt.userID+"/status", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/:user_id/status")
+			c.SetParamNames("user_id")
+			c.SetParamValues(tt.userID)
+
+			// Execute
+			require.NoError(t, handler.UpdateStatus(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			// Every swept currency is recorded as a transaction pair
+			var entries int64
+			require.NoError(t, dbInstance.Model(&model.OutboxEntry{}).Count(&entries).Error)
+			if tt.wantSwept {
+				assert.Equal(t, int64(2), entries)
+				var receiver model.Wallet
+				require.NoError(t, dbInstance.Preload("Balances").Where("user_id = ?", "test-user-002").First(&receiver).Error)
+				assert.Equal(t, tt.balance, receiver.Balance)
+				assert.Equal(t, tt.eurBalance, receiver.BalanceIn("EUR"))
+			} else {
+				assert.Zero(t, entries)
+			}
+
+			if tt.want.Response == nil {
+				return
+			}
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1, "id": 1, "status_changed_at": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestWalletHandler_Reverse(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	service := newTestWalletService(t, dbInstance)
+	handler := NewWalletController(service)
+
+	// Transactions 1 to 5 are returned by the mock transaction client, 1 and 5 are the legs of one transfer
+	tests := []struct {
+		name             string
+		transactionID    string
+		body             string
+		subjectBalance   int64
+		objectBalance    int64
+		objectEURBalance int64
+		objectStatus     model.Status
+		reversed         bool
+		spentDaysAgo     int // Days before the ledger time on which the wallet service counted the transfer as spent
+		want             want
+		wantSubject      int64 // Balance of test-user-001 after the request
+		wantSpent        int64 // Spending usage of test-user-001 after the request, which spent 3000
+	}{
+		{
+			name:           "full_transfer_reversal",
+			transactionID:  "01890a5d-ac96-774b-bcce-b302099a8001",
+			subjectBalance: 7000,
+			objectBalance:  3000,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"test-user-002", "transaction_type":"transfer", "operation_type":"credit", "amount":3000, "currency":"USD", "reversal_of":"01890a5d-ac96-774b-bcce-b302099a8001", "status":"completed"}}`),
+			},
+			wantSubject: 10000,
+			wantSpent:   0,
+		},
+		{
+			name:           "transfer_spent_before_ledger_day",
+			transactionID:  "01890a5d-ac96-774b-bcce-b302099a8001",
+			subjectBalance: 7000,
+			objectBalance:  3000,
+			spentDaysAgo:   1,
+			want: want{
+				StatusCode: http.StatusCreated,
+			},
+			wantSubject: 10000,
+			wantSpent:   0,
+		},
+		{
+			name:           "partial_deposit_refund",
+			transactionID:  "01890a5d-ac96-774b-bcce-b302099a8002",
+			body:           `{"amount":2000}`,
+			subjectBalance: 5000,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"deposit-provider-master", "transaction_type":"deposit", "operation_type":"debit", "amount":2000, "currency":"USD", "reversal_of":"01890a5d-ac96-774b-bcce-b302099a8002", "status":"completed"}}`),
+			},
+			wantSubject: 3000,
+			wantSpent:   3000,
+		},
+		{
+			name:             "conversion_reversal",
+			transactionID:    "01890a5d-ac96-774b-bcce-b302099a8003",
+			subjectBalance:   7000,
+			objectEURBalance: 2760,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"subject_wallet_id":"test-user-001", "object_wallet_id":"test-user-002", "transaction_type":"transfer", "operation_type":"credit", "amount":3000, "currency":"USD", "fx_rate":"1.08695652", "counter_amount":2760, "counter_currency":"EUR", "reversal_of":"01890a5d-ac96-774b-bcce-b302099a8003", "status":"completed"}}`),
+			},
+			wantSubject: 10000,
+			wantSpent:   0,
+		},
+		{
+			name:           "already_reversed",
+			transactionID:  "01890a5d-ac96-774b-bcce-b302099a8001",
+			subjectBalance: 7000,
+			objectBalance:  3000,
+			reversed:       true,
+			want: want{
+				StatusCode: http.StatusConflict,
+				Response:   []byte(`{"errors":[{"code":"ALREADY_REVERSED", "message":"Transaction has already been reversed"}]}`),
+			},
+			wantSubject: 7000,
+			wantSpent:   3000,
+		},
+		{
+			name:           "other_leg_already_reversed",
+			transactionID:  "01890a5d-ac96-774b-bcce-b302099a8005",
+			subjectBalance: 7000,
+			objectBalance:  3000,
+			reversed:       true,
+			want: want{
+				StatusCode: http.StatusConflict,
+				Response:   []byte(`{"errors":[{"code":"ALREADY_REVERSED", "message":"Transaction has already been reversed"}]}`),
+			},
+			wantSubject: 7000,
+			wantSpent:   3000,
+		},
+		{
+			name:           "amount_exceeds_transaction",
+			transactionID:  "01890a5d-ac96-774b-bcce-b302099a8001",
+			body:           `{"amount":3001}`,
+			subjectBalance: 7000,
+			objectBalance:  3000,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+			},
+			wantSubject: 7000,
+			wantSpent:   3000,
+		},
+		{
+			name:           "insufficient_funds",
+			transactionID:  "01890a5d-ac96-774b-bcce-b302099a8001",
+			subjectBalance: 7000,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+			},
+			wantSubject: 7000,
+			wantSpent:   3000,
+		},
+		{
+			name:           "object_wallet_suspended",
+			transactionID:  "01890a5d-ac96-774b-bcce-b302099a8001",
+			subjectBalance: 7000,
+			objectBalance:  3000,
+			objectStatus:   model.Suspended,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+			},
+			wantSubject: 7000,
+			wantSpent:   3000,
+		},
+		{
+			name:           "pending_transaction",
+			transactionID:  "01890a5d-ac96-774b-bcce-b302099a8004",
+			subjectBalance: 7000,
+			objectBalance:  3000,
+			want: want{
+				StatusCode: http.StatusUnprocessableEntity,
+			},
+			wantSubject: 7000,
+			wantSpent:   3000,
+		},
+		{
+			name:           "transaction_not_found",
+			transactionID:  "01890a5d-ac96-774b-bcce-b302099a8099",
+			subjectBalance: 7000,
+			want: want{
+				StatusCode: http.StatusNotFound,
+			},
+			wantSubject: 7000,
+			wantSpent:   3000,
+		},
+		{
+			name:           "invalid_transaction_id",
+			transactionID:  "abc",
+			subjectBalance: 7000,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+			wantSubject: 7000,
+			wantSpent:   3000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Clean database before each test
+			clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.Reversal{}, model.SpendingUsage{}, model.SpendingRecord{})
+
+			createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, tt.subjectBalance)
+			createTestWalletWithBalance(t, dbInstance, "test-user-002", model.User, tt.objectBalance)
+			createTestWalletWithBalance(t, dbInstance, "deposit-provider-master", model.Provider, 0)
+			if tt.objectEURBalance != 0 {
+				createTestCurrencyBalance(t, dbInstance, "test-user-002", "EUR", tt.objectEURBalance)
+			}
+			if tt.objectStatus != "" {
+				setTestWalletStatus(t, dbInstance, "test-user-002", tt.objectStatus)
+			}
+			spentOn := model.DayStart(time.Now().AddDate(0, 0, -tt.spentDaysAgo))
+			require.NoError(t, dbInstance.Create(&model.SpendingUsage{UserID: "test-user-001", Currency: model.DefaultCurrency, Day: spentOn, Amount: 3000, Count: 1}).Error)
+			if tt.spentDaysAgo != 0 {
+				require.NoError(t, dbInstance.Create(&model.SpendingRecord{PairID: "3b5f0c1e-8a2d-4c7e-9f41-2d6b8e0a7c15", UserID: "test-user-001", Day: spentOn}).Error)
+			}
+			if tt.reversed {
+				pairID := "3b5f0c1e-8a2d-4c7e-9f41-2d6b8e0a7c15"
+				require.NoError(t, dbInstance.Create(&model.Reversal{TransactionID: "01890a5d-ac96-774b-bcce-b302099a8001", PairID: &pairID, Amount: 3000, Currency: "USD"}).Error)
+			}
+
+			// Prepare
+			req := httptest.NewRequest(http.MethodPost, "/wallets/transactions/"+tt.transactionID+"/reverse", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/wallets/transactions/:id/reverse")
+			c.SetParamNames("id")
+			c.SetParamValues(tt.transactionID)
+
+			// Execute
+			require.NoError(t, handler.Reverse(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+			assertOutboxEntries(t, dbInstance, tt.want.StatusCode == http.StatusCreated)
+
+			var subject model.Wallet
+			require.NoError(t, dbInstance.Where("user_id = ?", "test-user-001").First(&subject).Error)
+			assert.Equal(t, tt.wantSubject, subject.Balance)
+
+			// A reversed withdrawal or transfer no longer counts against the sender's limit
+			var usage model.SpendingUsage
+			require.NoError(t, dbInstance.Where("user_id = ?", "test-user-001").Take(&usage).Error)
+			assert.Equal(t, tt.wantSpent, usage.Amount)
+
+			if tt.want.Response == nil {
+				return
+			}
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"created_at": 1, "updated_at": 1, "id": 1, "pair_id": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestWalletHandler_ReversePairLegs(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	handler := NewWalletController(newTestWalletService(t, dbInstance))
+
+	clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{}, model.Reversal{}, model.SpendingUsage{})
+	createTestWalletWithBalance(t, dbInstance, "test-user-001", model.User, 7000)
+	createTestWalletWithBalance(t, dbInstance, "test-user-002", model.User, 3000)
+
+	reverse := func(transactionID string) int {
+		req := httptest.NewRequest(http.MethodPost, "/wallets/transactions/"+transactionID+"/reverse", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/wallets/transactions/:id/reverse")
+		c.SetParamNames("id")
+		c.SetParamValues(transactionID)
+		require.NoError(t, handler.Reverse(c))
+		return rec.Code
+	}
+
+	// The debit and the credit of the transfer move the same funds, which are moved back once
+	assert.Equal(t, http.StatusCreated, reverse("01890a5d-ac96-774b-bcce-b302099a8001"))
+	assert.Equal(t, http.StatusConflict, reverse("01890a5d-ac96-774b-bcce-b302099a8005"))
+
+	for userID, want := range map[string]int64{"test-user-001": 10000, "test-user-002": 0} {
+		var wallet model.Wallet
+		require.NoError(t, dbInstance.Where("user_id = ?", userID).Take(&wallet).Error)
+		assert.Equal(t, want, wallet.Balance, userID)
+	}
+	var entries int64
+	require.NoError(t, dbInstance.Model(&model.OutboxEntry{}).Count(&entries).Error)
+	assert.Equal(t, int64(1), entries)
+}
+
+func TestWalletHandler_DepositAudit(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewTestDB()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	auditRepo := repository.NewAuditRepo(dbInstance)
+	walletService := newTestWalletService(t, dbInstance)
+	handler := NewWalletController(walletService)
+
+	clearDB(dbInstance, model.WalletBalance{}, model.Wallet{}, model.OutboxEntry{})
+	createTestWallet(t, dbInstance, "test-user-001", model.User)
+	createTestWalletWithBalance(t, dbInstance, "deposit-provider-master", model.Provider, 1000000)
+
+	requestID := uuid.NewString()
+	req := httptest.NewRequest(http.MethodPost, "/wallets/deposit", bytes.NewReader([]byte(`{"user_id":"test-user-001", "amount":5000}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderXRequestID, requestID)
+	rec := httptest.NewRecorder()
+	c := inDevMode(e.NewContext(req, rec))
+	require.NoError(t, handler.Deposit(c))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	// Both sides of the deposit are recorded with the balances before and after
+	var entries []model.AuditEntry
+	require.NoError(t, dbInstance.Where("request_id = ?", requestID).Order("id").Find(&entries).Error)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, model.AuditBalanceChanged, entry.Action)
+		assert.Equal(t, "user:test-user-001", entry.Actor)
+		assert.Equal(t, model.DefaultCurrency, entry.Currency)
+	}
+	assert.Equal(t, "deposit-provider-master", entries[0].UserID)
+	assert.Equal(t, int64(1000000), *entries[0].BalanceBefore)
+	assert.Equal(t, int64(995000), *entries[0].BalanceAfter)
+	assert.Equal(t, "test-user-001", entries[1].UserID)
+	assert.Equal(t, int64(0), *entries[1].BalanceBefore)
+	assert.Equal(t, int64(5000), *entries[1].BalanceAfter)
+	assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+
+	// The log cannot be changed in place
+	assert.Error(t, dbInstance.Model(&model.AuditEntry{}).Where("id = ?", entries[1].ID).Update("balance_after", 500000).Error)
+
+	result, err := service.NewAuditService(auditRepo).Verify(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, result.BrokenAt, result.Reason)
+}
+
 // Helper functions
 func clearDB(db *gorm.DB, models ...interface{}) {
 	for _, model := range models {
@@ -701,3 +1435,33 @@ func createTestWalletWithBalance(t *testing.T, db *gorm.DB, userID string, acntT
 	err := db.Create(wallet).Error
 	require.NoError(t, err)
 }
+
+func createTestCurrencyBalance(t *testing.T, db *gorm.DB, userID string, currency model.Currency, balance int64) {
+	var wallet model.Wallet
+	require.NoError(t, db.Where("user_id = ?", userID).First(&wallet).Error)
+	err := db.Create(&model.WalletBalance{WalletID: wallet.ID, Currency: currency, Balance: balance}).Error
+	require.NoError(t, err)
+}
+
+func setTestWalletStatus(t *testing.T, db *gorm.DB, userID string, status model.Status) {
+	err := db.Model(&model.Wallet{}).Where("user_id = ?", userID).Update("status", status).Error
+	require.NoError(t, err)
+}
+
+// assertOutboxEntries checks that a successful money movement enqueued exactly one
+// transaction pair for the relay, and that a failed one enqueued nothing.
+func assertOutboxEntries(t *testing.T, db *gorm.DB, wantEntry bool) {
+	var entries []model.OutboxEntry
+	require.NoError(t, db.Where("status = ?", model.OutboxPending).Find(&entries).Error)
+	if !wantEntry {
+		assert.Empty(t, entries)
+		return
+	}
+	require.Len(t, entries, 1)
+
+	// Both transactions of the pair are linked by the same pair ID
+	pair, err := entries[0].Pair()
+	require.NoError(t, err)
+	assert.NotEmpty(t, pair.Debit.PairID)
+	assert.Equal(t, pair.Debit.PairID, pair.Credit.PairID)
+}
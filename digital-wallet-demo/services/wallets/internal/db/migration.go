@@ -16,7 +16,7 @@ import (
 // It performs DDL migrations (schema) followed by DML migrations (data)
 func Migrate(db *gorm.DB) error {
 	// Step 1: Run GORM auto-migration for schema creation
-	if err := db.AutoMigrate(&model.Wallet{}); err != nil {
+	if err := db.AutoMigrate(&model.Wallet{}, &model.WalletBalance{}, &model.OutboxEntry{}, &model.IdempotencyRecord{}, &model.FXQuote{}, &model.Reversal{}, &model.Hold{}, &model.Schedule{}, &model.SpendingLimit{}, &model.SpendingUsage{}, &model.SpendingRecord{}, &model.RiskDecision{}, &model.Review{}, &model.ReviewFlag{}, &model.AuditEntry{}); err != nil {
 		fmt.Printf("ERROR: Auto-migration failed: %v\n", err)
 		return fmt.Errorf("failed to run auto-migration: %w", err)
 	}
@@ -10,4 +10,54 @@ const (
 	CodeNotFound = "NOT_FOUND"
 	// CodeBadRequest is a generic error message returned when the request is bad.
 	CodeBadRequest = "BAD_REQUEST"
+	// CodeUnauthorized is returned when a request has no valid bearer token.
+	CodeUnauthorized = "UNAUTHORIZED"
+	// CodeForbidden is returned when the caller may not act on the requested wallet or endpoint.
+	CodeForbidden = "FORBIDDEN"
+	// CodeIdempotencyKeyReused is returned when an Idempotency-Key is replayed with a different request.
+	CodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	// CodeRequestInProgress is returned when a request with the same Idempotency-Key is still being processed.
+	CodeRequestInProgress = "REQUEST_IN_PROGRESS"
+	// CodeRateUnavailable is returned when no exchange rate is available for a currency pair.
+	CodeRateUnavailable = "RATE_UNAVAILABLE"
+	// CodeQuoteExpired is returned when a transfer redeems a quote after its expiry.
+	CodeQuoteExpired = "QUOTE_EXPIRED"
+	// CodeQuoteUsed is returned when a transfer redeems a quote that was already used.
+	CodeQuoteUsed = "QUOTE_ALREADY_USED"
+	// CodeQuoteMismatch is returned when the currencies or amount of a transfer differ from its quote.
+	CodeQuoteMismatch = "QUOTE_MISMATCH"
+	// CodeWalletInactive is returned when funds are moved to or from an inactive wallet.
+	CodeWalletInactive = "WALLET_INACTIVE"
+	// CodeWalletSuspended is returned when funds are moved to or from a suspended wallet.
+	CodeWalletSuspended = "WALLET_SUSPENDED"
+	// CodeWalletClosed is returned when funds are moved to or from a closed wallet.
+	CodeWalletClosed = "WALLET_CLOSED"
+	// CodeInvalidStatusTransition is returned when a wallet cannot be moved to the requested status.
+	CodeInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
+	// CodeWalletNotEmpty is returned when a wallet holding funds is closed without a wallet to sweep them to.
+	CodeWalletNotEmpty = "WALLET_NOT_EMPTY"
+	// CodeFundsReserved is returned when a wallet is closed while active holds or pending reviews reserve its funds.
+	CodeFundsReserved = "FUNDS_RESERVED"
+	// CodeNotReversible is returned when a transaction that is not completed, or is itself a reversal, is reversed.
+	CodeNotReversible = "TRANSACTION_NOT_REVERSIBLE"
+	// CodeAlreadyReversed is returned when a transaction is reversed a second time.
+	CodeAlreadyReversed = "ALREADY_REVERSED"
+	// CodeReversalAmountExceeded is returned when a reversal is larger than the transaction it reverses.
+	CodeReversalAmountExceeded = "REVERSAL_AMOUNT_EXCEEDED"
+	// CodeHoldExpired is returned when a hold is captured or voided after its expiry.
+	CodeHoldExpired = "HOLD_EXPIRED"
+	// CodeHoldNotActive is returned when a hold that was already captured or voided is captured or voided.
+	CodeHoldNotActive = "HOLD_NOT_ACTIVE"
+	// CodeCaptureAmountExceeded is returned when a capture is larger than the held amount.
+	CodeCaptureAmountExceeded = "CAPTURE_AMOUNT_EXCEEDED"
+	// CodeInvalidScheduleState is returned when a schedule is paused, resumed or changed in a status that does not allow it.
+	CodeInvalidScheduleState = "INVALID_SCHEDULE_STATE"
+	// CodeScheduleConflict is returned when a schedule keeps being advanced by the runner while it is changed.
+	CodeScheduleConflict = "SCHEDULE_CONFLICT"
+	// CodeLimitExceeded is returned when a withdrawal or transfer exceeds a spending limit of its wallet.
+	CodeLimitExceeded = "LIMIT_EXCEEDED"
+	// CodeRiskRejected is returned when a withdrawal or transfer is rejected by a risk rule.
+	CodeRiskRejected = "RISK_REJECTED"
+	// CodeReviewDecided is returned when a review that was already approved or rejected is decided again.
+	CodeReviewDecided = "REVIEW_DECIDED"
 )
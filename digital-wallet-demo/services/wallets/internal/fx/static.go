@@ -0,0 +1,98 @@
+// Package fx provides exchange rate providers for currency conversion.
+package fx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+)
+
+// defaultRates are indicative rates against USD used when no rate file is
+// configured. They are not market data and only suit tests and offline environments.
+var defaultRates = map[model.Currency]string{
+	"EUR": "0.92",
+	"GBP": "0.79",
+	"BDT": "117.50",
+	"INR": "83.40",
+	"SGD": "1.35",
+	"JPY": "150.25",
+	"KRW": "1340",
+	"KWD": "0.307",
+	"BHD": "0.376",
+}
+
+// StaticRates serves exchange rates from a fixed table of rates against a base currency.
+type StaticRates struct {
+	base  model.Currency
+	rates map[model.Currency]*big.Rat
+}
+
+// RateFile is the format of a rate file: the price of one unit of Base in each currency.
+//
+//	{"base": "USD", "rates": {"EUR": "0.92", "JPY": "150.25"}}
+type RateFile struct {
+	Base  model.Currency            `json:"base"`
+	Rates map[model.Currency]string `json:"rates"`
+}
+
+// NewStaticRates returns a provider for the given rates against base.
+// Each rate is the price of one unit of base in that currency, as a decimal string.
+func NewStaticRates(base model.Currency, rates map[model.Currency]string) (*StaticRates, error) {
+	if !base.IsSupported() {
+		return nil, fmt.Errorf("base currency %q: %w", base, model.ErrUnsupportedCurrency)
+	}
+	s := &StaticRates{
+		base:  base,
+		rates: map[model.Currency]*big.Rat{base: big.NewRat(1, 1)},
+	}
+	for currency, value := range rates {
+		if !currency.IsSupported() {
+			return nil, fmt.Errorf("currency %q: %w", currency, model.ErrUnsupportedCurrency)
+		}
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid rate %q for %s", value, currency)
+		}
+		s.rates[currency] = rate
+	}
+	return s, nil
+}
+
+// DefaultStaticRates returns a provider for the built-in indicative USD rates.
+func DefaultStaticRates() *StaticRates {
+	s, err := NewStaticRates(model.DefaultCurrency, defaultRates)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// LoadStaticRates reads a rate file in the RateFile format.
+func LoadStaticRates(path string) (*StaticRates, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate file: %w", err)
+	}
+	var file RateFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rate file: %w", err)
+	}
+	return NewStaticRates(file.Base, file.Rates)
+}
+
+// Rate returns the price of one unit of from in units of to.
+// Rates between two non-base currencies are crossed through the base currency.
+func (s *StaticRates) Rate(from, to model.Currency) (*big.Rat, error) {
+	fromRate, ok := s.rates[from]
+	if !ok {
+		return nil, model.ErrRateUnavailable
+	}
+	toRate, ok := s.rates[to]
+	if !ok {
+		return nil, model.ErrRateUnavailable
+	}
+	return new(big.Rat).Quo(toRate, fromRate), nil
+}
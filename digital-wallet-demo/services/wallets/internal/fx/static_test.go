@@ -0,0 +1,75 @@
+package fx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticRates_Rate(t *testing.T) {
+	rates, err := NewStaticRates("USD", map[model.Currency]string{"EUR": "0.8", "GBP": "0.5"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		from, to model.Currency
+		want     string
+		wantErr  error
+	}{
+		{"base_to_quote", "USD", "EUR", "4/5", nil},
+		{"quote_to_base", "EUR", "USD", "5/4", nil},
+		{"cross_rate", "EUR", "GBP", "5/8", nil},
+		{"same_currency", "GBP", "GBP", "1", nil},
+		{"missing_rate", "USD", "JPY", "", model.ErrRateUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := rates.Rate(tt.from, tt.to)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rate.RatString())
+		})
+	}
+}
+
+func TestNewStaticRates_Invalid(t *testing.T) {
+	_, err := NewStaticRates("XYZ", nil)
+	assert.ErrorIs(t, err, model.ErrUnsupportedCurrency)
+
+	_, err = NewStaticRates("USD", map[model.Currency]string{"EUR": "-1"})
+	assert.Error(t, err)
+
+	_, err = NewStaticRates("USD", map[model.Currency]string{"EUR": "abc"})
+	assert.Error(t, err)
+}
+
+func TestLoadStaticRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"base":"EUR","rates":{"USD":"1.25"}}`), 0o600))
+
+	rates, err := LoadStaticRates(path)
+	require.NoError(t, err)
+	rate, err := rates.Rate("USD", "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, "4/5", rate.RatString())
+
+	_, err = LoadStaticRates(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestDefaultStaticRates(t *testing.T) {
+	rates := DefaultStaticRates()
+	for _, from := range model.SupportedCurrencies() {
+		for _, to := range model.SupportedCurrencies() {
+			_, err := rates.Rate(from, to)
+			assert.NoError(t, err, "%s/%s", from, to)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package metrics provides the Prometheus metrics of the wallet service.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry is the registry of the metrics served on /metrics.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// HTTPRequestDuration is the latency of API requests by method, route and status.
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of API requests by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// Transactions counts the deposits, withdrawals and transfers by type and status,
+	// completed or pending for review.
+	Transactions = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_transactions_total",
+		Help: "Deposits, withdrawals and transfers by type and status.",
+	}, []string{"type", "status"})
+
+	// TransactionAmount sums the amounts of the deposits, withdrawals and transfers
+	// by type and currency, in minor units.
+	TransactionAmount = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_transaction_amount_total",
+		Help: "Amount of deposits, withdrawals and transfers by type and currency, in minor units.",
+	}, []string{"type", "currency"})
+
+	// InsufficientFunds counts the withdrawals and transfers rejected for insufficient funds.
+	InsufficientFunds = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_insufficient_funds_total",
+		Help: "Withdrawals and transfers rejected for insufficient funds by type.",
+	}, []string{"type"})
+
+	// CacheRequests counts the lookups of the transaction history cache by result,
+	// hit, miss or error.
+	CacheRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_cache_requests_total",
+		Help: "Lookups of the transaction history cache by result.",
+	}, []string{"result"})
+
+	// ClientRequests counts the requests sent to the transaction service by method
+	// and status code, error if no response was received.
+	ClientRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_transaction_client_requests_total",
+		Help: "Requests sent to the transaction service by method and status code.",
+	}, []string{"method", "code"})
+
+	// ClientErrors counts the requests to the transaction service that failed to
+	// get a response or got a server error, by method.
+	ClientErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_transaction_client_errors_total",
+		Help: "Requests to the transaction service without a response or with a server error by method.",
+	}, []string{"method"})
+
+	// OutboxDeliveries counts the delivery attempts of outbox entries by result:
+	// delivered, retried, or failed when an entry ran out of attempts and waits
+	// for an operator.
+	OutboxDeliveries = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_outbox_deliveries_total",
+		Help: "Delivery attempts of outbox entries by result.",
+	}, []string{"result"})
+)
+
+// Cache lookup results
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+)
+
+// Outbox delivery results
+const (
+	OutboxDelivered = "delivered"
+	OutboxRetried   = "retried"
+	OutboxFailed    = "failed"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler returns the handler serving the metrics of Registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDBStats registers the connection pool stats of db, labeled with dbName.
+// A pool already registered under dbName is kept.
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	err := Registry.Register(collectors.NewDBStatsCollector(db, dbName))
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		return nil
+	}
+	return err
+}
+
+// ObserveTransaction records a deposit, withdrawal or transfer accepted by the service.
+func ObserveTransaction(txnType model.TransactionType, txn *model.Transaction) {
+	Transactions.WithLabelValues(string(txnType), string(txn.Status)).Inc()
+	TransactionAmount.WithLabelValues(string(txnType), string(txn.Currency)).Add(float64(txn.Amount))
+}
+
+// ObserveClientRequest records a request sent to the transaction service, with
+// the response status code, or err if none was received.
+func ObserveClientRequest(method string, statusCode int, err error) {
+	if err != nil {
+		ClientRequests.WithLabelValues(method, "error").Inc()
+		ClientErrors.WithLabelValues(method).Inc()
+		return
+	}
+	ClientRequests.WithLabelValues(method, strconv.Itoa(statusCode)).Inc()
+	if statusCode >= http.StatusInternalServerError {
+		ClientErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// Middleware returns a middleware recording the latency of each request.
+// Requests matching no route are recorded under the route "unmatched", so
+// that probing random paths does not create a series per path.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			// Handle errors here, so that the status of the response is known
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" || c.Response().Status == http.StatusNotFound && route == "/*" {
+				route = "unmatched"
+			}
+			HTTPRequestDuration.WithLabelValues(
+				c.Request().Method,
+				route,
+				strconv.Itoa(c.Response().Status),
+			).Observe(time.Since(start).Seconds())
+			return nil
+		}
+	}
+}
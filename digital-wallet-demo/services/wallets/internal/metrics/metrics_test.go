@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requestCounts returns the number of requests recorded by HTTPRequestDuration per method, route and status
+func requestCounts(t *testing.T) map[[3]string]uint64 {
+	families, err := Registry.Gather()
+	require.NoError(t, err)
+
+	counts := map[[3]string]uint64{}
+	for _, family := range families {
+		if family.GetName() != "http_request_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			var key [3]string
+			for _, label := range m.GetLabel() {
+				switch label.GetName() {
+				case "method":
+					key[0] = label.GetValue()
+				case "route":
+					key[1] = label.GetValue()
+				case "status":
+					key[2] = label.GetValue()
+				}
+			}
+			counts[key] = m.GetHistogram().GetSampleCount()
+		}
+	}
+	return counts
+}
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware())
+	e.GET("/api/v1/wallets/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/api/v1/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable)
+	})
+
+	for _, path := range []string{"/api/v1/wallets/1", "/api/v1/wallets/2", "/api/v1/fail", "/random/path"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	counts := requestCounts(t)
+	assert.Equal(t, uint64(2), counts[[3]string{http.MethodGet, "/api/v1/wallets/:id", "200"}])
+	assert.Equal(t, uint64(1), counts[[3]string{http.MethodGet, "/api/v1/fail", "503"}])
+	assert.Equal(t, uint64(1), counts[[3]string{http.MethodGet, "unmatched", "404"}])
+	assert.Len(t, counts, 3)
+}
+
+func TestObserveTransaction(t *testing.T) {
+	ObserveTransaction(model.Transfer, &model.Transaction{Amount: 1500, Currency: model.DefaultCurrency, Status: model.Completed})
+	ObserveTransaction(model.Transfer, &model.Transaction{Amount: 500, Currency: model.DefaultCurrency, Status: model.Pending})
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(Transactions.WithLabelValues("transfer", "completed")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(Transactions.WithLabelValues("transfer", "pending")))
+	assert.Equal(t, float64(2000), testutil.ToFloat64(TransactionAmount.WithLabelValues("transfer", "USD")))
+}
+
+func TestObserveClientRequest(t *testing.T) {
+	ObserveClientRequest(http.MethodPost, http.StatusCreated, nil)
+	ObserveClientRequest(http.MethodPost, http.StatusBadGateway, nil)
+	ObserveClientRequest(http.MethodPost, 0, errors.New("connection refused"))
+	ObserveClientRequest(http.MethodGet, http.StatusNotFound, nil)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(ClientRequests.WithLabelValues(http.MethodPost, "201")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(ClientRequests.WithLabelValues(http.MethodPost, "error")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(ClientErrors.WithLabelValues(http.MethodPost)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(ClientErrors.WithLabelValues(http.MethodGet)))
+}
@@ -0,0 +1,164 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditAction is the kind of change recorded by an audit entry.
+type AuditAction string
+
+const (
+	// AuditWalletCreated records the creation of a wallet.
+	AuditWalletCreated = AuditAction("wallet.created")
+	// AuditBalanceChanged records a change of a wallet balance in one currency.
+	AuditBalanceChanged = AuditAction("wallet.balance_changed")
+	// AuditStatusChanged records a change of a wallet status.
+	AuditStatusChanged = AuditAction("wallet.status_changed")
+	// AuditReviewDecided records the approval or rejection of a review.
+	AuditReviewDecided = AuditAction("review.decided")
+	// AuditReviewFlagSet records a wallet flagged for manual review.
+	AuditReviewFlagSet = AuditAction("review.flag_set")
+	// AuditReviewFlagRemoved records a wallet no longer flagged for manual review.
+	AuditReviewFlagRemoved = AuditAction("review.flag_removed")
+	// AuditLimitSet records a spending limit set or replaced.
+	AuditLimitSet = AuditAction("limit.set")
+	// AuditLimitDeleted records a spending limit removed.
+	AuditLimitDeleted = AuditAction("limit.deleted")
+	// AuditHoldPlaced records a hold placed on funds of a wallet.
+	AuditHoldPlaced = AuditAction("hold.placed")
+	// AuditHoldVoided records a hold released without moving funds.
+	AuditHoldVoided = AuditAction("hold.voided")
+	// AuditLedgerAdjusted records an adjustment pair enqueued by reconciliation.
+	AuditLedgerAdjusted = AuditAction("ledger.adjusted")
+)
+
+// Actors of changes not made through the API.
+const (
+	// SchedulerActor is the actor of transfers run by the schedule worker.
+	SchedulerActor = "system:scheduler"
+	// ReconcileActor is the actor of adjustments made by the reconcile command.
+	ReconcileActor = "cli:reconcile"
+)
+
+// AuditContext identifies who made a change and the request it was made in.
+type AuditContext struct {
+	Actor     string
+	RequestID string
+}
+
+// AuditEntry is an entry of the append-only audit log.
+//
+// Each entry holds the hash of the previous entry, and its own hash covers
+// all of its fields including that link, so that changing, removing or
+// reordering an entry breaks the chain from that entry on.
+type AuditEntry struct {
+	ID            int         `gorm:"primaryKey" json:"id"`
+	Action        AuditAction `gorm:"type:varchar(50);not null;index" json:"action"`
+	Actor         string      `gorm:"type:varchar(255);not null" json:"actor"`
+	RequestID     string      `gorm:"type:varchar(255)" json:"request_id,omitempty"`
+	UserID        string      `gorm:"type:varchar(255);index" json:"user_id,omitempty"` // Wallet or subject of the change
+	Currency      Currency    `gorm:"type:varchar(3)" json:"currency,omitempty"`
+	BalanceBefore *int64      `json:"balance_before,omitempty"`           // Balance in minor units of Currency
+	BalanceAfter  *int64      `json:"balance_after,omitempty"`            // Balance in minor units of Currency
+	Details       string      `gorm:"type:text" json:"details,omitempty"` // JSON object describing the change
+	PrevHash      string      `gorm:"type:varchar(64);not null" json:"prev_hash"`
+	Hash          string      `gorm:"type:varchar(64);not null;uniqueIndex" json:"hash"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// TableName returns the table of the audit log.
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+// NewAuditEntry returns an audit entry of the action made in ctx.
+// details, if not nil, is recorded as a JSON object.
+func NewAuditEntry(ctx AuditContext, action AuditAction, userID string, details map[string]any) (*AuditEntry, error) {
+	entry := &AuditEntry{
+		Action:    action,
+		Actor:     ctx.Actor,
+		RequestID: ctx.RequestID,
+		UserID:    userID,
+	}
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit details: %w", err)
+		}
+		entry.Details = string(data)
+	}
+	return entry, nil
+}
+
+// NewBalanceAuditEntry returns an audit entry of a change of the balance of
+// the wallet of userID in currency from before to after, made by operation.
+func NewBalanceAuditEntry(ctx AuditContext, userID string, currency Currency, before, after int64, operation string) (*AuditEntry, error) {
+	entry, err := NewAuditEntry(ctx, AuditBalanceChanged, userID, map[string]any{"operation": operation})
+	if err != nil {
+		return nil, err
+	}
+	entry.Currency = currency
+	entry.BalanceBefore, entry.BalanceAfter = &before, &after
+	return entry, nil
+}
+
+// Link chains the entry to the entry with hash prevHash and sets its creation time and hash.
+// The creation time is truncated to the precision stored by the database.
+func (e *AuditEntry) Link(prevHash string, at time.Time) {
+	e.PrevHash = prevHash
+	e.CreatedAt = at.UTC().Truncate(time.Microsecond)
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash returns the SHA-256 hash of the entry's fields and link, hex encoded.
+func (e *AuditEntry) ComputeHash() string {
+	data, _ := json.Marshal(struct {
+		PrevHash      string      `json:"prev_hash"`
+		Action        AuditAction `json:"action"`
+		Actor         string      `json:"actor"`
+		RequestID     string      `json:"request_id"`
+		UserID        string      `json:"user_id"`
+		Currency      Currency    `json:"currency"`
+		BalanceBefore *int64      `json:"balance_before"`
+		BalanceAfter  *int64      `json:"balance_after"`
+		Details       string      `json:"details"`
+		CreatedAt     string      `json:"created_at"`
+	}{
+		PrevHash:      e.PrevHash,
+		Action:        e.Action,
+		Actor:         e.Actor,
+		RequestID:     e.RequestID,
+		UserID:        e.UserID,
+		Currency:      e.Currency,
+		BalanceBefore: e.BalanceBefore,
+		BalanceAfter:  e.BalanceAfter,
+		Details:       e.Details,
+		CreatedAt:     e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditVerification is the result of walking the audit log.
+// BrokenAt is nil if every entry is intact and linked to the one before it.
+type AuditVerification struct {
+	Entries  int         `json:"entries"` // Entries verified before the first broken link
+	BrokenAt *AuditEntry `json:"broken_at,omitempty"`
+	Reason   string      `json:"reason,omitempty"`
+}
+
+// Verify checks that the entry follows the entry with hash prevHash and was not changed.
+// It returns the reason the link is broken, or an empty string.
+func (e *AuditEntry) Verify(prevHash string) string {
+	if e.PrevHash != prevHash {
+		return fmt.Sprintf("previous hash %q does not match hash %q of the entry before", e.PrevHash, prevHash)
+	}
+	if hash := e.ComputeHash(); e.Hash != hash {
+		return fmt.Sprintf("stored hash %q does not match computed hash %q", e.Hash, hash)
+	}
+	return ""
+}
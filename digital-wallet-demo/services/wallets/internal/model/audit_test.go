@@ -0,0 +1,105 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditChain returns three linked entries, as appended to an empty log.
+func auditChain(t *testing.T) []*AuditEntry {
+	ctx := AuditContext{Actor: "user:test-user-001", RequestID: "req-1"}
+	created, err := NewAuditEntry(ctx, AuditWalletCreated, "test-user-001", map[string]any{"currency": DefaultCurrency})
+	require.NoError(t, err)
+	credited, err := NewBalanceAuditEntry(ctx, "test-user-001", DefaultCurrency, 0, 1500, "deposit")
+	require.NoError(t, err)
+	closed, err := NewAuditEntry(AuditContext{Actor: "admin"}, AuditStatusChanged, "test-user-001", map[string]any{"from": Active, "to": Closed})
+	require.NoError(t, err)
+
+	at := time.Date(2024, 1, 1, 12, 0, 0, 123456789, time.UTC)
+	prevHash := ""
+	for i, e := range []*AuditEntry{created, credited, closed} {
+		e.Link(prevHash, at.Add(time.Duration(i)*time.Second))
+		prevHash = e.Hash
+	}
+	return []*AuditEntry{created, credited, closed}
+}
+
+func TestAuditEntry_Link(t *testing.T) {
+	chain := auditChain(t)
+
+	assert.Equal(t, "", chain[0].PrevHash)
+	assert.Len(t, chain[0].Hash, 64)
+	assert.Equal(t, chain[0].Hash, chain[1].PrevHash)
+	assert.Equal(t, chain[1].Hash, chain[2].PrevHash)
+	assert.Equal(t, 123456000, chain[0].CreatedAt.Nanosecond(), "creation time is truncated to microseconds")
+
+	assert.Equal(t, `{"operation":"deposit"}`, chain[1].Details)
+	require.NotNil(t, chain[1].BalanceBefore)
+	require.NotNil(t, chain[1].BalanceAfter)
+	assert.Equal(t, int64(0), *chain[1].BalanceBefore)
+	assert.Equal(t, int64(1500), *chain[1].BalanceAfter)
+}
+
+func TestAuditEntry_Verify(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(chain []*AuditEntry)
+		broken int // Index of the first broken entry, -1 if intact
+	}{
+		{
+			name:   "intact",
+			tamper: func([]*AuditEntry) {},
+			broken: -1,
+		},
+		{
+			name:   "changed balance",
+			tamper: func(chain []*AuditEntry) { *chain[1].BalanceAfter = 150000 },
+			broken: 1,
+		},
+		{
+			name:   "changed actor",
+			tamper: func(chain []*AuditEntry) { chain[2].Actor = "user:test-user-001" },
+			broken: 2,
+		},
+		{
+			name: "rehashed entry",
+			tamper: func(chain []*AuditEntry) {
+				chain[0].Details = `{"currency":"EUR"}`
+				chain[0].Hash = chain[0].ComputeHash()
+			},
+			broken: 1,
+		},
+		{
+			name: "removed entry",
+			tamper: func(chain []*AuditEntry) {
+				copy(chain[1:], chain[2:])
+				chain[2] = nil
+			},
+			broken: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := auditChain(t)
+			tt.tamper(chain)
+
+			broken := -1
+			prevHash := ""
+			for i, e := range chain {
+				if e == nil {
+					break
+				}
+				if reason := e.Verify(prevHash); reason != "" {
+					broken = i
+					break
+				}
+				prevHash = e.Hash
+			}
+			assert.Equal(t, tt.broken, broken)
+		})
+	}
+}
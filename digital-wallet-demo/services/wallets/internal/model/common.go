@@ -7,3 +7,31 @@ var ErrNotFound = fmt.Errorf("not found")
 
 // ErrInsufficientFunds is the error for insufficient funds.
 var ErrInsufficientFunds = fmt.Errorf("insufficient funds")
+
+// ErrInvalidTransactionQuery is the error for a transaction history query rejected by the transaction service.
+var ErrInvalidTransactionQuery = fmt.Errorf("invalid transaction query")
+
+// ErrTransactionsUnavailable is the error for a call to the transaction service that got no
+// response, a server error, or was not sent because its circuit breaker is open.
+var ErrTransactionsUnavailable = fmt.Errorf("transaction service unavailable")
+
+// ErrWalletInactive is the error for moving funds to or from an inactive wallet.
+var ErrWalletInactive = fmt.Errorf("wallet is inactive")
+
+// ErrWalletSuspended is the error for moving funds to or from a suspended wallet.
+var ErrWalletSuspended = fmt.Errorf("wallet is suspended")
+
+// ErrWalletClosed is the error for moving funds to or from a closed wallet.
+var ErrWalletClosed = fmt.Errorf("wallet is closed")
+
+// ErrInvalidStatusTransition is the error for a wallet status change that is not allowed.
+var ErrInvalidStatusTransition = fmt.Errorf("invalid wallet status transition")
+
+// ErrWalletNotEmpty is the error for closing a wallet that still holds funds without a wallet to sweep them to.
+var ErrWalletNotEmpty = fmt.Errorf("wallet balance is not zero")
+
+// ErrFundsReserved is the error for closing a wallet whose funds are reserved by active holds or pending reviews.
+var ErrFundsReserved = fmt.Errorf("wallet funds are reserved")
+
+// ErrInvalidSweepTarget is the error for sweeping a closing wallet's funds to itself or to a provider wallet.
+var ErrInvalidSweepTarget = fmt.Errorf("invalid sweep wallet")
@@ -1,13 +1,123 @@
 // Package model provides the data models for the application.
 package model
 
+import (
+	"fmt"
+	"time"
+)
+
 // Config is the configuration for the application.
 type Config struct {
 	APIServer     Server
 	SwaggerServer Server
+	MetricsServer Server // Serves the Prometheus metrics on /metrics
+	Worker        Worker
 	PostgreSQL    PostgreSQL
 	Redis         Redis
 	Services      Services
+	Outbox        Outbox
+	FX            FX
+	Holds         Holds
+	Schedules     Schedules
+	Risk          Risk
+	Auth          Auth
+	Tracing       Tracing
+	Health        Health
+}
+
+// Health is the configuration for the readiness checks and the draining of the instance on shutdown.
+type Health struct {
+	Timeout    time.Duration `validate:"gt=0"`  // Time each dependency has to answer a readiness check
+	DrainDelay time.Duration `validate:"gte=0"` // Time the instance reports not ready before it stops serving, for load balancers to stop routing to it
+}
+
+// Tracing is the configuration for exporting OpenTelemetry spans.
+// Spans are written to stdout, or sent over OTLP/HTTP to the collector at Endpoint.
+type Tracing struct {
+	Enable      bool
+	Exporter    string  `validate:"omitempty,oneof=stdout otlp"`
+	Endpoint    string  // host:port of the OTLP/HTTP collector, defaults to localhost:4318
+	Insecure    bool    // Send spans to the collector over plain HTTP
+	ServiceName string  // Defaults to wallets
+	SampleRatio float64 `validate:"gte=0,lte=1"` // Ratio of new traces that are sampled, traces of sampled callers are always sampled
+}
+
+// Auth is the configuration for authenticating API requests with JWTs.
+//
+// HS256 tokens are verified with Secret. RS256 tokens are verified with the
+// key of PublicKeyFile, or with the key of JWKSFile matching the token's kid.
+// The subject claim is the user_id of the caller.
+//
+// The API refuses to start with authentication disabled unless DevMode is set.
+// In development mode callers may act on every wallet but are never admins.
+type Auth struct {
+	Enable        bool
+	DevMode       bool   // Allows running without authentication, for development only
+	Algorithm     string `validate:"omitempty,oneof=HS256 RS256"`
+	Secret        string // Shared secret of HS256 tokens
+	PublicKeyFile string // PEM encoded RSA public key of RS256 tokens
+	JWKSFile      string // Local JWKS file with the RSA public keys of RS256 tokens
+	Issuer        string // Required iss claim, not checked if empty
+	Audience      string // Required aud claim, not checked if empty
+	RolesClaim    string // Claim listing the caller's roles, defaults to roles
+	AdminRole     string // Role allowed to act on every wallet and call the admin endpoints, defaults to admin
+}
+
+// Risk is the configuration for the risk rules of outgoing transfers and withdrawals.
+type Risk struct {
+	Enable bool
+	Rules  []RiskRule `validate:"dive"` // Evaluated in order, the first matching rule decides
+}
+
+// RiskRule is a risk rule. The fields used depend on the rule type:
+//
+//	amount:     Amount, Currency
+//	newWallet:  Period (cooling period), Amount and Currency (optional minimum)
+//	recipients: Period (window), MaxRecipients
+//	roundTrip:  Period (window)
+type RiskRule struct {
+	Name             string            `validate:"required"`
+	Type             RiskRuleType      `validate:"oneof=amount newWallet recipients roundTrip"`
+	Action           RiskAction        `validate:"oneof=approve reject review"`
+	TransactionTypes []TransactionType `validate:"dive,oneof=transfer withdraw"` // Transactions the rule applies to, all if empty
+	Amount           int64             `validate:"gte=0"`                        // Amount in minor units of Currency
+	Currency         Currency          // Defaults to USD
+	Period           time.Duration     `validate:"gte=0"`
+	MaxRecipients    int               `validate:"gte=0"`
+}
+
+// Schedules is the configuration for the scheduled transfer runner.
+type Schedules struct {
+	PollInterval time.Duration `validate:"gt=0"`
+	BatchSize    int           `validate:"gt=0"`
+}
+
+// Holds is the configuration for holds on wallet funds.
+type Holds struct {
+	TTL            time.Duration `validate:"gt=0"` // Time after which an uncaptured hold expires
+	ExpiryInterval time.Duration `validate:"gt=0"` // Interval of the job marking holds past their TTL as expired
+}
+
+// FX is the configuration for currency conversion.
+type FX struct {
+	// RatesFile is a JSON rate file, see fx.RateFile. The built-in indicative rates are used if empty.
+	RatesFile string
+	QuoteTTL  time.Duration `validate:"gt=0"`
+}
+
+// Worker is the configuration for the background worker server.
+type Worker struct {
+	Enable bool
+}
+
+// Outbox is the configuration for the outbox relay worker.
+type Outbox struct {
+	PollInterval time.Duration `validate:"gt=0"`
+	BatchSize    int           `validate:"gt=0"`
+	BaseBackoff  time.Duration `validate:"gt=0"`
+	MaxBackoff   time.Duration `validate:"gtefield=BaseBackoff"`
+	MaxAttempts  int           `validate:"gt=0"` // Failed deliveries after which an entry is marked failed
+	LeaseTimeout time.Duration `validate:"gt=0"` // Time a claimed batch has to be delivered before other relays claim it again
 }
 
 // Services is the configuration for external services.
@@ -16,8 +126,66 @@ type Services struct {
 }
 
 // Service is the configuration for the transaction service.
+// Requests are signed as ServiceID with the shared secret read from the
+// environment variable SecretEnv, and are not signed without SecretEnv.
+// The server refuses to start with a secret that is not set or equal to
+// DevServiceSecret unless DevMode is set.
 type Service struct {
-	BaseURL string `yaml:"baseURL"`
+	BaseURL   string        `yaml:"baseURL"`
+	ServiceID string        `yaml:"serviceID"`
+	SecretEnv string        `yaml:"secretEnv"` // Environment variable holding the shared secret
+	Secret    string        `mapstructure:"-"` // Read from SecretEnv by LoadSecret, never from a config file
+	DevMode   bool          `yaml:"devMode"`   // Falls back to DevServiceSecret for a secret that is not set, for development only
+	Timeout   time.Duration `validate:"gte=0"` // Deadline of a call including its retries, shortened by the deadline of the caller
+	Retry     Retry
+	Breaker   Breaker
+}
+
+// DevServiceSecret is the shared secret of development setups. It is public,
+// so it is only accepted in development mode.
+const DevServiceSecret = "dev-wallets-service-secret-change-me"
+
+// minServiceSecretLength is the minimum length of a shared secret.
+const minServiceSecretLength = 32
+
+// LoadSecret reads the shared secret from the environment variable SecretEnv
+// with getenv. Outside development mode, a secret that is not set or equal to
+// DevServiceSecret is an error.
+func (s *Service) LoadSecret(getenv func(string) string) error {
+	if s.SecretEnv == "" {
+		return nil
+	}
+	secret := getenv(s.SecretEnv)
+	if secret == "" && s.DevMode {
+		secret = DevServiceSecret
+	}
+	switch {
+	case secret == "":
+		return fmt.Errorf("secret of the transaction service is not set, set it in %s", s.SecretEnv)
+	case secret == DevServiceSecret && !s.DevMode:
+		return fmt.Errorf("secret of the transaction service in %s is the development secret", s.SecretEnv)
+	case len(secret) < minServiceSecretLength:
+		return fmt.Errorf("secret of the transaction service in %s is shorter than %d characters", s.SecretEnv, minServiceSecretLength)
+	}
+	s.Secret = secret
+	return nil
+}
+
+// Retry is the configuration for retrying idempotent calls that failed without
+// a response or with a 502, 503 or 504. Calls are idempotent if they are reads
+// or carry an idempotency key.
+type Retry struct {
+	MaxAttempts int           `validate:"gte=0"` // Attempts of a call including the first, one if zero
+	BaseBackoff time.Duration `validate:"gte=0"` // Delay before the first retry, doubled for every retry and jittered
+	MaxBackoff  time.Duration `validate:"gtefield=BaseBackoff"`
+}
+
+// Breaker is the configuration for the circuit breaker of calls to a service.
+// After FailureThreshold consecutive failures, calls fail fast for OpenTimeout,
+// after which a single call probes whether the service has recovered.
+type Breaker struct {
+	FailureThreshold int           `validate:"gte=0"` // Disabled if zero
+	OpenTimeout      time.Duration `validate:"gte=0"`
 }
 
 // Server is the configuration for the server.
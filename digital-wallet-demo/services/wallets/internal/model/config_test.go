@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_LoadSecret(t *testing.T) {
+	const secret = "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name       string
+		secretEnv  string // Defaults to WALLETS_SERVICE_SECRET, "-" for none
+		devMode    bool
+		env        string // Value of WALLETS_SERVICE_SECRET, unset if empty
+		wantSecret string
+		wantErr    bool
+	}{
+		{name: "secret_from_env", env: secret, wantSecret: secret},
+		{name: "unsigned_without_secret_env", secretEnv: "-"},
+		{name: "missing_secret", wantErr: true},
+		{name: "development_secret", env: DevServiceSecret, wantErr: true},
+		{name: "short_secret", env: "too-short", wantErr: true},
+		{name: "dev_mode_falls_back", devMode: true, wantSecret: DevServiceSecret},
+		{name: "dev_mode_prefers_env", devMode: true, env: secret, wantSecret: secret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secretEnv := tt.secretEnv
+			switch secretEnv {
+			case "":
+				secretEnv = "WALLETS_SERVICE_SECRET"
+			case "-":
+				secretEnv = ""
+			}
+			svc := Service{SecretEnv: secretEnv, DevMode: tt.devMode}
+			getenv := func(key string) string {
+				if key == "WALLETS_SERVICE_SECRET" {
+					return tt.env
+				}
+				return ""
+			}
+
+			err := svc.LoadSecret(getenv)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSecret, svc.Secret)
+		})
+	}
+}
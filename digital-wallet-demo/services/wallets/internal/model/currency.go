@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Currency is an ISO-4217 currency code.
+//
+// Amounts are always integers in the minor unit of their currency: cents for
+// USD, yen for JPY, fils for KWD.
+type Currency string
+
+// DefaultCurrency is the currency of wallets and requests that do not specify one.
+const DefaultCurrency = Currency("USD")
+
+// currencyMinorUnits lists the supported currencies with the number of decimal
+// places of their minor unit.
+var currencyMinorUnits = map[Currency]int{
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"BDT": 2,
+	"INR": 2,
+	"SGD": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"BHD": 3,
+}
+
+// ErrUnsupportedCurrency is the error for a currency code that is not supported.
+var ErrUnsupportedCurrency = fmt.Errorf("unsupported currency")
+
+// IsSupported reports whether c is a supported currency.
+func (c Currency) IsSupported() bool {
+	_, ok := currencyMinorUnits[c]
+	return ok
+}
+
+// MinorUnits returns the number of decimal places of the currency's minor unit.
+func (c Currency) MinorUnits() int {
+	return currencyMinorUnits[c]
+}
+
+// FormatAmount renders an amount in minor units as a decimal string, e.g. 1234 USD as "12.34".
+func (c Currency) FormatAmount(amount int64) string {
+	units := c.MinorUnits()
+	if units == 0 {
+		return fmt.Sprintf("%d", amount)
+	}
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	scale := int64(1)
+	for i := 0; i < units; i++ {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, units, amount%scale)
+}
+
+// SupportedCurrencies returns the codes of all supported currencies.
+func SupportedCurrencies() []Currency {
+	currencies := make([]Currency, 0, len(currencyMinorUnits))
+	for c := range currencyMinorUnits {
+		currencies = append(currencies, c)
+	}
+	return currencies
+}
+
+// IsValidCurrency checks if the currency is supported
+func IsValidCurrency(fl validator.FieldLevel) bool {
+	if fl.Field().IsZero() {
+		return true
+	}
+	currency := fl.Field().Interface().(Currency)
+	return currency.IsSupported()
+}
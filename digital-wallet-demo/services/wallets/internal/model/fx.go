@@ -0,0 +1,96 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// FXRatePrecision is the number of decimal places exchange rates are recorded with.
+const FXRatePrecision = 8
+
+var (
+	// ErrRateUnavailable is the error for a currency pair without an exchange rate.
+	ErrRateUnavailable = fmt.Errorf("exchange rate unavailable")
+	// ErrQuoteNotFound is the error for an unknown quote ID.
+	ErrQuoteNotFound = fmt.Errorf("quote not found")
+	// ErrQuoteExpired is the error for a quote used after its expiry.
+	ErrQuoteExpired = fmt.Errorf("quote expired")
+	// ErrQuoteUsed is the error for a quote that was already used by a transfer.
+	ErrQuoteUsed = fmt.Errorf("quote already used")
+	// ErrQuoteMismatch is the error for a quote whose currencies or amount differ from the transfer.
+	ErrQuoteMismatch = fmt.Errorf("quote does not match transfer")
+)
+
+// FXQuote is a conversion of Amount in FromCurrency to CounterAmount in
+// ToCurrency at Rate. A stored quote locks the rate until ExpiresAt and can be
+// used by a single transfer.
+type FXQuote struct {
+	ID            string     `gorm:"primaryKey;type:varchar(32)" json:"id"`
+	FromCurrency  Currency   `gorm:"type:varchar(3);not null" json:"from_currency"`
+	ToCurrency    Currency   `gorm:"type:varchar(3);not null" json:"to_currency"`
+	Amount        int64      `gorm:"not null" json:"amount"`         // Amount in minor units of FromCurrency
+	CounterAmount int64      `gorm:"not null" json:"counter_amount"` // Amount in minor units of ToCurrency
+	Rate          string     `gorm:"type:varchar(32);not null" json:"rate"`
+	ExpiresAt     time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt        *time.Time `json:"used_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// NewFXQuote converts amount at rate, the price of one unit of from in units
+// of to, and returns a quote valid for ttl. The rate is rounded to
+// FXRatePrecision decimal places before it is applied, so that the recorded
+// rate reproduces the counter amount.
+func NewFXQuote(from, to Currency, amount int64, rate *big.Rat, ttl time.Duration) (*FXQuote, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	rounded, _ := new(big.Rat).SetString(rate.FloatString(FXRatePrecision))
+	return &FXQuote{
+		ID:            hex.EncodeToString(id),
+		FromCurrency:  from,
+		ToCurrency:    to,
+		Amount:        amount,
+		CounterAmount: ConvertAmount(amount, from, to, rounded),
+		Rate:          rounded.FloatString(FXRatePrecision),
+		ExpiresAt:     time.Now().Add(ttl),
+	}, nil
+}
+
+// Redeemable checks that the quote can be used for a transfer of amount from
+// one currency to another at time now.
+func (q *FXQuote) Redeemable(from, to Currency, amount int64, now time.Time) error {
+	if q.UsedAt != nil {
+		return ErrQuoteUsed
+	}
+	if !now.Before(q.ExpiresAt) {
+		return ErrQuoteExpired
+	}
+	if q.FromCurrency != from || q.ToCurrency != to || q.Amount != amount {
+		return ErrQuoteMismatch
+	}
+	return nil
+}
+
+// ConvertAmount converts an amount in minor units of from into minor units of
+// to at rate. The result is rounded down so that a conversion never creates money.
+func ConvertAmount(amount int64, from, to Currency, rate *big.Rat) int64 {
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), rate)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(to.MinorUnits()-from.MinorUnits()))), nil)
+	if to.MinorUnits() >= from.MinorUnits() {
+		converted.Mul(converted, new(big.Rat).SetInt(scale))
+	} else {
+		converted.Quo(converted, new(big.Rat).SetInt(scale))
+	}
+	return new(big.Int).Quo(converted.Num(), converted.Denom()).Int64()
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
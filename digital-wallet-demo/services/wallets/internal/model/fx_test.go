@@ -0,0 +1,53 @@
+package model
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertAmount(t *testing.T) {
+	rat := func(s string) *big.Rat {
+		r, ok := new(big.Rat).SetString(s)
+		require.True(t, ok)
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		amount   int64
+		from, to Currency
+		rate     string
+		want     int64
+	}{
+		{"same_minor_units", 10000, "USD", "EUR", "0.92", 9200},
+		{"rounds_down", 1, "USD", "EUR", "0.92", 0},
+		{"to_zero_decimals", 10000, "USD", "JPY", "150.25", 15025},
+		{"from_zero_decimals", 15025, "JPY", "USD", "0.00665557", 9999},
+		{"to_three_decimals", 10000, "USD", "KWD", "0.307", 30700},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ConvertAmount(tt.amount, tt.from, tt.to, rat(tt.rate)))
+		})
+	}
+}
+
+func TestFXQuote_Redeemable(t *testing.T) {
+	quote, err := NewFXQuote("USD", "EUR", 10000, big.NewRat(92, 100), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "0.92000000", quote.Rate)
+	assert.Equal(t, int64(9200), quote.CounterAmount)
+
+	now := time.Now()
+	assert.NoError(t, quote.Redeemable("USD", "EUR", 10000, now))
+	assert.ErrorIs(t, quote.Redeemable("USD", "GBP", 10000, now), ErrQuoteMismatch)
+	assert.ErrorIs(t, quote.Redeemable("USD", "EUR", 5000, now), ErrQuoteMismatch)
+	assert.ErrorIs(t, quote.Redeemable("USD", "EUR", 10000, now.Add(2*time.Minute)), ErrQuoteExpired)
+
+	quote.UsedAt = &now
+	assert.ErrorIs(t, quote.Redeemable("USD", "EUR", 10000, now), ErrQuoteUsed)
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrHoldNotFound is the error for an unknown hold ID.
+	ErrHoldNotFound = fmt.Errorf("hold not found")
+	// ErrHoldExpired is the error for a hold captured or voided after its expiry.
+	ErrHoldExpired = fmt.Errorf("hold expired")
+	// ErrHoldNotActive is the error for a hold that was already captured or voided.
+	ErrHoldNotActive = fmt.Errorf("hold is not active")
+	// ErrCaptureAmount is the error for a capture of more than the held amount.
+	ErrCaptureAmount = fmt.Errorf("capture amount exceeds held amount")
+)
+
+// HoldStatus is the status of a hold.
+type HoldStatus string
+
+const (
+	// HoldActive is the status of a hold whose amount is reserved.
+	HoldActive = HoldStatus("active")
+	// HoldCaptured is the status of a hold that was converted into a transfer.
+	HoldCaptured = HoldStatus("captured")
+	// HoldVoided is the status of a hold that was released.
+	HoldVoided = HoldStatus("voided")
+	// HoldExpired is the status of a hold released after its expiry.
+	HoldExpired = HoldStatus("expired")
+)
+
+// Hold reserves Amount of the sender's balance in Currency for a later
+// transfer to the receiver. A held amount stays part of the sender's balance,
+// but is not available to withdrawals, transfers or other holds until the
+// hold is captured, voided or expires.
+type Hold struct {
+	ID             string     `gorm:"primaryKey;type:varchar(32)" json:"id"`
+	FromUserID     string     `gorm:"not null;index:idx_holds_from_user_status" json:"from_user_id"`
+	ToUserID       string     `gorm:"not null" json:"to_user_id"`
+	Currency       Currency   `gorm:"type:varchar(3);not null" json:"currency"`
+	Amount         int64      `gorm:"not null" json:"amount"`                    // Amount in minor units of Currency
+	CapturedAmount int64      `gorm:"not null;default:0" json:"captured_amount"` // Amount transferred by the capture
+	Status         HoldStatus `gorm:"type:varchar(16);not null;default:'active';index:idx_holds_from_user_status" json:"status"`
+	ExpiresAt      time.Time  `gorm:"not null;index" json:"expires_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// NewHold returns an active hold of amount in currency from one wallet to
+// another, which expires after ttl.
+func NewHold(fromUserID, toUserID string, currency Currency, amount int64, ttl time.Duration) (*Hold, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &Hold{
+		ID:         hex.EncodeToString(id),
+		FromUserID: fromUserID,
+		ToUserID:   toUserID,
+		Currency:   currency,
+		Amount:     amount,
+		Status:     HoldActive,
+		ExpiresAt:  time.Now().Add(ttl),
+	}, nil
+}
+
+// CheckActive returns an error unless the hold can be captured or voided at time now.
+// An active hold past its expiry is expired, even before the expiry job has marked it.
+func (h *Hold) CheckActive(now time.Time) error {
+	switch h.Status {
+	case HoldActive:
+		if !now.Before(h.ExpiresAt) {
+			return ErrHoldExpired
+		}
+		return nil
+	case HoldExpired:
+		return ErrHoldExpired
+	}
+	return ErrHoldNotActive
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHold_CheckActive(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		hold Hold
+		want error
+	}{
+		{"active", Hold{Status: HoldActive, ExpiresAt: now.Add(time.Minute)}, nil},
+		{"active_past_expiry", Hold{Status: HoldActive, ExpiresAt: now}, ErrHoldExpired},
+		{"expired", Hold{Status: HoldExpired, ExpiresAt: now.Add(-time.Minute)}, ErrHoldExpired},
+		{"captured", Hold{Status: HoldCaptured, ExpiresAt: now.Add(time.Minute)}, ErrHoldNotActive},
+		{"voided", Hold{Status: HoldVoided, ExpiresAt: now.Add(time.Minute)}, ErrHoldNotActive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.hold.CheckActive(now))
+		})
+	}
+}
+
+func TestWallet_AvailableIn(t *testing.T) {
+	w := Wallet{
+		Currency: "USD",
+		Balance:  10000,
+		Balances: []WalletBalance{{Currency: "EUR", Balance: 500}},
+		Reserved: map[Currency]int64{"USD": 2500},
+	}
+	assert.Equal(t, int64(7500), w.AvailableIn("USD"))
+	assert.Equal(t, int64(500), w.AvailableIn("EUR"))
+}
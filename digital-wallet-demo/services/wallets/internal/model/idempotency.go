@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// IdempotencyRecord stores the outcome of a request sent with an Idempotency-Key
+// header so that a retried request can be answered without executing it again.
+type IdempotencyRecord struct {
+	Key          string            `gorm:"primaryKey;type:text" json:"key"`
+	RequestHash  string            `gorm:"not null" json:"request_hash"`
+	Status       IdempotencyStatus `gorm:"not null" json:"status"`
+	StatusCode   int               `json:"status_code"`
+	ResponseBody string            `gorm:"type:text" json:"response_body"`
+	CreatedAt    time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// IdempotencyStatus is the processing status of an idempotent request.
+type IdempotencyStatus string
+
+const (
+	// IdempotencyInProgress is the status while the original request is being handled.
+	IdempotencyInProgress = IdempotencyStatus("in_progress")
+	// IdempotencyCompleted is the status once the response has been stored.
+	IdempotencyCompleted = IdempotencyStatus("completed")
+)
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrLimitExceeded is the error for an outgoing transaction over a spending limit of its wallet.
+	ErrLimitExceeded = fmt.Errorf("spending limit exceeded")
+	// ErrLimitNotFound is the error for removing a spending limit that is not set.
+	ErrLimitNotFound = fmt.Errorf("spending limit not found")
+)
+
+// SpendingLimit bounds the outgoing withdrawals and transfers of a wallet.
+//
+// A limit is set either for a single wallet (UserID) or for every wallet of an
+// account type (AcntType). The limit of a wallet takes the place of the limit
+// of its account type. Amounts are in minor units of Currency; outgoing
+// amounts in other currencies are converted at the current rate. A zero bound
+// is not enforced.
+type SpendingLimit struct {
+	ID             int       `gorm:"primaryKey" json:"id"`
+	UserID         *string   `gorm:"type:varchar(255);uniqueIndex" json:"user_id,omitempty"`
+	AcntType       *AcntType `gorm:"type:varchar(16);uniqueIndex" json:"acnt_type,omitempty"`
+	Currency       Currency  `gorm:"type:varchar(3);not null" json:"currency"`
+	MaxTransaction int64     `gorm:"not null;default:0" json:"max_transaction"` // Largest single outgoing transaction
+	DailyAmount    int64     `gorm:"not null;default:0" json:"daily_amount"`    // Outgoing total per UTC day
+	MonthlyAmount  int64     `gorm:"not null;default:0" json:"monthly_amount"`  // Outgoing total per UTC calendar month
+	DailyCount     int       `gorm:"not null;default:0" json:"daily_count"`     // Outgoing transactions per UTC day
+	MonthlyCount   int       `gorm:"not null;default:0" json:"monthly_count"`   // Outgoing transactions per UTC calendar month
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// SpendingUsage is the outgoing amount and number of transactions of a wallet
+// in one currency on one UTC day.
+type SpendingUsage struct {
+	UserID    string    `gorm:"primaryKey;type:varchar(255)" json:"user_id"`
+	Currency  Currency  `gorm:"primaryKey;type:varchar(3)" json:"currency"`
+	Day       time.Time `gorm:"primaryKey;type:date" json:"day"`
+	Amount    int64     `gorm:"not null;default:0" json:"amount"` // Amount in minor units of Currency
+	Count     int       `gorm:"not null;default:0" json:"count"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName keeps the usage table name singular, it holds counters rather than records.
+func (SpendingUsage) TableName() string {
+	return "spending_usage"
+}
+
+// SpendingRecord records the UTC day on which the outgoing transaction pair
+// PairID was counted in the spending usage of its sender. The ledger stamps the
+// pair with its own time when the outbox delivers it, which may fall on a later
+// day, so usage is released from the recorded day.
+type SpendingRecord struct {
+	PairID    string    `gorm:"primaryKey;type:varchar(36)" json:"pair_id"`
+	UserID    string    `gorm:"type:varchar(255);not null" json:"user_id"`
+	Day       time.Time `gorm:"type:date;not null" json:"day"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Spend is the outgoing amount and number of transactions of a wallet over a window.
+type Spend struct {
+	Amount int64 // Amount in minor units of the limit's currency
+	Count  int
+}
+
+// Check returns ErrLimitExceeded if an outgoing transaction of amount, in
+// minor units of the limit's currency, exceeds the limit given what was
+// already spent on the same day and in the same month.
+func (l *SpendingLimit) Check(amount int64, daily, monthly Spend) error {
+	switch {
+	case l.MaxTransaction > 0 && amount > l.MaxTransaction:
+		return ErrLimitExceeded
+	case l.DailyAmount > 0 && daily.Amount+amount > l.DailyAmount:
+		return ErrLimitExceeded
+	case l.MonthlyAmount > 0 && monthly.Amount+amount > l.MonthlyAmount:
+		return ErrLimitExceeded
+	case l.DailyCount > 0 && daily.Count+1 > l.DailyCount:
+		return ErrLimitExceeded
+	case l.MonthlyCount > 0 && monthly.Count+1 > l.MonthlyCount:
+		return ErrLimitExceeded
+	}
+	return nil
+}
+
+// DayStart returns the start of the UTC day of t, the window of daily limits.
+func DayStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// MonthStart returns the start of the UTC calendar month of t, the window of monthly limits.
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
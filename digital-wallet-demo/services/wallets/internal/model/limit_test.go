@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpendingLimit_Check(t *testing.T) {
+	limit := SpendingLimit{
+		Currency:       DefaultCurrency,
+		MaxTransaction: 5000,
+		DailyAmount:    8000,
+		MonthlyAmount:  20000,
+		DailyCount:     3,
+		MonthlyCount:   10,
+	}
+	tests := []struct {
+		name    string
+		limit   SpendingLimit
+		amount  int64
+		daily   Spend
+		monthly Spend
+		want    error
+	}{
+		{"within_limits", limit, 5000, Spend{Amount: 3000, Count: 2}, Spend{Amount: 15000, Count: 9}, nil},
+		{"max_transaction", limit, 5001, Spend{}, Spend{}, ErrLimitExceeded},
+		{"daily_amount", limit, 3000, Spend{Amount: 5001, Count: 1}, Spend{Amount: 5001, Count: 1}, ErrLimitExceeded},
+		{"monthly_amount", limit, 3000, Spend{}, Spend{Amount: 17001, Count: 5}, ErrLimitExceeded},
+		{"daily_count", limit, 100, Spend{Amount: 300, Count: 3}, Spend{Amount: 300, Count: 3}, ErrLimitExceeded},
+		{"monthly_count", limit, 100, Spend{}, Spend{Amount: 1000, Count: 10}, ErrLimitExceeded},
+		{"zero_bounds_not_enforced", SpendingLimit{DailyCount: 1}, 1000000, Spend{}, Spend{Amount: 1000000, Count: 50}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.limit.Check(tt.amount, tt.daily, tt.monthly))
+		})
+	}
+}
+
+func TestLimitWindows(t *testing.T) {
+	at := time.Date(2024, time.March, 15, 23, 30, 0, 0, time.FixedZone("UTC-5", -5*60*60))
+	assert.Equal(t, time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC), DayStart(at))
+	assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), MonthStart(at))
+}
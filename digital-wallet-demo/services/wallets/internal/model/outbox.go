@@ -0,0 +1,135 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OutboxEntry is a message recorded in the same database transaction as the
+// balance change it describes. The relay worker delivers it to the transaction
+// service afterwards, so a ledger entry is written if and only if the balance
+// change is committed. IdempotencyKey is sent with every delivery attempt so
+// that redeliveries are not recorded twice by the transaction service.
+type OutboxEntry struct {
+	ID             int             `gorm:"primaryKey" json:"id"`
+	IdempotencyKey string          `gorm:"not null;uniqueIndex" json:"idempotency_key"`
+	EventType      OutboxEventType `gorm:"not null" json:"event_type"`
+	Payload        string          `gorm:"type:jsonb;not null" json:"payload"`
+	RequestID      string          `json:"request_id,omitempty"`              // Request that enqueued the entry, forwarded on delivery
+	DependsOn      *int            `gorm:"index" json:"depends_on,omitempty"` // Entry delivered before this one, such as the pair of a status change
+	Status         OutboxStatus    `gorm:"not null;default:'pending';index" json:"status"`
+	Attempts       int             `gorm:"not null;default:0" json:"attempts"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time       `gorm:"not null;index" json:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// OutboxEventType is the type of message stored in the outbox.
+type OutboxEventType string
+
+const (
+	// OutboxTransactionPair is a debit/credit pair to be recorded by the transaction service.
+	OutboxTransactionPair = OutboxEventType("transaction_pair")
+	// OutboxPairStatus is the decided status of a pending pair to be recorded by the transaction service.
+	OutboxPairStatus = OutboxEventType("pair_status")
+)
+
+// OutboxStatus is the delivery status of an outbox entry.
+type OutboxStatus string
+
+const (
+	// OutboxPending is the status of an entry waiting to be delivered.
+	OutboxPending = OutboxStatus("pending")
+	// OutboxDelivered is the status of an entry accepted by the transaction service.
+	OutboxDelivered = OutboxStatus("delivered")
+	// OutboxFailed is the status of an entry given up after Outbox.MaxAttempts
+	// failed deliveries, or whose DependsOn entry was given up. It is left for
+	// an operator to inspect and requeue.
+	OutboxFailed = OutboxStatus("failed")
+)
+
+// TransactionPair is the payload of an OutboxTransactionPair entry.
+type TransactionPair struct {
+	Debit  Transaction `json:"debit"`
+	Credit Transaction `json:"credit"`
+}
+
+// PairStatus is the payload of an OutboxPairStatus entry. The transaction
+// service sets the status of both transactions linked to ReviewID; Debit and
+// Credit describe them with their new status.
+type PairStatus struct {
+	ReviewID string            `json:"review_id"`
+	Status   TransactionStatus `json:"status"`
+	Debit    Transaction       `json:"debit"`
+	Credit   Transaction       `json:"credit"`
+}
+
+// NewTransactionPairEntry returns a pending outbox entry carrying the given transaction pair,
+// and links both transactions with a pair ID unless they already share one.
+func NewTransactionPairEntry(debitTxn, creditTxn *Transaction) (*OutboxEntry, error) {
+	linkPair(debitTxn, creditTxn)
+	return newOutboxEntry(OutboxTransactionPair, TransactionPair{Debit: *debitTxn, Credit: *creditTxn})
+}
+
+// NewPairStatusEntry returns a pending outbox entry setting the status of the
+// pending pair of a review. debitTxn and creditTxn carry the new status.
+func NewPairStatusEntry(reviewID string, debitTxn, creditTxn *Transaction) (*OutboxEntry, error) {
+	return newOutboxEntry(OutboxPairStatus, PairStatus{ReviewID: reviewID, Status: debitTxn.Status, Debit: *debitTxn, Credit: *creditTxn})
+}
+
+func newOutboxEntry(eventType OutboxEventType, payload any) (*OutboxEntry, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &OutboxEntry{
+		IdempotencyKey: "outbox-" + hex.EncodeToString(key),
+		EventType:      eventType,
+		Payload:        string(data),
+		Status:         OutboxPending,
+		NextAttemptAt:  time.Now(),
+	}, nil
+}
+
+// TransactionPair decodes the payload of an OutboxTransactionPair entry.
+func (e *OutboxEntry) TransactionPair() (*TransactionPair, error) {
+	var pair TransactionPair
+	if err := json.Unmarshal([]byte(e.Payload), &pair); err != nil {
+		return nil, err
+	}
+	return &pair, nil
+}
+
+// PairStatus decodes the payload of an OutboxPairStatus entry.
+func (e *OutboxEntry) PairStatus() (*PairStatus, error) {
+	var status PairStatus
+	if err := json.Unmarshal([]byte(e.Payload), &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Pair returns the transactions described by an entry of either event type.
+// The transactions of an OutboxPairStatus entry carry their new status.
+func (e *OutboxEntry) Pair() (*TransactionPair, error) {
+	switch e.EventType {
+	case OutboxTransactionPair:
+		return e.TransactionPair()
+	case OutboxPairStatus:
+		status, err := e.PairStatus()
+		if err != nil {
+			return nil, err
+		}
+		return &TransactionPair{Debit: status.Debit, Credit: status.Credit}, nil
+	}
+	return nil, fmt.Errorf("unknown outbox event type %q", e.EventType)
+}
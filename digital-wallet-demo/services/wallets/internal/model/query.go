@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// TransactionQuery selects a page of a wallet's transaction history from the
+// transaction microservice. Zero-valued filters are not applied and a zero
+// Limit uses the transaction service default.
+type TransactionQuery struct {
+	Limit           int
+	Cursor          string // Opaque cursor returned with the previous page
+	TransactionType TransactionType
+	OperationType   OperationType
+	Status          TransactionStatus
+	MinAmount       *int64
+	MaxAmount       *int64
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+}
+
+// IsDefault reports whether the query asks for the first page without filters,
+// the only page of a wallet's history that is cached.
+func (q TransactionQuery) IsDefault() bool {
+	return q == TransactionQuery{}
+}
+
+// TransactionPage is a page of a wallet's transaction history.
+// NextCursor is empty on the last page.
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+	// Unavailable is set on the empty page returned while the transaction service is unavailable
+	Unavailable bool `json:"-"`
+}
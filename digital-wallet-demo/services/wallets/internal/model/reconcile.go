@@ -0,0 +1,160 @@
+package model
+
+import "time"
+
+// ReconciliationSuspenseID is the ledger-only account used as the counterparty of
+// compensating adjustments. It has no wallet and is excluded from reconciliation.
+const ReconciliationSuspenseID = "reconciliation-suspense"
+
+// LedgerBalance is the net effect of all completed transactions of a wallet
+// in one currency as reported by the transaction microservice.
+type LedgerBalance struct {
+	SubjectWalletID string   `json:"subject_wallet_id"`
+	Currency        Currency `json:"currency"`
+	Credits         int64    `json:"credits"` // Sum of credit amounts in minor units
+	Debits          int64    `json:"debits"`  // Sum of debit amounts in minor units
+	Net             int64    `json:"net"`     // Credits minus debits in minor units
+	Entries         int64    `json:"entries"`
+}
+
+// BalanceKey identifies the balance of a wallet in one currency.
+type BalanceKey struct {
+	WalletID string
+	Currency Currency
+}
+
+// Key returns the balance the ledger entries belong to.
+// Entries recorded before currencies were introduced are in the default currency.
+func (l LedgerBalance) Key() BalanceKey {
+	currency := l.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return BalanceKey{WalletID: l.SubjectWalletID, Currency: currency}
+}
+
+// ReconciliationStatus is the outcome of reconciling a single wallet.
+type ReconciliationStatus string
+
+const (
+	// Reconciled is the status of a wallet whose balance matches the ledger.
+	Reconciled = ReconciliationStatus("ok")
+	// Drifted is the status of a wallet whose balance does not match the ledger.
+	Drifted = ReconciliationStatus("drift")
+	// MissingWallet is the status of a ledger account without a wallet.
+	MissingWallet = ReconciliationStatus("missing_wallet")
+)
+
+// WalletReconciliation compares the stored balance of a wallet in one currency with its ledger.
+//
+// InFlight is the net of the wallet's outbox entries that have not been
+// delivered yet: the balance already includes them but the ledger does not.
+// Drift is Balance - (Ledger + InFlight); a positive drift means the ledger
+// is missing credits, a negative drift means it is missing debits.
+type WalletReconciliation struct {
+	WalletID string               `json:"wallet_id"`
+	Currency Currency             `json:"currency"`
+	Status   ReconciliationStatus `json:"status"`
+	Balance  int64                `json:"balance"`
+	Ledger   int64                `json:"ledger"`
+	InFlight int64                `json:"in_flight"`
+	Drift    int64                `json:"drift"`
+}
+
+// ReconciliationReport is the result of reconciling all wallets against the ledger.
+type ReconciliationReport struct {
+	GeneratedAt time.Time              `json:"generated_at"`
+	Checked     int                    `json:"checked"`
+	Drifted     int                    `json:"drifted"`
+	TotalDrift  int64                  `json:"total_drift"` // Sum of absolute drifts in minor units, across currencies
+	Wallets     []WalletReconciliation `json:"wallets"`
+}
+
+// NewReconciliationReport compares wallet balances with ledger balances, one
+// entry per wallet and currency. Wallets must have their Balances loaded.
+// inFlight holds the undelivered outbox net per wallet and currency.
+func NewReconciliationReport(wallets []Wallet, ledger []LedgerBalance, inFlight map[BalanceKey]int64) *ReconciliationReport {
+	ledgerNet := make(map[BalanceKey]int64, len(ledger))
+	for _, l := range ledger {
+		ledgerNet[l.Key()] += l.Net
+	}
+
+	report := &ReconciliationReport{GeneratedAt: time.Now()}
+	seen := make(map[BalanceKey]bool, len(wallets))
+	hasWallet := make(map[string]bool, len(wallets))
+	addBalance := func(walletID string, currency Currency, balance int64) {
+		key := BalanceKey{WalletID: walletID, Currency: currency}
+		seen[key] = true
+		report.add(WalletReconciliation{
+			WalletID: walletID,
+			Currency: currency,
+			Balance:  balance,
+			Ledger:   ledgerNet[key],
+			InFlight: inFlight[key],
+		})
+	}
+	for _, w := range wallets {
+		hasWallet[w.UserID] = true
+		addBalance(w.UserID, w.Currency, w.Balance)
+		for _, b := range w.Balances {
+			addBalance(w.UserID, b.Currency, b.Balance)
+		}
+	}
+
+	// Ledger accounts without a wallet balance. A wallet that has ledger entries
+	// in a currency it holds no balance in is compared against a zero balance.
+	for _, l := range ledger {
+		key := l.Key()
+		if seen[key] || l.SubjectWalletID == ReconciliationSuspenseID {
+			continue
+		}
+		seen[key] = true
+		var status ReconciliationStatus
+		if !hasWallet[key.WalletID] {
+			status = MissingWallet
+		}
+		report.add(WalletReconciliation{
+			WalletID: key.WalletID,
+			Currency: key.Currency,
+			Status:   status,
+			Ledger:   ledgerNet[key],
+			InFlight: inFlight[key],
+		})
+	}
+
+	return report
+}
+
+// add computes the drift of r and appends it to the report.
+func (rp *ReconciliationReport) add(r WalletReconciliation) {
+	r.Drift = r.Balance - (r.Ledger + r.InFlight)
+	if r.Status == "" {
+		r.Status = Reconciled
+		if r.Drift != 0 {
+			r.Status = Drifted
+		}
+	}
+
+	rp.Checked++
+	if r.Status != Reconciled {
+		rp.Drifted++
+		if r.Drift < 0 {
+			rp.TotalDrift -= r.Drift
+		} else {
+			rp.TotalDrift += r.Drift
+		}
+	}
+	rp.Wallets = append(rp.Wallets, r)
+}
+
+// OnlyDrift returns a copy of the report without the reconciled wallets.
+func (rp *ReconciliationReport) OnlyDrift() *ReconciliationReport {
+	filtered := *rp
+	filtered.Wallets = nil
+	for _, r := range rp.Wallets {
+		if r.Status != Reconciled {
+			filtered.Wallets = append(filtered.Wallets, r)
+		}
+	}
+	return &filtered
+}
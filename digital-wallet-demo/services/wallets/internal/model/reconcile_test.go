@@ -0,0 +1,48 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReconciliationReport(t *testing.T) {
+	wallets := []Wallet{
+		{UserID: "user-ok", Currency: "USD", Balance: 3000, Balances: []WalletBalance{{Currency: "EUR", Balance: 700}}},
+		{UserID: "user-in-flight", Currency: "USD", Balance: 1500},
+		{UserID: "user-missing-credit", Currency: "USD", Balance: 2000},
+		{UserID: "user-missing-debit", Currency: "USD", Balance: 500},
+	}
+	ledger := []LedgerBalance{
+		{SubjectWalletID: "user-ok", Currency: "USD", Net: 3000},
+		{SubjectWalletID: "user-ok", Currency: "EUR", Net: 700},
+		{SubjectWalletID: "user-ok", Currency: "JPY", Net: 50},
+		{SubjectWalletID: "user-in-flight", Net: 1000}, // recorded before currencies, counted as USD
+		{SubjectWalletID: "user-missing-credit", Currency: "USD", Net: 1200},
+		{SubjectWalletID: "user-missing-debit", Currency: "USD", Net: 800},
+		{SubjectWalletID: "deleted-user", Currency: "USD", Net: 100},
+		{SubjectWalletID: ReconciliationSuspenseID, Net: -500},
+	}
+	inFlight := map[BalanceKey]int64{{WalletID: "user-in-flight", Currency: "USD"}: 500}
+
+	report := NewReconciliationReport(wallets, ledger, inFlight)
+
+	want := []WalletReconciliation{
+		{WalletID: "user-ok", Currency: "USD", Status: Reconciled, Balance: 3000, Ledger: 3000},
+		{WalletID: "user-ok", Currency: "EUR", Status: Reconciled, Balance: 700, Ledger: 700},
+		{WalletID: "user-in-flight", Currency: "USD", Status: Reconciled, Balance: 1500, Ledger: 1000, InFlight: 500},
+		{WalletID: "user-missing-credit", Currency: "USD", Status: Drifted, Balance: 2000, Ledger: 1200, Drift: 800},
+		{WalletID: "user-missing-debit", Currency: "USD", Status: Drifted, Balance: 500, Ledger: 800, Drift: -300},
+		{WalletID: "user-ok", Currency: "JPY", Status: Drifted, Ledger: 50, Drift: -50},
+		{WalletID: "deleted-user", Currency: "USD", Status: MissingWallet, Ledger: 100, Drift: -100},
+	}
+	assert.Equal(t, want, report.Wallets)
+	assert.Equal(t, 7, report.Checked)
+	assert.Equal(t, 4, report.Drifted)
+	assert.Equal(t, int64(1250), report.TotalDrift)
+
+	drifted := report.OnlyDrift()
+	assert.Len(t, drifted.Wallets, 4)
+	assert.Equal(t, 4, drifted.Drifted)
+	assert.Len(t, report.Wallets, 7)
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	// ErrTransactionNotFound is the error for an unknown ledger transaction ID.
+	ErrTransactionNotFound = fmt.Errorf("transaction not found")
+	// ErrNotReversible is the error for a transaction that cannot be reversed.
+	ErrNotReversible = fmt.Errorf("transaction cannot be reversed")
+	// ErrAlreadyReversed is the error for a transaction that was already reversed.
+	ErrAlreadyReversed = fmt.Errorf("transaction already reversed")
+	// ErrReversalAmount is the error for a reversal of more than the transaction amount.
+	ErrReversalAmount = fmt.Errorf("reversal amount exceeds transaction amount")
+)
+
+// Reversal records that a ledger transaction was reversed. TransactionID is
+// the primary key and PairID is unique, so that a transaction pair is reversed
+// at most once, through either of its transactions.
+type Reversal struct {
+	TransactionID string    `gorm:"primaryKey;type:varchar(36)" json:"transaction_id"`
+	PairID        *string   `gorm:"type:varchar(36);uniqueIndex" json:"pair_id,omitempty"` // Pair of the reversed transaction, unset for transactions recorded before pairs
+	Amount        int64     `gorm:"not null" json:"amount"`                                // Amount reversed in minor units of Currency
+	Currency      Currency  `gorm:"type:varchar(3);not null" json:"currency"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// CheckReversible returns an error if amount of the transaction cannot be reversed.
+// Completed deposits, withdrawals and transfers can be reversed, except for
+// reversals themselves.
+func (t *Transaction) CheckReversible(amount int64) error {
+	if t.Status != Completed || t.ReversalOf != nil || t.TransactionType == Adjustment {
+		return ErrNotReversible
+	}
+	if amount > t.Amount {
+		return ErrReversalAmount
+	}
+	return nil
+}
+
+// ReversalCounterpart returns the amount, currency and exchange rate of the
+// other side of a reversal of amount of the transaction. The other side of a
+// conversion is reversed in proportion, rounded down, at the inverse of the
+// original rate, as the debited and credited currencies swap.
+func (t *Transaction) ReversalCounterpart(amount int64) (int64, Currency, string, error) {
+	if t.FXRate == "" {
+		return amount, t.Currency, "", nil
+	}
+	rate, ok := new(big.Rat).SetString(t.FXRate)
+	if !ok || rate.Sign() <= 0 || t.Amount <= 0 {
+		return 0, "", "", fmt.Errorf("invalid conversion on transaction %s", t.ID)
+	}
+	counter := new(big.Int).Mul(big.NewInt(amount), big.NewInt(t.CounterAmount))
+	counter.Quo(counter, big.NewInt(t.Amount))
+	return counter.Int64(), t.CounterCurrency, new(big.Rat).Inv(rate).FloatString(FXRatePrecision), nil
+}
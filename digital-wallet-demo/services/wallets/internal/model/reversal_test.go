@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction_CheckReversible(t *testing.T) {
+	reversalOf := "01890a5d-ac96-774b-bcce-b302099a8001"
+	tests := []struct {
+		name   string
+		txn    Transaction
+		amount int64
+		want   error
+	}{
+		{"full_amount", Transaction{TransactionType: Transfer, Amount: 1000, Status: Completed}, 1000, nil},
+		{"partial_amount", Transaction{TransactionType: Deposit, Amount: 1000, Status: Completed}, 400, nil},
+		{"exceeds_amount", Transaction{TransactionType: Transfer, Amount: 1000, Status: Completed}, 1001, ErrReversalAmount},
+		{"pending", Transaction{TransactionType: Transfer, Amount: 1000, Status: Pending}, 1000, ErrNotReversible},
+		{"adjustment", Transaction{TransactionType: Adjustment, Amount: 1000, Status: Completed}, 1000, ErrNotReversible},
+		{"reversal", Transaction{TransactionType: Transfer, Amount: 1000, Status: Completed, ReversalOf: &reversalOf}, 1000, ErrNotReversible},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.txn.CheckReversible(tt.amount))
+		})
+	}
+}
+
+func TestTransaction_ReversalCounterpart(t *testing.T) {
+	tests := []struct {
+		name         string
+		txn          Transaction
+		amount       int64
+		wantAmount   int64
+		wantCurrency Currency
+		wantRate     string
+	}{
+		{"same_currency", Transaction{Amount: 1000, Currency: "EUR"}, 400, 400, "EUR", ""},
+		{"full_conversion", Transaction{Amount: 3000, Currency: "USD", FXRate: "0.92000000", CounterAmount: 2760, CounterCurrency: "EUR"}, 3000, 2760, "EUR", "1.08695652"},
+		{"partial_conversion_rounds_down", Transaction{Amount: 3000, Currency: "USD", FXRate: "0.92000000", CounterAmount: 2760, CounterCurrency: "EUR"}, 1001, 920, "EUR", "1.08695652"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, currency, rate, err := tt.txn.ReversalCounterpart(tt.amount)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAmount, amount)
+			assert.Equal(t, tt.wantCurrency, currency)
+			assert.Equal(t, tt.wantRate, rate)
+		})
+	}
+}
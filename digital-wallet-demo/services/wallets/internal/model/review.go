@@ -0,0 +1,154 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrReviewNotFound is the error for an unknown review ID.
+	ErrReviewNotFound = fmt.Errorf("review not found")
+	// ErrReviewDecided is the error for approving or rejecting a review that was already decided.
+	ErrReviewDecided = fmt.Errorf("review was already decided")
+	// ErrReviewFlagNotFound is the error for removing the review flag of a wallet that is not flagged.
+	ErrReviewFlagNotFound = fmt.Errorf("review flag not found")
+)
+
+// ReviewStatus is the status of a review.
+type ReviewStatus string
+
+const (
+	// ReviewPending is the status of a review waiting for an admin decision.
+	ReviewPending = ReviewStatus("pending")
+	// ReviewApproved is the status of a review whose transaction was completed.
+	ReviewApproved = ReviewStatus("approved")
+	// ReviewRejected is the status of a review whose transaction was cancelled.
+	ReviewRejected = ReviewStatus("rejected")
+)
+
+// ReviewReason is the reason a transaction was queued for review.
+type ReviewReason string
+
+const (
+	// ReviewRisk is the reason of a transaction held by a risk rule, such as an amount threshold.
+	ReviewRisk = ReviewReason("risk")
+	// ReviewFlagged is the reason of a transaction from a wallet flagged by an admin.
+	ReviewFlagged = ReviewReason("flagged")
+)
+
+// Review is a transfer or withdrawal waiting for an admin decision.
+//
+// Its transaction pair is recorded as pending by the transaction service and
+// no balance moves until it is approved. Meanwhile Amount stays part of the
+// sender's balance, but is not available to other outgoing transactions.
+// Approving the review moves the funds and completes the pair; rejecting it
+// releases the funds and cancels the pair.
+type Review struct {
+	ID              string          `gorm:"primaryKey;type:varchar(32)" json:"id"`
+	TransactionType TransactionType `gorm:"type:varchar(16);not null" json:"transaction_type"`
+	FromUserID      string          `gorm:"not null;index:idx_reviews_from_user_status" json:"from_user_id"`
+	ToUserID        string          `gorm:"not null" json:"to_user_id"`
+	Amount          int64           `gorm:"not null" json:"amount"` // Debited amount in minor units of Currency
+	Currency        Currency        `gorm:"type:varchar(3);not null" json:"currency"`
+	CreditAmount    int64           `gorm:"not null" json:"credit_amount"` // Credited amount in minor units of CreditCurrency
+	CreditCurrency  Currency        `gorm:"type:varchar(3);not null" json:"credit_currency"`
+	FXRate          string          `json:"fx_rate,omitempty"` // Rate of a conversion
+	PairID          string          `gorm:"type:varchar(36)" json:"pair_id,omitempty"`
+	Reference       string          `gorm:"type:varchar(128)" json:"reference,omitempty"`
+	Reason          ReviewReason    `gorm:"type:varchar(16);not null" json:"reason"`
+	Rule            string          `json:"rule,omitempty"` // Name of the risk rule that held the transaction
+	Status          ReviewStatus    `gorm:"type:varchar(16);not null;default:'pending';index:idx_reviews_from_user_status;index" json:"status"`
+	Note            string          `json:"note,omitempty"` // Note of the admin decision
+	DecidedAt       *time.Time      `json:"decided_at,omitempty"`
+	CreatedAt       time.Time       `gorm:"autoCreateTime;index" json:"created_at"`
+	UpdatedAt       time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// NewReview returns a pending review of the given transaction pair and links
+// both transactions to it, so that the transaction service can settle them
+// together once the review is decided.
+func NewReview(debitTxn, creditTxn *Transaction, reason ReviewReason, rule string) (*Review, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	linkPair(debitTxn, creditTxn)
+	r := &Review{
+		ID:              hex.EncodeToString(id),
+		TransactionType: debitTxn.TransactionType,
+		FromUserID:      debitTxn.SubjectWalletID,
+		ToUserID:        creditTxn.SubjectWalletID,
+		Amount:          debitTxn.Amount,
+		Currency:        debitTxn.Currency,
+		CreditAmount:    creditTxn.Amount,
+		CreditCurrency:  creditTxn.Currency,
+		FXRate:          debitTxn.FXRate,
+		PairID:          debitTxn.PairID,
+		Reference:       debitTxn.Reference,
+		Reason:          reason,
+		Rule:            rule,
+		Status:          ReviewPending,
+	}
+	debitTxn.ReviewID, creditTxn.ReviewID = &r.ID, &r.ID
+	return r, nil
+}
+
+// Transactions returns the transaction pair of the review with the given status.
+func (r *Review) Transactions(status TransactionStatus) (debitTxn, creditTxn *Transaction) {
+	debitTxn = &Transaction{
+		SubjectWalletID: r.FromUserID,
+		ObjectWalletID:  r.ToUserID,
+		TransactionType: r.TransactionType,
+		OperationType:   Debit,
+		Amount:          r.Amount,
+		Currency:        r.Currency,
+		ReviewID:        &r.ID,
+		PairID:          r.PairID,
+		Reference:       r.Reference,
+		Status:          status,
+	}
+	creditTxn = &Transaction{
+		SubjectWalletID: r.ToUserID,
+		ObjectWalletID:  r.FromUserID,
+		TransactionType: r.TransactionType,
+		OperationType:   Credit,
+		Amount:          r.CreditAmount,
+		Currency:        r.CreditCurrency,
+		ReviewID:        &r.ID,
+		PairID:          r.PairID,
+		Reference:       r.Reference,
+		Status:          status,
+	}
+	if r.FXRate != "" {
+		debitTxn.FXRate, debitTxn.CounterAmount, debitTxn.CounterCurrency = r.FXRate, r.CreditAmount, r.CreditCurrency
+		creditTxn.FXRate, creditTxn.CounterAmount, creditTxn.CounterCurrency = r.FXRate, r.Amount, r.Currency
+	}
+	return debitTxn, creditTxn
+}
+
+// CheckPending returns ErrReviewDecided unless the review waits for a decision.
+func (r *Review) CheckPending() error {
+	if r.Status != ReviewPending {
+		return ErrReviewDecided
+	}
+	return nil
+}
+
+// ReviewQuery selects reviews of the queue. Zero-valued filters are not applied.
+type ReviewQuery struct {
+	Status          ReviewStatus
+	Reason          ReviewReason
+	TransactionType TransactionType
+	UserID          string // Sender or receiver
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+}
+
+// ReviewFlag queues every outgoing transfer and withdrawal of a wallet for review.
+type ReviewFlag struct {
+	UserID    string    `gorm:"primaryKey;type:varchar(255)" json:"user_id"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
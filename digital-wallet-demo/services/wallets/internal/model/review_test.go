@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReview(t *testing.T) {
+	debitTxn := &Transaction{SubjectWalletID: "sender", ObjectWalletID: "receiver", TransactionType: Transfer, OperationType: Debit, Amount: 10000, Currency: "USD", FXRate: "0.92", CounterAmount: 9200, CounterCurrency: "EUR", Reference: "invoice-42", Status: Pending}
+	creditTxn := &Transaction{SubjectWalletID: "receiver", ObjectWalletID: "sender", TransactionType: Transfer, OperationType: Credit, Amount: 9200, Currency: "EUR", FXRate: "0.92", CounterAmount: 10000, CounterCurrency: "USD", Reference: "invoice-42", Status: Pending}
+
+	review, err := NewReview(debitTxn, creditTxn, ReviewRisk, "large-amount")
+	require.NoError(t, err)
+	assert.Len(t, review.ID, 32)
+	assert.Equal(t, ReviewPending, review.Status)
+
+	// Both transactions are linked to the review
+	require.NotNil(t, debitTxn.ReviewID)
+	require.NotNil(t, creditTxn.ReviewID)
+	assert.Equal(t, review.ID, *debitTxn.ReviewID)
+	assert.Equal(t, review.ID, *creditTxn.ReviewID)
+
+	// Both transactions are linked as a pair before they are recorded
+	assert.NotEmpty(t, review.PairID)
+	assert.Equal(t, review.PairID, debitTxn.PairID)
+	assert.Equal(t, review.PairID, creditTxn.PairID)
+
+	// The pair settled by a decision is the pair that was queued, with the new status
+	gotDebit, gotCredit := review.Transactions(Completed)
+	debitTxn.Status, creditTxn.Status = Completed, Completed
+	assert.Equal(t, debitTxn, gotDebit)
+	assert.Equal(t, creditTxn, gotCredit)
+}
+
+func TestReview_CheckPending(t *testing.T) {
+	assert.NoError(t, (&Review{Status: ReviewPending}).CheckPending())
+	assert.Equal(t, ErrReviewDecided, (&Review{Status: ReviewApproved}).CheckPending())
+	assert.Equal(t, ErrReviewDecided, (&Review{Status: ReviewRejected}).CheckPending())
+}
+
+func TestOutboxEntry_Pair(t *testing.T) {
+	debitTxn := &Transaction{SubjectWalletID: "sender", OperationType: Debit, Amount: 100, Currency: "USD", Status: Pending}
+	creditTxn := &Transaction{SubjectWalletID: "receiver", OperationType: Credit, Amount: 100, Currency: "USD", Status: Pending}
+	review, err := NewReview(debitTxn, creditTxn, ReviewFlagged, "")
+	require.NoError(t, err)
+
+	entry, err := NewPairStatusEntry(review.ID, debitTxn, creditTxn)
+	require.NoError(t, err)
+	assert.Equal(t, OutboxPairStatus, entry.EventType)
+
+	status, err := entry.PairStatus()
+	require.NoError(t, err)
+	assert.Equal(t, review.ID, status.ReviewID)
+	assert.Equal(t, Pending, status.Status)
+
+	pair, err := entry.Pair()
+	require.NoError(t, err)
+	assert.Equal(t, *debitTxn, pair.Debit)
+	assert.Equal(t, *creditTxn, pair.Credit)
+
+	_, err = (&OutboxEntry{EventType: "unknown", Payload: "{}"}).Pair()
+	assert.Error(t, err)
+}
+
+func TestNewTransactionPairEntry(t *testing.T) {
+	debitTxn := &Transaction{SubjectWalletID: "sender", OperationType: Debit, Amount: 100, Currency: "USD", Status: Completed}
+	creditTxn := &Transaction{SubjectWalletID: "receiver", OperationType: Credit, Amount: 100, Currency: "USD", Status: Completed}
+
+	entry, err := NewTransactionPairEntry(debitTxn, creditTxn)
+	require.NoError(t, err)
+	assert.Len(t, debitTxn.PairID, 36)
+	assert.Equal(t, debitTxn.PairID, creditTxn.PairID)
+
+	pair, err := entry.TransactionPair()
+	require.NoError(t, err)
+	assert.Equal(t, debitTxn.PairID, pair.Debit.PairID)
+	assert.Equal(t, debitTxn.PairID, pair.Credit.PairID)
+
+	// A pair that is already linked keeps its pair ID
+	pairID := debitTxn.PairID
+	_, err = NewTransactionPairEntry(debitTxn, creditTxn)
+	require.NoError(t, err)
+	assert.Equal(t, pairID, debitTxn.PairID)
+	assert.Equal(t, pairID, creditTxn.PairID)
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrRiskRejected is the error for an outgoing transaction rejected by a risk rule.
+var ErrRiskRejected = fmt.Errorf("transaction rejected by risk rules")
+
+// RiskAction is the outcome of the risk rules for a transaction.
+type RiskAction string
+
+const (
+	// RiskApprove lets a transaction complete.
+	RiskApprove = RiskAction("approve")
+	// RiskReject refuses a transaction, no funds move.
+	RiskReject = RiskAction("reject")
+	// RiskReview records a transaction as pending for a manual review, no funds move.
+	RiskReview = RiskAction("review")
+)
+
+// RiskRuleType is the kind of check made by a risk rule.
+type RiskRuleType string
+
+const (
+	// AmountRule matches transactions of at least an amount.
+	AmountRule = RiskRuleType("amount")
+	// NewWalletRule matches transactions from wallets created less than a cooling period ago.
+	NewWalletRule = RiskRuleType("newWallet")
+	// RecipientsRule matches transfers from a wallet that sent to too many recipients within a window.
+	RecipientsRule = RiskRuleType("recipients")
+	// RoundTripRule matches transfers back to a wallet that sent to the sender within a window.
+	RoundTripRule = RiskRuleType("roundTrip")
+)
+
+// RiskCheck is an outgoing transaction evaluated by the risk rules.
+type RiskCheck struct {
+	TransactionType TransactionType
+	FromUserID      string
+	ToUserID        string // Receiver of a transfer, or provider of a withdrawal
+	Amount          int64  // Amount in minor units of Currency
+	Currency        Currency
+	WalletCreatedAt time.Time // Creation time of the sending wallet
+	At              time.Time
+}
+
+// RiskDecision records the outcome of the risk rules for a transaction, with
+// the rule that triggered it. Rule is empty if no rule matched and the
+// transaction was approved by default.
+type RiskDecision struct {
+	ID              int             `gorm:"primaryKey" json:"id"`
+	TransactionType TransactionType `gorm:"type:varchar(16);not null" json:"transaction_type"`
+	FromUserID      string          `gorm:"type:varchar(255);not null;index:idx_risk_decisions_from_user_created" json:"from_user_id"`
+	ToUserID        string          `gorm:"type:varchar(255);not null;index:idx_risk_decisions_to_user_created" json:"to_user_id"`
+	Amount          int64           `gorm:"not null" json:"amount"` // Amount in minor units of Currency
+	Currency        Currency        `gorm:"type:varchar(3);not null" json:"currency"`
+	Action          RiskAction      `gorm:"type:varchar(16);not null" json:"action"`
+	Rule            string          `gorm:"type:varchar(100)" json:"rule,omitempty"`
+	CreatedAt       time.Time       `gorm:"not null;index:idx_risk_decisions_from_user_created;index:idx_risk_decisions_to_user_created" json:"created_at"`
+}
+
+// NewRiskDecision returns the decision of action for the checked transaction, triggered by rule.
+func NewRiskDecision(check RiskCheck, action RiskAction, rule string) *RiskDecision {
+	return &RiskDecision{
+		TransactionType: check.TransactionType,
+		FromUserID:      check.FromUserID,
+		ToUserID:        check.ToUserID,
+		Amount:          check.Amount,
+		Currency:        check.Currency,
+		Action:          action,
+		Rule:            rule,
+		CreatedAt:       check.At,
+	}
+}
@@ -0,0 +1,176 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// ErrScheduleNotFound is the error for an unknown schedule ID.
+	ErrScheduleNotFound = fmt.Errorf("schedule not found")
+	// ErrInvalidSchedule is the error for a schedule with an invalid recurrence or start time.
+	ErrInvalidSchedule = fmt.Errorf("invalid schedule")
+	// ErrScheduleState is the error for pausing or resuming a schedule that is not active or paused.
+	ErrScheduleState = fmt.Errorf("schedule cannot be changed in its current status")
+	// ErrScheduleConflict is the error for a change of a schedule that the runner advanced concurrently.
+	ErrScheduleConflict = fmt.Errorf("schedule was changed concurrently")
+)
+
+// Recurrence is how often a scheduled transfer runs.
+type Recurrence string
+
+const (
+	// Once runs a scheduled transfer a single time, at its start time.
+	Once = Recurrence("once")
+	// Daily runs a scheduled transfer every day at the time of day of its start time.
+	Daily = Recurrence("daily")
+	// Weekly runs a scheduled transfer every week on the weekday and time of its start time.
+	Weekly = Recurrence("weekly")
+	// Monthly runs a scheduled transfer every month on the day and time of its start time.
+	// In shorter months it runs on the last day of the month.
+	Monthly = Recurrence("monthly")
+	// Cron runs a scheduled transfer at the times of a standard 5-field cron expression, in UTC.
+	Cron = Recurrence("cron")
+)
+
+// RecurrenceMap is a map of schedule recurrences.
+var RecurrenceMap = map[Recurrence]bool{
+	Once:    true,
+	Daily:   true,
+	Weekly:  true,
+	Monthly: true,
+	Cron:    true,
+}
+
+// ScheduleStatus is the status of a schedule.
+type ScheduleStatus string
+
+const (
+	// ScheduleActive is the status of a schedule that runs when due.
+	ScheduleActive = ScheduleStatus("active")
+	// SchedulePaused is the status of a schedule that is skipped until it is resumed.
+	SchedulePaused = ScheduleStatus("paused")
+	// ScheduleCompleted is the status of a schedule without further runs.
+	ScheduleCompleted = ScheduleStatus("completed")
+)
+
+// RunStatus is the outcome of a scheduled transfer run.
+type RunStatus string
+
+const (
+	// RunSucceeded is the outcome of a run whose transfer was made.
+	RunSucceeded = RunStatus("succeeded")
+	// RunFailed is the outcome of a run whose transfer was rejected.
+	RunFailed = RunStatus("failed")
+)
+
+// Schedule is a transfer from FromUserID to ToUserID made at StartAt and, for
+// a recurring schedule, at every following occurrence of its recurrence.
+type Schedule struct {
+	ID            int            `gorm:"primaryKey" json:"id"`
+	FromUserID    string         `gorm:"not null;index" json:"from_user_id"`
+	ToUserID      string         `gorm:"not null" json:"to_user_id"`
+	Amount        int64          `gorm:"not null" json:"amount"` // Amount in minor units of Currency
+	Currency      Currency       `gorm:"type:varchar(3)" json:"currency,omitempty"`
+	ToCurrency    Currency       `gorm:"type:varchar(3)" json:"to_currency,omitempty"`
+	Recurrence    Recurrence     `gorm:"type:varchar(16);not null" json:"recurrence"`
+	CronExpr      string         `gorm:"type:varchar(100)" json:"cron_expr,omitempty"`
+	StartAt       time.Time      `gorm:"not null" json:"start_at"`
+	NextRunAt     *time.Time     `gorm:"index" json:"next_run_at,omitempty"` // Unset once the schedule has no further runs
+	Status        ScheduleStatus `gorm:"type:varchar(16);not null;default:'active'" json:"status"`
+	LastRunAt     *time.Time     `json:"last_run_at,omitempty"`
+	LastRunStatus RunStatus      `gorm:"type:varchar(16)" json:"last_run_status,omitempty"`
+	LastError     string         `gorm:"type:text" json:"last_error,omitempty"`
+	RunCount      int            `gorm:"not null;default:0" json:"run_count"`
+	FailureCount  int            `gorm:"not null;default:0" json:"failure_count"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// CheckRecurrence returns ErrInvalidSchedule unless the recurrence is known and,
+// for a cron schedule, the cron expression parses.
+func (s *Schedule) CheckRecurrence() error {
+	if !RecurrenceMap[s.Recurrence] {
+		return ErrInvalidSchedule
+	}
+	if (s.Recurrence == Cron) != (s.CronExpr != "") {
+		return ErrInvalidSchedule
+	}
+	if s.Recurrence == Cron {
+		if _, err := cron.ParseStandard(s.CronExpr); err != nil {
+			return ErrInvalidSchedule
+		}
+	}
+	return nil
+}
+
+// NextRun returns the first occurrence of the schedule strictly after the
+// given time, or false if the schedule has no occurrence after it.
+func (s *Schedule) NextRun(after time.Time) (time.Time, bool, error) {
+	if after.Before(s.StartAt) && s.Recurrence != Cron {
+		return s.StartAt, true, nil
+	}
+
+	switch s.Recurrence {
+	case Once:
+		return time.Time{}, false, nil
+	case Daily:
+		return s.nextByDays(after, 1), true, nil
+	case Weekly:
+		return s.nextByDays(after, 7), true, nil
+	case Monthly:
+		n := (after.Year()-s.StartAt.Year())*12 + int(after.Month()-s.StartAt.Month())
+		next := addMonths(s.StartAt, n)
+		for !next.After(after) {
+			n++
+			next = addMonths(s.StartAt, n)
+		}
+		return next, true, nil
+	case Cron:
+		sched, err := cron.ParseStandard(s.CronExpr)
+		if err != nil {
+			return time.Time{}, false, ErrInvalidSchedule
+		}
+		// Occurrences before the start time are skipped
+		if after.Before(s.StartAt) {
+			after = s.StartAt.Add(-time.Nanosecond)
+		}
+		return sched.Next(after.UTC()), true, nil
+	}
+	return time.Time{}, false, ErrInvalidSchedule
+}
+
+// nextByDays returns the first time after the given time that is a multiple of days after StartAt.
+func (s *Schedule) nextByDays(after time.Time, days int) time.Time {
+	n := int(after.Sub(s.StartAt) / (time.Duration(days) * 24 * time.Hour))
+	next := s.StartAt.AddDate(0, 0, n*days)
+	for !next.After(after) {
+		n++
+		next = s.StartAt.AddDate(0, 0, n*days)
+	}
+	return next
+}
+
+// addMonths adds n months to t, moving to the last day of the month
+// where the day of t does not exist in it.
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// IsValidRecurrence checks if the recurrence is valid (once, daily, weekly, monthly, cron)
+func IsValidRecurrence(fl validator.FieldLevel) bool {
+	if fl.Field().IsZero() {
+		return true // Skip validation for empty or nil fields
+	}
+	recurrence := fl.Field().Interface().(Recurrence)
+	return RecurrenceMap[recurrence]
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_CheckRecurrence(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     error
+	}{
+		{"daily", Schedule{Recurrence: Daily}, nil},
+		{"cron", Schedule{Recurrence: Cron, CronExpr: "0 9 * * 1-5"}, nil},
+		{"unknown_recurrence", Schedule{Recurrence: "yearly"}, ErrInvalidSchedule},
+		{"cron_without_expression", Schedule{Recurrence: Cron}, ErrInvalidSchedule},
+		{"expression_without_cron", Schedule{Recurrence: Weekly, CronExpr: "0 9 * * *"}, ErrInvalidSchedule},
+		{"invalid_expression", Schedule{Recurrence: Cron, CronExpr: "every monday"}, ErrInvalidSchedule},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.schedule.CheckRecurrence())
+		})
+	}
+}
+
+func TestSchedule_NextRun(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		schedule Schedule
+		after    time.Time
+		want     time.Time
+		wantOK   bool
+	}{
+		{"once_before_start", Schedule{Recurrence: Once, StartAt: start}, start.Add(-time.Hour), start, true},
+		{"once_after_start", Schedule{Recurrence: Once, StartAt: start}, start, time.Time{}, false},
+		{"daily_before_start", Schedule{Recurrence: Daily, StartAt: start}, start.Add(-time.Hour), start, true},
+		{"daily_at_start", Schedule{Recurrence: Daily, StartAt: start}, start, start.AddDate(0, 0, 1), true},
+		{"daily_skips_missed_runs", Schedule{Recurrence: Daily, StartAt: start}, start.AddDate(0, 0, 10).Add(time.Hour), start.AddDate(0, 0, 11), true},
+		{"weekly", Schedule{Recurrence: Weekly, StartAt: start}, start.AddDate(0, 0, 8), start.AddDate(0, 0, 14), true},
+		{"monthly_clamps_to_month_end", Schedule{Recurrence: Monthly, StartAt: start}, start, time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC), true},
+		{"monthly_keeps_start_day", Schedule{Recurrence: Monthly, StartAt: start}, time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC), time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC), true},
+		{"cron_from_start", Schedule{Recurrence: Cron, CronExpr: "30 8 * * *", StartAt: start}, start.AddDate(0, 0, -5), time.Date(2024, time.February, 1, 8, 30, 0, 0, time.UTC), true},
+		{"cron_after", Schedule{Recurrence: Cron, CronExpr: "30 8 * * *", StartAt: start}, start.AddDate(0, 0, 2), time.Date(2024, time.February, 3, 8, 30, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := tt.schedule.NextRun(tt.after)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.True(t, tt.want.Equal(got), "got %v, want %v", got, tt.want)
+		})
+	}
+}
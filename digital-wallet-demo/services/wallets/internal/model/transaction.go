@@ -1,21 +1,41 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Transaction represents a wallet transaction for API communication
 // This is used for communication with the transaction microservice
 type Transaction struct {
-	ID              int               `json:"id"`
+	ID              string            `json:"id,omitempty"` // Public ID assigned by the transaction service
 	SubjectWalletID string            `json:"subject_wallet_id"`
 	ObjectWalletID  string            `json:"object_wallet_id,omitempty"`
 	TransactionType TransactionType   `json:"transaction_type"`
 	OperationType   OperationType     `json:"operation_type"`
-	Amount          int64             `json:"amount"` // Amount in cents
+	Amount          int64             `json:"amount"` // Amount in minor units of Currency
+	Currency        Currency          `json:"currency"`
+	FXRate          string            `json:"fx_rate,omitempty"`          // Price of one unit of the debited currency in the credited currency
+	CounterAmount   int64             `json:"counter_amount,omitempty"`   // Amount of the other side of a conversion
+	CounterCurrency Currency          `json:"counter_currency,omitempty"` // Currency of the other side of a conversion
+	ReversalOf      *string           `json:"reversal_of,omitempty"`      // ID of the transaction reversed by this one
+	ReviewID        *string           `json:"review_id,omitempty"`        // ID of the review deciding a pending transaction
+	PairID          string            `json:"pair_id,omitempty"`          // UUID shared by the debit and credit of a pair
+	Reference       string            `json:"reference,omitempty"`        // Client supplied reference of the pair
 	Status          TransactionStatus `json:"status"`
 	CreatedAt       time.Time         `json:"created_at"`
 	UpdatedAt       time.Time         `json:"updated_at"`
 }
 
+// linkPair assigns a new pair ID to both transactions of a pair unless the debit already has one.
+func linkPair(debitTxn, creditTxn *Transaction) {
+	if debitTxn.PairID == "" {
+		debitTxn.PairID = uuid.NewString()
+	}
+	creditTxn.PairID = debitTxn.PairID
+}
+
 // OperationType represents the operation type for transactions
 type OperationType string
 
@@ -36,6 +56,8 @@ const (
 	Withdraw = TransactionType("withdraw")
 	// Transfer transaction type
 	Transfer = TransactionType("transfer")
+	// Adjustment transaction type, recorded by ledger reconciliation
+	Adjustment = TransactionType("adjustment")
 )
 
 // TransactionStatus represents the status of a transaction
@@ -1,32 +1,98 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 )
 
 // Wallet is the model for the wallet endpoint.
+//
+// Balance is held in the wallet's base Currency. Balances in any other
+// currency are kept in Balances, one row per currency.
 type Wallet struct {
-	ID        int       `gorm:"primaryKey" json:"id"`
-	UserID    string    `gorm:"not null;uniqueIndex" json:"user_id"`
-	AcntType  AcntType  `gorm:"not null" json:"acnt_type"`
-	Balance   int64     `gorm:"default:0" json:"balance"` // Balance in cents
-	Status    Status    `json:"status"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID              int                `gorm:"primaryKey" json:"-"`             // Internal key, used for joins only
+	PublicID        string             `gorm:"type:uuid;uniqueIndex" json:"id"` // UUIDv7 identifying the wallet in the API
+	UserID          string             `gorm:"not null;uniqueIndex" json:"user_id"`
+	AcntType        AcntType           `gorm:"not null" json:"acnt_type"`
+	Currency        Currency           `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
+	Balance         int64              `gorm:"default:0" json:"balance"` // Balance in minor units of Currency
+	Balances        []WalletBalance    `gorm:"foreignKey:WalletID" json:"balances,omitempty"`
+	Reserved        map[Currency]int64 `gorm:"-" json:"-"` // Amounts reserved by active holds and pending reviews, loaded on demand
+	Status          Status             `json:"status"`
+	StatusReason    string             `gorm:"type:text" json:"status_reason,omitempty"` // Reason given for the last status change
+	StatusChangedAt *time.Time         `json:"status_changed_at,omitempty"`
+	CreatedAt       time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 // NewWallet returns a new instance of the wallet model.
 func NewWallet(userID string, acntType AcntType) *Wallet {
 	return &Wallet{
+		PublicID: NewPublicID(),
 		UserID:   userID,
 		AcntType: acntType,
+		Currency: DefaultCurrency,
 		Balance:  0,
 		Status:   Active,
 	}
 }
 
+// NewPublicID returns a new public wallet ID. IDs are UUIDv7, so they are
+// ordered by creation time without revealing how many wallets exist.
+func NewPublicID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// BalanceIn returns the wallet's balance in the given currency.
+// Balances must be loaded for currencies other than the base currency.
+func (w *Wallet) BalanceIn(currency Currency) int64 {
+	if currency == w.Currency {
+		return w.Balance
+	}
+	for _, b := range w.Balances {
+		if b.Currency == currency {
+			return b.Balance
+		}
+	}
+	return 0
+}
+
+// AvailableIn returns the wallet's balance in the given currency that is not
+// reserved by active holds or pending reviews. Reserved must be loaded for
+// reserved amounts to be deducted.
+func (w *Wallet) AvailableIn(currency Currency) int64 {
+	return w.BalanceIn(currency) - w.Reserved[currency]
+}
+
+// CheckTransactable returns an error if the wallet cannot send or receive funds.
+// Only active wallets can.
+func (w *Wallet) CheckTransactable() error {
+	switch w.Status {
+	case Active:
+		return nil
+	case Inactive:
+		return ErrWalletInactive
+	case Suspended:
+		return ErrWalletSuspended
+	case Closed:
+		return ErrWalletClosed
+	}
+	return fmt.Errorf("unknown wallet status %q", w.Status)
+}
+
+// WalletBalance is a wallet's balance in a currency other than its base currency.
+type WalletBalance struct {
+	ID        int       `gorm:"primaryKey" json:"-"`
+	WalletID  int       `gorm:"not null;uniqueIndex:idx_wallet_balances_wallet_currency" json:"-"`
+	Currency  Currency  `gorm:"type:varchar(3);not null;uniqueIndex:idx_wallet_balances_wallet_currency" json:"currency"`
+	Balance   int64     `gorm:"not null;default:0" json:"balance"` // Balance in minor units of Currency
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"-"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"-"`
+}
+
 // AcntType represents the account type
 type AcntType string
 
@@ -57,6 +123,8 @@ const (
 	Inactive = Status("inactive")
 	// Suspended is the status for a suspended wallet.
 	Suspended = Status("suspended")
+	// Closed is the status for a closed wallet. A closed wallet cannot be reopened.
+	Closed = Status("closed")
 )
 
 // StatusMap is a map of wallet status.
@@ -64,15 +132,22 @@ var StatusMap = map[Status]bool{
 	Active:    true,
 	Inactive:  true,
 	Suspended: true,
+	Closed:    true,
+}
+
+// CanTransitionTo reports whether a wallet in status s can be moved to status next.
+// Closed is final, every other status can be moved to any status.
+func (s Status) CanTransitionTo(next Status) bool {
+	return s != Closed && StatusMap[next]
 }
 
-// IsValidStatus checks if the status is valid (Active, Inactive, Suspended)
+// IsValidStatus checks if the status is valid (Active, Inactive, Suspended, Closed)
 func IsValidStatus(fl validator.FieldLevel) bool {
 	if fl.Field().IsZero() {
 		return true // Skip validation for empty or nil fields
 	}
 	status := fl.Field().Interface().(Status)
-	return status == Active || status == Inactive || status == Suspended
+	return StatusMap[status]
 }
 
 // IsValidAcntType checks if the account type is valid
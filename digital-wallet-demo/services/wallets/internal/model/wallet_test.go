@@ -0,0 +1,60 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWallet_PublicID(t *testing.T) {
+	first := NewWallet("test-user-001", User)
+	second := NewWallet("test-user-002", User)
+
+	for _, w := range []*Wallet{first, second} {
+		id, err := uuid.Parse(w.PublicID)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), id.Version())
+	}
+	assert.NotEqual(t, first.PublicID, second.PublicID)
+}
+
+func TestStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to Status
+		want     bool
+	}{
+		{Active, Suspended, true},
+		{Suspended, Active, true},
+		{Active, Inactive, true},
+		{Inactive, Active, true},
+		{Suspended, Closed, true},
+		{Closed, Active, false},
+		{Closed, Closed, false},
+		{Active, Status("frozen"), false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"_to_"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
+
+func TestWallet_CheckTransactable(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   error
+	}{
+		{Active, nil},
+		{Inactive, ErrWalletInactive},
+		{Suspended, ErrWalletSuspended},
+		{Closed, ErrWalletClosed},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			w := &Wallet{Status: tt.status}
+			assert.Equal(t, tt.want, w.CheckTransactable())
+		})
+	}
+}
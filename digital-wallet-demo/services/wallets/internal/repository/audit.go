@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+)
+
+// auditLockKey is the key of the advisory lock serializing appends to the audit log.
+const auditLockKey = 7_001_017
+
+// Audit provides database operations for the append-only audit log.
+type Audit interface {
+	// Append links the entry to the last entry of the log and inserts it within tx.
+	Append(tx *gorm.DB, entry *model.AuditEntry) error
+	// Walk calls fn with every entry of the log in chain order, reading batchSize entries at a time.
+	Walk(ctx context.Context, batchSize int, fn func(entry *model.AuditEntry) error) error
+}
+
+type audit struct {
+	db *gorm.DB
+}
+
+// NewAuditRepo creates a new audit log repository instance.
+func NewAuditRepo(db *gorm.DB) Audit {
+	return &audit{
+		db: db,
+	}
+}
+
+// Append inserts the entry within the given transaction after the last entry of the log.
+// Appends are serialized by a transaction-level advisory lock held until tx ends, so
+// that concurrent transactions cannot link two entries to the same predecessor.
+// Callers append after locking the wallets they change, so that the lock is
+// never held while waiting for a wallet.
+func (a *audit) Append(tx *gorm.DB, entry *model.AuditEntry) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditLockKey).Error; err != nil {
+		return err
+	}
+
+	var last []string
+	if err := tx.Model(&model.AuditEntry{}).Order("id desc").Limit(1).Pluck("hash", &last).Error; err != nil {
+		return err
+	}
+
+	// The first entry of the log links to the empty hash
+	prevHash := ""
+	if len(last) > 0 {
+		prevHash = last[0]
+	}
+	entry.Link(prevHash, time.Now())
+	return tx.Create(entry).Error
+}
+
+// Walk reads the log in ID order, which is the chain order as entries are appended one at a time.
+func (a *audit) Walk(ctx context.Context, batchSize int, fn func(entry *model.AuditEntry) error) error {
+	var entries []model.AuditEntry
+	return a.db.WithContext(ctx).Order("id").FindInBatches(&entries, batchSize, func(_ *gorm.DB, _ int) error {
+		for i := range entries {
+			if err := fn(&entries[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FXQuote provides database operations for locked exchange rate quotes.
+type FXQuote interface {
+	Create(ctx context.Context, quote *model.FXQuote) error
+
+	// FindForUpdate retrieves a quote and locks it until the end of tx.
+	FindForUpdate(tx *gorm.DB, id string) (*model.FXQuote, error)
+	MarkUsed(tx *gorm.DB, id string, usedAt time.Time) error
+}
+
+type fxQuote struct {
+	db *gorm.DB
+}
+
+// NewFXQuoteRepo creates a new FX quote repository instance.
+func NewFXQuoteRepo(db *gorm.DB) FXQuote {
+	return &fxQuote{
+		db: db,
+	}
+}
+
+// Create stores a new quote.
+func (q *fxQuote) Create(ctx context.Context, quote *model.FXQuote) error {
+	return q.db.WithContext(ctx).Create(quote).Error
+}
+
+// FindForUpdate retrieves a quote with a row-level lock, returns ErrQuoteNotFound if not exists.
+// The lock ensures a quote is redeemed by at most one concurrent transfer.
+func (q *fxQuote) FindForUpdate(tx *gorm.DB, id string) (*model.FXQuote, error) {
+	var quote model.FXQuote
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(&quote).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrQuoteNotFound
+		}
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// MarkUsed records that the quote was redeemed by a transfer.
+func (q *fxQuote) MarkUsed(tx *gorm.DB, id string, usedAt time.Time) error {
+	return tx.Model(&model.FXQuote{}).Where("id = ?", id).Update("used_at", usedAt).Error
+}
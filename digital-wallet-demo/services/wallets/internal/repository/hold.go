@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Hold provides database operations for holds on wallet funds.
+type Hold interface {
+	Create(tx *gorm.DB, hold *model.Hold) error
+	FindByID(ctx context.Context, id string) (*model.Hold, error)
+
+	// FindForUpdate retrieves a hold and locks it until the end of tx.
+	FindForUpdate(tx *gorm.DB, id string) (*model.Hold, error)
+	Update(tx *gorm.DB, hold *model.Hold) error
+
+	// SumActive returns the amounts held from a wallet at time now, per currency.
+	SumActive(ctx context.Context, userID string, now time.Time) (map[model.Currency]int64, error)
+	// SumActiveIn returns the amount held from a wallet in currency at time now, within tx.
+	SumActiveIn(tx *gorm.DB, userID string, currency model.Currency, now time.Time) (int64, error)
+
+	// ExpireDue marks active holds past their expiry at time now as expired.
+	ExpireDue(ctx context.Context, now time.Time) (int64, error)
+}
+
+type hold struct {
+	db *gorm.DB
+}
+
+// NewHoldRepo creates a new hold repository instance.
+func NewHoldRepo(db *gorm.DB) Hold {
+	return &hold{
+		db: db,
+	}
+}
+
+// Create stores a new hold within tx.
+func (h *hold) Create(tx *gorm.DB, hold *model.Hold) error {
+	return tx.Create(hold).Error
+}
+
+// FindByID retrieves a hold, returns ErrHoldNotFound if not exists.
+func (h *hold) FindByID(ctx context.Context, id string) (*model.Hold, error) {
+	var hold model.Hold
+	if err := h.db.WithContext(ctx).Where("id = ?", id).Take(&hold).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrHoldNotFound
+		}
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// FindForUpdate retrieves a hold with a row-level lock, returns ErrHoldNotFound if not exists.
+// The lock ensures a hold is captured or voided at most once.
+func (h *hold) FindForUpdate(tx *gorm.DB, id string) (*model.Hold, error) {
+	var hold model.Hold
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(&hold).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrHoldNotFound
+		}
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// Update saves the status and captured amount of a hold within tx.
+func (h *hold) Update(tx *gorm.DB, hold *model.Hold) error {
+	return tx.Model(hold).Select("status", "captured_amount", "updated_at").Updates(hold).Error
+}
+
+// SumActive returns the amounts held from a wallet at time now, per currency.
+// Holds past their expiry are not counted, even before they are marked expired.
+func (h *hold) SumActive(ctx context.Context, userID string, now time.Time) (map[model.Currency]int64, error) {
+	var rows []struct {
+		Currency model.Currency
+		Total    int64
+	}
+	if err := h.activeHolds(h.db.WithContext(ctx), userID, now).
+		Select("currency, SUM(amount) AS total").Group("currency").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	held := make(map[model.Currency]int64, len(rows))
+	for _, r := range rows {
+		held[r.Currency] = r.Total
+	}
+	return held, nil
+}
+
+// SumActiveIn returns the amount held from a wallet in currency at time now, within tx.
+func (h *hold) SumActiveIn(tx *gorm.DB, userID string, currency model.Currency, now time.Time) (int64, error) {
+	var total int64
+	err := h.activeHolds(tx, userID, now).Where("currency = ?", currency).
+		Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+	return total, err
+}
+
+// ExpireDue marks active holds past their expiry at time now as expired and returns their number.
+func (h *hold) ExpireDue(ctx context.Context, now time.Time) (int64, error) {
+	result := h.db.WithContext(ctx).Model(&model.Hold{}).
+		Where("status = ? AND expires_at <= ?", model.HoldActive, now).
+		Update("status", model.HoldExpired)
+	return result.RowsAffected, result.Error
+}
+
+func (h *hold) activeHolds(db *gorm.DB, userID string, now time.Time) *gorm.DB {
+	return db.Model(&model.Hold{}).
+		Where("from_user_id = ? AND status = ? AND expires_at > ?", userID, model.HoldActive, now)
+}
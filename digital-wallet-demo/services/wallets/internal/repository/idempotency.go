@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Idempotency provides database operations for idempotency keys.
+type Idempotency interface {
+	Reserve(ctx context.Context, record *model.IdempotencyRecord) (bool, error)
+	FindByKey(ctx context.Context, key string) (*model.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, statusCode int, responseBody string) error
+	Release(ctx context.Context, key string) error
+}
+
+type idempotency struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepo creates a new idempotency repository instance.
+func NewIdempotencyRepo(db *gorm.DB) Idempotency {
+	return &idempotency{
+		db: db,
+	}
+}
+
+// Reserve inserts the record unless its key already exists.
+// It reports whether the caller now owns the key.
+func (i *idempotency) Reserve(ctx context.Context, record *model.IdempotencyRecord) (bool, error) {
+	result := i.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindByKey retrieves a record by key, returns ErrNotFound if not exists.
+func (i *idempotency) FindByKey(ctx context.Context, key string) (*model.IdempotencyRecord, error) {
+	var record *model.IdempotencyRecord
+	err := i.db.WithContext(ctx).Where("key = ?", key).Take(&record).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+// Complete stores the response of the original request.
+func (i *idempotency) Complete(ctx context.Context, key string, statusCode int, responseBody string) error {
+	return i.db.WithContext(ctx).Model(&model.IdempotencyRecord{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"status":        model.IdempotencyCompleted,
+		"status_code":   statusCode,
+		"response_body": responseBody,
+	}).Error
+}
+
+// Release deletes a reserved key so that the request can be retried.
+func (i *idempotency) Release(ctx context.Context, key string) error {
+	return i.db.WithContext(ctx).Where("key = ?", key).Delete(&model.IdempotencyRecord{}).Error
+}
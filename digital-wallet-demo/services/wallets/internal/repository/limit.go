@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Limit provides database operations for spending limits and the usage they are checked against.
+type Limit interface {
+	FindAll(ctx context.Context) ([]model.SpendingLimit, error)
+	// FindApplicable returns the limit of the wallet, or else of its account type, or nil if neither is set.
+	FindApplicable(tx *gorm.DB, userID string, acntType model.AcntType) (*model.SpendingLimit, error)
+	// Upsert stores the limit within tx, replacing the limit already set for the same wallet or account type.
+	Upsert(tx *gorm.DB, limit *model.SpendingLimit) error
+	DeleteForWallet(tx *gorm.DB, userID string) error
+	DeleteForType(tx *gorm.DB, acntType model.AcntType) error
+
+	// UsageSince returns the daily usage of the wallet from the given day on.
+	UsageSince(tx *gorm.DB, userID string, since time.Time) ([]model.SpendingUsage, error)
+	// RecordUsage adds an outgoing transaction of amount in currency to the wallet's usage on day.
+	RecordUsage(tx *gorm.DB, userID string, currency model.Currency, day time.Time, amount int64) error
+	// ReleaseUsage takes amount in currency and count transactions off the wallet's usage on day.
+	ReleaseUsage(tx *gorm.DB, userID string, currency model.Currency, day time.Time, amount int64, count int) error
+	// RecordSpending stores the day the pair of record was counted as usage.
+	RecordSpending(tx *gorm.DB, record *model.SpendingRecord) error
+	// FindSpending returns the spending record of the pair, or nil if it has none.
+	FindSpending(tx *gorm.DB, pairID string) (*model.SpendingRecord, error)
+}
+
+type limit struct {
+	db *gorm.DB
+}
+
+// NewLimitRepo creates a new spending limit repository instance.
+func NewLimitRepo(db *gorm.DB) Limit {
+	return &limit{
+		db: db,
+	}
+}
+
+// FindAll retrieves every spending limit, account type limits first.
+func (r *limit) FindAll(ctx context.Context) ([]model.SpendingLimit, error) {
+	var limits []model.SpendingLimit
+	err := r.db.WithContext(ctx).Order("user_id NULLS FIRST").Order("acnt_type").Order("id").Find(&limits).Error
+	if err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// FindApplicable retrieves the limit of the wallet within tx, falling back to the limit of its account type.
+func (r *limit) FindApplicable(tx *gorm.DB, userID string, acntType model.AcntType) (*model.SpendingLimit, error) {
+	var limits []model.SpendingLimit
+	err := tx.Where("user_id = ? OR acnt_type = ?", userID, acntType).Find(&limits).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var applicable *model.SpendingLimit
+	for i := range limits {
+		if limits[i].UserID != nil {
+			return &limits[i], nil
+		}
+		applicable = &limits[i]
+	}
+	return applicable, nil
+}
+
+// Upsert inserts the limit, or updates the bounds of the limit set for the same wallet or account type.
+func (r *limit) Upsert(tx *gorm.DB, limit *model.SpendingLimit) error {
+	column := "acnt_type"
+	if limit.UserID != nil {
+		column = "user_id"
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: column}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"currency", "max_transaction", "daily_amount", "monthly_amount", "daily_count", "monthly_count", "updated_at",
+		}),
+	}).Create(limit).Error
+}
+
+// DeleteForWallet removes the limit of a wallet, returns ErrLimitNotFound if none is set.
+func (r *limit) DeleteForWallet(tx *gorm.DB, userID string) error {
+	return r.delete(tx.Where("user_id = ?", userID))
+}
+
+// DeleteForType removes the limit of an account type, returns ErrLimitNotFound if none is set.
+func (r *limit) DeleteForType(tx *gorm.DB, acntType model.AcntType) error {
+	return r.delete(tx.Where("acnt_type = ?", acntType))
+}
+
+func (r *limit) delete(scope *gorm.DB) error {
+	result := scope.Delete(&model.SpendingLimit{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrLimitNotFound
+	}
+	return nil
+}
+
+// UsageSince retrieves the usage rows of the wallet within tx from the given day on.
+func (r *limit) UsageSince(tx *gorm.DB, userID string, since time.Time) ([]model.SpendingUsage, error) {
+	var usage []model.SpendingUsage
+	err := tx.Where("user_id = ? AND day >= ?", userID, since).Find(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// RecordUsage increments the usage row of the wallet, currency and day within tx, creating it if needed.
+func (r *limit) RecordUsage(tx *gorm.DB, userID string, currency model.Currency, day time.Time, amount int64) error {
+	usage := &model.SpendingUsage{
+		UserID:   userID,
+		Currency: currency,
+		Day:      day,
+		Amount:   amount,
+		Count:    1,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "currency"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"amount":     gorm.Expr("spending_usage.amount + EXCLUDED.amount"),
+			"count":      gorm.Expr("spending_usage.count + 1"),
+			"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Create(usage).Error
+}
+
+// ReleaseUsage decrements the usage row of the wallet, currency and day within tx.
+// The usage never drops below zero, and a missing row is left missing.
+func (r *limit) ReleaseUsage(tx *gorm.DB, userID string, currency model.Currency, day time.Time, amount int64, count int) error {
+	return tx.Model(&model.SpendingUsage{}).
+		Where("user_id = ? AND currency = ? AND day = ?", userID, currency, day).
+		Updates(map[string]interface{}{
+			"amount": gorm.Expr("GREATEST(amount - ?, 0)", amount),
+			"count":  gorm.Expr("GREATEST(count - ?, 0)", count),
+		}).Error
+}
+
+// RecordSpending inserts the spending record within tx.
+func (r *limit) RecordSpending(tx *gorm.DB, record *model.SpendingRecord) error {
+	return tx.Create(record).Error
+}
+
+// FindSpending retrieves the spending record of the pair within tx.
+func (r *limit) FindSpending(tx *gorm.DB, pairID string) (*model.SpendingRecord, error) {
+	var records []model.SpendingRecord
+	if err := tx.Where("pair_id = ?", pairID).Limit(1).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return &records[0], nil
+}
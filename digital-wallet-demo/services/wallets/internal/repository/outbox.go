@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Outbox provides database operations for the transactional outbox.
+type Outbox interface {
+	// Enqueue writes an entry as part of the caller's transaction.
+	Enqueue(tx *gorm.DB, entry *model.OutboxEntry) error
+	// FindPairEntry returns the transaction_pair entry of the pair, or nil if none was written, within tx.
+	FindPairEntry(tx *gorm.DB, pairID string) (*model.OutboxEntry, error)
+
+	// Relay operations
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]model.OutboxEntry, error)
+	MarkDelivered(ctx context.Context, id int, deliveredAt time.Time) error
+	MarkRetry(ctx context.Context, id int, attempts int, lastErr string, nextAttemptAt time.Time) error
+	MarkFailed(ctx context.Context, id int, attempts int, lastErr string) error
+	// FailBlocked marks failed the pending entries depending on a failed entry and returns their number.
+	FailBlocked(ctx context.Context) (int64, error)
+
+	// FindPending lists the entries not yet delivered, including failed ones, without locking them.
+	FindPending(tx *gorm.DB) ([]model.OutboxEntry, error)
+
+	// FindLegacyReversalOf lists up to limit serial IDs of transactions reversed by undelivered entries recorded before public IDs.
+	FindLegacyReversalOf(ctx context.Context, limit int) ([]int64, error)
+	// ReplaceLegacyReversalOf replaces the serial ID of a reversed transaction in undelivered entries with its public ID.
+	ReplaceLegacyReversalOf(ctx context.Context, legacyID int64, publicID string) error
+}
+
+// legacyIDPattern matches the serial ID of a transaction recorded before
+// public IDs, which wallet tables keep until it is replaced by the public ID.
+const legacyIDPattern = "^[0-9]+$"
+
+type outbox struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepo creates a new outbox repository instance.
+func NewOutboxRepo(db *gorm.DB) Outbox {
+	return &outbox{
+		db: db,
+	}
+}
+
+// Enqueue inserts a new outbox entry within the given transaction.
+func (o *outbox) Enqueue(tx *gorm.DB, entry *model.OutboxEntry) error {
+	return tx.Create(entry).Error
+}
+
+// FindPairEntry retrieves the transaction_pair entry carrying the pair within tx.
+func (o *outbox) FindPairEntry(tx *gorm.DB, pairID string) (*model.OutboxEntry, error) {
+	var entries []model.OutboxEntry
+	err := tx.Where("event_type = ? AND payload #>> '{debit,pair_id}' = ?", model.OutboxTransactionPair, pairID).
+		Order("id").
+		Limit(1).
+		Find(&entries).Error
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// ClaimDue leases up to limit pending entries whose next attempt is due and
+// returns them. The lease moves their next attempt to leaseUntil, so that the
+// entries are delivered outside of any database transaction without being
+// claimed again; entries of a relay that stops before recording the outcome
+// are claimed again once the lease runs out. Rows locked by another relay
+// instance while it leases them are skipped.
+// An entry depending on another is only due once that entry was delivered, and
+// an entry reversing a transaction by its serial ID once it was replaced by
+// the public ID, which the transaction service requires.
+func (o *outbox) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]model.OutboxEntry, error) {
+	var entries []model.OutboxEntry
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.OutboxPending, now).
+			Where("(depends_on IS NULL OR depends_on IN (?))", o.db.Model(&model.OutboxEntry{}).Select("id").Where("status = ?", model.OutboxDelivered)).
+			Where("(payload #>> '{debit,reversal_of}' ~ ?) IS NOT TRUE AND (payload #>> '{credit,reversal_of}' ~ ?) IS NOT TRUE", legacyIDPattern, legacyIDPattern).
+			Order("id").
+			Limit(limit).
+			Find(&entries).Error
+		if err != nil || len(entries) == 0 {
+			return err
+		}
+
+		ids := make([]int, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.ID
+		}
+		return tx.Model(&model.OutboxEntry{}).Where("id IN ?", ids).Update("next_attempt_at", leaseUntil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// MarkDelivered flags a pending entry as accepted by the transaction service.
+func (o *outbox) MarkDelivered(ctx context.Context, id int, deliveredAt time.Time) error {
+	return o.db.WithContext(ctx).Model(&model.OutboxEntry{}).Where("id = ? AND status = ?", id, model.OutboxPending).Updates(map[string]interface{}{
+		"status":       model.OutboxDelivered,
+		"delivered_at": deliveredAt,
+		"last_error":   "",
+	}).Error
+}
+
+// MarkRetry records a failed delivery attempt of a pending entry and schedules the next one.
+func (o *outbox) MarkRetry(ctx context.Context, id int, attempts int, lastErr string, nextAttemptAt time.Time) error {
+	return o.db.WithContext(ctx).Model(&model.OutboxEntry{}).Where("id = ? AND status = ?", id, model.OutboxPending).Updates(map[string]interface{}{
+		"attempts":        attempts,
+		"last_error":      lastErr,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
+
+// MarkFailed records the last failed delivery attempt of a pending entry and
+// stops delivering it.
+func (o *outbox) MarkFailed(ctx context.Context, id int, attempts int, lastErr string) error {
+	return o.db.WithContext(ctx).Model(&model.OutboxEntry{}).Where("id = ? AND status = ?", id, model.OutboxPending).Updates(map[string]interface{}{
+		"status":     model.OutboxFailed,
+		"attempts":   attempts,
+		"last_error": lastErr,
+	}).Error
+}
+
+// FailBlocked marks failed the pending entries whose dependency was marked
+// failed, as they cannot be delivered before it. Requeuing the dependency
+// does not requeue them.
+func (o *outbox) FailBlocked(ctx context.Context) (int64, error) {
+	result := o.db.WithContext(ctx).Model(&model.OutboxEntry{}).
+		Where("status = ? AND depends_on IN (?)", model.OutboxPending, o.db.Model(&model.OutboxEntry{}).Select("id").Where("status = ?", model.OutboxFailed)).
+		Updates(map[string]interface{}{
+			"status":     model.OutboxFailed,
+			"last_error": "entry it depends on failed",
+		})
+	return result.RowsAffected, result.Error
+}
+
+// FindPending retrieves all entries that have not been delivered yet. Failed
+// entries are included: their balance changes are committed and reach the
+// ledger once an operator requeues them.
+func (o *outbox) FindPending(tx *gorm.DB) ([]model.OutboxEntry, error) {
+	var entries []model.OutboxEntry
+	if err := tx.Where("status <> ?", model.OutboxDelivered).Order("id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindLegacyReversalOf retrieves the serial IDs of the transactions reversed by
+// undelivered entries recorded before public IDs, from either side of a pair.
+func (o *outbox) FindLegacyReversalOf(ctx context.Context, limit int) ([]int64, error) {
+	var ids []int64
+	err := o.db.WithContext(ctx).Raw(`SELECT DISTINCT legacy_id FROM (
+		SELECT (payload #>> '{debit,reversal_of}')::BIGINT AS legacy_id FROM outbox_entries
+		WHERE status <> ? AND payload #>> '{debit,reversal_of}' ~ ?
+		UNION
+		SELECT (payload #>> '{credit,reversal_of}')::BIGINT AS legacy_id FROM outbox_entries
+		WHERE status <> ? AND payload #>> '{credit,reversal_of}' ~ ?
+	) AS legacy ORDER BY legacy_id LIMIT ?`,
+		model.OutboxDelivered, legacyIDPattern, model.OutboxDelivered, legacyIDPattern, limit).
+		Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ReplaceLegacyReversalOf replaces the serial ID of a reversed transaction on
+// either side of undelivered entries with its public ID.
+func (o *outbox) ReplaceLegacyReversalOf(ctx context.Context, legacyID int64, publicID string) error {
+	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, side := range []string{"debit", "credit"} {
+			path := "'{" + side + ",reversal_of}'"
+			err := tx.Model(&model.OutboxEntry{}).
+				Where("status <> ? AND payload #>> "+path+" = ?", model.OutboxDelivered, strconv.FormatInt(legacyID, 10)).
+				Update("payload", gorm.Expr("jsonb_set(payload, "+path+", to_jsonb(?::TEXT))", publicID)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reversal provides database operations for transaction reversals.
+type Reversal interface {
+	// Reserve records the reversal within tx unless its transaction, or the other transaction of its pair, was already reversed.
+	// It reports whether the reversal was recorded.
+	Reserve(tx *gorm.DB, reversal *model.Reversal) (bool, error)
+
+	// FindLegacyIDs lists up to limit serial IDs of transactions reversed before public IDs.
+	FindLegacyIDs(ctx context.Context, limit int) ([]int64, error)
+	// ReplaceLegacyID keys the reversal of a transaction reversed before public IDs by its public ID.
+	ReplaceLegacyID(ctx context.Context, legacyID int64, publicID string) error
+}
+
+type reversal struct {
+	db *gorm.DB
+}
+
+// NewReversalRepo creates a new reversal repository instance.
+func NewReversalRepo(db *gorm.DB) Reversal {
+	return &reversal{
+		db: db,
+	}
+}
+
+// Reserve inserts the reversal within the given transaction unless one exists for the same transaction or pair.
+func (r *reversal) Reserve(tx *gorm.DB, reversal *model.Reversal) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(reversal)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindLegacyIDs retrieves the serial IDs that reversals recorded before public IDs are keyed by.
+func (r *reversal) FindLegacyIDs(ctx context.Context, limit int) ([]int64, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&model.Reversal{}).
+		Where("transaction_id ~ ?", legacyIDPattern).
+		Order("created_at").
+		Limit(limit).
+		Pluck("transaction_id::BIGINT", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ReplaceLegacyID replaces the serial ID of a reversed transaction with its
+// public ID. A reversal already keyed by the public ID is left as it is.
+func (r *reversal) ReplaceLegacyID(ctx context.Context, legacyID int64, publicID string) error {
+	return r.db.WithContext(ctx).Model(&model.Reversal{}).
+		Where("transaction_id = ?", strconv.FormatInt(legacyID, 10)).
+		Where("NOT EXISTS (?)", r.db.Model(&model.Reversal{}).Select("1").Where("transaction_id = ?", publicID)).
+		Update("transaction_id", publicID).Error
+}
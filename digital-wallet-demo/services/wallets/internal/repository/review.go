@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Review provides database operations for the review queue and the wallets flagged for review.
+type Review interface {
+	Create(tx *gorm.DB, review *model.Review) error
+	FindByID(ctx context.Context, id string) (*model.Review, error)
+	FindAll(ctx context.Context, query model.ReviewQuery) ([]model.Review, error)
+
+	// FindForUpdate retrieves a review and locks it until the end of tx.
+	FindForUpdate(tx *gorm.DB, id string) (*model.Review, error)
+	Update(tx *gorm.DB, review *model.Review) error
+
+	// SumPending returns the amounts of a wallet's pending reviews, per currency.
+	SumPending(ctx context.Context, userID string) (map[model.Currency]int64, error)
+	// SumPendingIn returns the amount of a wallet's pending reviews in currency, within tx.
+	SumPendingIn(tx *gorm.DB, userID string, currency model.Currency) (int64, error)
+
+	FindFlags(ctx context.Context) ([]model.ReviewFlag, error)
+	// IsFlagged reports whether the wallet is flagged for review, within tx.
+	IsFlagged(tx *gorm.DB, userID string) (bool, error)
+	// UpsertFlag flags a wallet for review within tx, replacing the reason of an existing flag.
+	UpsertFlag(tx *gorm.DB, flag *model.ReviewFlag) error
+	DeleteFlag(tx *gorm.DB, userID string) error
+}
+
+type review struct {
+	db *gorm.DB
+}
+
+// NewReviewRepo creates a new review repository instance.
+func NewReviewRepo(db *gorm.DB) Review {
+	return &review{
+		db: db,
+	}
+}
+
+// Create stores a new review within tx.
+func (r *review) Create(tx *gorm.DB, review *model.Review) error {
+	return tx.Create(review).Error
+}
+
+// FindByID retrieves a review, returns ErrReviewNotFound if not exists.
+func (r *review) FindByID(ctx context.Context, id string) (*model.Review, error) {
+	var review model.Review
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Take(&review).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+// FindAll retrieves the reviews matching the query, oldest first.
+func (r *review) FindAll(ctx context.Context, query model.ReviewQuery) ([]model.Review, error) {
+	tx := r.db.WithContext(ctx).Model(&model.Review{})
+	if query.Status != "" {
+		tx = tx.Where("status = ?", query.Status)
+	}
+	if query.Reason != "" {
+		tx = tx.Where("reason = ?", query.Reason)
+	}
+	if query.TransactionType != "" {
+		tx = tx.Where("transaction_type = ?", query.TransactionType)
+	}
+	if query.UserID != "" {
+		tx = tx.Where("from_user_id = ? OR to_user_id = ?", query.UserID, query.UserID)
+	}
+	if query.CreatedFrom != nil {
+		tx = tx.Where("created_at >= ?", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		tx = tx.Where("created_at < ?", *query.CreatedTo)
+	}
+
+	var reviews []model.Review
+	if err := tx.Order("created_at").Order("id").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// FindForUpdate retrieves a review with a row-level lock, returns ErrReviewNotFound if not exists.
+// The lock ensures a review is decided at most once.
+func (r *review) FindForUpdate(tx *gorm.DB, id string) (*model.Review, error) {
+	var review model.Review
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(&review).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+// Update saves the decision of a review within tx.
+func (r *review) Update(tx *gorm.DB, review *model.Review) error {
+	return tx.Model(review).Select("status", "note", "decided_at", "updated_at").Updates(review).Error
+}
+
+// SumPending returns the amounts of a wallet's pending reviews, per currency.
+func (r *review) SumPending(ctx context.Context, userID string) (map[model.Currency]int64, error) {
+	var rows []struct {
+		Currency model.Currency
+		Total    int64
+	}
+	if err := r.pendingReviews(r.db.WithContext(ctx), userID).
+		Select("currency, SUM(amount) AS total").Group("currency").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	pending := make(map[model.Currency]int64, len(rows))
+	for _, row := range rows {
+		pending[row.Currency] = row.Total
+	}
+	return pending, nil
+}
+
+// SumPendingIn returns the amount of a wallet's pending reviews in currency, within tx.
+func (r *review) SumPendingIn(tx *gorm.DB, userID string, currency model.Currency) (int64, error) {
+	var total int64
+	err := r.pendingReviews(tx, userID).Where("currency = ?", currency).
+		Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+	return total, err
+}
+
+// FindFlags retrieves every wallet flagged for review.
+func (r *review) FindFlags(ctx context.Context) ([]model.ReviewFlag, error) {
+	var flags []model.ReviewFlag
+	if err := r.db.WithContext(ctx).Order("user_id").Find(&flags).Error; err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// IsFlagged reports whether the wallet is flagged for review, within tx.
+func (r *review) IsFlagged(tx *gorm.DB, userID string) (bool, error) {
+	var count int64
+	err := tx.Model(&model.ReviewFlag{}).Where("user_id = ?", userID).Count(&count).Error
+	return count > 0, err
+}
+
+// UpsertFlag flags a wallet for review within tx, replacing the reason of an existing flag.
+func (r *review) UpsertFlag(tx *gorm.DB, flag *model.ReviewFlag) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason"}),
+	}).Create(flag).Error
+}
+
+// DeleteFlag removes the review flag of a wallet, returns ErrReviewFlagNotFound if it is not flagged.
+func (r *review) DeleteFlag(tx *gorm.DB, userID string) error {
+	result := tx.Where("user_id = ?", userID).Delete(&model.ReviewFlag{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrReviewFlagNotFound
+	}
+	return nil
+}
+
+func (r *review) pendingReviews(db *gorm.DB, userID string) *gorm.DB {
+	return db.Model(&model.Review{}).Where("from_user_id = ? AND status = ?", userID, model.ReviewPending)
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+)
+
+// RiskDecision provides database operations for risk decisions and the transfer history the risk rules check.
+type RiskDecision interface {
+	// Create stores a decision on its own, so that it is kept when the transaction it rejected is rolled back.
+	Create(ctx context.Context, decision *model.RiskDecision) error
+	// CreateIn stores a decision within tx, together with the transaction it let through.
+	CreateIn(tx *gorm.DB, decision *model.RiskDecision) error
+	// CountRecipientsSince counts the distinct receivers of transfers let through from the wallet since the given time, other than exceptUserID.
+	CountRecipientsSince(tx *gorm.DB, fromUserID, exceptUserID string, since time.Time) (int64, error)
+	// TransferredSince reports whether a transfer from one wallet to another was let through since the given time.
+	TransferredSince(tx *gorm.DB, fromUserID, toUserID string, since time.Time) (bool, error)
+}
+
+type riskDecision struct {
+	db *gorm.DB
+}
+
+// NewRiskDecisionRepo creates a new risk decision repository instance.
+func NewRiskDecisionRepo(db *gorm.DB) RiskDecision {
+	return &riskDecision{
+		db: db,
+	}
+}
+
+// Create inserts the decision outside of any transaction.
+func (r *riskDecision) Create(ctx context.Context, decision *model.RiskDecision) error {
+	return r.db.WithContext(ctx).Create(decision).Error
+}
+
+// CreateIn inserts the decision within the given transaction.
+func (r *riskDecision) CreateIn(tx *gorm.DB, decision *model.RiskDecision) error {
+	return tx.Create(decision).Error
+}
+
+// CountRecipientsSince counts the receivers of approved or pending transfers from the wallet within tx.
+func (r *riskDecision) CountRecipientsSince(tx *gorm.DB, fromUserID, exceptUserID string, since time.Time) (int64, error) {
+	var count int64
+	err := r.transfersSince(tx, since).
+		Where("from_user_id = ? AND to_user_id <> ?", fromUserID, exceptUserID).
+		Distinct("to_user_id").
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// TransferredSince looks up an approved or pending transfer between the wallets within tx.
+func (r *riskDecision) TransferredSince(tx *gorm.DB, fromUserID, toUserID string, since time.Time) (bool, error) {
+	var count int64
+	err := r.transfersSince(tx, since).
+		Where("from_user_id = ? AND to_user_id = ?", fromUserID, toUserID).
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// transfersSince scopes the decisions to the transfers let through since the given time.
+func (r *riskDecision) transfersSince(tx *gorm.DB, since time.Time) *gorm.DB {
+	return tx.Model(&model.RiskDecision{}).
+		Where("transaction_type = ? AND action IN ? AND created_at >= ?", model.Transfer, []model.RiskAction{model.RiskApprove, model.RiskReview}, since)
+}
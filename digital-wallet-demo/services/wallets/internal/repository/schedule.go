@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Schedule provides database operations for scheduled transfers.
+type Schedule interface {
+	Create(ctx context.Context, schedule *model.Schedule) error
+	FindByID(ctx context.Context, userID string, id int) (*model.Schedule, error)
+	FindByUserID(ctx context.Context, userID string) ([]model.Schedule, error)
+	Update(ctx context.Context, schedule *model.Schedule, status model.ScheduleStatus, nextRunAt *time.Time) error
+	Delete(ctx context.Context, userID string, id int) error
+
+	// Runner operations
+	BeginTransaction(ctx context.Context) *gorm.DB
+	ClaimDue(tx *gorm.DB, now time.Time, limit int) ([]model.Schedule, error)
+	Advance(tx *gorm.DB, id int, nextRunAt *time.Time, status model.ScheduleStatus) error
+	RecordRun(ctx context.Context, id int, at time.Time, runErr error) error
+}
+
+type schedule struct {
+	db *gorm.DB
+}
+
+// NewScheduleRepo creates a new schedule repository instance.
+func NewScheduleRepo(db *gorm.DB) Schedule {
+	return &schedule{
+		db: db,
+	}
+}
+
+// Create stores a new schedule.
+func (s *schedule) Create(ctx context.Context, schedule *model.Schedule) error {
+	return s.db.WithContext(ctx).Create(schedule).Error
+}
+
+// FindByID retrieves a schedule of the given sender, returns ErrScheduleNotFound if not exists.
+func (s *schedule) FindByID(ctx context.Context, userID string, id int) (*model.Schedule, error) {
+	var schedule model.Schedule
+	if err := s.db.WithContext(ctx).Where("id = ? AND from_user_id = ?", id, userID).Take(&schedule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrScheduleNotFound
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// FindByUserID retrieves the schedules of the given sender, oldest first.
+func (s *schedule) FindByUserID(ctx context.Context, userID string) ([]model.Schedule, error) {
+	var schedules []model.Schedule
+	if err := s.db.WithContext(ctx).Where("from_user_id = ?", userID).Order("id").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// Update writes the fields a client may change of a schedule read with the
+// given status and next run. The runner advances schedules concurrently, so
+// the write is refused with ErrScheduleConflict if either changed since:
+// writing back a stale next run would run an occurrence twice.
+func (s *schedule) Update(ctx context.Context, schedule *model.Schedule, status model.ScheduleStatus, nextRunAt *time.Time) error {
+	query := s.db.WithContext(ctx).Model(&model.Schedule{}).Where("id = ? AND from_user_id = ? AND status = ?", schedule.ID, schedule.FromUserID, status)
+	if nextRunAt == nil {
+		query = query.Where("next_run_at IS NULL")
+	} else {
+		query = query.Where("next_run_at = ?", *nextRunAt)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"to_user_id":  schedule.ToUserID,
+		"amount":      schedule.Amount,
+		"recurrence":  schedule.Recurrence,
+		"cron_expr":   schedule.CronExpr,
+		"start_at":    schedule.StartAt,
+		"next_run_at": schedule.NextRunAt,
+		"status":      schedule.Status,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrScheduleConflict
+	}
+	return nil
+}
+
+// Delete removes a schedule of the given sender, returns ErrScheduleNotFound if not exists.
+func (s *schedule) Delete(ctx context.Context, userID string, id int) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND from_user_id = ?", id, userID).Delete(&model.Schedule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrScheduleNotFound
+	}
+	return nil
+}
+
+// BeginTransaction starts a new database transaction for claiming due schedules.
+func (s *schedule) BeginTransaction(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Begin()
+}
+
+// ClaimDue locks up to limit active schedules whose next run is due.
+// Rows locked by another runner are skipped, so several wallet instances
+// can run schedules concurrently without running the same one twice.
+func (s *schedule) ClaimDue(tx *gorm.DB, now time.Time, limit int) ([]model.Schedule, error) {
+	var schedules []model.Schedule
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_run_at <= ?", model.ScheduleActive, now).
+		Order("next_run_at").
+		Limit(limit).
+		Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// Advance moves a claimed schedule to its next run, or to the given status if it has none.
+func (s *schedule) Advance(tx *gorm.DB, id int, nextRunAt *time.Time, status model.ScheduleStatus) error {
+	return tx.Model(&model.Schedule{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"next_run_at": nextRunAt,
+		"status":      status,
+	}).Error
+}
+
+// RecordRun records the outcome of a run. A nil runErr records a successful run.
+func (s *schedule) RecordRun(ctx context.Context, id int, at time.Time, runErr error) error {
+	updates := map[string]interface{}{
+		"last_run_at":     at,
+		"last_run_status": model.RunSucceeded,
+		"last_error":      "",
+		"run_count":       gorm.Expr("run_count + 1"),
+	}
+	if runErr != nil {
+		updates["last_run_status"] = model.RunFailed
+		updates["last_error"] = runErr.Error()
+		updates["failure_count"] = gorm.Expr("failure_count + 1")
+	}
+	return s.db.WithContext(ctx).Model(&model.Schedule{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -2,6 +2,10 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"time"
+
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -10,13 +14,17 @@ import (
 // Wallet provides database operations for wallet management.
 type Wallet interface {
 	// Wallet operations
-	Create(t *model.Wallet) error
-	FindByUserID(userID string) (*model.Wallet, error)
-	FindProviderWallet(providerID string) (*model.Wallet, error)
+	Create(tx *gorm.DB, t *model.Wallet) error
+	FindByUserID(ctx context.Context, userID string) (*model.Wallet, error)
+	FindProviderWallet(ctx context.Context, providerID string) (*model.Wallet, error)
+	FindAll(tx *gorm.DB) ([]model.Wallet, error)
 
-	// Atomic operations
-	BeginTransaction() *gorm.DB
-	UpdateWalletBalance(tx *gorm.DB, walletID int, amount int64, isCredit bool) error
+	// Atomic operations, the queries run in a transaction are cancelled with ctx
+	BeginTransaction(ctx context.Context) *gorm.DB
+	BeginSnapshot(ctx context.Context) *gorm.DB
+	UpdateWalletBalance(tx *gorm.DB, walletID int, currency model.Currency, amount int64, isCredit bool) (int64, error)
+	LockWallets(tx *gorm.DB, walletIDs ...int) ([]model.Wallet, error)
+	UpdateStatus(tx *gorm.DB, walletID int, status model.Status, reason string, at time.Time) error
 }
 
 type wallet struct {
@@ -30,18 +38,18 @@ func NewWalletRepo(db *gorm.DB) Wallet {
 	}
 }
 
-// Create inserts a new wallet record into the database.
-func (td *wallet) Create(t *model.Wallet) error {
-	if err := td.db.Create(t).Error; err != nil {
+// Create inserts a new wallet record within the given transaction.
+func (td *wallet) Create(tx *gorm.DB, t *model.Wallet) error {
+	if err := tx.Create(t).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
 // FindByUserID retrieves a wallet by user ID, returns ErrNotFound if not exists.
-func (td *wallet) FindByUserID(userID string) (*model.Wallet, error) {
+func (td *wallet) FindByUserID(ctx context.Context, userID string) (*model.Wallet, error) {
 	var wallet *model.Wallet
-	err := td.db.Where("user_id = ?", userID).Take(&wallet).Error
+	err := td.db.WithContext(ctx).Preload("Balances").Where("user_id = ?", userID).Take(&wallet).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, model.ErrNotFound
@@ -52,9 +60,9 @@ func (td *wallet) FindByUserID(userID string) (*model.Wallet, error) {
 }
 
 // FindProviderWallet retrieves a provider wallet by provider ID for system operations.
-func (td *wallet) FindProviderWallet(providerID string) (*model.Wallet, error) {
+func (td *wallet) FindProviderWallet(ctx context.Context, providerID string) (*model.Wallet, error) {
 	var wallet *model.Wallet
-	err := td.db.Where("user_id = ? AND acnt_type = ?", providerID, model.Provider).Take(&wallet).Error
+	err := td.db.WithContext(ctx).Preload("Balances").Where("user_id = ? AND acnt_type = ?", providerID, model.Provider).Take(&wallet).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, model.ErrNotFound
@@ -64,30 +72,89 @@ func (td *wallet) FindProviderWallet(providerID string) (*model.Wallet, error) {
 	return wallet, nil
 }
 
+// FindAll retrieves all wallets within the given transaction.
+func (td *wallet) FindAll(tx *gorm.DB) ([]model.Wallet, error) {
+	var wallets []model.Wallet
+	if err := tx.Preload("Balances").Order("user_id").Find(&wallets).Error; err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
 // BeginTransaction starts a new database transaction for atomic operations.
-func (td *wallet) BeginTransaction() *gorm.DB {
-	return td.db.Begin()
+func (td *wallet) BeginTransaction(ctx context.Context) *gorm.DB {
+	return td.db.WithContext(ctx).Begin()
 }
 
-// UpdateWalletBalance atomically updates wallet balance
+// BeginSnapshot starts a read-only transaction in which every query sees the same
+// snapshot of the database, so that balances and outbox entries read in it are consistent.
+func (td *wallet) BeginSnapshot(ctx context.Context) *gorm.DB {
+	return td.db.WithContext(ctx).Begin(&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+// UpdateWalletBalance atomically updates wallet balance in the given currency
 // Used row-level Exclusive Locking to ensure single transaction can update the wallet balance at a time
-func (td *wallet) UpdateWalletBalance(tx *gorm.DB, walletID int, amount int64, isCredit bool) error {
+// The wallet row is locked for every currency, so balances in other currencies are serialized by the same lock
+// The balance after the update is returned
+func (td *wallet) UpdateWalletBalance(tx *gorm.DB, walletID int, currency model.Currency, amount int64, isCredit bool) (int64, error) {
 	var wallet model.Wallet
 
 	// Acquire row-level lock
 	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("id = ?", walletID).First(&wallet).Error; err != nil {
-		return err
+		return 0, err
+	}
+
+	if currency == wallet.Currency {
+		if isCredit {
+			wallet.Balance += amount
+		} else {
+			wallet.Balance -= amount
+			if wallet.Balance < 0 {
+				return 0, model.ErrInsufficientFunds
+			}
+		}
+
+		return wallet.Balance, tx.Save(&wallet).Error
+	}
+
+	// Balance in another currency, created on first use
+	balance := model.WalletBalance{WalletID: walletID, Currency: currency}
+	if err := tx.Where(&balance).FirstOrCreate(&balance).Error; err != nil {
+		return 0, err
 	}
 
 	if isCredit {
-		wallet.Balance += amount
+		balance.Balance += amount
 	} else {
-		wallet.Balance -= amount
-		if wallet.Balance < 0 {
-			return model.ErrInsufficientFunds
+		balance.Balance -= amount
+		if balance.Balance < 0 {
+			return 0, model.ErrInsufficientFunds
 		}
 	}
 
-	return tx.Save(&wallet).Error
+	return balance.Balance, tx.Save(&balance).Error
+}
+
+// LockWallets acquires row-level locks on the given wallets and returns them with their balances.
+// Rows are locked in ID order, so that transactions locking the same wallets cannot deadlock.
+func (td *wallet) LockWallets(tx *gorm.DB, walletIDs ...int) ([]model.Wallet, error) {
+	var wallets []model.Wallet
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Balances").Where("id IN ?", walletIDs).Order("id").Find(&wallets).Error; err != nil {
+		return nil, err
+	}
+	if len(wallets) != len(walletIDs) {
+		return nil, model.ErrNotFound
+	}
+	return wallets, nil
+}
+
+// UpdateStatus sets the status of a wallet with the reason for the change.
+func (td *wallet) UpdateStatus(tx *gorm.DB, walletID int, status model.Status, reason string, at time.Time) error {
+	return tx.Model(&model.Wallet{}).Where("id = ?", walletID).Updates(map[string]interface{}{
+		"status":            status,
+		"status_reason":     reason,
+		"status_changed_at": at,
+	}).Error
 }
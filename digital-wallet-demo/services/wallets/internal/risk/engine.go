@@ -0,0 +1,77 @@
+// Package risk provides the rule engine deciding whether outgoing transfers and withdrawals may complete.
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Rates supplies the exchange rates converting amounts to the currency of a rule.
+type Rates interface {
+	Rate(from, to model.Currency) (*big.Rat, error)
+}
+
+// Rule is a risk check made on an outgoing transaction.
+type Rule interface {
+	Name() string
+	// Action is the decision for the transactions the rule matches.
+	Action() model.RiskAction
+	// Matches reports whether the rule applies to the checked transaction, reading history within tx.
+	Matches(tx *gorm.DB, check model.RiskCheck) (bool, error)
+}
+
+// Engine evaluates an ordered chain of rules and records each decision.
+type Engine struct {
+	enable             bool
+	rules              []Rule
+	decisionRepository repository.RiskDecision
+}
+
+// NewEngine builds the rules of cfg in order. A disabled engine approves every transaction.
+func NewEngine(cfg model.Risk, dr repository.RiskDecision, rates Rates) (*Engine, error) {
+	e := &Engine{enable: cfg.Enable, decisionRepository: dr}
+	for _, rc := range cfg.Rules {
+		rule, err := newRule(rc, dr, rates)
+		if err != nil {
+			return nil, fmt.Errorf("risk rule %q: %w", rc.Name, err)
+		}
+		e.rules = append(e.rules, rule)
+	}
+	return e, nil
+}
+
+// Evaluate decides on the checked transaction with the first matching rule,
+// approving it if none matches. Approvals and reviews are recorded within tx,
+// with the transaction they let through; rejections are recorded on their own,
+// so that they are kept when tx is rolled back.
+func (e *Engine) Evaluate(ctx context.Context, tx *gorm.DB, check model.RiskCheck) (*model.RiskDecision, error) {
+	decision := model.NewRiskDecision(check, model.RiskApprove, "")
+	if e.enable {
+		for _, rule := range e.rules {
+			matched, err := rule.Matches(tx, check)
+			if err != nil {
+				return nil, fmt.Errorf("risk rule %q: %w", rule.Name(), err)
+			}
+			if matched {
+				decision = model.NewRiskDecision(check, rule.Action(), rule.Name())
+				break
+			}
+		}
+	}
+
+	var err error
+	if decision.Action == model.RiskReject {
+		err = e.decisionRepository.Create(ctx, decision)
+	} else {
+		err = e.decisionRepository.CreateIn(tx, decision)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
@@ -0,0 +1,116 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/fx"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeHistory records decisions in memory and serves fixed transfer history.
+type fakeHistory struct {
+	recipients  int64
+	transferred bool
+	created     []*model.RiskDecision
+}
+
+func (f *fakeHistory) Create(_ context.Context, d *model.RiskDecision) error {
+	f.created = append(f.created, d)
+	return nil
+}
+
+func (f *fakeHistory) CreateIn(_ *gorm.DB, d *model.RiskDecision) error {
+	f.created = append(f.created, d)
+	return nil
+}
+
+func (f *fakeHistory) CountRecipientsSince(_ *gorm.DB, _, _ string, _ time.Time) (int64, error) {
+	return f.recipients, nil
+}
+
+func (f *fakeHistory) TransferredSince(_ *gorm.DB, _, _ string, _ time.Time) (bool, error) {
+	return f.transferred, nil
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	now := time.Now()
+	transfer := model.RiskCheck{
+		TransactionType: model.Transfer,
+		FromUserID:      "test-user-001",
+		ToUserID:        "test-user-002",
+		Amount:          1000,
+		Currency:        model.DefaultCurrency,
+		WalletCreatedAt: now.Add(-48 * time.Hour),
+		At:              now,
+	}
+	withCheck := func(change func(c *model.RiskCheck)) model.RiskCheck {
+		c := transfer
+		change(&c)
+		return c
+	}
+
+	rules := []model.RiskRule{
+		{Name: "large-amount", Type: model.AmountRule, Action: model.RiskReview, Amount: 50000},
+		{Name: "large-withdrawal", Type: model.AmountRule, Action: model.RiskReject, TransactionTypes: []model.TransactionType{model.Withdraw}, Amount: 500},
+		{Name: "new-wallet", Type: model.NewWalletRule, Action: model.RiskReview, Period: 24 * time.Hour},
+		{Name: "fan-out", Type: model.RecipientsRule, Action: model.RiskReject, Period: time.Hour, MaxRecipients: 3},
+		{Name: "round-trip", Type: model.RoundTripRule, Action: model.RiskReview, Period: time.Hour},
+	}
+
+	tests := []struct {
+		name     string
+		disabled bool
+		history  fakeHistory
+		check    model.RiskCheck
+		want     model.RiskAction
+		wantRule string
+	}{
+		{"no_match", false, fakeHistory{}, transfer, model.RiskApprove, ""},
+		{"disabled", true, fakeHistory{recipients: 10}, withCheck(func(c *model.RiskCheck) { c.Amount = 100000 }), model.RiskApprove, ""},
+		{"amount", false, fakeHistory{}, withCheck(func(c *model.RiskCheck) { c.Amount = 50000 }), model.RiskReview, "large-amount"},
+		{"amount_converted", false, fakeHistory{}, withCheck(func(c *model.RiskCheck) { c.Amount, c.Currency = 7600000, "JPY" }), model.RiskReview, "large-amount"},
+		{"transaction_type_scope", false, fakeHistory{}, withCheck(func(c *model.RiskCheck) { c.TransactionType = model.Withdraw }), model.RiskReject, "large-withdrawal"},
+		{"new_wallet", false, fakeHistory{}, withCheck(func(c *model.RiskCheck) { c.WalletCreatedAt = now.Add(-time.Hour) }), model.RiskReview, "new-wallet"},
+		{"recipients_below_max", false, fakeHistory{recipients: 2}, transfer, model.RiskApprove, ""},
+		{"recipients_at_max", false, fakeHistory{recipients: 3}, transfer, model.RiskReject, "fan-out"},
+		{"round_trip", false, fakeHistory{transferred: true}, transfer, model.RiskReview, "round-trip"},
+		{"first_match_decides", false, fakeHistory{recipients: 3, transferred: true}, withCheck(func(c *model.RiskCheck) { c.Amount = 50000 }), model.RiskReview, "large-amount"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := tt.history
+			engine, err := NewEngine(model.Risk{Enable: !tt.disabled, Rules: rules}, &history, fx.DefaultStaticRates())
+			require.NoError(t, err)
+
+			decision, err := engine.Evaluate(context.Background(), nil, tt.check)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, decision.Action)
+			assert.Equal(t, tt.wantRule, decision.Rule)
+			require.Len(t, history.created, 1)
+			assert.Equal(t, decision, history.created[0])
+		})
+	}
+}
+
+func TestNewEngine_InvalidRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule model.RiskRule
+	}{
+		{"unknown_type", model.RiskRule{Name: "r", Type: "velocity", Action: model.RiskReject}},
+		{"amount_without_amount", model.RiskRule{Name: "r", Type: model.AmountRule, Action: model.RiskReject}},
+		{"recipients_without_max", model.RiskRule{Name: "r", Type: model.RecipientsRule, Action: model.RiskReject, Period: time.Hour}},
+		{"unsupported_currency", model.RiskRule{Name: "r", Type: model.AmountRule, Action: model.RiskReject, Amount: 1, Currency: "XXX"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEngine(model.Risk{Enable: true, Rules: []model.RiskRule{tt.rule}}, &fakeHistory{}, fx.DefaultStaticRates())
+			assert.Error(t, err)
+		})
+	}
+}
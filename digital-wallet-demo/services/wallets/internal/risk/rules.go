@@ -0,0 +1,164 @@
+package risk
+
+import (
+	"fmt"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"gorm.io/gorm"
+)
+
+// newRule builds the rule of cfg, scoped to the transaction types of cfg.
+func newRule(cfg model.RiskRule, dr repository.RiskDecision, rates Rates) (Rule, error) {
+	currency := cfg.Currency
+	if currency == "" {
+		currency = model.DefaultCurrency
+	}
+	if !currency.IsSupported() {
+		return nil, model.ErrUnsupportedCurrency
+	}
+
+	base := rule{name: cfg.Name, action: cfg.Action, types: cfg.TransactionTypes}
+	threshold := amountThreshold{amount: cfg.Amount, currency: currency, rates: rates}
+
+	switch cfg.Type {
+	case model.AmountRule:
+		if cfg.Amount <= 0 {
+			return nil, fmt.Errorf("amount rule needs an amount")
+		}
+		return &amountRule{rule: base, threshold: threshold}, nil
+	case model.NewWalletRule:
+		if cfg.Period <= 0 {
+			return nil, fmt.Errorf("newWallet rule needs a cooling period")
+		}
+		return &newWalletRule{rule: base, threshold: threshold, cfg: cfg}, nil
+	case model.RecipientsRule:
+		if cfg.Period <= 0 || cfg.MaxRecipients <= 0 {
+			return nil, fmt.Errorf("recipients rule needs a period and maxRecipients")
+		}
+		return &recipientsRule{rule: base, decisionRepository: dr, cfg: cfg}, nil
+	case model.RoundTripRule:
+		if cfg.Period <= 0 {
+			return nil, fmt.Errorf("roundTrip rule needs a period")
+		}
+		return &roundTripRule{rule: base, decisionRepository: dr, cfg: cfg}, nil
+	}
+	return nil, fmt.Errorf("unknown rule type %q", cfg.Type)
+}
+
+// rule holds what every rule has in common.
+type rule struct {
+	name   string
+	action model.RiskAction
+	types  []model.TransactionType // Transaction types the rule applies to, all if empty
+}
+
+func (r *rule) Name() string {
+	return r.name
+}
+
+func (r *rule) Action() model.RiskAction {
+	return r.action
+}
+
+// appliesTo reports whether the rule checks transactions of type t.
+func (r *rule) appliesTo(t model.TransactionType) bool {
+	if len(r.types) == 0 {
+		return true
+	}
+	for _, typ := range r.types {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
+
+// amountThreshold compares transaction amounts against an amount in a currency.
+type amountThreshold struct {
+	amount   int64
+	currency model.Currency
+	rates    Rates
+}
+
+// reached reports whether the checked amount, converted at the current rate, is at least the threshold.
+// A zero threshold is always reached.
+func (a amountThreshold) reached(check model.RiskCheck) (bool, error) {
+	if a.amount == 0 {
+		return true, nil
+	}
+	amount := check.Amount
+	if check.Currency != a.currency {
+		rate, err := a.rates.Rate(check.Currency, a.currency)
+		if err != nil {
+			return false, err
+		}
+		quote, err := model.NewFXQuote(check.Currency, a.currency, check.Amount, rate, 0)
+		if err != nil {
+			return false, err
+		}
+		amount = quote.CounterAmount
+	}
+	return amount >= a.amount, nil
+}
+
+// amountRule matches transactions of at least an amount.
+type amountRule struct {
+	rule
+	threshold amountThreshold
+}
+
+func (r *amountRule) Matches(_ *gorm.DB, check model.RiskCheck) (bool, error) {
+	if !r.appliesTo(check.TransactionType) {
+		return false, nil
+	}
+	return r.threshold.reached(check)
+}
+
+// newWalletRule matches transactions, optionally of at least an amount, from
+// wallets still in their cooling period after creation.
+type newWalletRule struct {
+	rule
+	threshold amountThreshold
+	cfg       model.RiskRule
+}
+
+func (r *newWalletRule) Matches(_ *gorm.DB, check model.RiskCheck) (bool, error) {
+	if !r.appliesTo(check.TransactionType) || check.At.Sub(check.WalletCreatedAt) >= r.cfg.Period {
+		return false, nil
+	}
+	return r.threshold.reached(check)
+}
+
+// recipientsRule matches transfers to a new recipient from a wallet that
+// already sent to MaxRecipients others within the window.
+type recipientsRule struct {
+	rule
+	decisionRepository repository.RiskDecision
+	cfg                model.RiskRule
+}
+
+func (r *recipientsRule) Matches(tx *gorm.DB, check model.RiskCheck) (bool, error) {
+	if check.TransactionType != model.Transfer || !r.appliesTo(check.TransactionType) {
+		return false, nil
+	}
+	others, err := r.decisionRepository.CountRecipientsSince(tx, check.FromUserID, check.ToUserID, check.At.Add(-r.cfg.Period))
+	if err != nil {
+		return false, err
+	}
+	return others >= int64(r.cfg.MaxRecipients), nil
+}
+
+// roundTripRule matches transfers to a wallet that sent to the sender within the window.
+type roundTripRule struct {
+	rule
+	decisionRepository repository.RiskDecision
+	cfg                model.RiskRule
+}
+
+func (r *roundTripRule) Matches(tx *gorm.DB, check model.RiskCheck) (bool, error) {
+	if check.TransactionType != model.Transfer || !r.appliesTo(check.TransactionType) {
+		return false, nil
+	}
+	return r.decisionRepository.TransferredSince(tx, check.ToUserID, check.FromUserID, check.At.Add(-r.cfg.Period))
+}
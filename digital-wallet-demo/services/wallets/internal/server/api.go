@@ -3,22 +3,34 @@ package server
 
 import (
 	"fmt"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/auth"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/controller"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/fx"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/risk"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
 	"github.com/labstack/echo/v4/middleware"
 
-	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/db"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 // WalletAPIServerOpts is the options for the WalletAPIServer
 type WalletAPIServerOpts struct {
 	ListenPort int
 	Config     model.Config
+	// The database and clients are shared with the worker of the instance and
+	// closed by the caller once every server has shut down
+	DB          *gorm.DB
+	RedisClient cache.RedisClient
+	TxnClient   client.NewTransaction
 }
 
 // NewAPI returns a new instance of the Wallet API server
@@ -29,25 +41,75 @@ func NewAPI(opts WalletAPIServerOpts) (Server, error) {
 	// Initialize global logger
 	utils.InitLogger(logger)
 
-	dbInstance, err := db.New(opts.Config.PostgreSQL)
+	dbInstance := opts.DB
+	sqlDB, err := dbInstance.DB()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %v", err)
+		return nil, fmt.Errorf("failed to get database connection pool: %v", err)
 	}
 
 	engine := echo.New()
 
 	// Allow all origins for CORS
 	engine.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, controller.HeaderIdempotencyKey, tracing.HeaderRequestID},
+		ExposeHeaders: []string{tracing.HeaderRequestID},
 	}))
 
+	// Every request carries a request ID and a span, for the logs and spans of its work
+	engine.Use(tracing.RequestIDMiddleware(), tracing.Middleware(), metrics.Middleware())
+
+	rates, err := loadRates(opts.Config.FX)
+	if err != nil {
+		return nil, err
+	}
+
+	riskEngine, err := risk.NewEngine(opts.Config.Risk, repository.NewRiskDecisionRepo(dbInstance), rates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load risk rules: %v", err)
+	}
+
+	// Running without authentication must be asked for explicitly, so that a
+	// missing setting never leaves the API open
+	var verifier *auth.Verifier
+	switch {
+	case opts.Config.Auth.Enable:
+		verifier, err = auth.NewVerifier(opts.Config.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load authentication keys: %v", err)
+		}
+	case !opts.Config.Auth.DevMode:
+		return nil, fmt.Errorf("authentication is disabled: enable auth, or set auth.devMode to run without it in development")
+	default:
+		log.Warn("authentication is disabled in development mode: callers may act on every wallet and are never admins")
+	}
+
+	redisClient := opts.RedisClient
+	txnClient := opts.TxnClient
+
+	// The instance is not ready without its database. Without Redis, reads skip the
+	// cache, and the outbox holds writes until the transaction service is back.
+	timeout := opts.Config.Health.Timeout
+	health := controller.NewHealth(
+		controller.Dependency{Name: "postgres", Hard: true, Timeout: timeout, Ping: sqlDB.PingContext},
+		controller.Dependency{Name: "redis", Timeout: timeout, Ping: redisClient.Ping},
+		controller.Dependency{Name: "transactions", Timeout: timeout, Ping: txnClient.Ping},
+	)
+
 	s := &walletAPIServer{
-		port:   opts.ListenPort,
-		engine: engine,
-		log:    logger,
-		db:     dbInstance,
+		port:        opts.ListenPort,
+		engine:      engine,
+		log:         logger,
+		db:          dbInstance,
+		redisClient: redisClient,
+		txnClient:   txnClient,
+		rates:       rates,
+		risk:        riskEngine,
+		quoteTTL:    opts.Config.FX.QuoteTTL,
+		holdTTL:     opts.Config.Holds.TTL,
+		verifier:    verifier,
+		health:      health,
 	}
 
 	s.setupRoutes(engine)
@@ -57,6 +119,18 @@ func NewAPI(opts WalletAPIServerOpts) (Server, error) {
 	return s, nil
 }
 
+// loadRates returns the exchange rates of the rate file in cfg, or the built-in rates if none is configured
+func loadRates(cfg model.FX) (service.FXRateProvider, error) {
+	if cfg.RatesFile == "" {
+		return fx.DefaultStaticRates(), nil
+	}
+	rates, err := fx.LoadStaticRates(cfg.RatesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exchange rates: %v", err)
+	}
+	return rates, nil
+}
+
 // initWalletController creates and configures the wallet handler with its dependencies
 //
 //	Repository ====> Service =====> Controller
@@ -65,24 +139,70 @@ func NewAPI(opts WalletAPIServerOpts) (Server, error) {
 func (s *walletAPIServer) initWalletController() controller.WalletHandler {
 
 	// Initialize dependencies (Repository -> Service -> Controller)
-	walletRepo := repository.NewWalletRepo(s.db)
-	walletService := service.NewWalletService(walletRepo)
+	walletService := service.NewWalletService(service.WalletServiceOpts{
+		WalletRepository:   repository.NewWalletRepo(s.db),
+		OutboxRepository:   repository.NewOutboxRepo(s.db),
+		FXQuoteRepository:  repository.NewFXQuoteRepo(s.db),
+		ReversalRepository: repository.NewReversalRepo(s.db),
+		HoldRepository:     repository.NewHoldRepo(s.db),
+		LimitRepository:    repository.NewLimitRepo(s.db),
+		ReviewRepository:   repository.NewReviewRepo(s.db),
+		AuditRepository:    repository.NewAuditRepo(s.db),
+		RedisClient:        s.redisClient,
+		TxnClient:          s.txnClient,
+		RiskEngine:         s.risk,
+		Rates:              s.rates,
+		HoldTTL:            s.holdTTL,
+	})
 	walletController := controller.NewWalletController(walletService)
 
 	return walletController
 }
 
+// initFXController creates the FX handler with its dependencies
+func (s *walletAPIServer) initFXController() controller.FXHandler {
+	fxService := service.NewFXService(repository.NewFXQuoteRepo(s.db), s.rates, s.quoteTTL)
+	return controller.NewFXController(fxService)
+}
+
+// initScheduleController creates the schedule handler with its dependencies
+func (s *walletAPIServer) initScheduleController() controller.ScheduleHandler {
+	scheduleService := service.NewScheduleService(repository.NewScheduleRepo(s.db), repository.NewWalletRepo(s.db))
+	return controller.NewScheduleController(scheduleService)
+}
+
+// initLimitController creates the spending limit handler with its dependencies
+func (s *walletAPIServer) initLimitController() controller.LimitHandler {
+	limitService := service.NewLimitService(repository.NewLimitRepo(s.db), repository.NewWalletRepo(s.db), repository.NewAuditRepo(s.db))
+	return controller.NewLimitController(limitService)
+}
+
+// initReviewController creates the review queue handler with its dependencies
+func (s *walletAPIServer) initReviewController() controller.ReviewHandler {
+	reviewService := service.NewReviewService(service.ReviewServiceOpts{
+		ReviewRepository: repository.NewReviewRepo(s.db),
+		WalletRepository: repository.NewWalletRepo(s.db),
+		LimitRepository:  repository.NewLimitRepo(s.db),
+		OutboxRepository: repository.NewOutboxRepo(s.db),
+		AuditRepository:  repository.NewAuditRepo(s.db),
+		RedisClient:      s.redisClient,
+	})
+	return controller.NewReviewController(reviewService)
+}
+
 // setupRoutes registers the routes for the application.
 func (s *walletAPIServer) setupRoutes(e *echo.Echo) {
 	e.Validator = controller.NewCustomValidator()
 
 	api := e.Group("/api/v1")
 
-	// Health check
-	healthHandler := controller.NewHealth()
-	api.GET("/health", healthHandler.Health)
+	// Health checks
+	api.GET("/health/live", s.health.Live)
+	api.GET("/health/ready", s.health.Ready)
 
 	walletHandler := s.initWalletController()
 
-	controller.InitRoutes(api, walletHandler)
+	idempotency := controller.Idempotency(repository.NewIdempotencyRepo(s.db))
+
+	controller.InitRoutes(api, walletHandler, s.initFXController(), s.initScheduleController(), s.initLimitController(), s.initReviewController(), idempotency, controller.Authenticate(s.verifier))
 }
@@ -3,6 +3,13 @@ package server
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/auth"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/controller"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -10,10 +17,18 @@ import (
 
 // walletAPIServer is the API server for Wallet
 type walletAPIServer struct {
-	port   int
-	engine *echo.Echo
-	log    *log.Entry
-	db     *gorm.DB
+	port        int
+	engine      *echo.Echo
+	log         *log.Entry
+	db          *gorm.DB
+	redisClient cache.RedisClient
+	txnClient   client.NewTransaction
+	rates       service.FXRateProvider
+	risk        service.RiskEngine
+	quoteTTL    time.Duration
+	holdTTL     time.Duration
+	verifier    *auth.Verifier // Verifies the bearer tokens of API requests, nil in development mode
+	health      controller.HealthHandler
 }
 
 func (s *walletAPIServer) Name() string {
@@ -26,6 +41,12 @@ func (s *walletAPIServer) Run() error {
 	return s.engine.Start(fmt.Sprintf(":%d", s.port))
 }
 
+// Drain makes the Wallet API server report not ready, so that load balancers stop routing to it
+func (s *walletAPIServer) Drain() {
+	log.Infof("draining %s serving on port %d", s.Name(), s.port)
+	s.health.Drain()
+}
+
 // Shutdown stops the Wallet API server
 func (s *walletAPIServer) Shutdown(ctx context.Context) error {
 	log.Infof("shutting down %s serving on port %d", s.Name(), s.port)
@@ -3,15 +3,22 @@ package server
 import (
 	"testing"
 
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 func TestNewAPI(t *testing.T) {
+	// The pool connects on first use, the server is built without a database
+	dbInstance, err := gorm.Open(postgres.Open("host=localhost user=postgres dbname=wallet_test sslmode=disable"), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+
 	tests := []struct {
 		name    string
 		opts    WalletAPIServerOpts
@@ -22,39 +29,30 @@ func TestNewAPI(t *testing.T) {
 			opts: WalletAPIServerOpts{
 				ListenPort: 8081,
 				Config: model.Config{
-					PostgreSQL: model.PostgreSQL{
-						Host:     "localhost",
-						Port:     5432,
-						User:     "postgres",
-						Password: "postgres",
-						DBName:   "wallet_test",
-						SSLMode:  "disable",
-					},
 					SwaggerServer: model.Server{
 						Enable: true,
 						Port:   8081,
 					},
+					Auth: model.Auth{DevMode: true},
 				},
 			},
 			wantErr: false,
 		},
 		{
-			name: "Invalid database configuration",
+			name: "Authentication disabled outside development mode",
+			opts: WalletAPIServerOpts{
+				ListenPort: 8081,
+				Config:     model.Config{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid exchange rates file",
 			opts: WalletAPIServerOpts{
 				ListenPort: 8081,
 				Config: model.Config{
-					PostgreSQL: model.PostgreSQL{
-						Host:     "invalid-host",
-						Port:     5432,
-						User:     "invalid-user",
-						Password: "invalid-password",
-						DBName:   "invalid-db",
-						SSLMode:  "disable",
-					},
-					SwaggerServer: model.Server{
-						Enable: true,
-						Port:   8081,
-					},
+					FX:   model.FX{RatesFile: "testdata/missing-rates.yaml"},
+					Auth: model.Auth{DevMode: true},
 				},
 			},
 			wantErr: true,
@@ -63,6 +61,10 @@ func TestNewAPI(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.DB = dbInstance
+			tt.opts.RedisClient = cache.NewMockRedisClient()
+			tt.opts.TxnClient = &client.MockTransactionClient{}
+
 			server, err := NewAPI(tt.opts)
 			if tt.wantErr {
 				require.Error(t, err)
@@ -73,8 +75,23 @@ func TestNewAPI(t *testing.T) {
 				assert.Equal(t, tt.opts.ListenPort, server.(*walletAPIServer).port)
 				assert.IsType(t, &echo.Echo{}, server.(*walletAPIServer).engine)
 				assert.IsType(t, &log.Entry{}, server.(*walletAPIServer).log)
-				assert.IsType(t, &gorm.DB{}, server.(*walletAPIServer).db)
+				assert.Same(t, dbInstance, server.(*walletAPIServer).db)
+				assert.Same(t, tt.opts.TxnClient, server.(*walletAPIServer).txnClient)
 			}
 		})
 	}
 }
+
+func TestNewWorker(t *testing.T) {
+	dbInstance, err := gorm.Open(postgres.Open("host=localhost user=postgres dbname=wallet_test sslmode=disable"), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	server, err := NewWorker(WorkerOpts{
+		DB:          dbInstance,
+		RedisClient: cache.NewMockRedisClient(),
+		TxnClient:   &client.MockTransactionClient{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "workerServer", server.Name())
+	assert.Len(t, server.(*workerServer).jobs, 3)
+}
@@ -6,8 +6,8 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func writeRequestLogJSON(_ echo.Context, v middleware.RequestLoggerValues) error {
-	log.WithFields(log.Fields{
+func writeRequestLogJSON(c echo.Context, v middleware.RequestLoggerValues) error {
+	log.WithContext(c.Request().Context()).WithFields(log.Fields{
 		"method":         v.Method,
 		"host":           v.Host,
 		"path":           v.URIPath,
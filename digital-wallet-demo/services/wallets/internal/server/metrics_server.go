@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// metricsServer serves the Prometheus metrics, apart from the API so that they are not exposed through the gateway
+type metricsServer struct {
+	port   int
+	engine *echo.Echo
+	log    *log.Entry
+}
+
+// MetricsServerOpts is the options for the metricsServer
+type MetricsServerOpts struct {
+	ListenPort int
+}
+
+// NewMetrics returns a new instance of the metrics server
+func NewMetrics(opts MetricsServerOpts) Server {
+	logger := log.NewEntry(log.StandardLogger())
+
+	engine := echo.New()
+	engine.HideBanner = true
+
+	engine.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+
+	return &metricsServer{
+		port:   opts.ListenPort,
+		engine: engine,
+		log:    logger,
+	}
+}
+
+func (s *metricsServer) Name() string {
+	return "metricsServer"
+}
+
+func (s *metricsServer) Run() error {
+	log.Infof("%s serving on port %d", s.Name(), s.port)
+	return s.engine.Start(fmt.Sprintf(":%d", s.port))
+}
+
+func (s *metricsServer) Shutdown(ctx context.Context) error {
+	log.Infof("shutting down %s serving on port %d", s.Name(), s.port)
+	return s.engine.Shutdown(ctx)
+}
@@ -9,3 +9,8 @@ type Server interface {
 	Run() error
 	Shutdown(ctx context.Context) error
 }
+
+// Drainer is implemented by servers that stop taking new traffic before they are shut down
+type Drainer interface {
+	Drain()
+}
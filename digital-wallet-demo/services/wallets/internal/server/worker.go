@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/risk"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/worker"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// workerServer runs the background jobs of the wallet service
+type workerServer struct {
+	log    *log.Entry
+	db     *gorm.DB
+	jobs   []worker.Job
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// WorkerOpts is the options for the workerServer
+type WorkerOpts struct {
+	Config model.Config
+	// The database and clients are shared with the API server of the instance
+	// and closed by the caller once every server has shut down
+	DB          *gorm.DB
+	RedisClient cache.RedisClient
+	TxnClient   client.NewTransaction
+}
+
+// NewWorker returns a new instance of the background worker server
+func NewWorker(opts WorkerOpts) (Server, error) {
+	logger := log.NewEntry(log.StandardLogger())
+	log.SetFormatter(&log.JSONFormatter{})
+
+	// Initialize global logger
+	utils.InitLogger(logger)
+
+	dbInstance := opts.DB
+	redisClient := opts.RedisClient
+	txnClient := opts.TxnClient
+
+	rates, err := loadRates(opts.Config.FX)
+	if err != nil {
+		return nil, err
+	}
+
+	riskEngine, err := risk.NewEngine(opts.Config.Risk, repository.NewRiskDecisionRepo(dbInstance), rates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load risk rules: %v", err)
+	}
+
+	// Scheduled transfers are made through the same service as the API's transfers
+	walletService := service.NewWalletService(service.WalletServiceOpts{
+		WalletRepository:   repository.NewWalletRepo(dbInstance),
+		OutboxRepository:   repository.NewOutboxRepo(dbInstance),
+		FXQuoteRepository:  repository.NewFXQuoteRepo(dbInstance),
+		ReversalRepository: repository.NewReversalRepo(dbInstance),
+		HoldRepository:     repository.NewHoldRepo(dbInstance),
+		LimitRepository:    repository.NewLimitRepo(dbInstance),
+		ReviewRepository:   repository.NewReviewRepo(dbInstance),
+		AuditRepository:    repository.NewAuditRepo(dbInstance),
+		RedisClient:        redisClient,
+		TxnClient:          txnClient,
+		RiskEngine:         riskEngine,
+		Rates:              rates,
+		HoldTTL:            opts.Config.Holds.TTL,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &workerServer{
+		log:    logger,
+		db:     dbInstance,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	s.jobs = []worker.Job{
+		worker.NewOutboxRelay(repository.NewOutboxRepo(dbInstance), txnClient, redisClient, opts.Config.Outbox),
+		worker.NewHoldExpiry(repository.NewHoldRepo(dbInstance), opts.Config.Holds),
+		worker.NewScheduleRunner(repository.NewScheduleRepo(dbInstance), walletService, opts.Config.Schedules),
+		worker.NewLegacyIDResolver(repository.NewReversalRepo(dbInstance), repository.NewOutboxRepo(dbInstance), txnClient, opts.Config.Outbox),
+	}
+
+	return s, nil
+}
+
+func (s *workerServer) Name() string {
+	return "workerServer"
+}
+
+// Run starts every job and blocks until the server is shut down
+func (s *workerServer) Run() error {
+	for _, j := range s.jobs {
+		job := j
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.runJob(job)
+		}()
+	}
+	log.Infof("%s running %d jobs", s.Name(), len(s.jobs))
+
+	<-s.ctx.Done()
+	return nil
+}
+
+// runJob executes job every interval until the server is shut down
+func (s *workerServer) runJob(job worker.Job) {
+	ticker := time.NewTicker(job.Interval())
+	defer ticker.Stop()
+
+	for {
+		if err := job.RunOnce(s.ctx); err != nil {
+			utils.LogErrorContext(s.ctx, fmt.Sprintf("Job %s failed", job.Name()), err)
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown stops the jobs, waiting for in-flight runs to finish
+func (s *workerServer) Shutdown(ctx context.Context) error {
+	log.Infof("shutting down %s", s.Name())
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+)
+
+// auditBatchSize is the number of audit entries read at a time while verifying the log.
+const auditBatchSize = 500
+
+// errChainBroken stops the walk of the audit log at the first broken link.
+var errChainBroken = errors.New("audit chain broken")
+
+// Audit is the service for the audit log.
+type Audit interface {
+	Verify(ctx context.Context) (*model.AuditVerification, error)
+}
+
+type auditLog struct {
+	auditRepository repository.Audit
+}
+
+// NewAuditService creates a new Audit service.
+// Entries are appended by the other services in the transaction of the change they record.
+func NewAuditService(ar repository.Audit) Audit {
+	return &auditLog{
+		auditRepository: ar,
+	}
+}
+
+// Verify walks the audit log from its first entry and checks that every entry
+// is unchanged and linked to the entry before it. The walk stops at the first broken link.
+func (s *auditLog) Verify(ctx context.Context) (*model.AuditVerification, error) {
+	result := &model.AuditVerification{}
+	prevHash := ""
+	err := s.auditRepository.Walk(ctx, auditBatchSize, func(entry *model.AuditEntry) error {
+		if reason := entry.Verify(prevHash); reason != "" {
+			broken := *entry
+			result.BrokenAt, result.Reason = &broken, reason
+			return errChainBroken
+		}
+		result.Entries++
+		prevHash = entry.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errChainBroken) {
+		return nil, err
+	}
+	return result, nil
+}
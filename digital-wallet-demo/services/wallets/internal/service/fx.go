@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+)
+
+// FXRateProvider supplies exchange rates for currency conversion.
+type FXRateProvider interface {
+	// Rate returns the price of one unit of from in units of to.
+	// It returns model.ErrRateUnavailable if the pair is not quoted.
+	Rate(from, to model.Currency) (*big.Rat, error)
+}
+
+// FX is the service for exchange rate quotes.
+type FX interface {
+	Quote(ctx context.Context, from, to model.Currency, amount int64) (*model.FXQuote, error)
+}
+
+type fx struct {
+	quoteRepository repository.FXQuote
+	rates           FXRateProvider
+	quoteTTL        time.Duration
+}
+
+// NewFXService creates a new FX service. Quotes lock their rate for quoteTTL.
+func NewFXService(qr repository.FXQuote, rates FXRateProvider, quoteTTL time.Duration) FX {
+	return &fx{
+		quoteRepository: qr,
+		rates:           rates,
+		quoteTTL:        quoteTTL,
+	}
+}
+
+// Quote converts amount at the current rate and stores the quote so that a
+// transfer can redeem it at the same rate until it expires.
+func (f *fx) Quote(ctx context.Context, from, to model.Currency, amount int64) (*model.FXQuote, error) {
+	quote, err := convert(f.rates, from, to, amount, f.quoteTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.quoteRepository.Create(ctx, quote); err != nil {
+		utils.LogErrorContext(ctx, "Failed to store FX quote", err)
+		return nil, err
+	}
+	return quote, nil
+}
+
+// convert returns an unsaved quote converting amount at the provider's current rate.
+func convert(rates FXRateProvider, from, to model.Currency, amount int64, ttl time.Duration) (*model.FXQuote, error) {
+	if !from.IsSupported() || !to.IsSupported() {
+		return nil, model.ErrUnsupportedCurrency
+	}
+	rate, err := rates.Rate(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewFXQuote(from, to, amount, rate, ttl)
+}
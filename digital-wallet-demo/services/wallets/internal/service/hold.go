@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"gorm.io/gorm"
+)
+
+// HoldParams are the parameters of a hold.
+type HoldParams struct {
+	FromUserID string
+	ToUserID   string
+	Amount     int64              // Amount in minor units of Currency
+	Currency   model.Currency     // Defaults to the sender's base currency
+	Audit      model.AuditContext // Who made the change, recorded in the audit log
+}
+
+// CaptureParams are the parameters of a hold capture.
+type CaptureParams struct {
+	HoldID string
+	Amount int64              // Amount in minor units of the hold's currency, defaults to the full held amount
+	Audit  model.AuditContext // Who made the change, recorded in the audit log
+}
+
+// CreateHold reserves an amount of the sender's balance for a later capture by the receiver.
+// The held amount is no longer available, but no funds move until the hold is captured.
+func (t *wallet) CreateHold(ctx context.Context, params HoldParams) (*model.Hold, error) {
+	// Validate amount
+	if params.Amount <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+
+	// Fetch sender wallet to check balance
+	fromWallet, err := t.walletRepository.FindByUserID(ctx, params.FromUserID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Sender wallet not found for hold", err)
+		return nil, err
+	}
+	if err := fromWallet.CheckTransactable(); err != nil {
+		return nil, err
+	}
+
+	currency, err := resolveCurrency(params.Currency, fromWallet.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check balance
+	if fromWallet.BalanceIn(currency) < params.Amount {
+		return nil, model.ErrInsufficientFunds
+	}
+
+	// Fetch receiver wallet
+	toWallet, err := t.walletRepository.FindByUserID(ctx, params.ToUserID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Receiver wallet not found for hold", err)
+		return nil, err
+	}
+	if err := toWallet.CheckTransactable(); err != nil {
+		return nil, err
+	}
+
+	// Begin database transaction
+	tx := t.walletRepository.BeginTransaction(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// Lock the wallets, so that the available balance cannot change until the hold is stored
+	locked, err := lockTransactable(tx, t.walletRepository, fromWallet.ID, toWallet.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := t.checkAvailable(tx, locked[fromWallet.ID], currency, params.Amount); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	hold, err := model.NewHold(fromWallet.UserID, toWallet.UserID, currency, params.Amount, t.holdTTL)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := t.holdRepository.Create(tx, hold); err != nil {
+		utils.LogErrorContext(ctx, "Failed to create hold", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Record the hold in the audit log as part of the same transaction
+	if err := t.auditHold(tx, params.Audit, model.AuditHoldPlaced, hold); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		utils.LogErrorContext(ctx, "Failed to commit hold transaction", err)
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// GetHold retrieves a hold by ID.
+func (t *wallet) GetHold(ctx context.Context, id string) (*model.Hold, error) {
+	return t.holdRepository.FindByID(ctx, id)
+}
+
+// CaptureHold transfers all or part of a held amount to the hold's receiver.
+// The remainder of a partial capture is released.
+func (t *wallet) CaptureHold(ctx context.Context, params CaptureParams) (*model.Transaction, error) {
+	if params.Amount < 0 {
+		return nil, errors.New("invalid amount")
+	}
+
+	// Begin database transaction
+	tx := t.walletRepository.BeginTransaction(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// Lock the hold, so that it is captured or voided at most once
+	hold, err := t.holdRepository.FindForUpdate(tx, params.HoldID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := hold.CheckActive(time.Now()); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	amount := params.Amount
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount > hold.Amount {
+		tx.Rollback()
+		return nil, model.ErrCaptureAmount
+	}
+
+	// Fetch the wallets of both sides of the hold
+	fromWallet, err := t.walletRepository.FindByUserID(ctx, hold.FromUserID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Sender wallet not found for capture", err)
+		tx.Rollback()
+		return nil, err
+	}
+	toWallet, err := t.walletRepository.FindByUserID(ctx, hold.ToUserID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Receiver wallet not found for capture", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Check the wallets under lock, their status may have changed since the hold was created
+	locked, err := lockTransactable(tx, t.walletRepository, fromWallet.ID, toWallet.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// The capture is the outgoing transfer, so it is checked against the sender's spending limit
+	spentOn, err := t.applyLimits(tx, locked[fromWallet.ID], hold.Currency, amount)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Release the hold before moving the funds it reserved
+	hold.Status = model.HoldCaptured
+	hold.CapturedAmount = amount
+	if err := t.holdRepository.Update(tx, hold); err != nil {
+		utils.LogErrorContext(ctx, "Failed to update hold for capture", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Create debit transaction for sender
+	debitTxn := &model.Transaction{
+		SubjectWalletID: fromWallet.UserID,
+		ObjectWalletID:  toWallet.UserID,
+		TransactionType: model.Transfer,
+		OperationType:   model.Debit,
+		Amount:          amount,
+		Currency:        hold.Currency,
+		Status:          model.Completed,
+	}
+
+	// Create credit transaction for receiver
+	creditTxn := &model.Transaction{
+		SubjectWalletID: toWallet.UserID,
+		ObjectWalletID:  fromWallet.UserID,
+		TransactionType: model.Transfer,
+		OperationType:   model.Credit,
+		Amount:          amount,
+		Currency:        hold.Currency,
+		Status:          model.Completed,
+	}
+
+	// Update wallet balances
+	if err := updateBalance(tx, t.walletRepository, t.auditRepository, params.Audit, "hold_capture", fromWallet, hold.Currency, amount, false); err != nil {
+		utils.LogErrorContext(ctx, "Failed to update sender wallet balance for capture", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := updateBalance(tx, t.walletRepository, t.auditRepository, params.Audit, "hold_capture", toWallet, hold.Currency, amount, true); err != nil {
+		utils.LogErrorContext(ctx, "Failed to update receiver wallet balance for capture", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Record the ledger entries in the outbox as part of the same transaction
+	if err := t.enqueueTransactionPair(tx, debitTxn, creditTxn, params.Audit); err != nil {
+		utils.LogErrorContext(ctx, "Failed to enqueue transaction pair for capture", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Record the day the capture counted as spent, so that a reversal releases it from that day
+	if err := recordSpending(tx, t.limitRepository, debitTxn, spentOn); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		utils.LogErrorContext(ctx, "Failed to commit capture transaction", err)
+		return nil, err
+	}
+
+	// Invalidate cache for both sender and receiver
+	cacheCtx := context.WithoutCancel(ctx)
+	if err := t.redisClient.DeleteTransactionHistory(cacheCtx, fromWallet.UserID); err != nil {
+		utils.LogErrorContext(ctx, "Failed to invalidate sender cache after capture", err)
+	}
+	if err := t.redisClient.DeleteTransactionHistory(cacheCtx, toWallet.UserID); err != nil {
+		utils.LogErrorContext(ctx, "Failed to invalidate receiver cache after capture", err)
+	}
+
+	// Return the debit transaction for the sender
+	return debitTxn, nil
+}
+
+// VoidHold releases a hold without moving any funds.
+func (t *wallet) VoidHold(ctx context.Context, id string, audit model.AuditContext) (*model.Hold, error) {
+	// Begin database transaction
+	tx := t.walletRepository.BeginTransaction(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	hold, err := t.holdRepository.FindForUpdate(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := hold.CheckActive(time.Now()); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	hold.Status = model.HoldVoided
+	if err := t.holdRepository.Update(tx, hold); err != nil {
+		utils.LogErrorContext(ctx, "Failed to void hold", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Record the release in the audit log as part of the same transaction
+	if err := t.auditHold(tx, audit, model.AuditHoldVoided, hold); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		utils.LogErrorContext(ctx, "Failed to commit void transaction", err)
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// auditHold appends an entry of the action on hold to the audit log within tx.
+func (t *wallet) auditHold(tx *gorm.DB, audit model.AuditContext, action model.AuditAction, hold *model.Hold) error {
+	entry, err := model.NewAuditEntry(audit, action, hold.FromUserID, map[string]any{
+		"hold_id":    hold.ID,
+		"to_user_id": hold.ToUserID,
+		"amount":     hold.Amount,
+	})
+	if err != nil {
+		return err
+	}
+	entry.Currency = hold.Currency
+	if err := t.auditRepository.Append(tx, entry); err != nil {
+		utils.LogErrorContext(tx.Statement.Context, "Failed to append hold to audit log", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"gorm.io/gorm"
+)
+
+// updateBalance updates the balance of the locked wallet w in currency within tx
+// and records the change in the audit log as part of the same transaction.
+func updateBalance(tx *gorm.DB, wr repository.Wallet, ar repository.Audit, audit model.AuditContext, operation string, w *model.Wallet, currency model.Currency, amount int64, isCredit bool) error {
+	after, err := wr.UpdateWalletBalance(tx, w.ID, currency, amount, isCredit)
+	if err != nil {
+		return err
+	}
+
+	before := after + amount
+	if isCredit {
+		before = after - amount
+	}
+	entry, err := model.NewBalanceAuditEntry(audit, w.UserID, currency, before, after, operation)
+	if err != nil {
+		return err
+	}
+	if err := ar.Append(tx, entry); err != nil {
+		utils.LogErrorContext(tx.Statement.Context, "Failed to append balance change to audit log", err)
+		return err
+	}
+	return nil
+}
+
+// inAuditedTransaction runs fn in a new transaction and appends the audit entry it returns
+// before committing, so that a change is made if and only if it is recorded.
+func inAuditedTransaction(ctx context.Context, wr repository.Wallet, ar repository.Audit, fn func(tx *gorm.DB) (*model.AuditEntry, error)) error {
+	tx := wr.BeginTransaction(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	entry, err := fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := ar.Append(tx, entry); err != nil {
+		utils.LogErrorContext(ctx, "Failed to append to audit log", err)
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
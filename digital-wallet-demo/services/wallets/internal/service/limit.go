@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"gorm.io/gorm"
+)
+
+// Limit is the service for managing spending limits.
+type Limit interface {
+	List(ctx context.Context) ([]model.SpendingLimit, error)
+	SetForWallet(ctx context.Context, userID string, params LimitParams) (*model.SpendingLimit, error)
+	SetForType(ctx context.Context, acntType model.AcntType, params LimitParams) (*model.SpendingLimit, error)
+	DeleteForWallet(ctx context.Context, userID string, audit model.AuditContext) error
+	DeleteForType(ctx context.Context, acntType model.AcntType, audit model.AuditContext) error
+}
+
+// LimitParams are the bounds of a spending limit. Zero bounds are not enforced.
+type LimitParams struct {
+	Currency       model.Currency // Currency of the amounts, defaults to the wallet's base currency or DefaultCurrency for an account type
+	MaxTransaction int64
+	DailyAmount    int64
+	MonthlyAmount  int64
+	DailyCount     int
+	MonthlyCount   int
+	Audit          model.AuditContext // Who made the change, recorded in the audit log
+}
+
+type limit struct {
+	limitRepository  repository.Limit
+	walletRepository repository.Wallet
+	auditRepository  repository.Audit
+}
+
+// NewLimitService creates a new Limit service.
+// Limits are enforced by the wallet service on withdrawals, transfers and hold captures.
+// Changes of limits are recorded in the audit log of ar.
+func NewLimitService(lr repository.Limit, wr repository.Wallet, ar repository.Audit) Limit {
+	return &limit{
+		limitRepository:  lr,
+		walletRepository: wr,
+		auditRepository:  ar,
+	}
+}
+
+// List returns every spending limit.
+func (s *limit) List(ctx context.Context) ([]model.SpendingLimit, error) {
+	return s.limitRepository.FindAll(ctx)
+}
+
+// SetForWallet sets the limit of a wallet, which takes the place of the limit of its account type.
+func (s *limit) SetForWallet(ctx context.Context, userID string, params LimitParams) (*model.SpendingLimit, error) {
+	w, err := s.walletRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Wallet not found for spending limit", err)
+		return nil, err
+	}
+
+	currency, err := resolveCurrency(params.Currency, w.Currency)
+	if err != nil {
+		return nil, err
+	}
+	l := newSpendingLimit(currency, params)
+	l.UserID = &w.UserID
+	if err := s.upsert(ctx, l, w.UserID, params.Audit); err != nil {
+		utils.LogErrorContext(ctx, "Failed to set wallet spending limit", err)
+		return nil, err
+	}
+	return l, nil
+}
+
+// SetForType sets the limit of every wallet of an account type without a limit of its own.
+func (s *limit) SetForType(ctx context.Context, acntType model.AcntType, params LimitParams) (*model.SpendingLimit, error) {
+	currency, err := resolveCurrency(params.Currency, model.DefaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+	l := newSpendingLimit(currency, params)
+	l.AcntType = &acntType
+	if err := s.upsert(ctx, l, "", params.Audit); err != nil {
+		utils.LogErrorContext(ctx, "Failed to set account type spending limit", err)
+		return nil, err
+	}
+	return l, nil
+}
+
+// DeleteForWallet removes the limit of a wallet; the limit of its account type applies again.
+func (s *limit) DeleteForWallet(ctx context.Context, userID string, audit model.AuditContext) error {
+	return inAuditedTransaction(ctx, s.walletRepository, s.auditRepository, func(tx *gorm.DB) (*model.AuditEntry, error) {
+		if err := s.limitRepository.DeleteForWallet(tx, userID); err != nil {
+			return nil, err
+		}
+		return model.NewAuditEntry(audit, model.AuditLimitDeleted, userID, nil)
+	})
+}
+
+// DeleteForType removes the limit of an account type.
+func (s *limit) DeleteForType(ctx context.Context, acntType model.AcntType, audit model.AuditContext) error {
+	return inAuditedTransaction(ctx, s.walletRepository, s.auditRepository, func(tx *gorm.DB) (*model.AuditEntry, error) {
+		if err := s.limitRepository.DeleteForType(tx, acntType); err != nil {
+			return nil, err
+		}
+		return model.NewAuditEntry(audit, model.AuditLimitDeleted, "", map[string]any{"acnt_type": acntType})
+	})
+}
+
+// upsert stores the limit and records it in the audit log in one transaction.
+// userID is empty for the limit of an account type.
+func (s *limit) upsert(ctx context.Context, l *model.SpendingLimit, userID string, audit model.AuditContext) error {
+	return inAuditedTransaction(ctx, s.walletRepository, s.auditRepository, func(tx *gorm.DB) (*model.AuditEntry, error) {
+		if err := s.limitRepository.Upsert(tx, l); err != nil {
+			return nil, err
+		}
+		return model.NewAuditEntry(audit, model.AuditLimitSet, userID, map[string]any{
+			"acnt_type":       l.AcntType,
+			"currency":        l.Currency,
+			"max_transaction": l.MaxTransaction,
+			"daily_amount":    l.DailyAmount,
+			"monthly_amount":  l.MonthlyAmount,
+			"daily_count":     l.DailyCount,
+			"monthly_count":   l.MonthlyCount,
+		})
+	})
+}
+
+func newSpendingLimit(currency model.Currency, params LimitParams) *model.SpendingLimit {
+	return &model.SpendingLimit{
+		Currency:       currency,
+		MaxTransaction: params.MaxTransaction,
+		DailyAmount:    params.DailyAmount,
+		MonthlyAmount:  params.MonthlyAmount,
+		DailyCount:     params.DailyCount,
+		MonthlyCount:   params.MonthlyCount,
+	}
+}
+
+// applyLimits checks an outgoing transaction of amount in currency from the
+// locked wallet against its spending limit, and records it as spent within tx.
+// It returns the day the transaction was counted on, for recordSpending.
+// The wallet lock serializes the check with the wallet's other outgoing transactions.
+func (t *wallet) applyLimits(tx *gorm.DB, w *model.Wallet, currency model.Currency, amount int64) (time.Time, error) {
+	now := time.Now()
+	day := model.DayStart(now)
+
+	l, err := t.limitRepository.FindApplicable(tx, w.UserID, w.AcntType)
+	if err != nil {
+		utils.LogErrorContext(tx.Statement.Context, "Failed to find spending limit", err)
+		return time.Time{}, err
+	}
+	if l != nil {
+		usage, err := t.limitRepository.UsageSince(tx, w.UserID, model.MonthStart(now))
+		if err != nil {
+			utils.LogErrorContext(tx.Statement.Context, "Failed to load spending usage", err)
+			return time.Time{}, err
+		}
+		daily, monthly, err := t.spendIn(l.Currency, usage, day)
+		if err != nil {
+			return time.Time{}, err
+		}
+		converted, err := t.amountIn(l.Currency, currency, amount)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if err := l.Check(converted, daily, monthly); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	// Usage is recorded without a limit too, so that a limit set later counts what was already spent
+	if err := t.limitRepository.RecordUsage(tx, w.UserID, currency, day, amount); err != nil {
+		utils.LogErrorContext(tx.Statement.Context, "Failed to record spending usage", err)
+		return time.Time{}, err
+	}
+	return day, nil
+}
+
+// recordSpending records within tx that the pair of debitTxn was counted in
+// the spending usage of its sender on day, as returned by applyLimits.
+func recordSpending(tx *gorm.DB, lr repository.Limit, debitTxn *model.Transaction, day time.Time) error {
+	record := &model.SpendingRecord{PairID: debitTxn.PairID, UserID: debitTxn.SubjectWalletID, Day: day}
+	if err := lr.RecordSpending(tx, record); err != nil {
+		utils.LogErrorContext(tx.Statement.Context, "Failed to record spending", err)
+		return err
+	}
+	return nil
+}
+
+// releaseLimits takes an outgoing transaction of the wallet that was cancelled
+// or reversed off its spending usage within tx, so that it no longer counts
+// against the limit. The usage is released from the day recorded for the pair
+// by recordSpending, or for pairs recorded before, from the day of spentAt.
+func releaseLimits(tx *gorm.DB, lr repository.Limit, userID string, currency model.Currency, pairID string, spentAt time.Time, amount int64, count int) error {
+	day := model.DayStart(spentAt)
+	if pairID != "" {
+		record, err := lr.FindSpending(tx, pairID)
+		if err != nil {
+			utils.LogErrorContext(tx.Statement.Context, "Failed to find spending record", err)
+			return err
+		}
+		if record != nil {
+			day = record.Day
+		}
+	}
+	if err := lr.ReleaseUsage(tx, userID, currency, day, amount, count); err != nil {
+		utils.LogErrorContext(tx.Statement.Context, "Failed to release spending usage", err)
+		return err
+	}
+	return nil
+}
+
+// spendIn totals the usage of the month in the limit's currency, and separately the usage from today on.
+func (t *wallet) spendIn(currency model.Currency, usage []model.SpendingUsage, today time.Time) (daily, monthly model.Spend, err error) {
+	for _, u := range usage {
+		amount, err := t.amountIn(currency, u.Currency, u.Amount)
+		if err != nil {
+			return model.Spend{}, model.Spend{}, err
+		}
+		monthly.Amount += amount
+		monthly.Count += u.Count
+		if !u.Day.Before(today) {
+			daily.Amount += amount
+			daily.Count += u.Count
+		}
+	}
+	return daily, monthly, nil
+}
+
+// amountIn converts amount from one currency to another at the current rate.
+func (t *wallet) amountIn(to, from model.Currency, amount int64) (int64, error) {
+	if from == to {
+		return amount, nil
+	}
+	quote, err := convert(t.rates, from, to, amount, 0)
+	if err != nil {
+		return 0, err
+	}
+	return quote.CounterAmount, nil
+}
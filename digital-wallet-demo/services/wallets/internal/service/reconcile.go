@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+)
+
+// Reconciliation checks wallet balances against the ledger kept by the transaction service.
+type Reconciliation interface {
+	Reconcile(ctx context.Context) (*model.ReconciliationReport, error)
+	Compensate(ctx context.Context, report *model.ReconciliationReport, audit model.AuditContext) ([]model.WalletReconciliation, error)
+}
+
+type reconciliation struct {
+	walletRepository repository.Wallet
+	outboxRepository repository.Outbox
+	auditRepository  repository.Audit
+	txnClient        client.NewTransaction
+}
+
+// NewReconciliationService creates a new Reconciliation service.
+// Ledger balances are read from the transaction service through tc, and
+// adjustments are recorded in the audit log of ar.
+func NewReconciliationService(wr repository.Wallet, or repository.Outbox, ar repository.Audit, tc client.NewTransaction) Reconciliation {
+	return &reconciliation{
+		walletRepository: wr,
+		outboxRepository: or,
+		auditRepository:  ar,
+		txnClient:        tc,
+	}
+}
+
+// Reconcile compares every wallet balance with the net of its ledger entries.
+//
+// Balances and undelivered outbox entries are read from a single snapshot so
+// that a balance change committed during the run is either fully counted as
+// in flight or not seen at all.
+func (r *reconciliation) Reconcile(ctx context.Context) (*model.ReconciliationReport, error) {
+	tx := r.walletRepository.BeginSnapshot(ctx)
+	defer func() {
+		if rec := recover(); rec != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	wallets, err := r.walletRepository.FindAll(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to load wallets: %w", err)
+	}
+
+	pending, err := r.outboxRepository.FindPending(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to load pending outbox entries: %w", err)
+	}
+	tx.Rollback()
+
+	inFlight, err := outboxNetByWallet(pending)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := r.txnClient.FetchLedgerBalances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ledger balances: %w", err)
+	}
+
+	return model.NewReconciliationReport(wallets, ledger, inFlight), nil
+}
+
+// Compensate records an adjustment pair against the suspense account for every
+// drifted wallet of the report, so that its ledger matches its balance again.
+//
+// The wallets are reconciled a second time first. A wallet is only compensated
+// if it has no undelivered outbox entries and its drift is unchanged, which
+// rules out drift caused by transactions running concurrently with the check.
+// The adjustments are delivered by the outbox relay like any other pair.
+func (r *reconciliation) Compensate(ctx context.Context, report *model.ReconciliationReport, audit model.AuditContext) ([]model.WalletReconciliation, error) {
+	recheck, err := r.Reconcile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[model.BalanceKey]model.WalletReconciliation, len(recheck.Wallets))
+	for _, w := range recheck.Wallets {
+		current[model.BalanceKey{WalletID: w.WalletID, Currency: w.Currency}] = w
+	}
+
+	var stable []model.WalletReconciliation
+	for _, w := range report.Wallets {
+		if w.Status != model.Drifted {
+			continue
+		}
+		if cur, ok := current[model.BalanceKey{WalletID: w.WalletID, Currency: w.Currency}]; !ok || cur != w || w.InFlight != 0 {
+			utils.LogErrorfContext(ctx, "Skipping compensation of wallet %s in %s: drift is not stable", w.WalletID, w.Currency)
+			continue
+		}
+		stable = append(stable, w)
+	}
+	if len(stable) == 0 {
+		return nil, nil
+	}
+
+	tx := r.walletRepository.BeginTransaction(ctx)
+	defer func() {
+		if rec := recover(); rec != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	for _, w := range stable {
+		debitTxn, creditTxn := adjustmentPair(w.WalletID, w.Currency, w.Drift)
+		entry, err := model.NewTransactionPairEntry(debitTxn, creditTxn)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		entry.RequestID = audit.RequestID
+		if err := r.outboxRepository.Enqueue(tx, entry); err != nil {
+			utils.LogErrorContext(ctx, "Failed to enqueue adjustment pair", err)
+			tx.Rollback()
+			return nil, err
+		}
+
+		auditEntry, err := model.NewAuditEntry(audit, model.AuditLedgerAdjusted, w.WalletID, map[string]any{
+			"currency": w.Currency,
+			"balance":  w.Balance,
+			"ledger":   w.Ledger,
+			"drift":    w.Drift,
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := r.auditRepository.Append(tx, auditEntry); err != nil {
+			utils.LogErrorContext(ctx, "Failed to append adjustment to audit log", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.LogErrorContext(ctx, "Failed to commit adjustment pairs", err)
+		return nil, err
+	}
+
+	return stable, nil
+}
+
+// adjustmentPair returns the pair moving drift between the wallet's ledger and the suspense account.
+// A positive drift credits the wallet, a negative drift debits it.
+func adjustmentPair(walletID string, currency model.Currency, drift int64) (*model.Transaction, *model.Transaction) {
+	from, to, amount := model.ReconciliationSuspenseID, walletID, drift
+	if drift < 0 {
+		from, to, amount = walletID, model.ReconciliationSuspenseID, -drift
+	}
+
+	debitTxn := &model.Transaction{
+		SubjectWalletID: from,
+		ObjectWalletID:  to,
+		TransactionType: model.Adjustment,
+		OperationType:   model.Debit,
+		Amount:          amount,
+		Currency:        currency,
+		Status:          model.Completed,
+	}
+	creditTxn := &model.Transaction{
+		SubjectWalletID: to,
+		ObjectWalletID:  from,
+		TransactionType: model.Adjustment,
+		OperationType:   model.Credit,
+		Amount:          amount,
+		Currency:        currency,
+		Status:          model.Completed,
+	}
+	return debitTxn, creditTxn
+}
+
+// outboxNetByWallet sums credits minus debits of undelivered completed pairs per wallet and currency,
+// including pending pairs completed by an undelivered status change.
+func outboxNetByWallet(entries []model.OutboxEntry) (map[model.BalanceKey]int64, error) {
+	net := make(map[model.BalanceKey]int64)
+	for _, entry := range entries {
+		pair, err := entry.Pair()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode outbox entry %d: %w", entry.ID, err)
+		}
+		for _, txn := range []model.Transaction{pair.Debit, pair.Credit} {
+			if txn.Status != model.Completed {
+				continue
+			}
+			key := model.LedgerBalance{SubjectWalletID: txn.SubjectWalletID, Currency: txn.Currency}.Key()
+			if txn.OperationType == model.Credit {
+				net[key] += txn.Amount
+			} else {
+				net[key] -= txn.Amount
+			}
+		}
+	}
+	return net, nil
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"gorm.io/gorm"
+)
+
+// Review is the service for the manual review queue.
+type Review interface {
+	List(ctx context.Context, query model.ReviewQuery) ([]model.Review, error)
+	Get(ctx context.Context, id string) (*model.Review, error)
+	Approve(ctx context.Context, id, note string, audit model.AuditContext) (*model.Review, error)
+	Reject(ctx context.Context, id, note string, audit model.AuditContext) (*model.Review, error)
+	ListFlags(ctx context.Context) ([]model.ReviewFlag, error)
+	Flag(ctx context.Context, userID, reason string, audit model.AuditContext) (*model.ReviewFlag, error)
+	Unflag(ctx context.Context, userID string, audit model.AuditContext) error
+}
+
+type review struct {
+	reviewRepository repository.Review
+	walletRepository repository.Wallet
+	limitRepository  repository.Limit
+	outboxRepository repository.Outbox
+	auditRepository  repository.Audit
+	redisClient      cache.RedisClient
+}
+
+// ReviewServiceOpts is the options for the Review service
+type ReviewServiceOpts struct {
+	ReviewRepository repository.Review
+	WalletRepository repository.Wallet
+	LimitRepository  repository.Limit  // Spending usage of rejected transactions is released from it
+	OutboxRepository repository.Outbox // Decisions are delivered to the transaction service through it
+	AuditRepository  repository.Audit  // Log decisions and flag changes are recorded in
+	RedisClient      cache.RedisClient // Cached histories of the wallets of a decided review are invalidated in it
+}
+
+// NewReviewService creates a new Review service.
+// Transactions are queued for review by the wallet service.
+func NewReviewService(opts ReviewServiceOpts) Review {
+	return &review{
+		reviewRepository: opts.ReviewRepository,
+		walletRepository: opts.WalletRepository,
+		limitRepository:  opts.LimitRepository,
+		outboxRepository: opts.OutboxRepository,
+		auditRepository:  opts.AuditRepository,
+		redisClient:      opts.RedisClient,
+	}
+}
+
+// List returns the reviews matching the query, oldest first.
+func (s *review) List(ctx context.Context, query model.ReviewQuery) ([]model.Review, error) {
+	return s.reviewRepository.FindAll(ctx, query)
+}
+
+// Get returns a review.
+func (s *review) Get(ctx context.Context, id string) (*model.Review, error) {
+	return s.reviewRepository.FindByID(ctx, id)
+}
+
+// Approve moves the reserved funds of a pending review and completes its transaction pair.
+func (s *review) Approve(ctx context.Context, id, note string, audit model.AuditContext) (*model.Review, error) {
+	return s.decide(ctx, id, note, model.ReviewApproved, audit)
+}
+
+// Reject releases the reserved funds of a pending review and cancels its transaction pair.
+func (s *review) Reject(ctx context.Context, id, note string, audit model.AuditContext) (*model.Review, error) {
+	return s.decide(ctx, id, note, model.ReviewRejected, audit)
+}
+
+func (s *review) decide(ctx context.Context, id, note string, status model.ReviewStatus, audit model.AuditContext) (*model.Review, error) {
+	// Begin database transaction
+	tx := s.walletRepository.BeginTransaction(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// Lock the review so that it is decided at most once
+	rv, err := s.reviewRepository.FindForUpdate(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := rv.CheckPending(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	txnStatus := model.Cancelled
+	if status == model.ReviewApproved {
+		txnStatus = model.Completed
+		if err := s.settle(ctx, tx, rv, audit); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else {
+		// The transaction counted against the sender's limit when it was queued
+		if err := releaseLimits(tx, s.limitRepository, rv.FromUserID, rv.Currency, rv.PairID, rv.CreatedAt, rv.Amount, 1); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	rv.Status, rv.Note, rv.DecidedAt = status, note, &now
+	if err := s.reviewRepository.Update(tx, rv); err != nil {
+		utils.LogErrorContext(ctx, "Failed to update review", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Record the decision in the audit log as part of the same transaction
+	auditEntry, err := model.NewAuditEntry(audit, model.AuditReviewDecided, rv.FromUserID, map[string]any{
+		"review_id": rv.ID,
+		"status":    status,
+		"note":      note,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := s.auditRepository.Append(tx, auditEntry); err != nil {
+		utils.LogErrorContext(ctx, "Failed to append review decision to audit log", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Record the new status of the pending pair in the outbox as part of the same transaction
+	debitTxn, creditTxn := rv.Transactions(txnStatus)
+	entry, err := model.NewPairStatusEntry(rv.ID, debitTxn, creditTxn)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	entry.RequestID = audit.RequestID
+
+	// The status is delivered once the pending pair it settles was delivered
+	if rv.PairID != "" {
+		pairEntry, err := s.outboxRepository.FindPairEntry(tx, rv.PairID)
+		if err != nil {
+			utils.LogErrorContext(ctx, "Failed to find outbox entry of reviewed pair", err)
+			tx.Rollback()
+			return nil, err
+		}
+		if pairEntry != nil {
+			entry.DependsOn = &pairEntry.ID
+		}
+	}
+
+	if err := s.outboxRepository.Enqueue(tx, entry); err != nil {
+		utils.LogErrorContext(ctx, "Failed to enqueue pair status for review", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		utils.LogErrorContext(ctx, "Failed to commit review decision", err)
+		return nil, err
+	}
+
+	// Invalidate cache for both wallets of the pair
+	cacheCtx := context.WithoutCancel(ctx)
+	for _, userID := range []string{rv.FromUserID, rv.ToUserID} {
+		if err := s.redisClient.DeleteTransactionHistory(cacheCtx, userID); err != nil {
+			utils.LogErrorContext(ctx, "Failed to invalidate cache after review decision", err)
+		}
+	}
+
+	return rv, nil
+}
+
+// settle moves the funds of an approved review within tx.
+// Both wallets must still be able to send and receive funds.
+func (s *review) settle(ctx context.Context, tx *gorm.DB, rv *model.Review, audit model.AuditContext) error {
+	fromWallet, err := s.walletRepository.FindByUserID(ctx, rv.FromUserID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Sender wallet not found for review", err)
+		return err
+	}
+	toWallet, err := s.walletRepository.FindByUserID(ctx, rv.ToUserID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Receiver wallet not found for review", err)
+		return err
+	}
+	if _, err := lockTransactable(tx, s.walletRepository, fromWallet.ID, toWallet.ID); err != nil {
+		return err
+	}
+
+	if err := updateBalance(tx, s.walletRepository, s.auditRepository, audit, "review_approval", fromWallet, rv.Currency, rv.Amount, false); err != nil {
+		utils.LogErrorContext(ctx, "Failed to update sender wallet balance for review", err)
+		return err
+	}
+	if err := updateBalance(tx, s.walletRepository, s.auditRepository, audit, "review_approval", toWallet, rv.CreditCurrency, rv.CreditAmount, true); err != nil {
+		utils.LogErrorContext(ctx, "Failed to update receiver wallet balance for review", err)
+		return err
+	}
+	return nil
+}
+
+// ListFlags returns every wallet flagged for review.
+func (s *review) ListFlags(ctx context.Context) ([]model.ReviewFlag, error) {
+	return s.reviewRepository.FindFlags(ctx)
+}
+
+// Flag queues every later transfer and withdrawal of a wallet for review.
+func (s *review) Flag(ctx context.Context, userID, reason string, audit model.AuditContext) (*model.ReviewFlag, error) {
+	w, err := s.walletRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Wallet not found for review flag", err)
+		return nil, err
+	}
+
+	flag := &model.ReviewFlag{UserID: w.UserID, Reason: reason}
+	err = inAuditedTransaction(ctx, s.walletRepository, s.auditRepository, func(tx *gorm.DB) (*model.AuditEntry, error) {
+		if err := s.reviewRepository.UpsertFlag(tx, flag); err != nil {
+			return nil, err
+		}
+		return model.NewAuditEntry(audit, model.AuditReviewFlagSet, w.UserID, map[string]any{"reason": reason})
+	})
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to flag wallet for review", err)
+		return nil, err
+	}
+	return flag, nil
+}
+
+// Unflag removes the review flag of a wallet.
+func (s *review) Unflag(ctx context.Context, userID string, audit model.AuditContext) error {
+	return inAuditedTransaction(ctx, s.walletRepository, s.auditRepository, func(tx *gorm.DB) (*model.AuditEntry, error) {
+		if err := s.reviewRepository.DeleteFlag(tx, userID); err != nil {
+			return nil, err
+		}
+		return model.NewAuditEntry(audit, model.AuditReviewFlagRemoved, userID, nil)
+	})
+}
+
+// queueForReview queues a transaction pair for review within tx if the risk
+// decision holds it or its sender is flagged for review, and marks both
+// transactions pending. It returns nil if the pair completes right away.
+func (t *wallet) queueForReview(tx *gorm.DB, decision *model.RiskDecision, debitTxn, creditTxn *model.Transaction) (*model.Review, error) {
+	reason, rule := model.ReviewRisk, decision.Rule
+	if decision.Action != model.RiskReview {
+		flagged, err := t.reviewRepository.IsFlagged(tx, debitTxn.SubjectWalletID)
+		if err != nil || !flagged {
+			return nil, err
+		}
+		reason, rule = model.ReviewFlagged, ""
+	}
+
+	debitTxn.Status, creditTxn.Status = model.Pending, model.Pending
+	rv, err := model.NewReview(debitTxn, creditTxn, reason, rule)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.reviewRepository.Create(tx, rv); err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"gorm.io/gorm"
+)
+
+// RiskEngine decides whether an outgoing transfer or withdrawal may complete.
+type RiskEngine interface {
+	// Evaluate returns the recorded decision for the checked transaction, made within tx.
+	Evaluate(ctx context.Context, tx *gorm.DB, check model.RiskCheck) (*model.RiskDecision, error)
+}
+
+// screen evaluates the risk rules for an outgoing transaction within tx.
+// It returns ErrRiskRejected for a rejected transaction; an approved one
+// completes, and one held for review is recorded as pending.
+func (t *wallet) screen(ctx context.Context, tx *gorm.DB, check model.RiskCheck) (*model.RiskDecision, error) {
+	check.At = time.Now()
+	decision, err := t.riskEngine.Evaluate(ctx, tx, check)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to evaluate risk rules", err)
+		return nil, err
+	}
+	if decision.Action == model.RiskReject {
+		return nil, model.ErrRiskRejected
+	}
+	return decision, nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+)
+
+// Schedule is the service for scheduled and recurring transfers.
+type Schedule interface {
+	Create(ctx context.Context, params ScheduleParams) (*model.Schedule, error)
+	List(ctx context.Context, userID string) ([]model.Schedule, error)
+	Get(ctx context.Context, userID string, id int) (*model.Schedule, error)
+	Update(ctx context.Context, params UpdateScheduleParams) (*model.Schedule, error)
+	Delete(ctx context.Context, userID string, id int) error
+	Pause(ctx context.Context, userID string, id int) (*model.Schedule, error)
+	Resume(ctx context.Context, userID string, id int) (*model.Schedule, error)
+}
+
+// ScheduleParams are the parameters of a scheduled transfer.
+type ScheduleParams struct {
+	FromUserID string
+	ToUserID   string
+	Amount     int64          // Amount in minor units of Currency
+	Currency   model.Currency // Defaults to the sender's base currency at the time of each run
+	ToCurrency model.Currency // Currency credited to the receiver, converted at the rate at the time of each run
+	Recurrence model.Recurrence
+	CronExpr   string // Standard 5-field cron expression of a cron recurrence
+	StartAt    time.Time
+}
+
+// UpdateScheduleParams are the parameters of a schedule change.
+// Zero fields are left unchanged.
+type UpdateScheduleParams struct {
+	FromUserID string
+	ID         int
+	ToUserID   string
+	Amount     int64
+	Recurrence model.Recurrence
+	CronExpr   string
+	StartAt    *time.Time
+}
+
+type schedule struct {
+	scheduleRepository repository.Schedule
+	walletRepository   repository.Wallet
+}
+
+// NewScheduleService creates a new Schedule service.
+// Due schedules are run by the worker, see worker.NewScheduleRunner.
+func NewScheduleService(sr repository.Schedule, wr repository.Wallet) Schedule {
+	return &schedule{
+		scheduleRepository: sr,
+		walletRepository:   wr,
+	}
+}
+
+// Create stores a scheduled transfer, which first runs at its start time or,
+// for a cron schedule, at the first occurrence from its start time.
+func (s *schedule) Create(ctx context.Context, params ScheduleParams) (*model.Schedule, error) {
+	if _, err := s.walletRepository.FindByUserID(ctx, params.FromUserID); err != nil {
+		utils.LogErrorContext(ctx, "Sender wallet not found for schedule", err)
+		return nil, err
+	}
+	if _, err := s.walletRepository.FindByUserID(ctx, params.ToUserID); err != nil {
+		utils.LogErrorContext(ctx, "Receiver wallet not found for schedule", err)
+		return nil, err
+	}
+
+	sched := &model.Schedule{
+		FromUserID: params.FromUserID,
+		ToUserID:   params.ToUserID,
+		Amount:     params.Amount,
+		Currency:   params.Currency,
+		ToCurrency: params.ToCurrency,
+		Recurrence: params.Recurrence,
+		CronExpr:   params.CronExpr,
+		StartAt:    params.StartAt,
+		Status:     model.ScheduleActive,
+	}
+	if err := plan(sched, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepository.Create(ctx, sched); err != nil {
+		utils.LogErrorContext(ctx, "Failed to create schedule", err)
+		return nil, err
+	}
+	return sched, nil
+}
+
+// List returns the schedules of a wallet.
+func (s *schedule) List(ctx context.Context, userID string) ([]model.Schedule, error) {
+	if _, err := s.walletRepository.FindByUserID(ctx, userID); err != nil {
+		utils.LogErrorContext(ctx, "Wallet not found for schedules", err)
+		return nil, err
+	}
+	return s.scheduleRepository.FindByUserID(ctx, userID)
+}
+
+// Get returns a schedule of a wallet.
+func (s *schedule) Get(ctx context.Context, userID string, id int) (*model.Schedule, error) {
+	return s.scheduleRepository.FindByID(ctx, userID, id)
+}
+
+// Update changes the receiver, amount or timing of a schedule that has runs left.
+// A change of timing moves the next run to the first occurrence from now.
+func (s *schedule) Update(ctx context.Context, params UpdateScheduleParams) (*model.Schedule, error) {
+	return s.change(ctx, params.FromUserID, params.ID, func(sched *model.Schedule) error {
+		if sched.Status == model.ScheduleCompleted {
+			return model.ErrScheduleState
+		}
+
+		if params.ToUserID != "" {
+			if _, err := s.walletRepository.FindByUserID(ctx, params.ToUserID); err != nil {
+				utils.LogErrorContext(ctx, "Receiver wallet not found for schedule", err)
+				return err
+			}
+			sched.ToUserID = params.ToUserID
+		}
+		if params.Amount != 0 {
+			sched.Amount = params.Amount
+		}
+
+		if params.Recurrence != "" || params.CronExpr != "" || params.StartAt != nil {
+			if params.Recurrence != "" {
+				sched.Recurrence = params.Recurrence
+				sched.CronExpr = ""
+			}
+			if params.CronExpr != "" {
+				sched.CronExpr = params.CronExpr
+			}
+			if params.StartAt != nil {
+				sched.StartAt = *params.StartAt
+			}
+			return plan(sched, time.Now())
+		}
+		return nil
+	})
+}
+
+// Delete removes a schedule of a wallet. Runs already made are not affected.
+func (s *schedule) Delete(ctx context.Context, userID string, id int) error {
+	return s.scheduleRepository.Delete(ctx, userID, id)
+}
+
+// Pause stops an active schedule from running until it is resumed.
+func (s *schedule) Pause(ctx context.Context, userID string, id int) (*model.Schedule, error) {
+	return s.change(ctx, userID, id, func(sched *model.Schedule) error {
+		if sched.Status != model.ScheduleActive {
+			return model.ErrScheduleState
+		}
+		sched.Status = model.SchedulePaused
+		return nil
+	})
+}
+
+// Resume reactivates a paused schedule. Occurrences of a recurring schedule
+// missed while it was paused are skipped; a one-off transfer that became due
+// while paused runs on the next poll.
+func (s *schedule) Resume(ctx context.Context, userID string, id int) (*model.Schedule, error) {
+	return s.change(ctx, userID, id, func(sched *model.Schedule) error {
+		if sched.Status != model.SchedulePaused {
+			return model.ErrScheduleState
+		}
+
+		now := time.Now()
+		if sched.Recurrence != model.Once && sched.NextRunAt != nil && sched.NextRunAt.Before(now) {
+			next, _, err := sched.NextRun(now)
+			if err != nil {
+				return err
+			}
+			sched.NextRunAt = &next
+		}
+
+		sched.Status = model.ScheduleActive
+		return nil
+	})
+}
+
+// maxScheduleChangeAttempts is the number of times a change is applied to a
+// fresh copy of a schedule that the runner keeps advancing.
+const maxScheduleChangeAttempts = 3
+
+// change reads a schedule of a wallet, applies edit to it and stores it. If the
+// runner advanced the schedule in between, the edit is applied again to the
+// advanced schedule, so that a change never undoes a run.
+func (s *schedule) change(ctx context.Context, userID string, id int, edit func(sched *model.Schedule) error) (*model.Schedule, error) {
+	for attempt := 1; ; attempt++ {
+		sched, err := s.scheduleRepository.FindByID(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		status, nextRunAt := sched.Status, sched.NextRunAt
+		if err := edit(sched); err != nil {
+			return nil, err
+		}
+
+		err = s.scheduleRepository.Update(ctx, sched, status, nextRunAt)
+		if err == model.ErrScheduleConflict && attempt < maxScheduleChangeAttempts {
+			continue
+		}
+		if err != nil {
+			utils.LogErrorContext(ctx, "Failed to change schedule", err)
+			return nil, err
+		}
+		return sched, nil
+	}
+}
+
+// plan checks the recurrence of a schedule and sets its next run to the first occurrence after now.
+// A schedule without any occurrence after now, such as a one-off transfer in the past, is invalid.
+func plan(sched *model.Schedule, now time.Time) error {
+	if err := sched.CheckRecurrence(); err != nil {
+		return err
+	}
+	next, ok, err := sched.NextRun(now)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return model.ErrInvalidSchedule
+	}
+	sched.NextRunAt = &next
+	return nil
+}
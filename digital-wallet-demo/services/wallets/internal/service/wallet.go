@@ -4,53 +4,176 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
+
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
 	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"gorm.io/gorm"
 )
 
 // Wallet is the service for the wallet endpoint.
+// Its database queries and calls to Redis and the transaction service are made
+// with ctx, except for the cache invalidations after a commit, which are made
+// even if ctx has been cancelled in the meantime.
 type Wallet interface {
-	Create(wallet *model.Wallet) error
-	Deposit(userID string, amount int, providerID *string) (*model.Transaction, error)
-	Withdraw(userID string, amount int, providerID *string) (*model.Transaction, error)
-	Transfer(fromUserID string, toUserID string, amount int) (*model.Transaction, error)
-	GetWalletWithTransactions(userID string) (*model.Wallet, []model.Transaction, error)
+	Create(ctx context.Context, wallet *model.Wallet, audit model.AuditContext) error
+	Deposit(ctx context.Context, params DepositParams) (*model.Transaction, error)
+	Withdraw(ctx context.Context, params WithdrawParams) (*model.Transaction, error)
+	Transfer(ctx context.Context, params TransferParams) (*model.Transaction, error)
+	GetWalletWithTransactions(ctx context.Context, userID string, query model.TransactionQuery) (*model.Wallet, *model.TransactionPage, error)
+	UpdateStatus(ctx context.Context, params UpdateStatusParams) (*model.Wallet, error)
+	Reverse(ctx context.Context, params ReverseParams) (*model.Transaction, error)
+	CreateHold(ctx context.Context, params HoldParams) (*model.Hold, error)
+	GetHold(ctx context.Context, id string) (*model.Hold, error)
+	CaptureHold(ctx context.Context, params CaptureParams) (*model.Transaction, error)
+	VoidHold(ctx context.Context, id string, audit model.AuditContext) (*model.Hold, error)
+}
+
+// DepositParams are the parameters of a deposit.
+type DepositParams struct {
+	UserID     string
+	Amount     int64          // Amount in minor units of Currency
+	Currency   model.Currency // Defaults to the wallet's base currency
+	ProviderID *string
+	Reference  string             // Client supplied reference recorded on both transactions
+	Audit      model.AuditContext // Who made the change, recorded in the audit log
+}
+
+// WithdrawParams are the parameters of a withdrawal.
+type WithdrawParams struct {
+	UserID     string
+	Amount     int64          // Amount in minor units of Currency
+	Currency   model.Currency // Defaults to the wallet's base currency
+	ProviderID *string
+	Reference  string             // Client supplied reference recorded on both transactions
+	Audit      model.AuditContext // Who made the change, recorded in the audit log
+}
+
+// TransferParams are the parameters of a transfer.
+type TransferParams struct {
+	FromUserID string
+	ToUserID   string
+	Amount     int64              // Amount in minor units of Currency
+	Currency   model.Currency     // Defaults to the sender's base currency
+	ToCurrency model.Currency     // Currency credited to the receiver, defaults to Currency
+	QuoteID    string             // Optional quote locking the rate of a conversion
+	Reference  string             // Client supplied reference recorded on both transactions
+	Audit      model.AuditContext // Who made the change, recorded in the audit log
+}
+
+// UpdateStatusParams are the parameters of a wallet status change.
+type UpdateStatusParams struct {
+	UserID        string
+	Status        model.Status
+	Reason        string
+	SweepToUserID string             // Wallet receiving the remaining funds of a wallet being closed
+	Audit         model.AuditContext // Who made the change, recorded in the audit log
+}
+
+// ReverseParams are the parameters of a reversal.
+type ReverseParams struct {
+	TransactionID string
+	Amount        int64              // Amount in minor units of the transaction's currency, defaults to the full amount
+	Audit         model.AuditContext // Who made the change, recorded in the audit log
 }
 
 type wallet struct {
-	walletRepository repository.Wallet
+	walletRepository   repository.Wallet
+	outboxRepository   repository.Outbox
+	fxQuoteRepository  repository.FXQuote
+	reversalRepository repository.Reversal
+	holdRepository     repository.Hold
+	limitRepository    repository.Limit
+	reviewRepository   repository.Review
+	auditRepository    repository.Audit
+	redisClient        cache.RedisClient
+	txnClient          client.NewTransaction
+	riskEngine         RiskEngine
+	rates              FXRateProvider
+	holdTTL            time.Duration
+}
+
+// WalletServiceOpts is the options for the Wallet service
+type WalletServiceOpts struct {
+	WalletRepository   repository.Wallet
+	OutboxRepository   repository.Outbox
+	FXQuoteRepository  repository.FXQuote
+	ReversalRepository repository.Reversal
+	HoldRepository     repository.Hold
+	LimitRepository    repository.Limit  // Spending limits outgoing transactions are checked against
+	ReviewRepository   repository.Review // Queue of transfers and withdrawals held for review
+	AuditRepository    repository.Audit  // Log every change of a wallet is recorded in
+	// Transaction histories are read from the transaction service through
+	// TxnClient and cached in RedisClient
+	RedisClient cache.RedisClient
+	TxnClient   client.NewTransaction
+	RiskEngine  RiskEngine     // Screens transfers and withdrawals
+	Rates       FXRateProvider // Rates transfers between currencies are converted at
+	HoldTTL     time.Duration  // Holds expire after HoldTTL unless they are captured or voided
 }
 
 // NewWalletService creates a new Wallet service.
-func NewWalletService(wr repository.Wallet) Wallet {
+// Transfers and withdrawals are queued for review if the risk engine or a flag
+// on the sender's wallet asks for it.
+func NewWalletService(opts WalletServiceOpts) Wallet {
 	return &wallet{
-		walletRepository: wr,
+		walletRepository:   opts.WalletRepository,
+		outboxRepository:   opts.OutboxRepository,
+		fxQuoteRepository:  opts.FXQuoteRepository,
+		reversalRepository: opts.ReversalRepository,
+		holdRepository:     opts.HoldRepository,
+		limitRepository:    opts.LimitRepository,
+		reviewRepository:   opts.ReviewRepository,
+		auditRepository:    opts.AuditRepository,
+		redisClient:        opts.RedisClient,
+		txnClient:          opts.TxnClient,
+		riskEngine:         opts.RiskEngine,
+		rates:              opts.Rates,
+		holdTTL:            opts.HoldTTL,
 	}
 }
 
-func (t *wallet) Create(wallet *model.Wallet) error {
-	err := t.walletRepository.Create(wallet)
+func (t *wallet) Create(ctx context.Context, wallet *model.Wallet, audit model.AuditContext) error {
+	err := inAuditedTransaction(ctx, t.walletRepository, t.auditRepository, func(tx *gorm.DB) (*model.AuditEntry, error) {
+		if err := t.walletRepository.Create(tx, wallet); err != nil {
+			return nil, err
+		}
+		return model.NewAuditEntry(audit, model.AuditWalletCreated, wallet.UserID, map[string]any{
+			"id":        wallet.PublicID,
+			"acnt_type": wallet.AcntType,
+			"currency":  wallet.Currency,
+		})
+	})
 	if err != nil {
-		utils.LogError("Failed to create wallet", err)
+		utils.LogErrorContext(ctx, "Failed to create wallet", err)
 		return err
 	}
 	return nil
 }
 
-func (t *wallet) Deposit(userID string, amount int, providerID *string) (*model.Transaction, error) {
+func (t *wallet) Deposit(ctx context.Context, params DepositParams) (*model.Transaction, error) {
 	// Validate amount
-	if amount <= 0 {
+	if params.Amount <= 0 {
 		return nil, errors.New("invalid amount")
 	}
-	amountCents := int64(amount)
+	amountCents := params.Amount
+	providerID := params.ProviderID
 
 	// FetchTransactions user wallet
-	userWallet, err := t.walletRepository.FindByUserID(userID)
+	userWallet, err := t.walletRepository.FindByUserID(ctx, params.UserID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "User wallet not found for deposit", err)
+		return nil, err
+	}
+	if err := userWallet.CheckTransactable(); err != nil {
+		return nil, err
+	}
+
+	currency, err := resolveCurrency(params.Currency, userWallet.Currency)
 	if err != nil {
-		utils.LogError("User wallet not found for deposit", err)
 		return nil, err
 	}
 
@@ -61,14 +184,17 @@ func (t *wallet) Deposit(userID string, amount int, providerID *string) (*model.
 	}
 
 	// FetchTransactions or get provider wallet
-	providerWallet, err := t.walletRepository.FindProviderWallet(*providerID)
+	providerWallet, err := t.walletRepository.FindProviderWallet(ctx, *providerID)
 	if err != nil {
-		utils.LogError("Provider wallet not found for deposit", err)
+		utils.LogErrorContext(ctx, "Provider wallet not found for deposit", err)
 		return nil, errors.New("deposit provider wallet not found")
 	}
+	if err := providerWallet.CheckTransactable(); err != nil {
+		return nil, err
+	}
 
 	// Begin database transaction
-	tx := t.walletRepository.BeginTransaction()
+	tx := t.walletRepository.BeginTransaction(ctx)
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -79,6 +205,12 @@ func (t *wallet) Deposit(userID string, amount int, providerID *string) (*model.
 		return nil, err
 	}
 
+	// Check the wallets again under lock, their status may have changed since they were read
+	if _, err := lockTransactable(tx, t.walletRepository, providerWallet.ID, userWallet.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Create debit transaction for provider
 	debitTxn := &model.Transaction{
 		SubjectWalletID: providerWallet.UserID,
@@ -86,6 +218,8 @@ func (t *wallet) Deposit(userID string, amount int, providerID *string) (*model.
 		TransactionType: model.Deposit,
 		OperationType:   model.Debit,
 		Amount:          amountCents,
+		Currency:        currency,
+		Reference:       params.Reference,
 		Status:          model.Completed,
 	}
 
@@ -96,65 +230,75 @@ func (t *wallet) Deposit(userID string, amount int, providerID *string) (*model.
 		TransactionType: model.Deposit,
 		OperationType:   model.Credit,
 		Amount:          amountCents,
+		Currency:        currency,
+		Reference:       params.Reference,
 		Status:          model.Completed,
 	}
 
-	// Create transaction pair via microservice asynchronously
-	go func() {
-		if err := client.NewTxnClient().CreateTransactionPair(debitTxn, creditTxn); err != nil {
-			utils.LogError("Failed to create transaction pair for deposit", err)
-		}
-	}()
-
 	// Update wallet balances
-	if err := t.walletRepository.UpdateWalletBalance(tx, providerWallet.ID, amountCents, false); err != nil {
-		utils.LogError("Failed to update provider wallet balance for deposit", err)
+	if err := updateBalance(tx, t.walletRepository, t.auditRepository, params.Audit, "deposit", providerWallet, currency, amountCents, false); err != nil {
+		utils.LogErrorContext(ctx, "Failed to update provider wallet balance for deposit", err)
 		tx.Rollback()
 		return nil, err
 	}
 
-	if err := t.walletRepository.UpdateWalletBalance(tx, userWallet.ID, amountCents, true); err != nil {
-		utils.LogError("Failed to update user wallet balance for deposit", err)
+	if err := updateBalance(tx, t.walletRepository, t.auditRepository, params.Audit, "deposit", userWallet, currency, amountCents, true); err != nil {
+		utils.LogErrorContext(ctx, "Failed to update user wallet balance for deposit", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Record the ledger entries in the outbox as part of the same transaction
+	if err := t.enqueueTransactionPair(tx, debitTxn, creditTxn, params.Audit); err != nil {
+		utils.LogErrorContext(ctx, "Failed to enqueue transaction pair for deposit", err)
 		tx.Rollback()
 		return nil, err
 	}
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
-		utils.LogError("Failed to commit deposit transaction", err)
+		utils.LogErrorContext(ctx, "Failed to commit deposit transaction", err)
 		return nil, err
 	}
 
 	// Invalidate cache for both user and provider
-	ctx := context.Background()
-	redisClient := cache.NewRedisClient()
-	if err := redisClient.DeleteTransactionHistory(ctx, userWallet.UserID); err != nil {
-		utils.LogError("Failed to invalidate user cache after deposit", err)
+	cacheCtx := context.WithoutCancel(ctx)
+	if err := t.redisClient.DeleteTransactionHistory(cacheCtx, userWallet.UserID); err != nil {
+		utils.LogErrorContext(ctx, "Failed to invalidate user cache after deposit", err)
 	}
-	if err := redisClient.DeleteTransactionHistory(ctx, providerWallet.UserID); err != nil {
-		utils.LogError("Failed to invalidate provider cache after deposit", err)
+	if err := t.redisClient.DeleteTransactionHistory(cacheCtx, providerWallet.UserID); err != nil {
+		utils.LogErrorContext(ctx, "Failed to invalidate provider cache after deposit", err)
 	}
 
 	// Return the credit transaction for the user
 	return creditTxn, nil
 }
 
-func (t *wallet) Withdraw(userID string, amount int, providerID *string) (*model.Transaction, error) {
+func (t *wallet) Withdraw(ctx context.Context, params WithdrawParams) (*model.Transaction, error) {
 	// Validate amount
-	if amount <= 0 {
+	if params.Amount <= 0 {
 		return nil, errors.New("invalid amount")
 	}
-	amountCents := int64(amount)
+	amountCents := params.Amount
+	providerID := params.ProviderID
 
 	// FetchTransactions user wallet
-	userWallet, err := t.walletRepository.FindByUserID(userID)
+	userWallet, err := t.walletRepository.FindByUserID(ctx, params.UserID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "User wallet not found for withdraw", err)
+		return nil, err
+	}
+	if err := userWallet.CheckTransactable(); err != nil {
+		return nil, err
+	}
+
+	currency, err := resolveCurrency(params.Currency, userWallet.Currency)
 	if err != nil {
-		utils.LogError("User wallet not found for withdraw", err)
 		return nil, err
 	}
 
 	// Check balance
-	if userWallet.Balance < amountCents {
+	if userWallet.BalanceIn(currency) < amountCents {
 		return nil, model.ErrInsufficientFunds
 	}
 
@@ -165,14 +309,17 @@ func (t *wallet) Withdraw(userID string, amount int, providerID *string) (*model
 	}
 
 	// FetchTransactions or get provider wallet
-	providerWallet, err := t.walletRepository.FindProviderWallet(*providerID)
+	providerWallet, err := t.walletRepository.FindProviderWallet(ctx, *providerID)
 	if err != nil {
-		utils.LogError("Provider wallet not found for withdraw", err)
+		utils.LogErrorContext(ctx, "Provider wallet not found for withdraw", err)
 		return nil, errors.New("withdraw provider wallet not found")
 	}
+	if err := providerWallet.CheckTransactable(); err != nil {
+		return nil, err
+	}
 
 	// Begin database transaction
-	tx := t.walletRepository.BeginTransaction()
+	tx := t.walletRepository.BeginTransaction(ctx)
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -183,6 +330,40 @@ func (t *wallet) Withdraw(userID string, amount int, providerID *string) (*model
 		return nil, err
 	}
 
+	// Check the wallets again under lock, their status may have changed since they were read
+	locked, err := lockTransactable(tx, t.walletRepository, userWallet.ID, providerWallet.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Funds reserved by holds cannot be withdrawn
+	if err := t.checkAvailable(tx, locked[userWallet.ID], currency, amountCents); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Check the withdrawal against the spending limit of the wallet
+	spentOn, err := t.applyLimits(tx, locked[userWallet.ID], currency, amountCents)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Screen the withdrawal with the risk rules
+	decision, err := t.screen(ctx, tx, model.RiskCheck{
+		TransactionType: model.Withdraw,
+		FromUserID:      userWallet.UserID,
+		ToUserID:        providerWallet.UserID,
+		Amount:          amountCents,
+		Currency:        currency,
+		WalletCreatedAt: userWallet.CreatedAt,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Create debit transaction for user
 	debitTxn := &model.Transaction{
 		SubjectWalletID: userWallet.UserID,
@@ -190,6 +371,8 @@ func (t *wallet) Withdraw(userID string, amount int, providerID *string) (*model
 		TransactionType: model.Withdraw,
 		OperationType:   model.Debit,
 		Amount:          amountCents,
+		Currency:        currency,
+		Reference:       params.Reference,
 		Status:          model.Completed,
 	}
 
@@ -200,77 +383,109 @@ func (t *wallet) Withdraw(userID string, amount int, providerID *string) (*model
 		TransactionType: model.Withdraw,
 		OperationType:   model.Credit,
 		Amount:          amountCents,
+		Currency:        currency,
+		Reference:       params.Reference,
 		Status:          model.Completed,
 	}
 
-	// Create transaction pair via microservice asynchronously
-	go func() {
-		if err := client.NewTxnClient().CreateTransactionPair(debitTxn, creditTxn); err != nil {
-			utils.LogError("Failed to create transaction pair for withdraw", err)
+	// A withdrawal queued for review is recorded as pending, and its funds are reserved until it is decided
+	queued, err := t.queueForReview(tx, decision, debitTxn, creditTxn)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to queue withdrawal for review", err)
+		tx.Rollback()
+		return nil, err
+	}
+	if queued == nil {
+		// Update wallet balances
+		if err := updateBalance(tx, t.walletRepository, t.auditRepository, params.Audit, "withdrawal", userWallet, currency, amountCents, false); err != nil {
+			utils.LogErrorContext(ctx, "Failed to update user wallet balance for withdraw", err)
+			tx.Rollback()
+			return nil, err
 		}
-	}()
 
-	// Update wallet balances
-	if err := t.walletRepository.UpdateWalletBalance(tx, userWallet.ID, amountCents, false); err != nil {
-		utils.LogError("Failed to update user wallet balance for withdraw", err)
+		if err := updateBalance(tx, t.walletRepository, t.auditRepository, params.Audit, "withdrawal", providerWallet, currency, amountCents, true); err != nil {
+			utils.LogErrorContext(ctx, "Failed to update provider wallet balance for withdraw", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Record the ledger entries in the outbox as part of the same transaction
+	if err := t.enqueueTransactionPair(tx, debitTxn, creditTxn, params.Audit); err != nil {
+		utils.LogErrorContext(ctx, "Failed to enqueue transaction pair for withdraw", err)
 		tx.Rollback()
 		return nil, err
 	}
 
-	if err := t.walletRepository.UpdateWalletBalance(tx, providerWallet.ID, amountCents, true); err != nil {
-		utils.LogError("Failed to update provider wallet balance for withdraw", err)
+	// Record the day the withdrawal counted as spent, so that a reversal releases it from that day
+	if err := recordSpending(tx, t.limitRepository, debitTxn, spentOn); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
-		utils.LogError("Failed to commit withdraw transaction", err)
+		utils.LogErrorContext(ctx, "Failed to commit withdraw transaction", err)
 		return nil, err
 	}
 
 	// Invalidate cache for both user and provider
-	ctx := context.Background()
-	redisClient := cache.NewRedisClient()
-	if err := redisClient.DeleteTransactionHistory(ctx, userWallet.UserID); err != nil {
-		utils.LogError("Failed to invalidate user cache after withdraw", err)
+	cacheCtx := context.WithoutCancel(ctx)
+	if err := t.redisClient.DeleteTransactionHistory(cacheCtx, userWallet.UserID); err != nil {
+		utils.LogErrorContext(ctx, "Failed to invalidate user cache after withdraw", err)
 	}
-	if err := redisClient.DeleteTransactionHistory(ctx, providerWallet.UserID); err != nil {
-		utils.LogError("Failed to invalidate provider cache after withdraw", err)
+	if err := t.redisClient.DeleteTransactionHistory(cacheCtx, providerWallet.UserID); err != nil {
+		utils.LogErrorContext(ctx, "Failed to invalidate provider cache after withdraw", err)
 	}
 
 	// Return the debit transaction for the user
 	return debitTxn, nil
 }
 
-func (t *wallet) Transfer(fromUserID string, toUserID string, amount int) (*model.Transaction, error) {
+func (t *wallet) Transfer(ctx context.Context, params TransferParams) (*model.Transaction, error) {
 	// Validate amount
-	if amount <= 0 {
+	if params.Amount <= 0 {
 		return nil, errors.New("invalid amount")
 	}
-	amountCents := int64(amount)
+	amountCents := params.Amount
 
 	// FetchTransactions sender wallet to check balance
-	fromWallet, err := t.walletRepository.FindByUserID(fromUserID)
+	fromWallet, err := t.walletRepository.FindByUserID(ctx, params.FromUserID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Sender wallet not found for transfer", err)
+		return nil, err
+	}
+	if err := fromWallet.CheckTransactable(); err != nil {
+		return nil, err
+	}
+
+	currency, err := resolveCurrency(params.Currency, fromWallet.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	toCurrency, err := resolveCurrency(params.ToCurrency, currency)
 	if err != nil {
-		utils.LogError("Sender wallet not found for transfer", err)
 		return nil, err
 	}
 
 	// Check balance
-	if fromWallet.Balance < amountCents {
+	if fromWallet.BalanceIn(currency) < amountCents {
 		return nil, model.ErrInsufficientFunds
 	}
 
 	// FetchTransactions receiver wallet
-	toWallet, err := t.walletRepository.FindByUserID(toUserID)
+	toWallet, err := t.walletRepository.FindByUserID(ctx, params.ToUserID)
 	if err != nil {
-		utils.LogError("Receiver wallet not found for transfer", err)
+		utils.LogErrorContext(ctx, "Receiver wallet not found for transfer", err)
+		return nil, err
+	}
+	if err := toWallet.CheckTransactable(); err != nil {
 		return nil, err
 	}
 
 	// Begin database transaction
-	tx := t.walletRepository.BeginTransaction()
+	tx := t.walletRepository.BeginTransaction(ctx)
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -281,6 +496,51 @@ func (t *wallet) Transfer(fromUserID string, toUserID string, amount int) (*mode
 		return nil, err
 	}
 
+	// Check the wallets again under lock, their status may have changed since they were read
+	locked, err := lockTransactable(tx, t.walletRepository, fromWallet.ID, toWallet.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Funds reserved by holds cannot be transferred
+	if err := t.checkAvailable(tx, locked[fromWallet.ID], currency, amountCents); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Check the transfer against the spending limit of the sender
+	spentOn, err := t.applyLimits(tx, locked[fromWallet.ID], currency, amountCents)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Screen the transfer with the risk rules
+	decision, err := t.screen(ctx, tx, model.RiskCheck{
+		TransactionType: model.Transfer,
+		FromUserID:      fromWallet.UserID,
+		ToUserID:        toWallet.UserID,
+		Amount:          amountCents,
+		Currency:        currency,
+		WalletCreatedAt: fromWallet.CreatedAt,
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Convert the amount credited to the receiver
+	quote, err := t.transferQuote(tx, params.QuoteID, currency, toCurrency, amountCents)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	creditAmount := amountCents
+	if quote != nil {
+		creditAmount = quote.CounterAmount
+	}
+
 	// Create debit transaction for sender
 	debitTxn := &model.Transaction{
 		SubjectWalletID: fromWallet.UserID,
@@ -288,6 +548,8 @@ func (t *wallet) Transfer(fromUserID string, toUserID string, amount int) (*mode
 		TransactionType: model.Transfer,
 		OperationType:   model.Debit,
 		Amount:          amountCents,
+		Currency:        currency,
+		Reference:       params.Reference,
 		Status:          model.Completed,
 	}
 
@@ -297,82 +559,592 @@ func (t *wallet) Transfer(fromUserID string, toUserID string, amount int) (*mode
 		ObjectWalletID:  fromWallet.UserID,
 		TransactionType: model.Transfer,
 		OperationType:   model.Credit,
-		Amount:          amountCents,
+		Amount:          creditAmount,
+		Currency:        toCurrency,
+		Reference:       params.Reference,
 		Status:          model.Completed,
 	}
 
-	// Create transaction pair via microservice asynchronously
-	go func() {
-		if err := client.NewTxnClient().CreateTransactionPair(debitTxn, creditTxn); err != nil {
-			utils.LogError("Failed to create transaction pair for transfer", err)
+	// Record the applied rate and the other side's amount on both transactions
+	if quote != nil {
+		debitTxn.FXRate, debitTxn.CounterAmount, debitTxn.CounterCurrency = quote.Rate, creditAmount, toCurrency
+		creditTxn.FXRate, creditTxn.CounterAmount, creditTxn.CounterCurrency = quote.Rate, amountCents, currency
+	}
+
+	// A transfer queued for review is recorded as pending, and its funds are reserved until it is decided
+	queued, err := t.queueForReview(tx, decision, debitTxn, creditTxn)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to queue transfer for review", err)
+		tx.Rollback()
+		return nil, err
+	}
+	if queued == nil {
+		// Update wallet balances
+		if err := updateBalance(tx, t.walletRepository, t.auditRepository, params.Audit, "transfer", fromWallet, currency, amountCents, false); err != nil {
+			utils.LogErrorContext(ctx, "Failed to update sender wallet balance for transfer", err)
+			tx.Rollback()
+			return nil, err
 		}
-	}()
 
-	// Update wallet balances
-	if err := t.walletRepository.UpdateWalletBalance(tx, fromWallet.ID, amountCents, false); err != nil {
-		utils.LogError("Failed to update sender wallet balance for transfer", err)
+		if err := updateBalance(tx, t.walletRepository, t.auditRepository, params.Audit, "transfer", toWallet, toCurrency, creditAmount, true); err != nil {
+			utils.LogErrorContext(ctx, "Failed to update receiver wallet balance for transfer", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Record the ledger entries in the outbox as part of the same transaction
+	if err := t.enqueueTransactionPair(tx, debitTxn, creditTxn, params.Audit); err != nil {
+		utils.LogErrorContext(ctx, "Failed to enqueue transaction pair for transfer", err)
 		tx.Rollback()
 		return nil, err
 	}
 
-	if err := t.walletRepository.UpdateWalletBalance(tx, toWallet.ID, amountCents, true); err != nil {
-		utils.LogError("Failed to update receiver wallet balance for transfer", err)
+	// Record the day the transfer counted as spent, so that a reversal releases it from that day
+	if err := recordSpending(tx, t.limitRepository, debitTxn, spentOn); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
-		utils.LogError("Failed to commit transfer transaction", err)
+		utils.LogErrorContext(ctx, "Failed to commit transfer transaction", err)
 		return nil, err
 	}
 
 	// Invalidate cache for both sender and receiver
-	ctx := context.Background()
-	redisClient := cache.NewRedisClient()
-	if err := redisClient.DeleteTransactionHistory(ctx, fromWallet.UserID); err != nil {
-		utils.LogError("Failed to invalidate sender cache after transfer", err)
+	cacheCtx := context.WithoutCancel(ctx)
+	if err := t.redisClient.DeleteTransactionHistory(cacheCtx, fromWallet.UserID); err != nil {
+		utils.LogErrorContext(ctx, "Failed to invalidate sender cache after transfer", err)
 	}
-	if err := redisClient.DeleteTransactionHistory(ctx, toWallet.UserID); err != nil {
-		utils.LogError("Failed to invalidate receiver cache after transfer", err)
+	if err := t.redisClient.DeleteTransactionHistory(cacheCtx, toWallet.UserID); err != nil {
+		utils.LogErrorContext(ctx, "Failed to invalidate receiver cache after transfer", err)
 	}
 
 	// Return the debit transaction for the sender
 	return debitTxn, nil
 }
 
-func (t *wallet) GetWalletWithTransactions(userID string) (*model.Wallet, []model.Transaction, error) {
+// GetWalletWithTransactions returns a wallet with a page of its transaction history.
+// While the transaction service is unavailable, the wallet is returned with an
+// empty page marked Unavailable.
+func (t *wallet) GetWalletWithTransactions(ctx context.Context, userID string, query model.TransactionQuery) (*model.Wallet, *model.TransactionPage, error) {
 	// Get wallet
-	wallet, err := t.walletRepository.FindByUserID(userID)
+	wallet, err := t.walletRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Wallet not found", err)
+		return nil, nil, err
+	}
+
+	// Load the reserved amounts, so that the available balance can be reported
+	wallet.Reserved, err = t.reserved(ctx, wallet.UserID)
 	if err != nil {
-		utils.LogError("Wallet not found", err)
 		return nil, nil, err
 	}
 
-	ctx := context.Background()
-	redisClient := cache.NewRedisClient()
+	// Only the first unfiltered page is cached, other pages go to the transaction service
+	if !query.IsDefault() {
+		page, err := t.txnClient.FetchTransactions(ctx, wallet.UserID, query)
+		if errors.Is(err, model.ErrTransactionsUnavailable) {
+			utils.LogErrorContext(ctx, "Transaction service unavailable, returning wallet without transactions", err)
+			return wallet, unavailablePage(), nil
+		}
+		if err != nil {
+			utils.LogErrorContext(ctx, "Failed to retrieve transactions from transaction service", err)
+			return nil, nil, err
+		}
+		return wallet, page, nil
+	}
 
 	// Try to get transactions from Redis cache first
-	transactions, err := redisClient.GetTransactionHistory(ctx, wallet.UserID)
+	page, err := t.redisClient.GetTransactionHistory(ctx, wallet.UserID)
 	if err != nil {
-		utils.LogError("Failed to get transactions from cache", err)
+		utils.LogErrorContext(ctx, "Failed to get transactions from cache", err)
 		// Continue to fetch from transaction service
 	}
 
 	// If cache miss or error, fetch from transaction microservice
-	if transactions == nil {
-		transactions, err = client.NewTxnClient().FetchTransactions(wallet.UserID)
+	if page == nil {
+		page, err = t.txnClient.FetchTransactions(ctx, wallet.UserID, query)
+		if errors.Is(err, model.ErrTransactionsUnavailable) {
+			utils.LogErrorContext(ctx, "Transaction service unavailable, returning wallet without transactions", err)
+			return wallet, unavailablePage(), nil
+		}
 		if err != nil {
-			utils.LogError("Failed to retrieve transactions from transaction service", err)
+			utils.LogErrorContext(ctx, "Failed to retrieve transactions from transaction service", err)
 			return nil, nil, err
 		}
 
 		// Save to cache for future requests
-		if err := redisClient.SaveTransactionHistory(ctx, wallet.UserID, transactions); err != nil {
-			utils.LogError("Failed to save transactions to cache", err)
+		if err := t.redisClient.SaveTransactionHistory(ctx, wallet.UserID, page); err != nil {
+			utils.LogErrorContext(ctx, "Failed to save transactions to cache", err)
 			// Continue without caching - not a critical error
 		}
 	}
 
-	return wallet, transactions, nil
+	return wallet, page, nil
+}
+
+// unavailablePage returns the empty page of transactions returned while the
+// transaction service is unavailable, so that the balance can still be read
+func unavailablePage() *model.TransactionPage {
+	return &model.TransactionPage{Transactions: []model.Transaction{}, Unavailable: true}
+}
+
+func (t *wallet) UpdateStatus(ctx context.Context, params UpdateStatusParams) (*model.Wallet, error) {
+	w, err := t.walletRepository.FindByUserID(ctx, params.UserID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Wallet not found for status update", err)
+		return nil, err
+	}
+
+	// Provider wallets back every deposit and withdrawal and are never closed
+	if params.Status == model.Closed && w.AcntType == model.Provider {
+		return nil, model.ErrInvalidStatusTransition
+	}
+
+	// Funds are only swept when a wallet is closed
+	walletIDs := []int{w.ID}
+	var sweepTo *model.Wallet
+	if params.SweepToUserID != "" {
+		if params.Status != model.Closed || params.SweepToUserID == w.UserID {
+			return nil, model.ErrInvalidSweepTarget
+		}
+		sweepTo, err = t.walletRepository.FindByUserID(ctx, params.SweepToUserID)
+		if err != nil {
+			utils.LogErrorContext(ctx, "Sweep wallet not found for wallet closure", err)
+			return nil, err
+		}
+		walletIDs = append(walletIDs, sweepTo.ID)
+	}
+
+	// Begin database transaction
+	tx := t.walletRepository.BeginTransaction(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// Lock the wallets, so that no funds move while the status changes
+	locked, err := t.walletRepository.LockWallets(tx, walletIDs...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for i := range locked {
+		if locked[i].ID == w.ID {
+			w = &locked[i]
+		} else {
+			sweepTo = &locked[i]
+		}
+	}
+
+	if !w.Status.CanTransitionTo(params.Status) {
+		tx.Rollback()
+		return nil, model.ErrInvalidStatusTransition
+	}
+
+	if params.Status == model.Closed {
+		if err := t.sweepBalances(tx, w, sweepTo, params.Audit); err != nil {
+			utils.LogErrorContext(ctx, "Failed to sweep balances of closing wallet", err)
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := t.walletRepository.UpdateStatus(tx, w.ID, params.Status, params.Reason, time.Now()); err != nil {
+		utils.LogErrorContext(ctx, "Failed to update wallet status", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Record the status change in the audit log as part of the same transaction
+	details := map[string]any{"from": w.Status, "to": params.Status, "reason": params.Reason}
+	if sweepTo != nil {
+		details["sweep_to_user_id"] = sweepTo.UserID
+	}
+	entry, err := model.NewAuditEntry(params.Audit, model.AuditStatusChanged, w.UserID, details)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := t.auditRepository.Append(tx, entry); err != nil {
+		utils.LogErrorContext(ctx, "Failed to append status change to audit log", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		utils.LogErrorContext(ctx, "Failed to commit wallet status update", err)
+		return nil, err
+	}
+
+	// Invalidate cache for both wallets if funds were swept
+	if sweepTo != nil {
+		cacheCtx := context.WithoutCancel(ctx)
+		if err := t.redisClient.DeleteTransactionHistory(cacheCtx, w.UserID); err != nil {
+			utils.LogErrorContext(ctx, "Failed to invalidate closed wallet cache after sweep", err)
+		}
+		if err := t.redisClient.DeleteTransactionHistory(cacheCtx, sweepTo.UserID); err != nil {
+			utils.LogErrorContext(ctx, "Failed to invalidate sweep wallet cache after sweep", err)
+		}
+	}
+
+	return t.walletRepository.FindByUserID(ctx, params.UserID)
+}
+
+// Reverse moves the amount of a ledger transaction back between its wallets.
+// The compensating transaction pair references the original transaction, and
+// the other side of a conversion is reversed in proportion to the amount.
+// Both transactions of a pair move the same funds, so a pair is reversed once
+// through either of them.
+func (t *wallet) Reverse(ctx context.Context, params ReverseParams) (*model.Transaction, error) {
+	// Fetch the original transaction from the ledger
+	original, err := t.txnClient.FetchTransaction(ctx, params.TransactionID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to retrieve transaction to reverse", err)
+		return nil, err
+	}
+
+	amount := params.Amount
+	if amount == 0 {
+		amount = original.Amount
+	}
+	if amount < 0 {
+		return nil, errors.New("invalid amount")
+	}
+	if err := original.CheckReversible(amount); err != nil {
+		return nil, err
+	}
+	counterAmount, counterCurrency, rate, err := original.ReversalCounterpart(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the wallets of both sides of the original transaction
+	subjectWallet, err := t.walletRepository.FindByUserID(ctx, original.SubjectWalletID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Subject wallet not found for reversal", err)
+		return nil, err
+	}
+	if err := subjectWallet.CheckTransactable(); err != nil {
+		return nil, err
+	}
+	objectWallet, err := t.walletRepository.FindByUserID(ctx, original.ObjectWalletID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Object wallet not found for reversal", err)
+		return nil, err
+	}
+	if err := objectWallet.CheckTransactable(); err != nil {
+		return nil, err
+	}
+
+	// Begin database transaction
+	tx := t.walletRepository.BeginTransaction(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	// Check the wallets again under lock, their status may have changed since they were read
+	locked, err := lockTransactable(tx, t.walletRepository, subjectWallet.ID, objectWallet.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Record the reversal, so that neither transaction of the pair can be reversed again
+	reversal := &model.Reversal{
+		TransactionID: original.ID,
+		Amount:        amount,
+		Currency:      original.Currency,
+	}
+	if original.PairID != "" {
+		reversal.PairID = &original.PairID
+	}
+	reserved, err := t.reversalRepository.Reserve(tx, reversal)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to record reversal", err)
+		tx.Rollback()
+		return nil, err
+	}
+	if !reserved {
+		tx.Rollback()
+		return nil, model.ErrAlreadyReversed
+	}
+
+	// Create the compensating transaction for each side, with the operations swapped
+	subjectTxn := &model.Transaction{
+		SubjectWalletID: subjectWallet.UserID,
+		ObjectWalletID:  objectWallet.UserID,
+		TransactionType: original.TransactionType,
+		OperationType:   model.Credit,
+		Amount:          amount,
+		Currency:        original.Currency,
+		ReversalOf:      &original.ID,
+		Status:          model.Completed,
+	}
+	objectTxn := &model.Transaction{
+		SubjectWalletID: objectWallet.UserID,
+		ObjectWalletID:  subjectWallet.UserID,
+		TransactionType: original.TransactionType,
+		OperationType:   model.Debit,
+		Amount:          counterAmount,
+		Currency:        counterCurrency,
+		ReversalOf:      &original.ID,
+		Status:          model.Completed,
+	}
+	debitTxn, creditTxn := objectTxn, subjectTxn
+	debitWallet := objectWallet
+	if original.OperationType == model.Credit {
+		subjectTxn.OperationType, objectTxn.OperationType = model.Debit, model.Credit
+		debitTxn, creditTxn = subjectTxn, objectTxn
+		debitWallet = subjectWallet
+	}
+
+	// Funds reserved by holds cannot be taken back
+	if err := t.checkAvailable(tx, locked[debitWallet.ID], debitTxn.Currency, debitTxn.Amount); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Record the rate and the other side's amount on both transactions of a conversion
+	if rate != "" {
+		subjectTxn.FXRate, subjectTxn.CounterAmount, subjectTxn.CounterCurrency = rate, counterAmount, counterCurrency
+		objectTxn.FXRate, objectTxn.CounterAmount, objectTxn.CounterCurrency = rate, amount, original.Currency
+	}
+
+	// Update wallet balances
+	if err := updateBalance(tx, t.walletRepository, t.auditRepository, params.Audit, "reversal", subjectWallet, subjectTxn.Currency, subjectTxn.Amount, subjectTxn.OperationType == model.Credit); err != nil {
+		utils.LogErrorContext(ctx, "Failed to update subject wallet balance for reversal", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := updateBalance(tx, t.walletRepository, t.auditRepository, params.Audit, "reversal", objectWallet, objectTxn.Currency, objectTxn.Amount, objectTxn.OperationType == model.Credit); err != nil {
+		utils.LogErrorContext(ctx, "Failed to update object wallet balance for reversal", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// The sender of a withdrawal or transfer is credited back, which it no longer spent
+	if original.TransactionType == model.Withdraw || original.TransactionType == model.Transfer {
+		count := 0
+		if amount == original.Amount {
+			count = 1
+		}
+		if err := releaseLimits(tx, t.limitRepository, creditTxn.SubjectWalletID, creditTxn.Currency, original.PairID, original.CreatedAt, creditTxn.Amount, count); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Record the ledger entries in the outbox as part of the same transaction
+	if err := t.enqueueTransactionPair(tx, debitTxn, creditTxn, params.Audit); err != nil {
+		utils.LogErrorContext(ctx, "Failed to enqueue transaction pair for reversal", err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		utils.LogErrorContext(ctx, "Failed to commit reversal transaction", err)
+		return nil, err
+	}
+
+	// Invalidate cache for both wallets
+	cacheCtx := context.WithoutCancel(ctx)
+	if err := t.redisClient.DeleteTransactionHistory(cacheCtx, subjectWallet.UserID); err != nil {
+		utils.LogErrorContext(ctx, "Failed to invalidate subject cache after reversal", err)
+	}
+	if err := t.redisClient.DeleteTransactionHistory(cacheCtx, objectWallet.UserID); err != nil {
+		utils.LogErrorContext(ctx, "Failed to invalidate object cache after reversal", err)
+	}
+
+	// Return the compensating transaction of the original transaction's wallet
+	return subjectTxn, nil
+}
+
+// sweepBalances moves every balance of a closing wallet to sweepTo within tx.
+// Without a wallet to sweep to, the closing wallet must hold no funds. Funds
+// reserved by active holds or pending reviews would be taken again when the
+// holds are captured or the reviews approved, so none may be reserved.
+func (t *wallet) sweepBalances(tx *gorm.DB, closing, sweepTo *model.Wallet, audit model.AuditContext) error {
+	balances := append([]model.WalletBalance{{Currency: closing.Currency, Balance: closing.Balance}}, closing.Balances...)
+
+	for _, b := range balances {
+		currency, amount := b.Currency, b.Balance
+		if amount == 0 {
+			continue
+		}
+		if sweepTo == nil {
+			return model.ErrWalletNotEmpty
+		}
+		reserved, err := t.reservedIn(tx, closing.UserID, currency)
+		if err != nil {
+			return err
+		}
+		if reserved > 0 {
+			return model.ErrFundsReserved
+		}
+		if err := sweepTo.CheckTransactable(); err != nil {
+			return err
+		}
+
+		debitTxn := &model.Transaction{
+			SubjectWalletID: closing.UserID,
+			ObjectWalletID:  sweepTo.UserID,
+			TransactionType: model.Transfer,
+			OperationType:   model.Debit,
+			Amount:          amount,
+			Currency:        currency,
+			Status:          model.Completed,
+		}
+		creditTxn := &model.Transaction{
+			SubjectWalletID: sweepTo.UserID,
+			ObjectWalletID:  closing.UserID,
+			TransactionType: model.Transfer,
+			OperationType:   model.Credit,
+			Amount:          amount,
+			Currency:        currency,
+			Status:          model.Completed,
+		}
+
+		if err := updateBalance(tx, t.walletRepository, t.auditRepository, audit, "sweep", closing, currency, amount, false); err != nil {
+			return err
+		}
+		if err := updateBalance(tx, t.walletRepository, t.auditRepository, audit, "sweep", sweepTo, currency, amount, true); err != nil {
+			return err
+		}
+		if err := t.enqueueTransactionPair(tx, debitTxn, creditTxn, audit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockTransactable locks the given wallets within tx and checks that each of them can send and receive funds.
+// The locked wallets are returned by ID.
+func lockTransactable(tx *gorm.DB, wr repository.Wallet, walletIDs ...int) (map[int]*model.Wallet, error) {
+	wallets, err := wr.LockWallets(tx, walletIDs...)
+	if err != nil {
+		return nil, err
+	}
+	locked := make(map[int]*model.Wallet, len(wallets))
+	for i := range wallets {
+		if err := wallets[i].CheckTransactable(); err != nil {
+			return nil, err
+		}
+		locked[wallets[i].ID] = &wallets[i]
+	}
+	return locked, nil
+}
+
+// checkAvailable returns ErrInsufficientFunds unless amount of the locked wallet's
+// balance in currency is not reserved by active holds or pending reviews.
+func (t *wallet) checkAvailable(tx *gorm.DB, w *model.Wallet, currency model.Currency, amount int64) error {
+	reserved, err := t.reservedIn(tx, w.UserID, currency)
+	if err != nil {
+		return err
+	}
+	if w.BalanceIn(currency)-reserved < amount {
+		return model.ErrInsufficientFunds
+	}
+	return nil
+}
+
+// reservedIn returns the amount of the wallet's balance in currency reserved
+// by active holds and pending reviews, within tx.
+func (t *wallet) reservedIn(tx *gorm.DB, userID string, currency model.Currency) (int64, error) {
+	held, err := t.holdRepository.SumActiveIn(tx, userID, currency, time.Now())
+	if err != nil {
+		utils.LogErrorContext(tx.Statement.Context, "Failed to sum active holds", err)
+		return 0, err
+	}
+	reviewing, err := t.reviewRepository.SumPendingIn(tx, userID, currency)
+	if err != nil {
+		utils.LogErrorContext(tx.Statement.Context, "Failed to sum pending reviews", err)
+		return 0, err
+	}
+	return held + reviewing, nil
+}
+
+// reserved returns the amounts of the wallet's balances reserved by active
+// holds and pending reviews, per currency. It counts what reservedIn counts
+// for a single currency.
+func (t *wallet) reserved(ctx context.Context, userID string) (map[model.Currency]int64, error) {
+	reserved, err := t.holdRepository.SumActive(ctx, userID, time.Now())
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to sum active holds", err)
+		return nil, err
+	}
+	reviewing, err := t.reviewRepository.SumPending(ctx, userID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "Failed to sum pending reviews", err)
+		return nil, err
+	}
+	for currency, amount := range reviewing {
+		reserved[currency] += amount
+	}
+	return reserved, nil
+}
+
+// resolveCurrency returns the requested currency, or the wallet's base currency if none was requested.
+func resolveCurrency(requested, base model.Currency) (model.Currency, error) {
+	if requested == "" {
+		return base, nil
+	}
+	if !requested.IsSupported() {
+		return "", model.ErrUnsupportedCurrency
+	}
+	return requested, nil
+}
+
+// transferQuote returns the conversion applied to a transfer, or nil if no conversion is needed.
+// A quote ID redeems the stored quote within tx; without one, a cross-currency
+// transfer is converted at the current rate.
+func (t *wallet) transferQuote(tx *gorm.DB, quoteID string, from, to model.Currency, amount int64) (*model.FXQuote, error) {
+	if quoteID == "" {
+		if from == to {
+			return nil, nil
+		}
+		return convert(t.rates, from, to, amount, 0)
+	}
+
+	quote, err := t.fxQuoteRepository.FindForUpdate(tx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := quote.Redeemable(from, to, amount, now); err != nil {
+		return nil, err
+	}
+	if err := t.fxQuoteRepository.MarkUsed(tx, quote.ID, now); err != nil {
+		utils.LogErrorContext(tx.Statement.Context, "Failed to redeem FX quote", err)
+		return nil, err
+	}
+	return quote, nil
+}
+
+// enqueueTransactionPair writes the debit/credit pair to the outbox within tx.
+// The relay worker delivers it to the transaction service once tx is committed,
+// with the ID of the request recorded in audit.
+func (t *wallet) enqueueTransactionPair(tx *gorm.DB, debitTxn, creditTxn *model.Transaction, audit model.AuditContext) error {
+	entry, err := model.NewTransactionPairEntry(debitTxn, creditTxn)
+	if err != nil {
+		return err
+	}
+	entry.RequestID = audit.RequestID
+	return t.outboxRepository.Enqueue(tx, entry)
 }
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedisHook records a span for every command run with the context of a traced
+// request or job, as a child of its span. Commands outside a trace are not recorded.
+type RedisHook struct{}
+
+var _ redis.Hook = RedisHook{}
+
+// BeforeProcess starts the span of a command.
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return startRedisSpan(ctx, cmd.Name(), 1), nil
+}
+
+// AfterProcess ends the span of a command.
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	endRedisSpan(ctx, cmd.Err())
+	return nil
+}
+
+// BeforeProcessPipeline starts the span of a pipeline.
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return startRedisSpan(ctx, "pipeline", len(cmds)), nil
+}
+
+// AfterProcessPipeline ends the span of a pipeline, failed if any of its commands failed.
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmd.Err() != nil && !errors.Is(cmd.Err(), redis.Nil) {
+			err = cmd.Err()
+			break
+		}
+	}
+	endRedisSpan(ctx, err)
+	return nil
+}
+
+type redisSpanKey struct{}
+
+func startRedisSpan(ctx context.Context, operation string, commands int) context.Context {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	ctx, span := Tracer().Start(ctx, "redis."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameRedis,
+			semconv.DBOperationName(operation),
+			attribute.Int("db.operation.batch.size", commands),
+		),
+	)
+	// Marks the span as ours, so that the span of the caller is never ended here
+	return context.WithValue(ctx, redisSpanKey{}, span)
+}
+
+func endRedisSpan(ctx context.Context, err error) {
+	span, ok := ctx.Value(redisSpanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	// A missing key is a cache miss, not a failure
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// HeaderRequestID is the header carrying the ID correlating the logs and spans of a request.
+const HeaderRequestID = echo.HeaderXRequestID
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of ctx, or an empty string if it has none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random request ID.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestIDMiddleware returns a middleware that keeps the X-Request-ID of the
+// caller, or generates one, echoes it on the response and stores it in the
+// request context.
+func RequestIDMiddleware() echo.MiddlewareFunc {
+	return middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		Generator: NewRequestID,
+		RequestIDHandler: func(c echo.Context, id string) {
+			c.SetRequest(c.Request().WithContext(WithRequestID(c.Request().Context(), id)))
+		},
+	})
+}
+
+// InjectHeaders sets the request ID and the trace context of ctx on the
+// headers of an outgoing request.
+func InjectHeaders(ctx context.Context, header http.Header) {
+	if id := RequestID(ctx); id != "" {
+		header.Set(HeaderRequestID, id)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
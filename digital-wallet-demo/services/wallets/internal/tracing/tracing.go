@@ -0,0 +1,77 @@
+// Package tracing propagates request IDs and OpenTelemetry traces through
+// the API, the database, the cache and the calls to the transaction service.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/fardinabir/digital-wallet-demo/services/wallets"
+	defaultServiceName  = "wallets"
+	defaultEndpoint     = "localhost:4318"
+)
+
+func init() {
+	// Trace context is passed on even when spans are not exported
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Tracer returns the tracer of the service.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Init installs the tracer provider exporting the spans described by cfg and
+// returns a function flushing and stopping it. Without tracing, spans are not
+// recorded and the returned function does nothing.
+func Init(ctx context.Context, cfg model.Tracing) (func(context.Context) error, error) {
+	if !cfg.Enable {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "otlp":
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = defaultEndpoint
+		}
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case "stdout", "":
+		exporter, err = stdouttrace.New()
+	default:
+		err = fmt.Errorf("unsupported exporter %q, expected stdout or otlp", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create span exporter: %w", err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a tracer provider recording the ended spans for the duration of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := recordSpans(t)
+
+	e := echo.New()
+	e.Use(RequestIDMiddleware(), Middleware())
+	var handlerCtx context.Context
+	e.GET("/api/v1/wallets/:user_id", func(c echo.Context) error {
+		handlerCtx = c.Request().Context()
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable)
+	})
+
+	t.Run("continues_the_caller", func(t *testing.T) {
+		recorder.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/test-user-001", nil)
+		req.Header.Set(HeaderRequestID, "req-1")
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "req-1", rec.Header().Get(HeaderRequestID))
+		assert.Equal(t, "req-1", RequestID(handlerCtx))
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "GET /api/v1/wallets/:user_id", spans[0].Name())
+		assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+		assert.Equal(t, spans[0].SpanContext(), trace.SpanContextFromContext(handlerCtx))
+	})
+
+	t.Run("starts_a_trace", func(t *testing.T) {
+		recorder.Reset()
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/wallets/test-user-001", nil))
+
+		assert.Len(t, rec.Header().Get(HeaderRequestID), 32, "generated request ID")
+		assert.Equal(t, rec.Header().Get(HeaderRequestID), RequestID(handlerCtx))
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.False(t, spans[0].Parent().IsValid())
+	})
+
+	t.Run("server_error", func(t *testing.T) {
+		recorder.Reset()
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+	})
+}
+
+func TestInjectHeaders(t *testing.T) {
+	recordSpans(t)
+	ctx, span := Tracer().Start(WithRequestID(context.Background(), "req-1"), "caller")
+	defer span.End()
+
+	header := http.Header{}
+	InjectHeaders(ctx, header)
+
+	assert.Equal(t, "req-1", header.Get(HeaderRequestID))
+	assert.Contains(t, header.Get("traceparent"), span.SpanContext().TraceID().String())
+}
+
+func TestRedisHook(t *testing.T) {
+	recorder := recordSpans(t)
+	hook := RedisHook{}
+
+	// Commands outside a trace are not recorded
+	ctx, err := hook.BeforeProcess(context.Background(), redis.NewStringCmd(context.Background(), "get", "key"))
+	require.NoError(t, err)
+	require.NoError(t, hook.AfterProcess(ctx, redis.NewStringCmd(ctx, "get", "key")))
+	assert.Empty(t, recorder.Ended())
+
+	parent, span := Tracer().Start(context.Background(), "request")
+	miss := redis.NewStringCmd(parent, "get", "key")
+	miss.SetErr(redis.Nil)
+	failed := redis.NewStatusCmd(parent, "set", "key", "value")
+	failed.SetErr(errors.New("connection refused"))
+	for _, cmd := range []redis.Cmder{miss, failed} {
+		ctx, err := hook.BeforeProcess(parent, cmd)
+		require.NoError(t, err)
+		require.NoError(t, hook.AfterProcess(ctx, cmd))
+	}
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "redis.get", spans[0].Name())
+	assert.Equal(t, span.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code, "a cache miss is not an error")
+	assert.Equal(t, "redis.set", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestContextHook(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&log.JSONFormatter{})
+	logger.AddHook(contextHook{})
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa},
+	})
+	ctx := trace.ContextWithSpanContext(tracing.WithRequestID(context.Background(), "req-1"), sc)
+
+	logger.WithContext(ctx).Info("with context")
+	logger.Info("without context")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var withCtx, withoutCtx map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &withCtx))
+	require.NoError(t, json.Unmarshal(lines[1], &withoutCtx))
+	assert.Equal(t, "req-1", withCtx["request_id"])
+	assert.Equal(t, sc.TraceID().String(), withCtx["trace_id"])
+	assert.Equal(t, sc.SpanID().String(), withCtx["span_id"])
+	assert.NotContains(t, withoutCtx, "request_id")
+	assert.NotContains(t, withoutCtx, "trace_id")
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+// holdExpiry marks holds past their TTL as expired.
+//
+// Expired holds no longer reserve funds as soon as their expiry passes, the
+// job only brings their recorded status in line.
+type holdExpiry struct {
+	holdRepository repository.Hold
+	cfg            model.Holds
+}
+
+// NewHoldExpiry returns a job expiring holds that were neither captured nor voided in time.
+func NewHoldExpiry(hr repository.Hold, cfg model.Holds) Job {
+	return &holdExpiry{
+		holdRepository: hr,
+		cfg:            cfg,
+	}
+}
+
+func (h *holdExpiry) Name() string {
+	return "holdExpiry"
+}
+
+func (h *holdExpiry) Interval() time.Duration {
+	return h.cfg.ExpiryInterval
+}
+
+// RunOnce marks every active hold past its expiry as expired.
+func (h *holdExpiry) RunOnce(ctx context.Context) error {
+	expired, err := h.holdRepository.ExpireDue(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to expire holds: %w", err)
+	}
+	if expired > 0 {
+		log.Infof("expired %d holds", expired)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+// legacyIDResolver replaces the serial IDs of transactions recorded before
+// public IDs, which reversals and undelivered outbox entries still carry, with
+// the public IDs the transaction service gave them.
+//
+// Outbox entries reversing a transaction by its serial ID are not delivered
+// until it is replaced. Serial IDs unknown to the transaction service are kept
+// and looked up again on the next run.
+type legacyIDResolver struct {
+	reversalRepository repository.Reversal
+	outboxRepository   repository.Outbox
+	txnClient          client.NewTransaction
+	cfg                model.Outbox
+}
+
+// NewLegacyIDResolver returns a job resolving the serial IDs of legacy
+// transactions through tc, in batches of the outbox batch size.
+func NewLegacyIDResolver(rr repository.Reversal, or repository.Outbox, tc client.NewTransaction, cfg model.Outbox) Job {
+	return &legacyIDResolver{
+		reversalRepository: rr,
+		outboxRepository:   or,
+		txnClient:          tc,
+		cfg:                cfg,
+	}
+}
+
+func (l *legacyIDResolver) Name() string {
+	return "legacyIDResolver"
+}
+
+func (l *legacyIDResolver) Interval() time.Duration {
+	return l.cfg.PollInterval
+}
+
+// RunOnce resolves a batch of the serial IDs of reversals and of undelivered outbox entries.
+func (l *legacyIDResolver) RunOnce(ctx context.Context) error {
+	reversed, err := l.reversalRepository.FindLegacyIDs(ctx, l.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to find legacy reversal IDs: %w", err)
+	}
+	resolved, err := l.resolve(ctx, reversed)
+	if err != nil {
+		return err
+	}
+	for legacyID, publicID := range resolved {
+		if err := l.reversalRepository.ReplaceLegacyID(ctx, legacyID, publicID); err != nil {
+			return fmt.Errorf("failed to replace legacy reversal ID %d: %w", legacyID, err)
+		}
+	}
+	if len(resolved) > 0 {
+		log.Infof("resolved %d legacy reversal IDs", len(resolved))
+	}
+
+	pending, err := l.outboxRepository.FindLegacyReversalOf(ctx, l.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to find legacy outbox IDs: %w", err)
+	}
+	resolved, err = l.resolve(ctx, pending)
+	if err != nil {
+		return err
+	}
+	for legacyID, publicID := range resolved {
+		if err := l.outboxRepository.ReplaceLegacyReversalOf(ctx, legacyID, publicID); err != nil {
+			return fmt.Errorf("failed to replace legacy outbox ID %d: %w", legacyID, err)
+		}
+	}
+	if len(resolved) > 0 {
+		log.Infof("resolved %d legacy outbox IDs", len(resolved))
+	}
+	return nil
+}
+
+// resolve looks up the public IDs of legacyIDs, if any.
+func (l *legacyIDResolver) resolve(ctx context.Context, legacyIDs []int64) (map[int64]string, error) {
+	if len(legacyIDs) == 0 {
+		return nil, nil
+	}
+	resolved, err := l.txnClient.ResolveLegacyIDs(ctx, legacyIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve legacy transaction IDs: %w", err)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,189 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/metrics"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/tracing"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// outboxRelay delivers pending outbox entries to the transaction service.
+//
+// Entries are leased in a short database transaction and delivered outside of
+// it, so that no rows stay locked while the transaction service answers. They
+// are only marked delivered after the transaction service accepted them, so
+// every entry is delivered at least once. Failed deliveries are retried with
+// exponential backoff capped at the configured maximum, until an entry runs out
+// of attempts and is marked failed.
+//
+// An entry depending on another, such as the status change of a reviewed pair,
+// is only claimed once that entry was delivered, and is marked failed with it.
+type outboxRelay struct {
+	outboxRepository repository.Outbox
+	txnClient        client.NewTransaction
+	redisClient      cache.RedisClient
+	cfg              model.Outbox
+}
+
+// NewOutboxRelay returns a job relaying the outbox to the transaction service
+// through tc. The cached histories of the wallets of delivered pairs are
+// invalidated in rc.
+func NewOutboxRelay(or repository.Outbox, tc client.NewTransaction, rc cache.RedisClient, cfg model.Outbox) Job {
+	return &outboxRelay{
+		outboxRepository: or,
+		txnClient:        tc,
+		redisClient:      rc,
+		cfg:              cfg,
+	}
+}
+
+func (o *outboxRelay) Name() string {
+	return "outboxRelay"
+}
+
+func (o *outboxRelay) Interval() time.Duration {
+	return o.cfg.PollInterval
+}
+
+// RunOnce claims a batch of due entries and attempts to deliver each of them.
+func (o *outboxRelay) RunOnce(ctx context.Context) error {
+	// Entries waiting on a failed entry would never become due
+	blocked, err := o.outboxRepository.FailBlocked(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fail blocked outbox entries: %w", err)
+	}
+	if blocked > 0 {
+		utils.LogErrorfContext(ctx, "Giving up on %d outbox entries depending on failed entries", blocked)
+		metrics.OutboxDeliveries.WithLabelValues(metrics.OutboxFailed).Add(float64(blocked))
+	}
+
+	now := time.Now()
+	entries, err := o.outboxRepository.ClaimDue(ctx, now, now.Add(o.cfg.LeaseTimeout), o.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to claim outbox entries: %w", err)
+	}
+
+	// Claimed entries are settled even on shutdown, so that they are not left leased
+	storeCtx := context.WithoutCancel(ctx)
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			// Hand the rest of the batch back instead of waiting for the lease to run out
+			if err := o.outboxRepository.MarkRetry(storeCtx, entry.ID, entry.Attempts, entry.LastError, time.Now()); err != nil {
+				utils.LogErrorContext(ctx, fmt.Sprintf("Failed to release outbox entry %d", entry.ID), err)
+			}
+			continue
+		}
+
+		pair, err := o.deliver(ctx, &entry)
+		if err != nil {
+			o.recordFailure(storeCtx, &entry, err)
+			continue
+		}
+
+		if err := o.outboxRepository.MarkDelivered(storeCtx, entry.ID, time.Now()); err != nil {
+			// The entry is delivered again once its lease runs out, which the idempotency key makes harmless
+			utils.LogErrorContext(ctx, fmt.Sprintf("Failed to mark outbox entry %d delivered", entry.ID), err)
+		}
+		metrics.OutboxDeliveries.WithLabelValues(metrics.OutboxDelivered).Inc()
+
+		// The ledger changed for these wallets, so their cached history is stale
+		for _, walletID := range []string{pair.Debit.SubjectWalletID, pair.Credit.SubjectWalletID} {
+			if err := o.redisClient.DeleteTransactionHistory(ctx, walletID); err != nil {
+				utils.LogErrorContext(ctx, "Failed to invalidate cache after outbox delivery", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordFailure schedules the next delivery attempt of an entry, or marks it
+// failed once it has used up its attempts.
+func (o *outboxRelay) recordFailure(ctx context.Context, entry *model.OutboxEntry, deliveryErr error) {
+	attempts := entry.Attempts + 1
+	if attempts >= o.cfg.MaxAttempts {
+		utils.LogErrorfContext(ctx, "Giving up on outbox entry %d after %d attempts: %v", entry.ID, attempts, deliveryErr)
+		if err := o.outboxRepository.MarkFailed(ctx, entry.ID, attempts, deliveryErr.Error()); err != nil {
+			utils.LogErrorContext(ctx, fmt.Sprintf("Failed to mark outbox entry %d failed", entry.ID), err)
+		}
+		metrics.OutboxDeliveries.WithLabelValues(metrics.OutboxFailed).Inc()
+		return
+	}
+
+	utils.LogErrorfContext(ctx, "Failed to deliver outbox entry %d (attempt %d): %v", entry.ID, attempts, deliveryErr)
+	if err := o.outboxRepository.MarkRetry(ctx, entry.ID, attempts, deliveryErr.Error(), time.Now().Add(OutboxBackoff(attempts, o.cfg))); err != nil {
+		utils.LogErrorContext(ctx, fmt.Sprintf("Failed to reschedule outbox entry %d", entry.ID), err)
+	}
+	metrics.OutboxDeliveries.WithLabelValues(metrics.OutboxRetried).Inc()
+}
+
+// deliver sends a single entry to the transaction service and returns the pair it records or settles.
+// The delivery is traced in its own span and carries the ID of the request that enqueued the entry.
+func (o *outboxRelay) deliver(ctx context.Context, entry *model.OutboxEntry) (*model.TransactionPair, error) {
+	ctx, span := tracing.Tracer().Start(tracing.WithRequestID(ctx, entry.RequestID), "outbox.deliver",
+		trace.WithAttributes(
+			attribute.Int("outbox.entry_id", entry.ID),
+			attribute.String("outbox.event_type", string(entry.EventType)),
+			attribute.Int("outbox.attempt", entry.Attempts+1),
+		),
+	)
+	defer span.End()
+
+	pair, err := o.deliverEntry(ctx, entry)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return pair, err
+}
+
+func (o *outboxRelay) deliverEntry(ctx context.Context, entry *model.OutboxEntry) (*model.TransactionPair, error) {
+	switch entry.EventType {
+	case model.OutboxTransactionPair:
+		pair, err := entry.TransactionPair()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode outbox payload: %w", err)
+		}
+		if err := o.txnClient.CreateTransactionPair(ctx, &pair.Debit, &pair.Credit, entry.IdempotencyKey); err != nil {
+			return nil, err
+		}
+		return pair, nil
+	case model.OutboxPairStatus:
+		// A status change depends on the pair it settles, so it is only claimed once the pair was delivered
+		status, err := entry.PairStatus()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode outbox payload: %w", err)
+		}
+		if err := o.txnClient.UpdatePairStatus(ctx, status.ReviewID, status.Status, entry.IdempotencyKey); err != nil {
+			return nil, err
+		}
+		return &model.TransactionPair{Debit: status.Debit, Credit: status.Credit}, nil
+	}
+	return nil, fmt.Errorf("unknown outbox event type %q", entry.EventType)
+}
+
+// OutboxBackoff returns the delay before the next delivery attempt:
+// BaseBackoff doubled for every failed attempt, capped at MaxBackoff.
+func OutboxBackoff(attempts int, cfg model.Outbox) time.Duration {
+	backoff := cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= cfg.MaxBackoff {
+			return cfg.MaxBackoff
+		}
+	}
+	if backoff > cfg.MaxBackoff {
+		return cfg.MaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,326 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/cache"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/client"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxBackoff(t *testing.T) {
+	cfg := model.Outbox{
+		BaseBackoff: time.Second,
+		MaxBackoff:  10 * time.Second,
+	}
+
+	tests := []struct {
+		name     string
+		attempts int
+		want     time.Duration
+	}{
+		{"first_attempt", 1, time.Second},
+		{"second_attempt", 2, 2 * time.Second},
+		{"third_attempt", 3, 4 * time.Second},
+		{"fourth_attempt", 4, 8 * time.Second},
+		{"capped_at_max", 5, 10 * time.Second},
+		{"stays_capped", 50, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, OutboxBackoff(tt.attempts, cfg))
+		})
+	}
+}
+
+// memoryOutbox is an in-memory outbox recording the leases and outcomes of the relay.
+type memoryOutbox struct {
+	repository.Outbox
+	entries    map[int]*model.OutboxEntry
+	leaseUntil time.Time
+}
+
+func (m *memoryOutbox) ClaimDue(_ context.Context, now, leaseUntil time.Time, limit int) ([]model.OutboxEntry, error) {
+	m.leaseUntil = leaseUntil
+	var due []model.OutboxEntry
+	for id := 1; id <= len(m.entries) && len(due) < limit; id++ {
+		entry := m.entries[id]
+		if entry.DependsOn != nil && m.entries[*entry.DependsOn].Status != model.OutboxDelivered {
+			continue
+		}
+		if len(legacyReversalOf(entry)) > 0 {
+			continue
+		}
+		if entry.Status == model.OutboxPending && !entry.NextAttemptAt.After(now) {
+			due = append(due, *entry)
+			entry.NextAttemptAt = leaseUntil
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryOutbox) MarkDelivered(_ context.Context, id int, deliveredAt time.Time) error {
+	m.entries[id].Status = model.OutboxDelivered
+	m.entries[id].DeliveredAt = &deliveredAt
+	return nil
+}
+
+func (m *memoryOutbox) MarkRetry(_ context.Context, id int, attempts int, lastErr string, nextAttemptAt time.Time) error {
+	m.entries[id].Attempts = attempts
+	m.entries[id].LastError = lastErr
+	m.entries[id].NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (m *memoryOutbox) MarkFailed(_ context.Context, id int, attempts int, lastErr string) error {
+	m.entries[id].Status = model.OutboxFailed
+	m.entries[id].Attempts = attempts
+	m.entries[id].LastError = lastErr
+	return nil
+}
+
+func (m *memoryOutbox) FailBlocked(_ context.Context) (int64, error) {
+	var failed int64
+	for _, entry := range m.entries {
+		if entry.Status == model.OutboxPending && entry.DependsOn != nil && m.entries[*entry.DependsOn].Status == model.OutboxFailed {
+			entry.Status = model.OutboxFailed
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+func (m *memoryOutbox) FindLegacyReversalOf(_ context.Context, limit int) ([]int64, error) {
+	var ids []int64
+	for id := 1; id <= len(m.entries) && len(ids) < limit; id++ {
+		if m.entries[id].Status != model.OutboxDelivered {
+			ids = append(ids, legacyReversalOf(m.entries[id])...)
+		}
+	}
+	return ids, nil
+}
+
+func (m *memoryOutbox) ReplaceLegacyReversalOf(_ context.Context, legacyID int64, publicID string) error {
+	for _, entry := range m.entries {
+		if entry.Status == model.OutboxDelivered || entry.EventType != model.OutboxTransactionPair {
+			continue
+		}
+		pair, err := entry.TransactionPair()
+		if err != nil {
+			return err
+		}
+		for _, txn := range []*model.Transaction{&pair.Debit, &pair.Credit} {
+			if txn.ReversalOf != nil && *txn.ReversalOf == strconv.FormatInt(legacyID, 10) {
+				txn.ReversalOf = &publicID
+			}
+		}
+		data, err := json.Marshal(pair)
+		if err != nil {
+			return err
+		}
+		entry.Payload = string(data)
+	}
+	return nil
+}
+
+// legacyReversalOf returns the serial IDs of the transactions reversed by entry.
+func legacyReversalOf(entry *model.OutboxEntry) []int64 {
+	pair, err := entry.Pair()
+	if err != nil {
+		return nil
+	}
+	var ids []int64
+	for _, txn := range []model.Transaction{pair.Debit, pair.Credit} {
+		if txn.ReversalOf == nil {
+			continue
+		}
+		if id, err := strconv.ParseInt(*txn.ReversalOf, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// memoryReversals is an in-memory reversal repository keyed by transaction ID.
+type memoryReversals struct {
+	repository.Reversal
+	ids map[string]bool
+}
+
+func (m *memoryReversals) FindLegacyIDs(_ context.Context, limit int) ([]int64, error) {
+	var ids []int64
+	for transactionID := range m.ids {
+		if id, err := strconv.ParseInt(transactionID, 10, 64); err == nil && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *memoryReversals) ReplaceLegacyID(_ context.Context, legacyID int64, publicID string) error {
+	legacy := strconv.FormatInt(legacyID, 10)
+	if m.ids[legacy] && !m.ids[publicID] {
+		delete(m.ids, legacy)
+		m.ids[publicID] = true
+	}
+	return nil
+}
+
+// addPairStatus adds a pending status change of the pair of entry pairID and returns its ID.
+func (m *memoryOutbox) addPairStatus(t *testing.T, pairID int) int {
+	pair, err := m.entries[pairID].TransactionPair()
+	require.NoError(t, err)
+	entry, err := model.NewPairStatusEntry("review-001", &pair.Debit, &pair.Credit)
+	require.NoError(t, err)
+	entry.ID = len(m.entries) + 1
+	entry.DependsOn = &pairID
+	entry.NextAttemptAt = time.Now().Add(-time.Second)
+	m.entries[entry.ID] = entry
+	return entry.ID
+}
+
+func newMemoryOutbox(t *testing.T, attempts ...int) *memoryOutbox {
+	m := &memoryOutbox{entries: map[int]*model.OutboxEntry{}}
+	for i, a := range attempts {
+		entry, err := model.NewTransactionPairEntry(
+			&model.Transaction{SubjectWalletID: "test-user-001", TransactionType: model.Transfer, OperationType: model.Debit, Amount: 100},
+			&model.Transaction{SubjectWalletID: "test-user-002", TransactionType: model.Transfer, OperationType: model.Credit, Amount: 100},
+		)
+		require.NoError(t, err)
+		entry.ID = i + 1
+		entry.Attempts = a
+		entry.NextAttemptAt = time.Now().Add(-time.Second)
+		m.entries[entry.ID] = entry
+	}
+	return m
+}
+
+func TestOutboxRelay_RunOnce(t *testing.T) {
+	cfg := model.Outbox{
+		BatchSize:    10,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   time.Minute,
+		MaxAttempts:  3,
+		LeaseTimeout: time.Minute,
+	}
+
+	t.Run("Delivered_entries_are_marked_delivered", func(t *testing.T) {
+		outbox := newMemoryOutbox(t, 0)
+		relay := NewOutboxRelay(outbox, &client.MockTransactionClient{}, cache.NewMockRedisClient(), cfg)
+
+		require.NoError(t, relay.RunOnce(context.Background()))
+		assert.Equal(t, model.OutboxDelivered, outbox.entries[1].Status)
+		assert.WithinDuration(t, time.Now().Add(cfg.LeaseTimeout), outbox.leaseUntil, time.Second)
+	})
+
+	t.Run("Failed_deliveries_are_retried_until_attempts_run_out", func(t *testing.T) {
+		outbox := newMemoryOutbox(t, 0, cfg.MaxAttempts-1)
+		relay := NewOutboxRelay(outbox, &client.MockTransactionClient{CreateErr: errors.New("service unavailable")}, cache.NewMockRedisClient(), cfg)
+
+		require.NoError(t, relay.RunOnce(context.Background()))
+
+		retried := outbox.entries[1]
+		assert.Equal(t, model.OutboxPending, retried.Status)
+		assert.Equal(t, 1, retried.Attempts)
+		assert.Equal(t, "service unavailable", retried.LastError)
+		assert.WithinDuration(t, time.Now().Add(cfg.BaseBackoff), retried.NextAttemptAt, time.Second)
+
+		failed := outbox.entries[2]
+		assert.Equal(t, model.OutboxFailed, failed.Status)
+		assert.Equal(t, cfg.MaxAttempts, failed.Attempts)
+	})
+
+	t.Run("Status_changes_wait_for_their_pair", func(t *testing.T) {
+		outbox := newMemoryOutbox(t, 0)
+		status := outbox.addPairStatus(t, 1)
+		relay := NewOutboxRelay(outbox, &client.MockTransactionClient{CreateErr: errors.New("service unavailable")}, cache.NewMockRedisClient(), cfg)
+
+		// The status change is not claimed in the batch of its pair, nor while the pair is retried
+		require.NoError(t, relay.RunOnce(context.Background()))
+		assert.Equal(t, model.OutboxPending, outbox.entries[1].Status)
+		assert.Equal(t, model.OutboxPending, outbox.entries[status].Status)
+		assert.Equal(t, 0, outbox.entries[status].Attempts)
+
+		// Once the pair is delivered, the status change follows
+		outbox.entries[1].NextAttemptAt = time.Now().Add(-time.Second)
+		relay = NewOutboxRelay(outbox, &client.MockTransactionClient{}, cache.NewMockRedisClient(), cfg)
+		require.NoError(t, relay.RunOnce(context.Background()))
+		assert.Equal(t, model.OutboxDelivered, outbox.entries[1].Status)
+		require.NoError(t, relay.RunOnce(context.Background()))
+		assert.Equal(t, model.OutboxDelivered, outbox.entries[status].Status)
+	})
+
+	t.Run("Status_changes_of_failed_pairs_are_failed", func(t *testing.T) {
+		outbox := newMemoryOutbox(t, cfg.MaxAttempts-1)
+		status := outbox.addPairStatus(t, 1)
+		relay := NewOutboxRelay(outbox, &client.MockTransactionClient{CreateErr: errors.New("service unavailable")}, cache.NewMockRedisClient(), cfg)
+
+		require.NoError(t, relay.RunOnce(context.Background()))
+		assert.Equal(t, model.OutboxFailed, outbox.entries[1].Status)
+
+		require.NoError(t, relay.RunOnce(context.Background()))
+		assert.Equal(t, model.OutboxFailed, outbox.entries[status].Status)
+		assert.Equal(t, 0, outbox.entries[status].Attempts)
+	})
+
+	t.Run("Cancelled_runs_hand_back_their_entries", func(t *testing.T) {
+		outbox := newMemoryOutbox(t, 0)
+		relay := NewOutboxRelay(outbox, &client.MockTransactionClient{}, cache.NewMockRedisClient(), cfg)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.NoError(t, relay.RunOnce(ctx))
+
+		entry := outbox.entries[1]
+		assert.Equal(t, model.OutboxPending, entry.Status)
+		assert.Equal(t, 0, entry.Attempts)
+		assert.False(t, entry.NextAttemptAt.After(time.Now()))
+	})
+
+	t.Run("Reversals_of_legacy_transactions_wait_for_their_public_ID", func(t *testing.T) {
+		outbox := newMemoryOutbox(t, 0)
+		pair, err := outbox.entries[1].TransactionPair()
+		require.NoError(t, err)
+		legacyID := "1"
+		pair.Debit.ReversalOf = &legacyID
+		pair.Credit.ReversalOf = &legacyID
+		data, err := json.Marshal(pair)
+		require.NoError(t, err)
+		outbox.entries[1].Payload = string(data)
+		relay := NewOutboxRelay(outbox, &client.MockTransactionClient{}, cache.NewMockRedisClient(), cfg)
+
+		// The transaction service refuses serial IDs, so the entry is not claimed
+		require.NoError(t, relay.RunOnce(context.Background()))
+		assert.Equal(t, model.OutboxPending, outbox.entries[1].Status)
+		assert.Equal(t, 0, outbox.entries[1].Attempts)
+
+		// Once the serial ID is replaced by the public ID, the entry is delivered
+		reversals := &memoryReversals{ids: map[string]bool{"1": true, "2": true, "99": true}}
+		resolver := NewLegacyIDResolver(reversals, outbox, &client.MockTransactionClient{}, cfg)
+		require.NoError(t, resolver.RunOnce(context.Background()))
+
+		pair, err = outbox.entries[1].TransactionPair()
+		require.NoError(t, err)
+		assert.Equal(t, "01890a5d-ac96-774b-bcce-b302099a8001", *pair.Debit.ReversalOf)
+		assert.Equal(t, "01890a5d-ac96-774b-bcce-b302099a8001", *pair.Credit.ReversalOf)
+
+		// Serial IDs unknown to the transaction service are kept
+		assert.Equal(t, map[string]bool{
+			"01890a5d-ac96-774b-bcce-b302099a8001": true,
+			"01890a5d-ac96-774b-bcce-b302099a8002": true,
+			"99":                                   true,
+		}, reversals.ids)
+
+		require.NoError(t, relay.RunOnce(context.Background()))
+		assert.Equal(t, model.OutboxDelivered, outbox.entries[1].Status)
+	})
+}
@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/model"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/repository"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/service"
+	"github.com/fardinabir/digital-wallet-demo/services/wallets/internal/utils"
+)
+
+// scheduleRunner makes the transfers of due schedules.
+//
+// A schedule is moved to its next run before its transfer is made, so that an
+// occurrence is run at most once even if the runner stops halfway. Occurrences
+// missed while no runner was running are not made up; the schedule runs once
+// and continues with its next occurrence from now.
+type scheduleRunner struct {
+	scheduleRepository repository.Schedule
+	walletService      service.Wallet
+	cfg                model.Schedules
+}
+
+// NewScheduleRunner returns a job running due scheduled transfers through the wallet service.
+func NewScheduleRunner(sr repository.Schedule, ws service.Wallet, cfg model.Schedules) Job {
+	return &scheduleRunner{
+		scheduleRepository: sr,
+		walletService:      ws,
+		cfg:                cfg,
+	}
+}
+
+func (r *scheduleRunner) Name() string {
+	return "scheduleRunner"
+}
+
+func (r *scheduleRunner) Interval() time.Duration {
+	return r.cfg.PollInterval
+}
+
+// RunOnce claims a batch of due schedules, advances them and makes their transfers.
+func (r *scheduleRunner) RunOnce(ctx context.Context) error {
+	now := time.Now()
+
+	tx := r.scheduleRepository.BeginTransaction(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	due, err := r.scheduleRepository.ClaimDue(tx, now, r.cfg.BatchSize)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to claim due schedules: %w", err)
+	}
+
+	for i := range due {
+		next, ok, err := due[i].NextRun(now)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to plan schedule %d: %w", due[i].ID, err)
+		}
+		nextRunAt, status := &next, model.ScheduleActive
+		if !ok {
+			nextRunAt, status = nil, model.ScheduleCompleted
+		}
+		if err := r.scheduleRepository.Advance(tx, due[i].ID, nextRunAt, status); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to advance schedule %d: %w", due[i].ID, err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit schedule runner transaction: %w", err)
+	}
+
+	// The schedules are advanced, so every claimed occurrence is run even on shutdown
+	runCtx := context.WithoutCancel(ctx)
+	for _, sched := range due {
+		_, runErr := r.walletService.Transfer(runCtx, service.TransferParams{
+			FromUserID: sched.FromUserID,
+			ToUserID:   sched.ToUserID,
+			Amount:     sched.Amount,
+			Currency:   sched.Currency,
+			ToCurrency: sched.ToCurrency,
+			Reference:  fmt.Sprintf("schedule-%d", sched.ID),
+			Audit:      model.AuditContext{Actor: model.SchedulerActor},
+		})
+		if runErr != nil {
+			utils.LogErrorfContext(runCtx, "Scheduled transfer %d failed: %v", sched.ID, runErr)
+		}
+		if err := r.scheduleRepository.RecordRun(runCtx, sched.ID, time.Now(), runErr); err != nil {
+			utils.LogErrorContext(runCtx, fmt.Sprintf("Failed to record run of schedule %d", sched.ID), err)
+		}
+	}
+
+	return nil
+}
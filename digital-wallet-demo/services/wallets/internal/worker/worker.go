@@ -0,0 +1,14 @@
+// Package worker provides the background jobs run alongside the API server.
+package worker
+
+import (
+	"context"
+	"time"
+)
+
+// Job is a unit of background work executed periodically by the worker server.
+type Job interface {
+	Name() string
+	Interval() time.Duration
+	RunOnce(ctx context.Context) error
+}
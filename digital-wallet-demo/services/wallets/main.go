@@ -13,6 +13,11 @@ import (
 // @host			localhost:8081
 // @BasePath		/api/v1
 // @schemes		http
+//
+// @securityDefinitions.apikey	BearerAuth
+// @in							header
+// @name						Authorization
+// @description				JWT of the caller as "Bearer <token>", required when authentication is enabled
 func main() {
 	cmd.Execute()
 }
@@ -0,0 +1,37 @@
+-- Transactional Outbox Schema
+-- Ledger entries destined for the transaction service are written here in the
+-- same database transaction as the wallet balance change, then relayed by the
+-- background worker with retries (at-least-once delivery)
+
+-- Create outbox_entries table
+CREATE TABLE IF NOT EXISTS outbox_entries (
+    id SERIAL PRIMARY KEY,
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create partial index used by the relay to find due entries
+CREATE INDEX IF NOT EXISTS idx_outbox_entries_pending ON outbox_entries(next_attempt_at) WHERE status = 'pending';
+
+-- Create triggers for automatic updated_at timestamp updates
+DROP TRIGGER IF EXISTS update_outbox_entries_updated_at ON outbox_entries;
+CREATE TRIGGER update_outbox_entries_updated_at
+    BEFORE UPDATE ON outbox_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments to tables and columns for documentation
+COMMENT ON TABLE outbox_entries IS 'Messages for the transaction service written atomically with balance changes';
+COMMENT ON COLUMN outbox_entries.event_type IS 'Message type: transaction_pair';
+COMMENT ON COLUMN outbox_entries.payload IS 'JSON encoded message body';
+COMMENT ON COLUMN outbox_entries.status IS 'Delivery status: pending or delivered';
+COMMENT ON COLUMN outbox_entries.attempts IS 'Number of failed delivery attempts';
+COMMENT ON COLUMN outbox_entries.next_attempt_at IS 'Earliest time of the next delivery attempt';
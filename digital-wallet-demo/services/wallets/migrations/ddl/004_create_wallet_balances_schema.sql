@@ -0,0 +1,33 @@
+-- Multi-Currency Wallet Schema
+-- Every wallet has a base currency held in wallets.balance. Balances in other
+-- currencies are kept in wallet_balances, one row per wallet and currency
+
+-- Add base currency to wallets
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Create wallet_balances table
+CREATE TABLE IF NOT EXISTS wallet_balances (
+    id SERIAL PRIMARY KEY,
+    wallet_id INTEGER NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    currency VARCHAR(3) NOT NULL,
+    balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create unique index so a wallet holds at most one balance per currency
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_balances_wallet_currency ON wallet_balances(wallet_id, currency);
+
+-- Create triggers for automatic updated_at timestamp updates
+DROP TRIGGER IF EXISTS update_wallet_balances_updated_at ON wallet_balances;
+CREATE TRIGGER update_wallet_balances_updated_at
+    BEFORE UPDATE ON wallet_balances
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments to tables and columns for documentation
+COMMENT ON COLUMN wallets.currency IS 'ISO-4217 base currency of the wallet, the currency of wallets.balance';
+COMMENT ON TABLE wallet_balances IS 'Wallet balances in currencies other than the wallet base currency';
+COMMENT ON COLUMN wallet_balances.wallet_id IS 'Wallet holding the balance';
+COMMENT ON COLUMN wallet_balances.currency IS 'ISO-4217 currency code';
+COMMENT ON COLUMN wallet_balances.balance IS 'Balance in minor units of the currency';
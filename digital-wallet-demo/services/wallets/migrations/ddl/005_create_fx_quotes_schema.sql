@@ -0,0 +1,27 @@
+-- FX Quote Schema
+-- Exchange rate quotes lock a conversion rate for a short window so that a
+-- cross-currency transfer can be made at the rate shown to the user
+
+-- Create fx_quotes table
+CREATE TABLE IF NOT EXISTS fx_quotes (
+    id VARCHAR(32) PRIMARY KEY,
+    from_currency VARCHAR(3) NOT NULL,
+    to_currency VARCHAR(3) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    counter_amount BIGINT NOT NULL CHECK (counter_amount >= 0),
+    rate VARCHAR(32) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create index on expiry for purging expired quotes
+CREATE INDEX IF NOT EXISTS idx_fx_quotes_expires_at ON fx_quotes(expires_at);
+
+-- Add comments to tables and columns for documentation
+COMMENT ON TABLE fx_quotes IS 'Exchange rate quotes redeemable once by a cross-currency transfer';
+COMMENT ON COLUMN fx_quotes.amount IS 'Amount in minor units of from_currency';
+COMMENT ON COLUMN fx_quotes.counter_amount IS 'Converted amount in minor units of to_currency, rounded down';
+COMMENT ON COLUMN fx_quotes.rate IS 'Price of one unit of from_currency in to_currency';
+COMMENT ON COLUMN fx_quotes.expires_at IS 'Time after which the quote can no longer be redeemed';
+COMMENT ON COLUMN fx_quotes.used_at IS 'Time the quote was redeemed by a transfer';
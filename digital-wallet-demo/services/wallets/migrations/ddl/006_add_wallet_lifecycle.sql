@@ -0,0 +1,16 @@
+-- Wallet Lifecycle Schema
+-- Wallets can be suspended, deactivated and reactivated, or closed for good.
+-- Only active wallets can send or receive funds
+
+-- Allow the closed status
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_status_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_status_check CHECK (status IN ('active', 'inactive', 'suspended', 'closed'));
+
+-- Record why and when the status last changed
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS status_reason TEXT;
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE;
+
+-- Add comments to tables and columns for documentation
+COMMENT ON COLUMN wallets.status IS 'Wallet status: active, inactive, suspended, or closed';
+COMMENT ON COLUMN wallets.status_reason IS 'Reason given for the last status change';
+COMMENT ON COLUMN wallets.status_changed_at IS 'Time of the last status change';
@@ -0,0 +1,17 @@
+-- Transaction Reversal Schema
+-- Records the ledger transactions reversed by the wallet service, one row per
+-- transaction, so that a transaction cannot be reversed twice
+
+-- Create reversals table
+CREATE TABLE IF NOT EXISTS reversals (
+    transaction_id INTEGER PRIMARY KEY,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add comments to tables and columns for documentation
+COMMENT ON TABLE reversals IS 'Ledger transactions reversed by a compensating transaction pair';
+COMMENT ON COLUMN reversals.transaction_id IS 'ID of the reversed transaction in the transaction service';
+COMMENT ON COLUMN reversals.amount IS 'Amount reversed in minor units of the currency, at most the transaction amount';
+COMMENT ON COLUMN reversals.currency IS 'ISO-4217 currency of the reversed transaction';
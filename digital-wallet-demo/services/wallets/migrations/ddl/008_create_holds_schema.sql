@@ -0,0 +1,32 @@
+-- Hold Schema
+-- Holds reserve part of a wallet's balance for a later transfer, so that a
+-- merchant can authorize an amount and capture it afterwards
+
+-- Create holds table
+CREATE TABLE IF NOT EXISTS holds (
+    id VARCHAR(32) PRIMARY KEY,
+    from_user_id VARCHAR(255) NOT NULL,
+    to_user_id VARCHAR(255) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    captured_amount BIGINT NOT NULL DEFAULT 0 CHECK (captured_amount >= 0 AND captured_amount <= amount),
+    status VARCHAR(16) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'captured', 'voided', 'expired')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create index for summing the active holds of a wallet
+CREATE INDEX IF NOT EXISTS idx_holds_from_user_status ON holds(from_user_id, status);
+
+-- Create index on expiry for the expiry job
+CREATE INDEX IF NOT EXISTS idx_holds_expires_at ON holds(expires_at);
+
+-- Add comments to tables and columns for documentation
+COMMENT ON TABLE holds IS 'Amounts reserved from a wallet until they are captured, voided or expire';
+COMMENT ON COLUMN holds.from_user_id IS 'Wallet the amount is held from';
+COMMENT ON COLUMN holds.to_user_id IS 'Wallet credited when the hold is captured';
+COMMENT ON COLUMN holds.amount IS 'Held amount in minor units of the currency';
+COMMENT ON COLUMN holds.captured_amount IS 'Amount transferred by the capture, the remainder is released';
+COMMENT ON COLUMN holds.status IS 'Hold status: active, captured, voided, expired';
+COMMENT ON COLUMN holds.expires_at IS 'Time after which the hold no longer reserves funds';
@@ -0,0 +1,40 @@
+-- Schedule Schema
+-- Scheduled transfers run once at a start time or on a recurrence, made by
+-- the worker's schedule runner through the regular transfer path
+
+-- Create schedules table
+CREATE TABLE IF NOT EXISTS schedules (
+    id SERIAL PRIMARY KEY,
+    from_user_id VARCHAR(255) NOT NULL,
+    to_user_id VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3),
+    to_currency VARCHAR(3),
+    recurrence VARCHAR(16) NOT NULL CHECK (recurrence IN ('once', 'daily', 'weekly', 'monthly', 'cron')),
+    cron_expr VARCHAR(100),
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(16) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused', 'completed')),
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_run_status VARCHAR(16),
+    last_error TEXT,
+    run_count INTEGER NOT NULL DEFAULT 0,
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create index for listing the schedules of a wallet
+CREATE INDEX IF NOT EXISTS idx_schedules_from_user_id ON schedules(from_user_id);
+
+-- Create partial index for claiming due schedules
+CREATE INDEX IF NOT EXISTS idx_schedules_due ON schedules(next_run_at) WHERE status = 'active';
+
+-- Add comments to tables and columns for documentation
+COMMENT ON TABLE schedules IS 'Transfers made once or on a recurrence by the schedule runner';
+COMMENT ON COLUMN schedules.amount IS 'Amount of each transfer in minor units of the currency';
+COMMENT ON COLUMN schedules.recurrence IS 'Recurrence: once, daily, weekly, monthly, cron';
+COMMENT ON COLUMN schedules.cron_expr IS 'Standard 5-field cron expression of a cron recurrence, evaluated in UTC';
+COMMENT ON COLUMN schedules.next_run_at IS 'Time of the next run, unset once the schedule has no further runs';
+COMMENT ON COLUMN schedules.last_error IS 'Error of the last run if it failed';
+COMMENT ON COLUMN schedules.failure_count IS 'Number of runs whose transfer was rejected';
@@ -0,0 +1,44 @@
+-- Spending Limit Schema
+-- Limits on the outgoing withdrawals and transfers of a wallet or of every
+-- wallet of an account type, and the daily usage they are checked against
+
+-- Create spending_limits table
+CREATE TABLE IF NOT EXISTS spending_limits (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255),
+    acnt_type VARCHAR(16),
+    currency VARCHAR(3) NOT NULL,
+    max_transaction BIGINT NOT NULL DEFAULT 0 CHECK (max_transaction >= 0),
+    daily_amount BIGINT NOT NULL DEFAULT 0 CHECK (daily_amount >= 0),
+    monthly_amount BIGINT NOT NULL DEFAULT 0 CHECK (monthly_amount >= 0),
+    daily_count INTEGER NOT NULL DEFAULT 0 CHECK (daily_count >= 0),
+    monthly_count INTEGER NOT NULL DEFAULT 0 CHECK (monthly_count >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK ((user_id IS NULL) <> (acnt_type IS NULL))
+);
+
+-- Create unique indexes, one limit per wallet and per account type
+CREATE UNIQUE INDEX IF NOT EXISTS idx_spending_limits_user_id ON spending_limits(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_spending_limits_acnt_type ON spending_limits(acnt_type);
+
+-- Create spending_usage table
+CREATE TABLE IF NOT EXISTS spending_usage (
+    user_id VARCHAR(255) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    day DATE NOT NULL,
+    amount BIGINT NOT NULL DEFAULT 0,
+    count INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, currency, day)
+);
+
+-- Add comments to tables and columns for documentation
+COMMENT ON TABLE spending_limits IS 'Limits on outgoing withdrawals and transfers, per wallet or per account type';
+COMMENT ON COLUMN spending_limits.user_id IS 'Wallet of the limit, takes the place of the limit of its account type';
+COMMENT ON COLUMN spending_limits.acnt_type IS 'Account type of the limit, applies to its wallets without a limit of their own';
+COMMENT ON COLUMN spending_limits.currency IS 'ISO-4217 currency of the amount bounds';
+COMMENT ON COLUMN spending_limits.max_transaction IS 'Largest single outgoing transaction, 0 for no bound';
+COMMENT ON COLUMN spending_limits.daily_amount IS 'Outgoing total per UTC day, 0 for no bound';
+COMMENT ON COLUMN spending_limits.monthly_amount IS 'Outgoing total per UTC calendar month, 0 for no bound';
+COMMENT ON TABLE spending_usage IS 'Outgoing amount and transaction count of a wallet per currency and UTC day';
@@ -0,0 +1,26 @@
+-- Risk Decision Schema
+-- Records the decision of the risk rules for every transfer and withdrawal,
+-- with the rule that triggered it
+
+-- Create risk_decisions table
+CREATE TABLE IF NOT EXISTS risk_decisions (
+    id SERIAL PRIMARY KEY,
+    transaction_type VARCHAR(16) NOT NULL CHECK (transaction_type IN ('transfer', 'withdraw')),
+    from_user_id VARCHAR(255) NOT NULL,
+    to_user_id VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    action VARCHAR(16) NOT NULL CHECK (action IN ('approve', 'reject', 'review')),
+    rule VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for the transfer history checked by the recipients and round-trip rules
+CREATE INDEX IF NOT EXISTS idx_risk_decisions_from_user_created ON risk_decisions(from_user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_risk_decisions_to_user_created ON risk_decisions(to_user_id, created_at);
+
+-- Add comments to tables and columns for documentation
+COMMENT ON TABLE risk_decisions IS 'Decisions of the risk rules on outgoing transfers and withdrawals';
+COMMENT ON COLUMN risk_decisions.to_user_id IS 'Receiver of a transfer, or provider of a withdrawal';
+COMMENT ON COLUMN risk_decisions.action IS 'Decision: approve, reject, review (recorded as pending)';
+COMMENT ON COLUMN risk_decisions.rule IS 'Name of the rule that triggered the decision, NULL when approved by default';
@@ -0,0 +1,46 @@
+-- Review Schema
+-- Transfers and withdrawals held by a risk rule or sent from a flagged wallet
+-- wait in the review queue for an admin to approve or reject them
+
+-- Create reviews table
+CREATE TABLE IF NOT EXISTS reviews (
+    id VARCHAR(32) PRIMARY KEY,
+    transaction_type VARCHAR(16) NOT NULL CHECK (transaction_type IN ('transfer', 'withdraw')),
+    from_user_id VARCHAR(255) NOT NULL,
+    to_user_id VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    credit_amount BIGINT NOT NULL CHECK (credit_amount > 0),
+    credit_currency VARCHAR(3) NOT NULL,
+    fx_rate TEXT,
+    reason VARCHAR(16) NOT NULL CHECK (reason IN ('risk', 'flagged')),
+    rule TEXT,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    note TEXT,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create index for summing the pending reviews of a wallet
+CREATE INDEX IF NOT EXISTS idx_reviews_from_user_status ON reviews(from_user_id, status);
+
+-- Create indexes for listing the queue
+CREATE INDEX IF NOT EXISTS idx_reviews_status ON reviews(status);
+CREATE INDEX IF NOT EXISTS idx_reviews_created_at ON reviews(created_at);
+
+-- Create review_flags table
+CREATE TABLE IF NOT EXISTS review_flags (
+    user_id VARCHAR(255) PRIMARY KEY,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add comments to tables and columns for documentation
+COMMENT ON TABLE reviews IS 'Transfers and withdrawals waiting for, or decided by, an admin review';
+COMMENT ON COLUMN reviews.amount IS 'Debited amount in minor units of currency, reserved from the sender while pending';
+COMMENT ON COLUMN reviews.credit_amount IS 'Credited amount in minor units of credit_currency';
+COMMENT ON COLUMN reviews.reason IS 'Reason for review: risk (held by a risk rule), flagged (sender flagged by an admin)';
+COMMENT ON COLUMN reviews.rule IS 'Name of the risk rule that held the transaction';
+COMMENT ON COLUMN reviews.status IS 'Review status: pending, approved (pair completed), rejected (pair cancelled)';
+COMMENT ON TABLE review_flags IS 'Wallets whose outgoing transfers and withdrawals are all queued for review';
@@ -0,0 +1,9 @@
+-- Review Pairs
+-- A review keeps the pair ID and reference of the transaction pair it holds,
+-- so that the pair settled by its decision is the one recorded when queued
+
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS pair_id VARCHAR(36);
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS reference VARCHAR(128);
+
+COMMENT ON COLUMN reviews.pair_id IS 'Pair ID shared by the debit and credit transactions of the reviewed pair';
+COMMENT ON COLUMN reviews.reference IS 'Client supplied reference of the reviewed pair';
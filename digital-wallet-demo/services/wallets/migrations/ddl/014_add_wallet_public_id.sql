@@ -0,0 +1,69 @@
+-- Public Wallet and Transaction IDs
+-- Wallets are identified in the API by a time-ordered UUIDv7 public ID. The
+-- serial id stays the primary key, used for joins only. Ledger transactions
+-- are identified by the UUIDv7 public ID assigned by the transaction service
+
+-- uuid_v7_at returns a UUIDv7 for the given time: the Unix time in
+-- milliseconds followed by random bits, with the version set to 7
+CREATE OR REPLACE FUNCTION uuid_v7_at(ts TIMESTAMP WITH TIME ZONE)
+RETURNS UUID AS $$
+    SELECT encode(
+        set_bit(
+            set_bit(
+                overlay(uuid_send(gen_random_uuid())
+                    PLACING substring(int8send(floor(extract(epoch FROM ts) * 1000)::BIGINT) FROM 3)
+                    FROM 1 FOR 6),
+                52, 1),
+            53, 1),
+        'hex')::UUID;
+$$ LANGUAGE SQL VOLATILE;
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS public_id UUID;
+
+-- Backfill the public IDs of existing wallets from their creation time.
+-- The service sets the public ID of new wallets, the default covers rows
+-- inserted by SQL such as the provider wallets
+UPDATE wallets SET public_id = uuid_v7_at(created_at) WHERE public_id IS NULL;
+ALTER TABLE wallets ALTER COLUMN public_id SET DEFAULT uuid_v7_at(clock_timestamp());
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_public_id ON wallets(public_id);
+
+-- Reversals are keyed by the public ID of the reversed transaction. Reversals
+-- recorded before public IDs keep the serial ID until the legacy ID resolver
+-- of the worker replaces it with the public ID the transaction service gave
+-- the transaction
+ALTER TABLE reversals ALTER COLUMN transaction_id TYPE VARCHAR(36) USING transaction_id::VARCHAR(36);
+
+-- migrate_legacy_outbox_leg converts the integer IDs of a transaction in an
+-- outbox payload to strings. Undelivered transactions were recorded with the
+-- ID zero, which the transaction service never assigned, and lose it. The
+-- serial ID of a reversed transaction is kept as a string until the legacy ID
+-- resolver replaces it with its public ID
+CREATE OR REPLACE FUNCTION migrate_legacy_outbox_leg(leg JSONB)
+RETURNS JSONB AS $$
+    SELECT CASE
+        WHEN jsonb_typeof(leg -> 'reversal_of') = 'number'
+        THEN jsonb_set(legacy_leg, '{reversal_of}', to_jsonb(leg ->> 'reversal_of'))
+        ELSE legacy_leg
+    END
+    FROM (SELECT CASE
+        WHEN jsonb_typeof(leg -> 'id') IS DISTINCT FROM 'number' THEN leg
+        WHEN (leg ->> 'id')::BIGINT = 0 THEN leg - 'id'
+        ELSE jsonb_set(leg, '{id}', to_jsonb(leg ->> 'id'))
+    END AS legacy_leg) AS converted;
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Pending entries of both event types carry a debit and a credit transaction
+UPDATE outbox_entries
+SET payload = jsonb_set(jsonb_set(payload,
+        '{debit}', migrate_legacy_outbox_leg(payload -> 'debit')),
+        '{credit}', migrate_legacy_outbox_leg(payload -> 'credit'))
+WHERE status = 'pending'
+  AND (jsonb_typeof(payload #> '{debit,id}') = 'number'
+    OR jsonb_typeof(payload #> '{credit,id}') = 'number'
+    OR jsonb_typeof(payload #> '{debit,reversal_of}') = 'number'
+    OR jsonb_typeof(payload #> '{credit,reversal_of}') = 'number');
+
+COMMENT ON COLUMN wallets.id IS 'Internal primary key for wallet records, not exposed in the API';
+COMMENT ON COLUMN wallets.public_id IS 'UUIDv7 identifying the wallet in the API';
+COMMENT ON COLUMN reversals.transaction_id IS 'Public ID of the reversed transaction in the transaction service, or its serial ID until the legacy ID resolver replaces it';
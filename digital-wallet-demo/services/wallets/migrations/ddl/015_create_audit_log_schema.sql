@@ -0,0 +1,47 @@
+-- Audit Log Schema
+-- Append-only, hash-chained record of wallet creations, balance changes,
+-- status changes and admin actions, verified by the verify-audit command
+
+-- Create audit_log table
+CREATE TABLE IF NOT EXISTS audit_log (
+    id SERIAL PRIMARY KEY,
+    action VARCHAR(50) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255),
+    user_id VARCHAR(255),
+    currency VARCHAR(3),
+    balance_before BIGINT,
+    balance_after BIGINT,
+    details TEXT,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for looking up the entries of a wallet or action
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_log_hash ON audit_log(hash);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON audit_log(user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(action);
+
+-- Reject updates and deletes, so that entries can only be appended
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only, % is not allowed', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+CREATE TRIGGER trg_audit_log_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+-- Add comments to tables and columns for documentation
+COMMENT ON TABLE audit_log IS 'Append-only, hash-chained audit log of wallet changes and admin actions';
+COMMENT ON COLUMN audit_log.action IS 'Change recorded: wallet.created, wallet.balance_changed, wallet.status_changed, review.decided, review.flag_set, review.flag_removed, limit.set, limit.deleted, ledger.adjusted';
+COMMENT ON COLUMN audit_log.actor IS 'Who made the change: user:<user_id>, api, admin, system:scheduler or cli:reconcile';
+COMMENT ON COLUMN audit_log.request_id IS 'X-Request-ID of the API request that made the change';
+COMMENT ON COLUMN audit_log.balance_before IS 'Balance in minor units of currency before a balance change';
+COMMENT ON COLUMN audit_log.balance_after IS 'Balance in minor units of currency after a balance change';
+COMMENT ON COLUMN audit_log.details IS 'JSON object describing the change';
+COMMENT ON COLUMN audit_log.prev_hash IS 'Hash of the previous entry, empty for the first entry';
+COMMENT ON COLUMN audit_log.hash IS 'SHA-256 of the entry''s fields and prev_hash, hex encoded';
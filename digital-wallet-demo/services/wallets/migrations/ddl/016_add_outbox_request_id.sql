@@ -0,0 +1,7 @@
+-- Outbox Request ID
+-- The relay forwards the ID of the request that wrote an entry when it
+-- delivers the entry, correlating both services' logs and spans
+
+ALTER TABLE outbox_entries ADD COLUMN IF NOT EXISTS request_id TEXT;
+
+COMMENT ON COLUMN outbox_entries.request_id IS 'X-Request-ID of the request that wrote the entry, sent on delivery';
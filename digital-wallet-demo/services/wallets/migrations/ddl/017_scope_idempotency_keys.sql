@@ -0,0 +1,8 @@
+-- Idempotency Key Scope
+-- Keys are stored prefixed with the subject of the caller, so that a key
+-- chosen by one user never replays the response of another user's request.
+-- The prefix makes stored keys longer than the 255 characters of the header.
+
+ALTER TABLE idempotency_records ALTER COLUMN key TYPE TEXT;
+
+COMMENT ON COLUMN idempotency_records.key IS 'Idempotency-Key header value, prefixed with "<subject>:" for authenticated callers';
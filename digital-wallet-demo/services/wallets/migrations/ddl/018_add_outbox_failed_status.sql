@@ -0,0 +1,11 @@
+-- Failed Outbox Entries
+-- Entries still failing after outbox.maxAttempts deliveries are marked failed
+-- and no longer delivered, until an operator requeues them
+
+ALTER TABLE outbox_entries DROP CONSTRAINT IF EXISTS outbox_entries_status_check;
+ALTER TABLE outbox_entries ADD CONSTRAINT outbox_entries_status_check CHECK (status IN ('pending', 'delivered', 'failed'));
+
+CREATE INDEX IF NOT EXISTS idx_outbox_entries_failed ON outbox_entries(id) WHERE status = 'failed';
+
+COMMENT ON COLUMN outbox_entries.status IS 'Delivery status: pending, delivered, or failed after outbox.maxAttempts deliveries';
+COMMENT ON COLUMN outbox_entries.next_attempt_at IS 'Time of the next delivery attempt; moved forward by the lease of the relay delivering the entry';
@@ -0,0 +1,20 @@
+-- Outbox Entry Dependencies
+-- The status change of a reviewed pair depends on the entry of the pair it
+-- settles: it is only delivered once that entry was delivered, and is marked
+-- failed when that entry is
+
+ALTER TABLE outbox_entries ADD COLUMN IF NOT EXISTS depends_on INTEGER REFERENCES outbox_entries(id);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_entries_depends_on ON outbox_entries(depends_on);
+
+-- Pending status changes written before dependencies wait for their pair
+UPDATE outbox_entries AS status_entry
+SET depends_on = pair_entry.id
+FROM outbox_entries AS pair_entry
+WHERE status_entry.event_type = 'pair_status'
+  AND status_entry.status = 'pending'
+  AND status_entry.depends_on IS NULL
+  AND pair_entry.event_type = 'transaction_pair'
+  AND pair_entry.payload #>> '{debit,pair_id}' = status_entry.payload #>> '{debit,pair_id}';
+
+COMMENT ON COLUMN outbox_entries.depends_on IS 'Entry delivered before this one, set on the status change of a reviewed pair to the entry of the pair';
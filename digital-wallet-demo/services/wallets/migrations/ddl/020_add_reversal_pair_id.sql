@@ -0,0 +1,11 @@
+-- Reversal Pairs
+-- Both transactions of a pair move the same funds, so a reversal records the
+-- pair ID of the reversed transaction and a pair is reversed at most once,
+-- through either of its transactions. Reversals recorded before keep a NULL
+-- pair ID and only block their own transaction
+
+ALTER TABLE reversals ADD COLUMN IF NOT EXISTS pair_id VARCHAR(36);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reversals_pair_id ON reversals(pair_id);
+
+COMMENT ON COLUMN reversals.pair_id IS 'Pair ID of the reversed transaction, unique so that either transaction of a pair reverses it once';
@@ -0,0 +1,18 @@
+-- Spending Records
+-- The day on which the wallet service counted an outgoing transaction pair in
+-- the spending usage of its sender. The ledger stamps the pair with its own
+-- time when the outbox delivers it, which may fall on a later day, so a
+-- reversal or rejection releases the usage from the recorded day. Pairs spent
+-- before have no record and are released from the ledger time
+
+CREATE TABLE IF NOT EXISTS spending_records (
+    pair_id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    day DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE spending_records IS 'UTC day on which each outgoing transaction pair was counted as spending usage';
+COMMENT ON COLUMN spending_records.pair_id IS 'Pair ID of the outgoing withdrawal or transfer';
+COMMENT ON COLUMN spending_records.user_id IS 'Sending wallet whose usage counted the pair';
+COMMENT ON COLUMN spending_records.day IS 'Usage day the pair is released from when it is reversed or rejected';
@@ -0,0 +1,29 @@
+-- Legacy Transaction IDs
+-- Transactions recorded before public IDs were first given a public ID made
+-- of a zero timestamp followed by their serial ID, which the wallet service
+-- derived from the serial IDs it stored. The transaction service gives them a
+-- UUIDv7 of their creation time instead, so the derived IDs are turned back
+-- into serial IDs, which the legacy ID resolver of the worker replaces with
+-- the public IDs the transaction service looks up for them
+
+-- legacy_serial_id returns the serial ID carried by a derived public ID
+CREATE OR REPLACE FUNCTION legacy_serial_id(id TEXT)
+RETURNS TEXT AS $$
+    SELECT ('x' || lpad(right(id, 12), 16, '0'))::BIT(64)::BIGINT::TEXT;
+$$ LANGUAGE SQL IMMUTABLE;
+
+UPDATE reversals
+SET transaction_id = legacy_serial_id(transaction_id)
+WHERE transaction_id LIKE '00000000-0000-7000-8000-%';
+
+-- Undelivered transactions had no ID to derive one from, only the reversed
+-- transactions of outbox entries carry a derived ID
+UPDATE outbox_entries
+SET payload = jsonb_set(payload, '{debit,reversal_of}', to_jsonb(legacy_serial_id(payload #>> '{debit,reversal_of}')))
+WHERE status <> 'delivered' AND payload #>> '{debit,reversal_of}' LIKE '00000000-0000-7000-8000-%';
+
+UPDATE outbox_entries
+SET payload = jsonb_set(payload, '{credit,reversal_of}', to_jsonb(legacy_serial_id(payload #>> '{credit,reversal_of}')))
+WHERE status <> 'delivered' AND payload #>> '{credit,reversal_of}' LIKE '00000000-0000-7000-8000-%';
+
+DROP FUNCTION IF EXISTS legacy_transaction_public_id(BIGINT);
@@ -0,0 +1,10 @@
+-- Provider Currency Balances
+-- Description: Funds the deposit provider in every supported currency besides
+-- its USD base currency so that deposits can be made in any of them
+
+INSERT INTO wallet_balances (wallet_id, currency, balance, created_at, updated_at)
+SELECT w.id, c.currency, 999999999999, NOW(), NOW()
+FROM wallets w
+CROSS JOIN (VALUES ('EUR'), ('GBP'), ('BDT'), ('INR'), ('SGD'), ('JPY'), ('KRW'), ('KWD'), ('BHD')) AS c(currency)
+WHERE w.user_id = 'deposit-provider-master'
+ON CONFLICT (wallet_id, currency) DO NOTHING;